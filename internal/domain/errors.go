@@ -7,8 +7,12 @@ var (
 	ErrDuplicateEmail        = errors.New("email already exists")
 	ErrDuplicateAbbreviation = errors.New("abbreviation already exists")
 	ErrReservedAbbreviation  = errors.New("abbreviation is reserved")
+	ErrDuplicateTag          = errors.New("tag already exists")
 	ErrUnauthorized          = errors.New("unauthorized")
 	ErrInvalidInput          = errors.New("invalid input")
 	ErrPatternLocked         = errors.New("pattern is locked")
 	ErrAlreadySaved          = errors.New("pattern already saved")
+	ErrShareExpired          = errors.New("share link has expired")
+	ErrShareLimitReached     = errors.New("share link has reached its save limit")
+	ErrShareViewOnly         = errors.New("share link is view only")
 )
@@ -13,21 +13,51 @@ const (
 )
 
 type Pattern struct {
-	ID                int64
-	UserID            int64
-	Name              string
-	Description       string
-	PatternType       PatternType
-	HookSize          string
-	YarnWeight        string
-	Difficulty        string
-	Locked            bool
-	SharedFromUserID  *int64
-	SharedFromName    string
-	PatternStitches   []PatternStitch
-	InstructionGroups []InstructionGroup
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	ID                    int64
+	UserID                int64
+	Name                  string
+	Description           string
+	PatternType           PatternType
+	HookSize              string
+	YarnWeight            string
+	Difficulty            string
+	Locked                bool
+	SharedFromUserID      *int64
+	SharedFromName        string
+	SharePermission       SharePermission // Permission a received copy was saved under; "" for the user's own patterns
+	SharedFromPatternID   *int64          // Pattern a received copy was saved from; nil once that pattern is deleted
+	SharedFromShareID     *int64          // Share a received copy was saved through; nil once that share is revoked
+	SharedSourceUpdatedAt *time.Time      // The source's UpdatedAt as of the copy's content
+	Sizes                 []string        // Size names for graded patterns, e.g. "S", "M", "L"; empty for a single size
+	Pieces                []PatternPiece  // Separately made pieces, e.g. head, arms; empty if the pattern is one piece
+	Colors                []PatternColor  // Yarn color palette; empty for single-color patterns
+	PatternStitches       []PatternStitch
+	InstructionGroups     []InstructionGroup
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// SavedFrom reports whether the pattern is a copy saved from source. Copies
+// saved before their source was tracked are matched by owner and name.
+func (p *Pattern) SavedFrom(source *Pattern) bool {
+	if p.SharedFromPatternID != nil {
+		return *p.SharedFromPatternID == source.ID
+	}
+	return p.SharedFromUserID != nil && *p.SharedFromUserID == source.UserID && p.Name == source.Name
+}
+
+// SourceUpdated reports whether a received snapshot's source pattern, last
+// changed at sourceUpdatedAt, has changed since the copy's content was taken
+// from it. Editable forks are never updated from their source.
+func (p *Pattern) SourceUpdated(sourceUpdatedAt time.Time) bool {
+	return p.IsReceivedSnapshot() && p.SharedSourceUpdatedAt != nil && sourceUpdatedAt.After(*p.SharedSourceUpdatedAt)
+}
+
+// IsReceivedSnapshot reports whether the pattern is a copy saved from
+// another user's share as a snapshot, which can't be changed. Editable forks
+// are received copies too, but belong to the user to change.
+func (p *Pattern) IsReceivedSnapshot() bool {
+	return p.SharedFromUserID != nil && p.SharePermission != SharePermissionSaveEditable
 }
 
 type PatternStitch struct {
@@ -37,18 +67,47 @@ type PatternStitch struct {
 	Name            string
 	Description     string
 	Category        string
+	Consumes        int    // See Stitch.Consumes
+	Produces        int    // See Stitch.Produces
+	UKAbbreviation  string // See Stitch.UKAbbreviation
 	LibraryStitchID *int64
 }
 
+// PatternPiece is a separately made piece of a pattern, such as the head or
+// an arm of an amigurumi. Its instruction groups are worked MakeCount times.
+type PatternPiece struct {
+	ID        int64
+	PatternID int64
+	SortOrder int
+	Name      string
+	Notes     string
+	MakeCount int
+}
+
+// PatternColor is a yarn color in a pattern's palette, referred to in
+// pattern text by its label, e.g. "with B:".
+type PatternColor struct {
+	ID        int64
+	PatternID int64
+	SortOrder int
+	Label     string // Short label used in pattern text, e.g. "A", "MC"
+	Name      string // Optional yarn color name, e.g. "Cream"
+	Hex       string // Optional swatch color as "#rrggbb"
+}
+
 type InstructionGroup struct {
-	ID            int64
-	PatternID     int64
-	SortOrder     int
-	Label         string
-	RepeatCount   int
-	StitchEntries []StitchEntry
-	ExpectedCount *int
-	Notes         string
+	ID                 int64
+	PatternID          int64
+	SortOrder          int
+	PieceIndex         int // Index into Pattern.Pieces; groups of a piece are contiguous. Ignored when there are no pieces
+	Label              string
+	RepeatCount        int
+	SizeRepeatCounts   []int // Per-size RepeatCount, aligned with Pattern.Sizes; nil when not graded
+	StitchEntries      []StitchEntry
+	RepeatBlocks       []RepeatBlock
+	ExpectedCount      *int
+	SizeExpectedCounts []int // Per-size ExpectedCount, aligned with Pattern.Sizes; nil when not graded
+	Notes              string
 }
 
 type StitchEntry struct {
@@ -57,16 +116,210 @@ type StitchEntry struct {
 	SortOrder          int
 	PatternStitchID    int64
 	Count              int
+	SizeCounts         []int // Per-size Count, aligned with Pattern.Sizes; nil when not graded
 	IntoStitch         string
 	RepeatCount        int
+	SizeRepeatCounts   []int // Per-size RepeatCount, aligned with Pattern.Sizes; nil when not graded
+	ColorIndex         *int  // Index into Pattern.Colors to work this entry in; nil keeps the current color
+}
+
+// PieceAt returns the piece the group at index gi belongs to, or nil if the
+// pattern has no pieces.
+func (p *Pattern) PieceAt(gi int) *PatternPiece {
+	if gi < 0 || gi >= len(p.InstructionGroups) {
+		return nil
+	}
+	pi := p.InstructionGroups[gi].PieceIndex
+	if pi < 0 || pi >= len(p.Pieces) {
+		return nil
+	}
+	return &p.Pieces[pi]
+}
+
+// MakeCount returns how many times the group at index gi is worked because
+// of the piece it belongs to (1 when the pattern has no pieces).
+func (p *Pattern) MakeCount(gi int) int {
+	if piece := p.PieceAt(gi); piece != nil && piece.MakeCount > 1 {
+		return piece.MakeCount
+	}
+	return 1
+}
+
+// PieceSpan returns the indices of the first and last groups of the piece
+// that the group at index gi belongs to. Without pieces every group is its
+// own span.
+func (p *Pattern) PieceSpan(gi int) (first, last int) {
+	first, last = gi, gi
+	if len(p.Pieces) == 0 || gi < 0 || gi >= len(p.InstructionGroups) {
+		return first, last
+	}
+	pi := p.InstructionGroups[gi].PieceIndex
+	for first > 0 && p.InstructionGroups[first-1].PieceIndex == pi {
+		first--
+	}
+	for last < len(p.InstructionGroups)-1 && p.InstructionGroups[last+1].PieceIndex == pi {
+		last++
+	}
+	return first, last
+}
+
+// SizeValue returns the value of a graded number for the size at index si.
+// Numbers that aren't graded (nil sizes) use base for every size.
+func SizeValue(base int, sizes []int, si int) int {
+	if si >= 0 && si < len(sizes) {
+		return sizes[si]
+	}
+	return base
+}
+
+// IsGraded reports whether the pattern is written for more than one size.
+func (p *Pattern) IsGraded() bool {
+	return len(p.Sizes) > 0
+}
+
+// ForSize returns a copy of the pattern with every graded number resolved to
+// the size at index si. The copy is a plain single-size pattern, so counting,
+// rendering and navigation code needs no knowledge of sizes. Patterns that
+// aren't graded are returned as is.
+func (p *Pattern) ForSize(si int) *Pattern {
+	if !p.IsGraded() {
+		return p
+	}
+
+	sized := *p
+	sized.Sizes = nil
+	sized.InstructionGroups = make([]InstructionGroup, len(p.InstructionGroups))
+	for gi, g := range p.InstructionGroups {
+		sized.InstructionGroups[gi] = g.ForSize(si)
+	}
+	return &sized
+}
+
+// ForSize returns a copy of the group with its graded numbers, and those of
+// its stitch entries, resolved to the size at index si.
+func (g InstructionGroup) ForSize(si int) InstructionGroup {
+	sized := g
+	sized.RepeatCount = SizeValue(g.RepeatCount, g.SizeRepeatCounts, si)
+	sized.SizeRepeatCounts = nil
+	if g.ExpectedCount != nil {
+		expected := SizeValue(*g.ExpectedCount, g.SizeExpectedCounts, si)
+		sized.ExpectedCount = &expected
+	}
+	sized.SizeExpectedCounts = nil
+
+	sized.StitchEntries = make([]StitchEntry, len(g.StitchEntries))
+	for ei, e := range g.StitchEntries {
+		e.Count = SizeValue(e.Count, e.SizeCounts, si)
+		e.RepeatCount = SizeValue(e.RepeatCount, e.SizeRepeatCounts, si)
+		e.SizeCounts = nil
+		e.SizeRepeatCounts = nil
+		sized.StitchEntries[ei] = e
+	}
+	return sized
+}
+
+// BracketStyle controls how a repeat block is written in pattern text.
+type BracketStyle string
+
+const (
+	BracketParen    BracketStyle = "paren"    // (sc, inc) x3
+	BracketSquare   BracketStyle = "square"   // [2 dc, ch 2, 2 dc] in next sp
+	BracketAsterisk BracketStyle = "asterisk" // *sc, inc* repeat 6 times
+)
+
+// RepeatBlock brackets a contiguous run of stitch entries within an
+// instruction group so they are worked together RepeatCount times.
+// Blocks may be nested inside one another but never partially overlap,
+// so the blocks of a group form a tree over its entries.
+type RepeatBlock struct {
+	ID                 int64
+	InstructionGroupID int64
+	StartEntry         int // Index into StitchEntries of the first covered entry
+	EndEntry           int // Index into StitchEntries of the last covered entry (inclusive)
+	RepeatCount        int
+	Bracket            BracketStyle
+	IntoStitch         string
+}
+
+// Contains reports whether the block covers the entry at index ei.
+func (b RepeatBlock) Contains(ei int) bool {
+	return b.StartEntry <= ei && ei <= b.EndEntry
+}
+
+// Encloses reports whether other lies entirely inside b (and is not b's exact span).
+func (b RepeatBlock) Encloses(other RepeatBlock) bool {
+	if b.StartEntry == other.StartEntry && b.EndEntry == other.EndEntry {
+		return false
+	}
+	return b.StartEntry <= other.StartEntry && other.EndEntry <= b.EndEntry
+}
+
+// BlockMultiplier returns how many times the entry at index ei is worked per
+// group repeat because of the repeat blocks enclosing it.
+func (g *InstructionGroup) BlockMultiplier(ei int) int {
+	m := 1
+	for _, b := range g.RepeatBlocks {
+		if b.Contains(ei) {
+			m *= b.RepeatCount
+		}
+	}
+	return m
 }
 
 // PatternFilter holds search/filter/sort options for pattern listing.
 type PatternFilter struct {
-	Query      string // Text search on name and description
-	Type       string // "round", "row", or "" for all
-	Difficulty string // "Beginner", "Intermediate", "Advanced", "Expert", or "" for all
-	Sort       string // "updated" (default), "name", "created", "stitches"
+	Query      string   // Full-text search on name, description, group labels and notes, and stitches
+	Uses       []string // Abbreviations or names of stitches the pattern must use
+	Include    []int64  // Library stitch IDs the pattern must use
+	Exclude    []int64  // Library stitch IDs the pattern must not use
+	KnownOnly  bool     // Only patterns whose stitches are all ones the user knows
+	Tags       []int64  // IDs of tags the pattern must have, all of them
+	TagNames   []string // Names of tags the pattern must have, for listings across users
+	Type       string   // "round", "row", or "" for all
+	Difficulty string   // "Beginner", "Intermediate", "Advanced", "Expert", or "" for all
+	Sort       string   // "relevance" (default with a query), "updated" (default otherwise), "name", "created", "stitches"
+}
+
+// HasStitchFilter reports whether the filter restricts patterns by the
+// stitches they use.
+func (f PatternFilter) HasStitchFilter() bool {
+	return len(f.Uses) > 0 || len(f.Include) > 0 || len(f.Exclude) > 0 || f.KnownOnly
+}
+
+// Markers around the matched terms in PatternSummary.Snippet.
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+// Page sizes for pattern summary listings.
+const (
+	DefaultPatternPageSize = 24
+	MaxPatternPageSize     = 100
+)
+
+// PatternPage requests one page of pattern summaries. After is the Next
+// cursor of the previous page, or "" for the first page. Cursors are only
+// valid for the sort they were returned for.
+type PatternPage struct {
+	Limit int
+	After string
+}
+
+// PageSize returns the page's limit, defaulting to DefaultPatternPageSize and
+// capped at MaxPatternPageSize.
+func (p PatternPage) PageSize() int {
+	if p.Limit <= 0 {
+		return DefaultPatternPageSize
+	}
+	return min(p.Limit, MaxPatternPageSize)
+}
+
+// PatternSummaryPage is one page of pattern summaries. Next is the cursor
+// for the following page, or "" when there are no more.
+type PatternSummaryPage struct {
+	Summaries []PatternSummary
+	Next      string
 }
 
 // PatternSummary holds lightweight pattern metadata for list views.
@@ -82,10 +335,14 @@ type PatternSummary struct {
 	Locked           bool
 	SharedFromUserID *int64
 	SharedFromName   string
+	SharePermission  SharePermission
+	UpdateAvailable  bool // A received snapshot whose source has changed since it was saved
 	GroupCount       int
-	StitchCount      int
+	StitchesWorked   int // Stitches worked, not produced: an inc counts once
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	Snippet          string // Matching text from a search, with SnippetMatchStart/End around matched terms
+	Tags             []Tag  // Sorted by name
 }
 
 type PatternRepository interface {
@@ -94,11 +351,14 @@ type PatternRepository interface {
 	GetNamesByIDs(ctx context.Context, ids []int64) (map[int64]string, error)
 	ListByUser(ctx context.Context, userID int64) ([]Pattern, error)
 	ListSharedWithUser(ctx context.Context, userID int64) ([]Pattern, error)
-	ListSummaryByUser(ctx context.Context, userID int64) ([]PatternSummary, error)
-	ListSummarySharedWithUser(ctx context.Context, userID int64) ([]PatternSummary, error)
-	SearchSummaryByUser(ctx context.Context, userID int64, filter PatternFilter) ([]PatternSummary, error)
+	ListSummaryByUser(ctx context.Context, userID int64, page PatternPage) (PatternSummaryPage, error)
+	ListSummarySharedWithUser(ctx context.Context, userID int64, page PatternPage) (PatternSummaryPage, error)
+	SearchSummaryByUser(ctx context.Context, userID int64, filter PatternFilter, page PatternPage) (PatternSummaryPage, error)
+	SearchPublishedSummaries(ctx context.Context, viewerID int64, filter PatternFilter, page PatternPage) (PatternSummaryPage, error)
 	Update(ctx context.Context, pattern *Pattern) error
+	UpdateWithVersion(ctx context.Context, pattern *Pattern, version *PatternVersion, keepVersions int) error
 	Delete(ctx context.Context, id int64) error
 	Duplicate(ctx context.Context, id int64, newUserID int64) (*Pattern, error)
-	DuplicateAsShared(ctx context.Context, id int64, newUserID int64, sharedFromUserID int64, sharedFromName string) (*Pattern, error)
+	DuplicateAsShared(ctx context.Context, share *PatternShare, newUserID int64, sharedFromUserID int64, sharedFromName string, withTags bool) (*Pattern, error)
+	UpdateFromSource(ctx context.Context, pattern *Pattern, source *Pattern) error
 }
@@ -10,11 +10,14 @@ type WorkSession struct {
 	ID                  int64
 	PatternID           int64
 	UserID              int64
-	CurrentGroupIndex   int // Which instruction group the user is on (0-based)
-	CurrentGroupRepeat  int // Which repeat of the group they're on (0-based)
-	CurrentStitchIndex  int // Which stitch entry within the group (0-based)
-	CurrentStitchRepeat int // Which repeat of the stitch entry (0-based)
-	CurrentStitchCount  int // Which individual stitch within the count (0-based)
+	SizeIndex           int   // Which of the pattern's sizes is being followed (0-based)
+	CurrentPieceCopy    int   // Which copy of the current piece the user is on (0-based)
+	CurrentGroupIndex   int   // Which instruction group the user is on (0-based)
+	CurrentGroupRepeat  int   // Which repeat of the group they're on (0-based)
+	CurrentStitchIndex  int   // Which stitch entry within the group (0-based)
+	CurrentStitchRepeat int   // Which repeat of the stitch entry (0-based)
+	CurrentStitchCount  int   // Which individual stitch within the count (0-based)
+	BlockRepeats        []int // Which repeat of each enclosing repeat block (0-based, outermost first)
 	Status              string
 	StartedAt           time.Time
 	LastActivityAt      time.Time
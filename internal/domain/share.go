@@ -10,6 +10,16 @@ type ShareType string
 const (
 	ShareTypeGlobal ShareType = "global"
 	ShareTypeEmail  ShareType = "email"
+	ShareTypePublic ShareType = "public" // Published to the gallery; at most one per pattern
+)
+
+// SharePermission is what a share link lets its recipients do.
+type SharePermission string
+
+const (
+	SharePermissionView         SharePermission = "view"          // View only; no saving
+	SharePermissionSaveLocked   SharePermission = "save_locked"   // Save a locked snapshot
+	SharePermissionSaveEditable SharePermission = "save_editable" // Save an editable fork, attributed to the owner
 )
 
 type PatternShare struct {
@@ -18,15 +28,46 @@ type PatternShare struct {
 	Token          string
 	ShareType      ShareType
 	RecipientEmail string
+	Permission     SharePermission
+	ExpiresAt      *time.Time // nil for links that never expire
+	MaxSaves       int        // 0 for no limit
 	CreatedAt      time.Time
 }
 
+// Expired reports whether the share's link has expired at now.
+func (s *PatternShare) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
+
+type ShareEventType string
+
+const (
+	ShareEventView ShareEventType = "view"
+	ShareEventSave ShareEventType = "save"
+)
+
+// ShareEvent records a share link being opened or saved from.
+type ShareEvent struct {
+	ID        int64
+	ShareID   int64
+	Type      ShareEventType
+	UserID    *int64 // nil for visitors who weren't logged in
+	UserName  string // Display name of UserID, filled in when listing
+	CreatedAt time.Time
+}
+
 type PatternShareRepository interface {
 	Create(ctx context.Context, share *PatternShare) error
 	GetByID(ctx context.Context, id int64) (*PatternShare, error)
 	GetByToken(ctx context.Context, token string) (*PatternShare, error)
 	ListByPattern(ctx context.Context, patternID int64) ([]PatternShare, error)
 	Delete(ctx context.Context, id int64) error
-	DeleteAllByPattern(ctx context.Context, patternID int64) error
+	// DeleteNonPublicByPattern deletes a pattern's global and email shares,
+	// leaving its gallery publication in place.
+	DeleteNonPublicByPattern(ctx context.Context, patternID int64) error
 	HasSharesByPatternIDs(ctx context.Context, patternIDs []int64) (map[int64]bool, error)
+	UpdateLimits(ctx context.Context, id int64, expiresAt *time.Time, maxSaves int) error
+	RecordEvent(ctx context.Context, event *ShareEvent) error
+	CountEvents(ctx context.Context, shareID int64, eventType ShareEventType) (int, error)
+	ListEventsByPattern(ctx context.Context, patternID int64) (map[int64][]ShareEvent, error)
 }
@@ -7,16 +7,28 @@ import (
 
 // Stitch represents a crochet stitch type, either predefined or user-created.
 type Stitch struct {
-	ID           int64
-	Abbreviation string
-	Name         string
-	Description  string
-	Category     string // "basic", "advanced", "decrease", "increase", "post", "specialty", "action"
-	IsCustom     bool
-	UserID       *int64
-	CreatedAt    time.Time
+	ID             int64
+	Abbreviation   string
+	Name           string
+	Description    string
+	Category       string // "basic", "advanced", "decrease", "increase", "post", "specialty", "action"
+	Consumes       int    // Stitches of the previous round/row worked into (sc2tog = 2, ch = 0)
+	Produces       int    // Stitches made for the next round/row to work into (inc = 2, sk = 0)
+	UKAbbreviation string // Abbreviation in UK terms, for custom stitches; empty if written the same
+	IsCustom       bool
+	UserID         *int64
+	CreatedAt      time.Time
 }
 
+// Terminology is a convention for naming crochet stitches. The same
+// abbreviation can mean different stitches: UK "dc" is US "sc".
+type Terminology string
+
+const (
+	TerminologyUS Terminology = "us"
+	TerminologyUK Terminology = "uk"
+)
+
 // StitchRepository defines persistence operations for stitches.
 type StitchRepository interface {
 	ListPredefined(ctx context.Context) ([]Stitch, error)
@@ -26,4 +38,6 @@ type StitchRepository interface {
 	Create(ctx context.Context, stitch *Stitch) error
 	Update(ctx context.Context, stitch *Stitch) error
 	Delete(ctx context.Context, id int64) error
+	ListKnownIDs(ctx context.Context, userID int64) ([]int64, error)
+	SetKnown(ctx context.Context, userID, stitchID int64, known bool) error
 }
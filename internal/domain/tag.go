@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Tag is a user-defined label for grouping patterns. Names are unique per
+// user, ignoring case.
+type Tag struct {
+	ID           int64
+	UserID       int64
+	Name         string
+	PatternCount int // Patterns with the tag; only set in ListByUser results
+	CreatedAt    time.Time
+}
+
+type TagRepository interface {
+	Create(ctx context.Context, tag *Tag) error
+	GetByID(ctx context.Context, id int64) (*Tag, error)
+	GetByName(ctx context.Context, userID int64, name string) (*Tag, error)
+	ListByUser(ctx context.Context, userID int64) ([]Tag, error)
+	ListByPatternIDs(ctx context.Context, patternIDs []int64) (map[int64][]Tag, error)
+	Rename(ctx context.Context, id int64, name string) error
+	Delete(ctx context.Context, id int64) error
+	AddToPatterns(ctx context.Context, tagID int64, patternIDs []int64) error
+	RemoveFromPatterns(ctx context.Context, tagID int64, patternIDs []int64) error
+}
+
+// Collection is a named, ordered list of a user's patterns, e.g.
+// "Christmas 2026 gifts". A pattern can be in any number of collections.
+type Collection struct {
+	ID           int64
+	UserID       int64
+	Name         string
+	Description  string
+	PatternIDs   []int64 // In the collection's order; nil in ListByUser results
+	PatternCount int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type CollectionRepository interface {
+	Create(ctx context.Context, collection *Collection) error
+	GetByID(ctx context.Context, id int64) (*Collection, error)
+	ListByUser(ctx context.Context, userID int64) ([]Collection, error)
+	Update(ctx context.Context, collection *Collection) error
+	Delete(ctx context.Context, id int64) error
+	AddPatterns(ctx context.Context, collectionID int64, patternIDs []int64) error
+	RemovePattern(ctx context.Context, collectionID, patternID int64) error
+	SetOrder(ctx context.Context, collectionID int64, patternIDs []int64) error
+}
@@ -11,6 +11,7 @@ type User struct {
 	Email        string
 	DisplayName  string
 	PasswordHash string
+	Terminology  Terminology // Stitch terms patterns are shown in
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
@@ -20,4 +21,5 @@ type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByID(ctx context.Context, id int64) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
+	UpdateTerminology(ctx context.Context, id int64, terminology Terminology) error
 }
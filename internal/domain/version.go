@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PatternVersion is a snapshot of a pattern's content, taken before each
+// change so that earlier versions can be previewed and restored. The snapshot
+// carries its own PatternStitches, so it doesn't depend on the stitch library.
+// Images belong to the live pattern and are not part of a snapshot.
+type PatternVersion struct {
+	ID        int64
+	PatternID int64
+	Version   int      // Increases by one with each snapshot of the pattern, from 1
+	Name      string   // Pattern name at the time of the snapshot
+	Snapshot  *Pattern // Full pattern content; nil in list results
+	CreatedAt time.Time
+}
+
+type PatternVersionRepository interface {
+	Create(ctx context.Context, version *PatternVersion) error
+	GetByID(ctx context.Context, id int64) (*PatternVersion, error)
+	ListByPattern(ctx context.Context, patternID int64) ([]PatternVersion, error)
+	Prune(ctx context.Context, patternID int64, keep int) error
+}
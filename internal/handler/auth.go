@@ -4,6 +4,8 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 	"github.com/msomdec/stitch-map-2/internal/service"
@@ -23,12 +25,12 @@ func NewAuthHandler(auth *service.AuthService, cookieSecure bool) *AuthHandler {
 
 // ShowLogin renders the login page.
 func (h *AuthHandler) ShowLogin(w http.ResponseWriter, r *http.Request) {
-	view.LoginPage("", "").Render(r.Context(), w)
+	view.LoginPage("", "", localPath(r.URL.Query().Get("next"))).Render(r.Context(), w)
 }
 
 // ShowRegister renders the registration page.
 func (h *AuthHandler) ShowRegister(w http.ResponseWriter, r *http.Request) {
-	view.RegisterPage("", "", "").Render(r.Context(), w)
+	view.RegisterPage("", "", "", localPath(r.URL.Query().Get("next"))).Render(r.Context(), w)
 }
 
 // HandleRegister processes the registration form submission.
@@ -43,6 +45,7 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	displayName := r.FormValue("display_name")
 	password := r.FormValue("password")
 	confirmPassword := r.FormValue("confirm_password")
+	next := localPath(r.FormValue("next"))
 
 	_, err := h.auth.Register(r.Context(), email, displayName, password, confirmPassword)
 	if err != nil {
@@ -56,10 +59,14 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 			errMsg = "An unexpected error occurred. Please try again."
 		}
 		w.WriteHeader(http.StatusUnprocessableEntity)
-		view.RegisterPage(errMsg, email, displayName).Render(r.Context(), w)
+		view.RegisterPage(errMsg, email, displayName, next).Render(r.Context(), w)
 		return
 	}
 
+	if next != "" {
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(next), http.StatusSeeOther)
+		return
+	}
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
@@ -73,6 +80,7 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 
 	email := r.FormValue("email")
 	password := r.FormValue("password")
+	next := localPath(r.FormValue("next"))
 
 	token, err := h.auth.Login(r.Context(), email, password)
 	if err != nil {
@@ -84,7 +92,7 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 			errMsg = "An unexpected error occurred. Please try again."
 		}
 		w.WriteHeader(http.StatusUnauthorized)
-		view.LoginPage(errMsg, email).Render(r.Context(), w)
+		view.LoginPage(errMsg, email, next).Render(r.Context(), w)
 		return
 	}
 
@@ -98,6 +106,10 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		MaxAge:   86400, // 24 hours
 	})
 
+	if next != "" {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
@@ -115,3 +127,60 @@ func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
+
+// ShowSettings renders the account settings page.
+func (h *AuthHandler) ShowSettings(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	view.SettingsPage(user.DisplayName, user.Terminology, r.URL.Query().Get("saved") == "1", "").Render(r.Context(), w)
+}
+
+// HandleUpdateSettings saves the user's preferences.
+func (h *AuthHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	terminology := domain.Terminology(r.FormValue("terminology"))
+	if err := h.auth.SetTerminology(r.Context(), user.ID, terminology); err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			view.SettingsPage(user.DisplayName, user.Terminology, false, err.Error()).Render(r.Context(), w)
+			return
+		}
+		slog.Error("update terminology", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+}
+
+// localPath returns next if it is a path on this site, or "" otherwise, so
+// that a crafted link can't send users elsewhere after logging in. Browsers
+// drop control characters and treat backslashes as slashes in redirects, so
+// any of those are refused too.
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		return ""
+	}
+	if strings.ContainsFunc(next, func(r rune) bool { return r < 0x20 || r == 0x7f || r == '\\' }) {
+		return ""
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return ""
+	}
+	return next
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/service"
+	"github.com/msomdec/stitch-map-2/internal/view"
+)
+
+// CollectionHandler handles HTTP requests for pattern collections.
+type CollectionHandler struct {
+	patterns *service.PatternService
+}
+
+// NewCollectionHandler creates a new CollectionHandler.
+func NewCollectionHandler(patterns *service.PatternService) *CollectionHandler {
+	return &CollectionHandler{patterns: patterns}
+}
+
+// HandleList renders the user's collections.
+// GET /collections
+func (h *CollectionHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	collections, err := h.patterns.ListCollections(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list collections", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.CollectionsPage(user.DisplayName, collections, "").Render(r.Context(), w)
+}
+
+// HandleCreate creates an empty collection and opens it.
+// POST /collections
+func (h *CollectionHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	c := &domain.Collection{
+		UserID:      user.ID,
+		Name:        r.FormValue("name"),
+		Description: r.FormValue("description"),
+	}
+	if err := h.patterns.CreateCollection(r.Context(), c); err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			collections, _ := h.patterns.ListCollections(r.Context(), user.ID)
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			view.CollectionsPage(user.DisplayName, collections, err.Error()).Render(r.Context(), w)
+			return
+		}
+		slog.Error("create collection", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+strconv.FormatInt(c.ID, 10), http.StatusSeeOther)
+}
+
+// HandleView renders a collection with its patterns in order.
+// GET /collections/{id}
+func (h *CollectionHandler) HandleView(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.patterns.GetCollection(r.Context(), user.ID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get collection", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderCollection(w, r, user, c, "")
+}
+
+// HandleUpdate changes a collection's name and description.
+// POST /collections/{id}/edit
+func (h *CollectionHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	c := &domain.Collection{ID: id, Name: r.FormValue("name"), Description: r.FormValue("description")}
+	if err := h.patterns.UpdateCollection(r.Context(), user.ID, c); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			existing, getErr := h.patterns.GetCollection(r.Context(), user.ID, id)
+			if getErr != nil {
+				http.Error(w, "Not Found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.renderCollection(w, r, user, existing, err.Error())
+			return
+		}
+		slog.Error("update collection", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+// HandleDelete deletes a collection, keeping its patterns.
+// POST /collections/{id}/delete
+func (h *CollectionHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.DeleteCollection(r.Context(), user.ID, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("delete collection", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/collections", http.StatusSeeOther)
+}
+
+// HandleRemovePattern takes a pattern out of a collection.
+// POST /collections/{id}/patterns/{patternID}/remove
+func (h *CollectionHandler) HandleRemovePattern(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	patternID, err := strconv.ParseInt(r.PathValue("patternID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.RemoveFromCollection(r.Context(), user.ID, id, patternID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("remove pattern from collection", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+// HandleReorder puts a collection's patterns in the order of the submitted
+// pattern IDs.
+// POST /collections/{id}/order
+func (h *CollectionHandler) HandleReorder(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.ReorderCollection(r.Context(), user.ID, id, formInt64s(r, "pattern")); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("reorder collection", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+func (h *CollectionHandler) renderCollection(w http.ResponseWriter, r *http.Request, user *domain.User, c *domain.Collection, errMsg string) {
+	names, err := h.patterns.GetNamesByIDs(r.Context(), c.PatternIDs)
+	if err != nil {
+		slog.Error("get collection pattern names", "error", err)
+		names = map[int64]string{}
+	}
+	view.CollectionPage(user.DisplayName, c, names, errMsg).Render(r.Context(), w)
+}
+
+// formInt64s parses every value of a repeated form field as an ID, skipping
+// malformed values.
+func formInt64s(r *http.Request, key string) []int64 {
+	var ids []int64
+	for _, v := range r.PostForm[key] {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/view"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// HandleGallery renders the public gallery of published patterns.
+// GET /gallery
+func (h *ShareHandler) HandleGallery(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter := galleryFilterFromQuery(r)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	patterns, owners, err := h.shares.SearchGallery(r.Context(), user.ID, filter, domain.PatternPage{Limit: limit})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("search gallery", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.GalleryPage(user.DisplayName, user.ID, patterns, owners, filter, limit).Render(r.Context(), w)
+}
+
+// HandleGalleryMore returns an SSE response that appends the next page of
+// the gallery and replaces its load-more control.
+// GET /gallery/more
+func (h *ShareHandler) HandleGalleryMore(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter := galleryFilterFromQuery(r)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page := domain.PatternPage{Limit: limit, After: r.URL.Query().Get("after")}
+
+	result, owners, err := h.shares.SearchGallery(r.Context(), user.ID, filter, page)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("search gallery", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.PatchElementTempl(
+		view.GalleryCards(result.Summaries, owners, user.ID),
+		datastar.WithSelectorID("gallery-cards"),
+		datastar.WithModeAppend(),
+	)
+	sse.PatchElementTempl(view.LoadMoreGallery(result.Next, filter, limit))
+}
+
+// HandleViewPublished opens a published pattern's preview page.
+// GET /gallery/{id}
+func (h *ShareHandler) HandleViewPublished(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	share, err := h.shares.PublishedShare(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get published share", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/s/"+share.Token, http.StatusSeeOther)
+}
+
+// HandleSaveFromGallery saves a published pattern to the viewer's library.
+// POST /gallery/{id}/save
+func (h *ShareHandler) HandleSaveFromGallery(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.shares.SaveFromGallery(r.Context(), user.ID, id, r.FormValue("tags") == "1")
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrAlreadySaved) {
+			http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Redirect(w, r, "/gallery", http.StatusSeeOther)
+			return
+		}
+		slog.Error("save pattern from gallery", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+}
+
+// HandlePublish publishes a pattern to the gallery.
+// POST /patterns/{id}/publish
+func (h *ShareHandler) HandlePublish(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.shares.Publish(r.Context(), user.ID, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, "This pattern cannot be published.", http.StatusBadRequest)
+			return
+		}
+		slog.Error("publish pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+// HandleUnpublish removes a pattern from the gallery.
+// POST /patterns/{id}/unpublish
+func (h *ShareHandler) HandleUnpublish(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.shares.Unpublish(r.Context(), user.ID, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("unpublish pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+// galleryFilterFromQuery reads the gallery's filter from the URL. Its tags
+// are names, as tag IDs belong to each pattern's owner.
+func galleryFilterFromQuery(r *http.Request) domain.PatternFilter {
+	filter := patternFilterFromQuery(r)
+	filter.Tags = nil
+	filter.TagNames = r.URL.Query()["tag"]
+	return filter
+}
@@ -3,6 +3,9 @@ package handler_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"html"
 	"image"
 	"image/color"
 	"image/jpeg"
@@ -13,11 +16,15 @@ import (
 	"net/http/cookiejar"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/msomdec/stitch-map-2/internal/domain"
 	"github.com/msomdec/stitch-map-2/internal/handler"
+	"github.com/msomdec/stitch-map-2/internal/service"
 )
 
 func TestIntegration_RegisterLoginDashboardLogout(t *testing.T) {
@@ -392,17 +399,16 @@ func TestIntegration_StitchLibrary_BrowseCreateEditDelete(t *testing.T) {
 	// 4. Delete the custom stitch (find its ID from the page).
 	// We need to extract the stitch ID. Let's find the delete form action.
 	// The form action is /stitches/{id}/delete.
-	idx := strings.Index(body, "/stitches/")
+	idx := strings.Index(body, "/delete")
 	if idx == -1 {
-		t.Fatal("expected to find /stitches/ in page body for delete form")
+		t.Fatal("expected to find /delete in page body for delete form")
 	}
-	// Extract the path segment after /stitches/ up to /delete.
-	rest := body[idx+len("/stitches/"):]
-	before, _, ok := strings.Cut(rest, "/")
-	if !ok {
-		t.Fatal("expected /delete path after stitch ID")
+	// Extract the stitch ID between /stitches/ and /delete.
+	start := strings.LastIndex(body[:idx], "/stitches/")
+	if start == -1 {
+		t.Fatal("expected /stitches/ before the /delete path")
 	}
-	stitchID := before
+	stitchID := body[start+len("/stitches/") : idx]
 
 	resp, err = client.PostForm(srv.URL+"/stitches/"+stitchID+"/delete", nil)
 	if err != nil {
@@ -429,6 +435,71 @@ func TestIntegration_StitchLibrary_BrowseCreateEditDelete(t *testing.T) {
 	}
 }
 
+func TestIntegration_StitchLibrary_RejectsBadArithmetic(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"arithmetic@example.com"},
+		"display_name":     {"Arithmetic User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"arithmetic@example.com"},
+		"password": {"password123"},
+	})
+
+	tests := []struct {
+		field, value, want string
+	}{
+		{"consumes", "-1", "stitches used must be a whole number"},
+		{"produces", "two", "stitches made must be a whole number"},
+	}
+	for _, tt := range tests {
+		resp, err := client.PostForm(srv.URL+"/stitches", url.Values{
+			"abbreviation": {"bad"},
+			"name":         {"Bad Arithmetic"},
+			"category":     {"custom"},
+			tt.field:       {tt.value},
+		})
+		if err != nil {
+			t.Fatalf("POST /stitches: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("%s=%s: expected 422, got %d", tt.field, tt.value, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), tt.want) {
+			t.Errorf("%s=%s: expected %q in the response", tt.field, tt.value, tt.want)
+		}
+	}
+
+	resp, err := client.Get(srv.URL + "/stitches")
+	if err != nil {
+		t.Fatalf("GET /stitches: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "Bad Arithmetic") {
+		t.Fatal("a stitch with bad arithmetic should not be saved")
+	}
+}
+
 func TestIntegration_StitchLibrary_FilterByCategory(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
@@ -760,6 +831,81 @@ func TestIntegration_Pattern_ViewWithTextPreview(t *testing.T) {
 	if !strings.Contains(body, "inc") {
 		t.Fatal("pattern view should contain stitch abbreviation 'inc'")
 	}
+	// 6 sc → inc ×6 is consistent, so no lint warnings are shown.
+	if strings.Contains(body, `id="lint-report"`) {
+		t.Fatal("pattern view should not show lint warnings for a consistent pattern")
+	}
+}
+
+func TestIntegration_Pattern_LintWarnings(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"lint@example.com"},
+		"display_name":     {"Lint Tester"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"lint@example.com"},
+		"password": {"password123"},
+	})
+
+	// Round 2 makes 12 stitches but claims 14.
+	resp, err := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Miscounted Ball"},
+		"text":   {"Rnd 1: 6 sc in MR (6)\nRnd 2: 6 inc (14)"},
+		"action": {"save"},
+	})
+	if err != nil {
+		t.Fatalf("POST /patterns/import/text: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("save: expected 303, got %d", resp.StatusCode)
+	}
+	patternURL := resp.Header.Get("Location")
+
+	for _, path := range []string{patternURL, patternURL + "/edit"} {
+		resp, err = client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		body := string(bodyBytes)
+
+		if !strings.Contains(body, `id="lint-report"`) {
+			t.Fatalf("GET %s: expected the lint report for a miscounted pattern", path)
+		}
+		if !strings.Contains(body, "Pattern check found 1 possible problem") {
+			t.Errorf("GET %s: expected the lint report to count one problem", path)
+		}
+		if !strings.Contains(body, "<strong>Rnd 2:</strong>") || !strings.Contains(body, "expected count is 14 but the stitches make 12") {
+			t.Errorf("GET %s: expected the expected-count warning for Rnd 2", path)
+		}
+	}
 }
 
 func TestIntegration_Pattern_EditorPreview(t *testing.T) {
@@ -961,6 +1107,22 @@ func TestIntegration_Pattern_Duplicate(t *testing.T) {
 	}
 }
 
+// extractVersionID finds the first version ID from /patterns/{id}/history/{versionID} links in HTML.
+func extractVersionID(t *testing.T, body, patternID string) string {
+	t.Helper()
+	prefix := "/patterns/" + patternID + "/history/"
+	idx := strings.Index(body, prefix)
+	if idx == -1 {
+		t.Fatal("couldn't find a version link on the page")
+	}
+	rest := body[idx+len(prefix):]
+	endIdx := strings.IndexAny(rest, "\"/ >")
+	if endIdx == -1 {
+		t.Fatal("couldn't extract version ID from page")
+	}
+	return rest[:endIdx]
+}
+
 // extractPatternID finds the first numeric pattern ID from /patterns/{id} links in HTML.
 func extractPatternID(t *testing.T, body string) string {
 	t.Helper()
@@ -1131,7 +1293,7 @@ func TestIntegration_WorkSession_NavigateToCompletion(t *testing.T) {
 	}
 }
 
-func TestIntegration_WorkSession_MultiGroupNavigateToCompletion(t *testing.T) {
+func TestIntegration_WorkSession_GradedPatternSize(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
 	if err := stitches.SeedPredefined(context.Background()); err != nil {
@@ -1152,60 +1314,45 @@ func TestIntegration_WorkSession_MultiGroupNavigateToCompletion(t *testing.T) {
 		},
 	}
 
-	// Register and login.
 	client.PostForm(srv.URL+"/register", url.Values{
-		"email":            {"multigroup@example.com"},
-		"display_name":     {"MultiGroup User"},
+		"email":            {"graded@example.com"},
+		"display_name":     {"Graded User"},
 		"password":         {"password123"},
 		"confirm_password": {"password123"},
 	})
 	client.PostForm(srv.URL+"/login", url.Values{
-		"email":    {"multigroup@example.com"},
+		"email":    {"graded@example.com"},
 		"password": {"password123"},
 	})
 
-	// Get sc and dc stitch IDs.
 	predefined, _ := stitches.ListPredefined(context.Background())
-	scID, dcID := "", ""
+	scID := ""
 	for _, s := range predefined {
-		switch s.Abbreviation {
-		case "sc":
+		if s.Abbreviation == "sc" {
 			scID = strconv.FormatInt(s.ID, 10)
-		case "dc":
-			dcID = strconv.FormatInt(s.ID, 10)
+			break
 		}
 	}
-	if scID == "" || dcID == "" {
-		t.Fatal("sc or dc stitch not found")
+	if scID == "" {
+		t.Fatal("sc stitch not found")
 	}
 
-	// Create a 2-group pattern:
-	//   Group 0 (Round 1): 3x sc = 3 stitches
-	//   Group 1 (Round 2): 3x dc = 3 stitches
-	//   Total: 6 stitches
+	// Row 1: 2 (4) sc in sizes S and M.
 	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
-		"name":             {"Multi-Group Test"},
-		"pattern_type":     {"round"},
-		"group_label_0":    {"Round 1"},
+		"name":             {"Graded Test"},
+		"pattern_type":     {"row"},
+		"sizes":            {"S, M"},
+		"group_label_0":    {"Row 1"},
 		"group_repeat_0":   {"1"},
 		"entry_stitch_0_0": {scID},
-		"entry_count_0_0":  {"3"},
+		"entry_count_0_0":  {"2, 4"},
 		"entry_repeat_0_0": {"1"},
-		"group_label_1":    {"Round 2"},
-		"group_repeat_1":   {"1"},
-		"entry_stitch_1_0": {dcID},
-		"entry_count_1_0":  {"3"},
-		"entry_repeat_1_0": {"1"},
 	})
 	if err != nil {
 		t.Fatalf("POST /patterns: %v", err)
 	}
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusSeeOther {
-		t.Fatalf("create pattern: expected 303, got %d", resp.StatusCode)
-	}
 
-	// Find pattern ID.
 	resp, err = client.Get(srv.URL + "/patterns")
 	if err != nil {
 		t.Fatalf("GET /patterns: %v", err)
@@ -1214,8 +1361,22 @@ func TestIntegration_WorkSession_MultiGroupNavigateToCompletion(t *testing.T) {
 	resp.Body.Close()
 	patternID := extractPatternID(t, string(bodyBytes))
 
-	// Start a work session.
-	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", nil)
+	resp, err = client.Get(srv.URL + "/patterns/" + patternID)
+	if err != nil {
+		t.Fatalf("GET pattern: %v", err)
+	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	body := string(bodyBytes)
+	if !strings.Contains(body, "Row 1: 2 (4) sc (2 (4))") {
+		t.Fatal("pattern text should show graded counts")
+	}
+	if !strings.Contains(body, `name="size"`) {
+		t.Fatal("pattern view should ask which size to follow")
+	}
+
+	// Follow size M: 4 stitches.
+	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", url.Values{"size": {"1"}})
 	if err != nil {
 		t.Fatalf("POST start-session: %v", err)
 	}
@@ -1224,63 +1385,47 @@ func TestIntegration_WorkSession_MultiGroupNavigateToCompletion(t *testing.T) {
 		t.Fatalf("start session: expected 303, got %d", resp.StatusCode)
 	}
 	sessionURL := resp.Header.Get("Location")
-	if sessionURL == "" {
-		t.Fatal("expected redirect location for session")
-	}
 
-	// Navigate forward 3 times (complete group 0).
-	for i := range 3 {
-		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
-		if err != nil {
-			t.Fatalf("POST next (group 0, stitch %d): %v", i+1, err)
-		}
-		resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			t.Fatalf("next group 0 stitch %d: expected 200, got %d", i+1, resp.StatusCode)
-		}
-	}
-
-	// After completing group 0, the session should still be active (NOT completed).
 	resp, err = client.Get(srv.URL + sessionURL)
 	if err != nil {
-		t.Fatalf("GET session after group 0: %v", err)
+		t.Fatalf("GET session: %v", err)
 	}
 	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	body := string(bodyBytes)
-	if strings.Contains(body, "Pattern Complete") {
-		t.Fatal("session should NOT be completed after finishing only group 0 — group 1 remains")
-	}
-	if !strings.Contains(body, "Round 2") {
-		t.Fatal("session should show 'Round 2' after completing group 0")
+	if !strings.Contains(string(bodyBytes), "Size M") {
+		t.Fatal("session page should show the size being followed")
 	}
 
-	// Navigate forward 3 more times (complete group 1).
-	for i := range 3 {
+	for i := range 4 {
 		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
 		if err != nil {
-			t.Fatalf("POST next (group 1, stitch %d): %v", i+1, err)
+			t.Fatalf("POST next (stitch %d): %v", i+1, err)
 		}
 		resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			t.Fatalf("next group 1 stitch %d: expected 200, got %d", i+1, resp.StatusCode)
-		}
 	}
 
-	// Now the session should be completed.
 	resp, err = client.Get(srv.URL + sessionURL)
 	if err != nil {
 		t.Fatalf("GET session after completion: %v", err)
 	}
 	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	body = string(bodyBytes)
-	if !strings.Contains(body, "Pattern Complete") {
-		t.Fatal("session should show 'Pattern Complete' after navigating through all stitches in both groups")
+	if !strings.Contains(string(bodyBytes), "Pattern Complete") {
+		t.Fatal("session should complete after the 4 stitches of size M")
+	}
+
+	// A size the pattern doesn't have is rejected.
+	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", url.Values{"size": {"5"}})
+	if err != nil {
+		t.Fatalf("POST start-session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("start session with unknown size: expected 422, got %d", resp.StatusCode)
 	}
 }
 
-func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
+func TestIntegration_WorkSession_PatternPieces(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
 	if err := stitches.SeedPredefined(context.Background()); err != nil {
@@ -1301,15 +1446,14 @@ func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
 		},
 	}
 
-	// Register and login.
 	client.PostForm(srv.URL+"/register", url.Values{
-		"email":            {"backward@example.com"},
-		"display_name":     {"Backward User"},
+		"email":            {"pieces@example.com"},
+		"display_name":     {"Pieces User"},
 		"password":         {"password123"},
 		"confirm_password": {"password123"},
 	})
 	client.PostForm(srv.URL+"/login", url.Values{
-		"email":    {"backward@example.com"},
+		"email":    {"pieces@example.com"},
 		"password": {"password123"},
 	})
 
@@ -1321,16 +1465,30 @@ func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
 			break
 		}
 	}
+	if scID == "" {
+		t.Fatal("sc stitch not found")
+	}
 
-	// Create pattern: Round 1: 4 sc.
+	// Body: 2 sc, then Arm (make 2): 1 sc.
 	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
-		"name":             {"Backward Test"},
+		"name":             {"Pieces Test"},
 		"pattern_type":     {"round"},
-		"group_label_0":    {"Round 1"},
+		"piece_name_0":     {"Body"},
+		"piece_make_0":     {"1"},
+		"piece_name_1":     {"Arm"},
+		"piece_make_1":     {"2"},
+		"group_label_0":    {"Rnd 1"},
 		"group_repeat_0":   {"1"},
+		"group_piece_0":    {"1"},
 		"entry_stitch_0_0": {scID},
-		"entry_count_0_0":  {"4"},
+		"entry_count_0_0":  {"2"},
 		"entry_repeat_0_0": {"1"},
+		"group_label_1":    {"Rnd 1"},
+		"group_repeat_1":   {"1"},
+		"group_piece_1":    {"2"},
+		"entry_stitch_1_0": {scID},
+		"entry_count_1_0":  {"1"},
+		"entry_repeat_1_0": {"1"},
 	})
 	if err != nil {
 		t.Fatalf("POST /patterns: %v", err)
@@ -1345,7 +1503,16 @@ func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
 	resp.Body.Close()
 	patternID := extractPatternID(t, string(bodyBytes))
 
-	// Start session.
+	resp, err = client.Get(srv.URL + "/patterns/" + patternID)
+	if err != nil {
+		t.Fatalf("GET pattern: %v", err)
+	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(bodyBytes), "Arm (make 2)") {
+		t.Fatal("pattern text should include the Arm piece heading")
+	}
+
 	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", nil)
 	if err != nil {
 		t.Fatalf("POST start-session: %v", err)
@@ -1353,7 +1520,7 @@ func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
 	resp.Body.Close()
 	sessionURL := resp.Header.Get("Location")
 
-	// Navigate forward 2 times.
+	// Work the body, then check the tracker shows the first arm.
 	for range 2 {
 		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
 		if err != nil {
@@ -1361,39 +1528,35 @@ func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
 		}
 		resp.Body.Close()
 	}
-
-	// Navigate backward 1 time.
-	resp, err = client.PostForm(srv.URL+sessionURL+"/prev", nil)
+	resp, err = client.Get(srv.URL + sessionURL)
 	if err != nil {
-		t.Fatalf("POST prev: %v", err)
+		t.Fatalf("GET session: %v", err)
 	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("prev: expected 200, got %d", resp.StatusCode)
+	if !strings.Contains(string(bodyBytes), "Arm 1 of 2") {
+		t.Fatal("session page should show which copy of the piece is being worked")
 	}
 
-	// View session — should show progress at 1/4 (stitch count position 1).
+	for range 2 {
+		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
+		if err != nil {
+			t.Fatalf("POST next: %v", err)
+		}
+		resp.Body.Close()
+	}
 	resp, err = client.Get(srv.URL + sessionURL)
 	if err != nil {
-		t.Fatalf("GET session: %v", err)
+		t.Fatalf("GET session after completion: %v", err)
 	}
 	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	body := string(bodyBytes)
-
-	// Should still be active (not completed).
-	if strings.Contains(body, "Pattern Complete") {
-		t.Fatal("session should not be completed after navigating backward")
-	}
-	if !strings.Contains(body, "sc") {
-		t.Fatal("session should show current stitch 'sc'")
+	if !strings.Contains(string(bodyBytes), "Pattern Complete") {
+		t.Fatal("session should complete after both arms")
 	}
 }
 
-// TestFullHappyPath is the complete regression test covering the entire user journey:
-// register -> create custom stitch -> create pattern -> preview -> start session ->
-// navigate to completion -> verify session marked complete.
-func TestFullHappyPath(t *testing.T) {
+func TestIntegration_WorkSession_ColorChanges(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
 	if err := stitches.SeedPredefined(context.Background()); err != nil {
@@ -1414,79 +1577,18 @@ func TestFullHappyPath(t *testing.T) {
 		},
 	}
 
-	// 1. Register a new user.
-	resp, err := client.PostForm(srv.URL+"/register", url.Values{
-		"email":            {"happy@example.com"},
-		"display_name":     {"Happy Crocheter"},
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"colors@example.com"},
+		"display_name":     {"Colors User"},
 		"password":         {"password123"},
 		"confirm_password": {"password123"},
 	})
-	if err != nil {
-		t.Fatalf("register: %v", err)
-	}
-	resp.Body.Close()
-	if resp.StatusCode != http.StatusSeeOther {
-		t.Fatalf("register: expected 303, got %d", resp.StatusCode)
-	}
-
-	// 2. Login.
-	resp, err = client.PostForm(srv.URL+"/login", url.Values{
-		"email":    {"happy@example.com"},
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"colors@example.com"},
 		"password": {"password123"},
 	})
-	if err != nil {
-		t.Fatalf("login: %v", err)
-	}
-	resp.Body.Close()
-	if resp.StatusCode != http.StatusSeeOther {
-		t.Fatalf("login: expected 303, got %d", resp.StatusCode)
-	}
-
-	// Verify dashboard is accessible.
-	resp, err = client.Get(srv.URL + "/dashboard")
-	if err != nil {
-		t.Fatalf("GET /dashboard: %v", err)
-	}
-	body, _ := io.ReadAll(resp.Body)
-	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("dashboard: expected 200, got %d", resp.StatusCode)
-	}
-	if !strings.Contains(string(body), "Happy Crocheter") {
-		t.Fatal("dashboard should show user display name")
-	}
-
-	// 3. Create a custom stitch.
-	resp, err = client.PostForm(srv.URL+"/stitches", url.Values{
-		"abbreviation": {"msc"},
-		"name":         {"Magic Single Crochet"},
-		"category":     {"custom"},
-		"description":  {"A magical stitch"},
-	})
-	if err != nil {
-		t.Fatalf("create custom stitch: %v", err)
-	}
-	resp.Body.Close()
-	if resp.StatusCode != http.StatusSeeOther {
-		t.Fatalf("create custom stitch: expected 303, got %d", resp.StatusCode)
-	}
-
-	// Verify stitch appears in library.
-	resp, err = client.Get(srv.URL + "/stitches")
-	if err != nil {
-		t.Fatalf("GET /stitches: %v", err)
-	}
-	body, _ = io.ReadAll(resp.Body)
-	resp.Body.Close()
-	if !strings.Contains(string(body), "msc") {
-		t.Fatal("stitch library should show custom stitch 'msc'")
-	}
 
-	// 4. Create a pattern using a predefined stitch (sc).
-	predefined, err := stitches.ListPredefined(context.Background())
-	if err != nil || len(predefined) == 0 {
-		t.Fatalf("ListPredefined: %v (count: %d)", err, len(predefined))
-	}
+	predefined, _ := stitches.ListPredefined(context.Background())
 	scID := ""
 	for _, s := range predefined {
 		if s.Abbreviation == "sc" {
@@ -1495,118 +1597,92 @@ func TestFullHappyPath(t *testing.T) {
 		}
 	}
 	if scID == "" {
-		t.Fatal("predefined 'sc' stitch not found")
+		t.Fatal("sc stitch not found")
 	}
 
-	resp, err = client.PostForm(srv.URL+"/patterns", url.Values{
-		"name":             {"Happy Path Pattern"},
+	form := url.Values{
+		"name":             {"Colors Test"},
 		"pattern_type":     {"round"},
-		"hook_size":        {"5.0mm"},
-		"yarn_weight":      {"Worsted"},
-		"description":      {"A full happy path test pattern"},
-		"group_label_0":    {"Round 1"},
+		"color_label_0":    {"A"},
+		"color_name_0":     {"Cream"},
+		"color_hex_0":      {"#f5e6c8"},
+		"color_label_1":    {"B"},
+		"color_name_1":     {"Navy"},
+		"group_label_0":    {"Rnd 1"},
 		"group_repeat_0":   {"1"},
 		"entry_stitch_0_0": {scID},
-		"entry_count_0_0":  {"3"},
+		"entry_count_0_0":  {"2"},
 		"entry_repeat_0_0": {"1"},
-	})
+		"entry_stitch_0_1": {scID},
+		"entry_count_0_1":  {"1"},
+		"entry_repeat_0_1": {"1"},
+		"entry_color_0_1":  {"C"},
+	}
+
+	// A color label that isn't in the palette is rejected.
+	resp, err := client.PostForm(srv.URL+"/patterns", form)
 	if err != nil {
-		t.Fatalf("create pattern: %v", err)
+		t.Fatalf("POST /patterns: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for an unknown color, got %d", resp.StatusCode)
+	}
+
+	// Labels match case-insensitively.
+	form.Set("entry_color_0_1", "b")
+	resp, err = client.PostForm(srv.URL+"/patterns", form)
+	if err != nil {
+		t.Fatalf("POST /patterns: %v", err)
 	}
 	resp.Body.Close()
 	if resp.StatusCode != http.StatusSeeOther {
-		t.Fatalf("create pattern: expected 303, got %d", resp.StatusCode)
+		t.Fatalf("expected 303, got %d", resp.StatusCode)
 	}
 
-	// 5. View pattern list and find the pattern.
 	resp, err = client.Get(srv.URL + "/patterns")
 	if err != nil {
 		t.Fatalf("GET /patterns: %v", err)
 	}
-	body, _ = io.ReadAll(resp.Body)
+	bodyBytes, _ := io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if !strings.Contains(string(body), "Happy Path Pattern") {
-		t.Fatal("pattern list should contain the new pattern")
-	}
-	patternID := extractPatternID(t, string(body))
+	patternID := extractPatternID(t, string(bodyBytes))
 
-	// 6. View the pattern detail (preview).
 	resp, err = client.Get(srv.URL + "/patterns/" + patternID)
 	if err != nil {
-		t.Fatalf("GET pattern detail: %v", err)
+		t.Fatalf("GET pattern: %v", err)
 	}
-	body, _ = io.ReadAll(resp.Body)
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("pattern detail: expected 200, got %d", resp.StatusCode)
-	}
-	if !strings.Contains(string(body), "Round 1") {
-		t.Fatal("pattern detail should show instruction group 'Round 1'")
+	if !strings.Contains(string(bodyBytes), "Rnd 1: 2 sc, with B: sc (3)") {
+		t.Fatal("pattern text should mark the change to B")
 	}
 
-	// 7. Start a work session.
 	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", nil)
 	if err != nil {
-		t.Fatalf("start session: %v", err)
+		t.Fatalf("POST start-session: %v", err)
 	}
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusSeeOther {
-		t.Fatalf("start session: expected 303, got %d", resp.StatusCode)
-	}
 	sessionURL := resp.Header.Get("Location")
-	if sessionURL == "" {
-		t.Fatal("expected session redirect location")
-	}
 
-	// Verify session view shows current stitch.
-	resp, err = client.Get(srv.URL + sessionURL)
+	// On the last stitch before the change, the tracker warns about it.
+	resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
 	if err != nil {
-		t.Fatalf("GET session: %v", err)
+		t.Fatalf("POST next: %v", err)
 	}
-	body, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("session view: expected 200, got %d", resp.StatusCode)
-	}
-	if !strings.Contains(string(body), "sc") {
-		t.Fatal("session page should show current stitch 'sc'")
-	}
-
-	// 8. Navigate forward through all 3 stitches to complete.
-	for i := range 3 {
-		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
-		if err != nil {
-			t.Fatalf("next stitch %d: %v", i+1, err)
-		}
-		resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			t.Fatalf("next stitch %d: expected 200, got %d", i+1, resp.StatusCode)
-		}
-	}
-
-	// 9. Verify session is marked complete.
 	resp, err = client.Get(srv.URL + sessionURL)
 	if err != nil {
-		t.Fatalf("GET session after completion: %v", err)
-	}
-	body, _ = io.ReadAll(resp.Body)
-	resp.Body.Close()
-	if !strings.Contains(string(body), "Pattern Complete") {
-		t.Fatal("session page should show 'Pattern Complete' after navigating through all stitches")
-	}
-
-	// 10. Dashboard should still be accessible.
-	resp, err = client.Get(srv.URL + "/dashboard")
-	if err != nil {
-		t.Fatalf("GET /dashboard after completion: %v", err)
+		t.Fatalf("GET session: %v", err)
 	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("dashboard after completion: expected 200, got %d", resp.StatusCode)
+	if !strings.Contains(string(bodyBytes), "Change to B (Navy)") {
+		t.Fatal("session page should warn about the upcoming color change")
 	}
 }
 
-func TestIntegration_ImageUploadAndServe(t *testing.T) {
+func TestIntegration_PatternVersionHistory(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
 	if err := stitches.SeedPredefined(context.Background()); err != nil {
@@ -1627,19 +1703,17 @@ func TestIntegration_ImageUploadAndServe(t *testing.T) {
 		},
 	}
 
-	// Register and login.
 	client.PostForm(srv.URL+"/register", url.Values{
-		"email":            {"img@example.com"},
-		"display_name":     {"Image User"},
+		"email":            {"history@example.com"},
+		"display_name":     {"History User"},
 		"password":         {"password123"},
 		"confirm_password": {"password123"},
 	})
 	client.PostForm(srv.URL+"/login", url.Values{
-		"email":    {"img@example.com"},
+		"email":    {"history@example.com"},
 		"password": {"password123"},
 	})
 
-	// Get sc stitch ID.
 	predefined, _ := stitches.ListPredefined(context.Background())
 	scID := ""
 	for _, s := range predefined {
@@ -1648,100 +1722,94 @@ func TestIntegration_ImageUploadAndServe(t *testing.T) {
 			break
 		}
 	}
+	if scID == "" {
+		t.Fatal("sc stitch not found")
+	}
 
-	// Create a pattern.
-	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
-		"name":             {"Image Test Pattern"},
+	form := url.Values{
+		"name":             {"First Draft"},
 		"pattern_type":     {"round"},
-		"group_label_0":    {"Round 1"},
+		"group_label_0":    {"Rnd 1"},
 		"group_repeat_0":   {"1"},
 		"entry_stitch_0_0": {scID},
 		"entry_count_0_0":  {"6"},
 		"entry_repeat_0_0": {"1"},
-	})
+	}
+	resp, err := client.PostForm(srv.URL+"/patterns", form)
 	if err != nil {
-		t.Fatalf("create pattern: %v", err)
+		t.Fatalf("POST /patterns: %v", err)
 	}
 	resp.Body.Close()
 
-	// Find the pattern ID.
-	resp, _ = client.Get(srv.URL + "/patterns")
-	body, _ := io.ReadAll(resp.Body)
+	resp, err = client.Get(srv.URL + "/patterns")
+	if err != nil {
+		t.Fatalf("GET /patterns: %v", err)
+	}
+	bodyBytes, _ := io.ReadAll(resp.Body)
 	resp.Body.Close()
-	patternID := extractPatternID(t, string(body))
+	patternID := extractPatternID(t, string(bodyBytes))
 
-	// 1. Upload a valid PNG image.
-	pngData := createTestPNG()
-	resp, err = uploadImage(client, srv.URL, patternID, "0", "test.png", "image/png", pngData)
+	form.Set("name", "Second Draft")
+	form.Set("entry_count_0_0", "8")
+	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/edit", form)
 	if err != nil {
-		t.Fatalf("upload PNG: %v", err)
+		t.Fatalf("POST edit: %v", err)
 	}
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("upload PNG: expected 200, got %d", resp.StatusCode)
-	}
 
-	// 2. Upload a valid JPEG image.
-	jpegData := createTestJPEG()
-	resp, err = uploadImage(client, srv.URL, patternID, "0", "test.jpg", "image/jpeg", jpegData)
+	resp, err = client.Get(srv.URL + "/patterns/" + patternID + "/history")
 	if err != nil {
-		t.Fatalf("upload JPEG: %v", err)
+		t.Fatalf("GET history: %v", err)
 	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("upload JPEG: expected 200, got %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(bodyBytes), "First Draft") {
+		t.Fatalf("history should list the first draft, got status %d", resp.StatusCode)
 	}
+	versionID := extractVersionID(t, string(bodyBytes), patternID)
+	versionURL := srv.URL + "/patterns/" + patternID + "/history/" + versionID
 
-	// 3. Edit page should show the images (2 / 5 images).
-	resp, _ = client.Get(srv.URL + "/patterns/" + patternID + "/edit")
-	body, _ = io.ReadAll(resp.Body)
+	resp, err = client.Get(versionURL)
+	if err != nil {
+		t.Fatalf("GET version: %v", err)
+	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if !strings.Contains(string(body), "2 / 5 images") {
-		t.Fatalf("edit page should show '2 / 5 images', body contains: %s", string(body))
+	if !strings.Contains(string(bodyBytes), "Rnd 1: 6 sc (6)") {
+		t.Fatal("version preview should render the first draft's pattern text")
 	}
 
-	// 4. Serve an image (find image URL from the edit page).
-	imageURL := extractImageURL(string(body))
-	if imageURL == "" {
-		t.Fatal("expected to find an image URL in the edit page")
-	}
-	resp, err = client.Get(srv.URL + imageURL)
+	resp, err = client.Get(versionURL + "/diff")
 	if err != nil {
-		t.Fatalf("GET image: %v", err)
+		t.Fatalf("GET version diff: %v", err)
 	}
-	imgBody, _ := io.ReadAll(resp.Body)
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("serve image: expected 200, got %d", resp.StatusCode)
-	}
-	ct := resp.Header.Get("Content-Type")
-	if ct != "image/png" && ct != "image/jpeg" {
-		t.Fatalf("expected image content type, got %s", ct)
-	}
-	if len(imgBody) == 0 {
-		t.Fatal("image body should not be empty")
+	if !strings.Contains(string(bodyBytes), "stitch 1 changed from &#34;6 sc&#34; to &#34;8 sc&#34;") {
+		t.Fatal("version diff should list the changed stitch count")
 	}
 
-	// 5. Upload wrong content type should fail.
-	resp, err = uploadImage(client, srv.URL, patternID, "0", "test.gif", "image/gif", []byte("GIF89a"))
+	resp, err = client.PostForm(versionURL+"/restore", nil)
 	if err != nil {
-		t.Fatalf("upload GIF: %v", err)
+		t.Fatalf("POST restore: %v", err)
 	}
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Fatalf("upload GIF: expected 400, got %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected 303 after restore, got %d", resp.StatusCode)
 	}
 
-	// 6. View page should show images in gallery.
-	resp, _ = client.Get(srv.URL + "/patterns/" + patternID)
-	body, _ = io.ReadAll(resp.Body)
+	resp, err = client.Get(srv.URL + "/patterns/" + patternID)
+	if err != nil {
+		t.Fatalf("GET pattern: %v", err)
+	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if !strings.Contains(string(body), "/images/") {
-		t.Fatal("view page should contain image references")
+	if !strings.Contains(string(bodyBytes), "First Draft") || !strings.Contains(string(bodyBytes), "Rnd 1: 6 sc (6)") {
+		t.Fatal("pattern should be back to the first draft after restoring")
 	}
 }
 
-func TestIntegration_ImageUploadLimits(t *testing.T) {
+func TestIntegration_WorkSession_MultiGroupNavigateToCompletion(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
 	if err := stitches.SeedPredefined(context.Background()); err != nil {
@@ -1764,68 +1832,133 @@ func TestIntegration_ImageUploadLimits(t *testing.T) {
 
 	// Register and login.
 	client.PostForm(srv.URL+"/register", url.Values{
-		"email":            {"limit@example.com"},
-		"display_name":     {"Limit User"},
+		"email":            {"multigroup@example.com"},
+		"display_name":     {"MultiGroup User"},
 		"password":         {"password123"},
 		"confirm_password": {"password123"},
 	})
 	client.PostForm(srv.URL+"/login", url.Values{
-		"email":    {"limit@example.com"},
+		"email":    {"multigroup@example.com"},
 		"password": {"password123"},
 	})
 
+	// Get sc and dc stitch IDs.
 	predefined, _ := stitches.ListPredefined(context.Background())
-	scID := ""
+	scID, dcID := "", ""
 	for _, s := range predefined {
-		if s.Abbreviation == "sc" {
+		switch s.Abbreviation {
+		case "sc":
 			scID = strconv.FormatInt(s.ID, 10)
-			break
+		case "dc":
+			dcID = strconv.FormatInt(s.ID, 10)
 		}
 	}
+	if scID == "" || dcID == "" {
+		t.Fatal("sc or dc stitch not found")
+	}
 
-	// Create a pattern.
-	resp, _ := client.PostForm(srv.URL+"/patterns", url.Values{
-		"name":             {"Limit Test Pattern"},
+	// Create a 2-group pattern:
+	//   Group 0 (Round 1): 3x sc = 3 stitches
+	//   Group 1 (Round 2): 3x dc = 3 stitches
+	//   Total: 6 stitches
+	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Multi-Group Test"},
 		"pattern_type":     {"round"},
 		"group_label_0":    {"Round 1"},
 		"group_repeat_0":   {"1"},
 		"entry_stitch_0_0": {scID},
-		"entry_count_0_0":  {"6"},
+		"entry_count_0_0":  {"3"},
 		"entry_repeat_0_0": {"1"},
-	})
+		"group_label_1":    {"Round 2"},
+		"group_repeat_1":   {"1"},
+		"entry_stitch_1_0": {dcID},
+		"entry_count_1_0":  {"3"},
+		"entry_repeat_1_0": {"1"},
+	})
+	if err != nil {
+		t.Fatalf("POST /patterns: %v", err)
+	}
 	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("create pattern: expected 303, got %d", resp.StatusCode)
+	}
 
-	respList, _ := client.Get(srv.URL + "/patterns")
-	body, _ := io.ReadAll(respList.Body)
-	respList.Body.Close()
-	patternID := extractPatternID(t, string(body))
+	// Find pattern ID.
+	resp, err = client.Get(srv.URL + "/patterns")
+	if err != nil {
+		t.Fatalf("GET /patterns: %v", err)
+	}
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	patternID := extractPatternID(t, string(bodyBytes))
 
-	// Upload 5 images to reach the limit.
-	for i := range 5 {
-		pngData := createTestPNG()
-		resp, err := uploadImage(client, srv.URL, patternID, "0", "img"+strconv.Itoa(i)+".png", "image/png", pngData)
+	// Start a work session.
+	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", nil)
+	if err != nil {
+		t.Fatalf("POST start-session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("start session: expected 303, got %d", resp.StatusCode)
+	}
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		t.Fatal("expected redirect location for session")
+	}
+
+	// Navigate forward 3 times (complete group 0).
+	for i := range 3 {
+		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
 		if err != nil {
-			t.Fatalf("upload %d: %v", i, err)
+			t.Fatalf("POST next (group 0, stitch %d): %v", i+1, err)
 		}
 		resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
-			t.Fatalf("upload %d: expected 200, got %d", i, resp.StatusCode)
+			t.Fatalf("next group 0 stitch %d: expected 200, got %d", i+1, resp.StatusCode)
 		}
 	}
 
-	// 6th image should be rejected.
-	pngData := createTestPNG()
-	resp, err := uploadImage(client, srv.URL, patternID, "0", "extra.png", "image/png", pngData)
+	// After completing group 0, the session should still be active (NOT completed).
+	resp, err = client.Get(srv.URL + sessionURL)
 	if err != nil {
-		t.Fatalf("upload 6th: %v", err)
+		t.Fatalf("GET session after group 0: %v", err)
 	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Fatalf("6th upload: expected 400, got %d", resp.StatusCode)
+	body := string(bodyBytes)
+	if strings.Contains(body, "Pattern Complete") {
+		t.Fatal("session should NOT be completed after finishing only group 0 — group 1 remains")
+	}
+	if !strings.Contains(body, "Round 2") {
+		t.Fatal("session should show 'Round 2' after completing group 0")
+	}
+
+	// Navigate forward 3 more times (complete group 1).
+	for i := range 3 {
+		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
+		if err != nil {
+			t.Fatalf("POST next (group 1, stitch %d): %v", i+1, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("next group 1 stitch %d: expected 200, got %d", i+1, resp.StatusCode)
+		}
+	}
+
+	// Now the session should be completed.
+	resp, err = client.Get(srv.URL + sessionURL)
+	if err != nil {
+		t.Fatalf("GET session after completion: %v", err)
+	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	body = string(bodyBytes)
+	if !strings.Contains(body, "Pattern Complete") {
+		t.Fatal("session should show 'Pattern Complete' after navigating through all stitches in both groups")
 	}
 }
 
-func TestIntegration_ImageDeleteAndCascade(t *testing.T) {
+func TestIntegration_WorkSession_NavigateBackward(t *testing.T) {
 	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
 	if err := stitches.SeedPredefined(context.Background()); err != nil {
@@ -1848,13 +1981,13 @@ func TestIntegration_ImageDeleteAndCascade(t *testing.T) {
 
 	// Register and login.
 	client.PostForm(srv.URL+"/register", url.Values{
-		"email":            {"cascade@example.com"},
-		"display_name":     {"Cascade User"},
+		"email":            {"backward@example.com"},
+		"display_name":     {"Backward User"},
 		"password":         {"password123"},
 		"confirm_password": {"password123"},
 	})
 	client.PostForm(srv.URL+"/login", url.Values{
-		"email":    {"cascade@example.com"},
+		"email":    {"backward@example.com"},
 		"password": {"password123"},
 	})
 
@@ -1867,103 +2000,2407 @@ func TestIntegration_ImageDeleteAndCascade(t *testing.T) {
 		}
 	}
 
-	// Create a pattern.
-	resp, _ := client.PostForm(srv.URL+"/patterns", url.Values{
-		"name":             {"Cascade Test Pattern"},
+	// Create pattern: Round 1: 4 sc.
+	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Backward Test"},
 		"pattern_type":     {"round"},
 		"group_label_0":    {"Round 1"},
 		"group_repeat_0":   {"1"},
 		"entry_stitch_0_0": {scID},
-		"entry_count_0_0":  {"6"},
+		"entry_count_0_0":  {"4"},
 		"entry_repeat_0_0": {"1"},
 	})
+	if err != nil {
+		t.Fatalf("POST /patterns: %v", err)
+	}
 	resp.Body.Close()
 
-	respList, _ := client.Get(srv.URL + "/patterns")
-	body, _ := io.ReadAll(respList.Body)
-	respList.Body.Close()
-	patternID := extractPatternID(t, string(body))
-
-	// Upload an image.
-	pngData := createTestPNG()
-	resp, _ = uploadImage(client, srv.URL, patternID, "0", "cascade.png", "image/png", pngData)
+	resp, err = client.Get(srv.URL + "/patterns")
+	if err != nil {
+		t.Fatalf("GET /patterns: %v", err)
+	}
+	bodyBytes, _ := io.ReadAll(resp.Body)
 	resp.Body.Close()
+	patternID := extractPatternID(t, string(bodyBytes))
 
-	// Get the image URL from the edit page.
-	resp, _ = client.Get(srv.URL + "/patterns/" + patternID + "/edit")
-	body, _ = io.ReadAll(resp.Body)
+	// Start session.
+	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", nil)
+	if err != nil {
+		t.Fatalf("POST start-session: %v", err)
+	}
 	resp.Body.Close()
-	imageURL := extractImageURL(string(body))
-	if imageURL == "" {
-		t.Fatal("expected image URL on edit page after upload")
+	sessionURL := resp.Header.Get("Location")
+
+	// Navigate forward 2 times.
+	for range 2 {
+		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
+		if err != nil {
+			t.Fatalf("POST next: %v", err)
+		}
+		resp.Body.Close()
 	}
 
-	// Verify image is accessible.
-	resp, _ = client.Get(srv.URL + imageURL)
+	// Navigate backward 1 time.
+	resp, err = client.PostForm(srv.URL+sessionURL+"/prev", nil)
+	if err != nil {
+		t.Fatalf("POST prev: %v", err)
+	}
 	resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("image should be accessible, got %d", resp.StatusCode)
+		t.Fatalf("prev: expected 200, got %d", resp.StatusCode)
 	}
 
-	// Delete the pattern (cascade should remove images).
-	resp, _ = client.PostForm(srv.URL+"/patterns/"+patternID+"/delete", nil)
+	// View session — should show progress at 1/4 (stitch count position 1).
+	resp, err = client.Get(srv.URL + sessionURL)
+	if err != nil {
+		t.Fatalf("GET session: %v", err)
+	}
+	bodyBytes, _ = io.ReadAll(resp.Body)
 	resp.Body.Close()
+	body := string(bodyBytes)
 
-	// Image should no longer be accessible (404 or similar).
-	resp, _ = client.Get(srv.URL + imageURL)
-	resp.Body.Close()
-	if resp.StatusCode == http.StatusOK {
-		t.Fatal("image should not be accessible after pattern deletion")
+	// Should still be active (not completed).
+	if strings.Contains(body, "Pattern Complete") {
+		t.Fatal("session should not be completed after navigating backward")
+	}
+	if !strings.Contains(body, "sc") {
+		t.Fatal("session should show current stitch 'sc'")
 	}
 }
 
-// uploadImage creates a multipart form request with the given image data.
-func uploadImage(client *http.Client, baseURL, patternID, groupIndex, filename, contentType string, data []byte) (*http.Response, error) {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-	part, err := writer.CreateFormFile("image", filename)
-	if err != nil {
-		return nil, err
-	}
-	part.Write(data)
-	writer.Close()
+// TestFullHappyPath is the complete regression test covering the entire user journey:
+// register -> create custom stitch -> create pattern -> preview -> start session ->
+// navigate to completion -> verify session marked complete.
+func TestFullHappyPath(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
 
-	req, err := http.NewRequest("POST", baseURL+"/patterns/"+patternID+"/parts/"+groupIndex+"/images", &buf)
-	if err != nil {
-		return nil, err
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	return client.Do(req)
-}
 
-// createTestPNG returns a minimal valid PNG file.
-func createTestPNG() []byte {
-	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
-	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
-	var buf bytes.Buffer
-	png.Encode(&buf, img)
-	return buf.Bytes()
-}
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
 
-// createTestJPEG returns a minimal valid JPEG file.
-func createTestJPEG() []byte {
-	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
-	img.Set(0, 0, color.RGBA{0, 255, 0, 255})
-	var buf bytes.Buffer
-	jpeg.Encode(&buf, img, nil)
-	return buf.Bytes()
-}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
 
-// extractImageURL finds the first /images/{id} URL in the page body.
-func extractImageURL(body string) string {
-	idx := strings.Index(body, "/images/")
-	if idx == -1 {
-		return ""
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
 	}
-	rest := body[idx:]
-	endIdx := strings.IndexAny(rest, "\"' >")
-	if endIdx == -1 {
-		return rest
+
+	// 1. Register a new user.
+	resp, err := client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"happy@example.com"},
+		"display_name":     {"Happy Crocheter"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("register: expected 303, got %d", resp.StatusCode)
+	}
+
+	// 2. Login.
+	resp, err = client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"happy@example.com"},
+		"password": {"password123"},
+	})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("login: expected 303, got %d", resp.StatusCode)
+	}
+
+	// Verify dashboard is accessible.
+	resp, err = client.Get(srv.URL + "/dashboard")
+	if err != nil {
+		t.Fatalf("GET /dashboard: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("dashboard: expected 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "Happy Crocheter") {
+		t.Fatal("dashboard should show user display name")
+	}
+
+	// 3. Create a custom stitch.
+	resp, err = client.PostForm(srv.URL+"/stitches", url.Values{
+		"abbreviation": {"msc"},
+		"name":         {"Magic Single Crochet"},
+		"category":     {"custom"},
+		"description":  {"A magical stitch"},
+	})
+	if err != nil {
+		t.Fatalf("create custom stitch: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("create custom stitch: expected 303, got %d", resp.StatusCode)
+	}
+
+	// Verify stitch appears in library.
+	resp, err = client.Get(srv.URL + "/stitches")
+	if err != nil {
+		t.Fatalf("GET /stitches: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "msc") {
+		t.Fatal("stitch library should show custom stitch 'msc'")
+	}
+
+	// 4. Create a pattern using a predefined stitch (sc).
+	predefined, err := stitches.ListPredefined(context.Background())
+	if err != nil || len(predefined) == 0 {
+		t.Fatalf("ListPredefined: %v (count: %d)", err, len(predefined))
+	}
+	scID := ""
+	for _, s := range predefined {
+		if s.Abbreviation == "sc" {
+			scID = strconv.FormatInt(s.ID, 10)
+			break
+		}
+	}
+	if scID == "" {
+		t.Fatal("predefined 'sc' stitch not found")
+	}
+
+	resp, err = client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Happy Path Pattern"},
+		"pattern_type":     {"round"},
+		"hook_size":        {"5.0mm"},
+		"yarn_weight":      {"Worsted"},
+		"description":      {"A full happy path test pattern"},
+		"group_label_0":    {"Round 1"},
+		"group_repeat_0":   {"1"},
+		"entry_stitch_0_0": {scID},
+		"entry_count_0_0":  {"3"},
+		"entry_repeat_0_0": {"1"},
+	})
+	if err != nil {
+		t.Fatalf("create pattern: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("create pattern: expected 303, got %d", resp.StatusCode)
+	}
+
+	// 5. View pattern list and find the pattern.
+	resp, err = client.Get(srv.URL + "/patterns")
+	if err != nil {
+		t.Fatalf("GET /patterns: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Happy Path Pattern") {
+		t.Fatal("pattern list should contain the new pattern")
+	}
+	patternID := extractPatternID(t, string(body))
+
+	// 6. View the pattern detail (preview).
+	resp, err = client.Get(srv.URL + "/patterns/" + patternID)
+	if err != nil {
+		t.Fatalf("GET pattern detail: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pattern detail: expected 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "Round 1") {
+		t.Fatal("pattern detail should show instruction group 'Round 1'")
+	}
+
+	// 7. Start a work session.
+	resp, err = client.PostForm(srv.URL+"/patterns/"+patternID+"/start-session", nil)
+	if err != nil {
+		t.Fatalf("start session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("start session: expected 303, got %d", resp.StatusCode)
+	}
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		t.Fatal("expected session redirect location")
+	}
+
+	// Verify session view shows current stitch.
+	resp, err = client.Get(srv.URL + sessionURL)
+	if err != nil {
+		t.Fatalf("GET session: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("session view: expected 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "sc") {
+		t.Fatal("session page should show current stitch 'sc'")
+	}
+
+	// 8. Navigate forward through all 3 stitches to complete.
+	for i := range 3 {
+		resp, err = client.PostForm(srv.URL+sessionURL+"/next", nil)
+		if err != nil {
+			t.Fatalf("next stitch %d: %v", i+1, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("next stitch %d: expected 200, got %d", i+1, resp.StatusCode)
+		}
+	}
+
+	// 9. Verify session is marked complete.
+	resp, err = client.Get(srv.URL + sessionURL)
+	if err != nil {
+		t.Fatalf("GET session after completion: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Pattern Complete") {
+		t.Fatal("session page should show 'Pattern Complete' after navigating through all stitches")
+	}
+
+	// 10. Dashboard should still be accessible.
+	resp, err = client.Get(srv.URL + "/dashboard")
+	if err != nil {
+		t.Fatalf("GET /dashboard after completion: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("dashboard after completion: expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_ImageUploadAndServe(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Register and login.
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"img@example.com"},
+		"display_name":     {"Image User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"img@example.com"},
+		"password": {"password123"},
+	})
+
+	// Get sc stitch ID.
+	predefined, _ := stitches.ListPredefined(context.Background())
+	scID := ""
+	for _, s := range predefined {
+		if s.Abbreviation == "sc" {
+			scID = strconv.FormatInt(s.ID, 10)
+			break
+		}
+	}
+
+	// Create a pattern.
+	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Image Test Pattern"},
+		"pattern_type":     {"round"},
+		"group_label_0":    {"Round 1"},
+		"group_repeat_0":   {"1"},
+		"entry_stitch_0_0": {scID},
+		"entry_count_0_0":  {"6"},
+		"entry_repeat_0_0": {"1"},
+	})
+	if err != nil {
+		t.Fatalf("create pattern: %v", err)
+	}
+	resp.Body.Close()
+
+	// Find the pattern ID.
+	resp, _ = client.Get(srv.URL + "/patterns")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	patternID := extractPatternID(t, string(body))
+
+	// 1. Upload a valid PNG image.
+	pngData := createTestPNG()
+	resp, err = uploadImage(client, srv.URL, patternID, "0", "test.png", "image/png", pngData)
+	if err != nil {
+		t.Fatalf("upload PNG: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload PNG: expected 200, got %d", resp.StatusCode)
+	}
+
+	// 2. Upload a valid JPEG image.
+	jpegData := createTestJPEG()
+	resp, err = uploadImage(client, srv.URL, patternID, "0", "test.jpg", "image/jpeg", jpegData)
+	if err != nil {
+		t.Fatalf("upload JPEG: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload JPEG: expected 200, got %d", resp.StatusCode)
+	}
+
+	// 3. Edit page should show the images (2 / 5 images).
+	resp, _ = client.Get(srv.URL + "/patterns/" + patternID + "/edit")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "2 / 5 images") {
+		t.Fatalf("edit page should show '2 / 5 images', body contains: %s", string(body))
+	}
+
+	// 4. Serve an image (find image URL from the edit page).
+	imageURL := extractImageURL(string(body))
+	if imageURL == "" {
+		t.Fatal("expected to find an image URL in the edit page")
+	}
+	resp, err = client.Get(srv.URL + imageURL)
+	if err != nil {
+		t.Fatalf("GET image: %v", err)
+	}
+	imgBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("serve image: expected 200, got %d", resp.StatusCode)
+	}
+	ct := resp.Header.Get("Content-Type")
+	if ct != "image/png" && ct != "image/jpeg" {
+		t.Fatalf("expected image content type, got %s", ct)
+	}
+	if len(imgBody) == 0 {
+		t.Fatal("image body should not be empty")
+	}
+
+	// 5. Upload wrong content type should fail.
+	resp, err = uploadImage(client, srv.URL, patternID, "0", "test.gif", "image/gif", []byte("GIF89a"))
+	if err != nil {
+		t.Fatalf("upload GIF: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("upload GIF: expected 400, got %d", resp.StatusCode)
+	}
+
+	// 6. View page should show images in gallery.
+	resp, _ = client.Get(srv.URL + "/patterns/" + patternID)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "/images/") {
+		t.Fatal("view page should contain image references")
+	}
+}
+
+func TestIntegration_ImageUploadLimits(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Register and login.
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"limit@example.com"},
+		"display_name":     {"Limit User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"limit@example.com"},
+		"password": {"password123"},
+	})
+
+	predefined, _ := stitches.ListPredefined(context.Background())
+	scID := ""
+	for _, s := range predefined {
+		if s.Abbreviation == "sc" {
+			scID = strconv.FormatInt(s.ID, 10)
+			break
+		}
+	}
+
+	// Create a pattern.
+	resp, _ := client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Limit Test Pattern"},
+		"pattern_type":     {"round"},
+		"group_label_0":    {"Round 1"},
+		"group_repeat_0":   {"1"},
+		"entry_stitch_0_0": {scID},
+		"entry_count_0_0":  {"6"},
+		"entry_repeat_0_0": {"1"},
+	})
+	resp.Body.Close()
+
+	respList, _ := client.Get(srv.URL + "/patterns")
+	body, _ := io.ReadAll(respList.Body)
+	respList.Body.Close()
+	patternID := extractPatternID(t, string(body))
+
+	// Upload 5 images to reach the limit.
+	for i := range 5 {
+		pngData := createTestPNG()
+		resp, err := uploadImage(client, srv.URL, patternID, "0", "img"+strconv.Itoa(i)+".png", "image/png", pngData)
+		if err != nil {
+			t.Fatalf("upload %d: %v", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("upload %d: expected 200, got %d", i, resp.StatusCode)
+		}
+	}
+
+	// 6th image should be rejected.
+	pngData := createTestPNG()
+	resp, err := uploadImage(client, srv.URL, patternID, "0", "extra.png", "image/png", pngData)
+	if err != nil {
+		t.Fatalf("upload 6th: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("6th upload: expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_ImageDeleteAndCascade(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Register and login.
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"cascade@example.com"},
+		"display_name":     {"Cascade User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"cascade@example.com"},
+		"password": {"password123"},
+	})
+
+	predefined, _ := stitches.ListPredefined(context.Background())
+	scID := ""
+	for _, s := range predefined {
+		if s.Abbreviation == "sc" {
+			scID = strconv.FormatInt(s.ID, 10)
+			break
+		}
+	}
+
+	// Create a pattern.
+	resp, _ := client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Cascade Test Pattern"},
+		"pattern_type":     {"round"},
+		"group_label_0":    {"Round 1"},
+		"group_repeat_0":   {"1"},
+		"entry_stitch_0_0": {scID},
+		"entry_count_0_0":  {"6"},
+		"entry_repeat_0_0": {"1"},
+	})
+	resp.Body.Close()
+
+	respList, _ := client.Get(srv.URL + "/patterns")
+	body, _ := io.ReadAll(respList.Body)
+	respList.Body.Close()
+	patternID := extractPatternID(t, string(body))
+
+	// Upload an image.
+	pngData := createTestPNG()
+	resp, _ = uploadImage(client, srv.URL, patternID, "0", "cascade.png", "image/png", pngData)
+	resp.Body.Close()
+
+	// Get the image URL from the edit page.
+	resp, _ = client.Get(srv.URL + "/patterns/" + patternID + "/edit")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	imageURL := extractImageURL(string(body))
+	if imageURL == "" {
+		t.Fatal("expected image URL on edit page after upload")
+	}
+
+	// Verify image is accessible.
+	resp, _ = client.Get(srv.URL + imageURL)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("image should be accessible, got %d", resp.StatusCode)
+	}
+
+	// Delete the pattern (cascade should remove images).
+	resp, _ = client.PostForm(srv.URL+"/patterns/"+patternID+"/delete", nil)
+	resp.Body.Close()
+
+	// Image should no longer be accessible (404 or similar).
+	resp, _ = client.Get(srv.URL + imageURL)
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		t.Fatal("image should not be accessible after pattern deletion")
+	}
+}
+
+func TestIntegration_PatternExportImport(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	newClient := func(email string) *http.Client {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		client.PostForm(srv.URL+"/register", url.Values{
+			"email":            {email},
+			"display_name":     {"Porter"},
+			"password":         {"password123"},
+			"confirm_password": {"password123"},
+		})
+		client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {email},
+			"password": {"password123"},
+		})
+		return client
+	}
+	author := newClient("author@example.com")
+	importer := newClient("importer@example.com")
+
+	predefined, _ := stitches.ListPredefined(context.Background())
+	scID := ""
+	for _, s := range predefined {
+		if s.Abbreviation == "sc" {
+			scID = strconv.FormatInt(s.ID, 10)
+			break
+		}
+	}
+
+	resp, err := author.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Export Test Pattern"},
+		"pattern_type":     {"round"},
+		"group_label_0":    {"Round 1"},
+		"group_repeat_0":   {"1"},
+		"entry_stitch_0_0": {scID},
+		"entry_count_0_0":  {"6"},
+		"entry_repeat_0_0": {"1"},
+	})
+	if err != nil {
+		t.Fatalf("create pattern: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, _ = author.Get(srv.URL + "/patterns")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	patternID := extractPatternID(t, string(body))
+
+	resp, err = uploadImage(author, srv.URL, patternID, "0", "chart.png", "image/png", createTestPNG())
+	if err != nil {
+		t.Fatalf("upload image: %v", err)
+	}
+	resp.Body.Close()
+
+	// 1. Export as JSON with the image embedded.
+	resp, _ = author.Get(srv.URL + "/patterns/" + patternID + "/export")
+	jsonExport, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export JSON: expected 200, got %d", resp.StatusCode)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, "export-test-pattern.json") {
+		t.Errorf("export JSON: Content-Disposition = %q", cd)
+	}
+	if !strings.Contains(string(jsonExport), `"format": "stitch-map-pattern"`) || !strings.Contains(string(jsonExport), `"filename": "chart.png"`) {
+		t.Fatalf("export JSON missing format or image: %s", jsonExport)
+	}
+
+	// 2. Export as a zip bundle.
+	resp, _ = author.Get(srv.URL + "/patterns/" + patternID + "/export?format=zip")
+	zipExport, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/zip" {
+		t.Fatalf("export zip: Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	// 3. Another user can't export the pattern.
+	resp, _ = importer.Get(srv.URL + "/patterns/" + patternID + "/export")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("export by other user: expected 404, got %d", resp.StatusCode)
+	}
+
+	// 4. Import both files as the other user; each becomes a new pattern
+	// with its image.
+	for _, upload := range []struct {
+		filename string
+		data     []byte
+	}{
+		{"pattern.json", jsonExport},
+		{"pattern.zip", zipExport},
+	} {
+		resp, err = importPattern(importer, srv.URL, upload.filename, upload.data)
+		if err != nil {
+			t.Fatalf("import %s: %v", upload.filename, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Fatalf("import %s: expected 303, got %d", upload.filename, resp.StatusCode)
+		}
+		location := resp.Header.Get("Location")
+		if location == "/patterns/"+patternID {
+			t.Fatalf("import %s: redirected to the original pattern", upload.filename)
+		}
+
+		resp, _ = importer.Get(srv.URL + location + "/edit")
+		body, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), "Export Test Pattern") || !strings.Contains(string(body), "1 / 5 images") {
+			t.Errorf("import %s: imported pattern missing name or image", upload.filename)
+		}
+	}
+
+	// 5. A file that isn't a pattern document is rejected.
+	resp, err = importPattern(importer, srv.URL, "notes.json", []byte(`{"format": "something-else"}`))
+	if err != nil {
+		t.Fatalf("import invalid: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(string(body), "not a pattern document") {
+		t.Errorf("import invalid: expected 422 with error, got %d", resp.StatusCode)
+	}
+}
+
+// failingFileStore saves the first n files it's given and fails after that.
+type failingFileStore struct {
+	domain.FileStore
+	n     int
+	saved []string
+}
+
+func (f *failingFileStore) Save(ctx context.Context, key string, data []byte) error {
+	if len(f.saved) >= f.n {
+		return errors.New("disk full")
+	}
+	f.saved = append(f.saved, key)
+	return f.FileStore.Save(ctx, key, data)
+}
+
+func TestIntegration_PatternImport_ImageFailure(t *testing.T) {
+	var files *failingFileStore
+	auth, stitches, patterns, sessions, images, shares, users := newTestServicesWithFiles(t, func(fs domain.FileStore) domain.FileStore {
+		files = &failingFileStore{FileStore: fs, n: 1}
+		return files
+	})
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"brokenimport@example.com"},
+		"display_name":     {"Broken Importer"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"brokenimport@example.com"},
+		"password": {"password123"},
+	})
+
+	// Two images: the first saves, the second fails.
+	png := createTestPNG()
+	data, err := json.Marshal(service.PatternDocument{
+		Format:  service.PatternDocumentFormat,
+		Version: service.PatternDocumentVersion,
+		Pattern: service.DocumentPattern{
+			Name:        "Half Imported",
+			PatternType: "round",
+			Stitches:    []service.DocumentStitch{{Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1}},
+			Groups: []service.DocumentGroup{{
+				Label:       "Rnd 1",
+				RepeatCount: 1,
+				Entries:     []service.DocumentEntry{{Stitch: 0, Count: 6, RepeatCount: 1}},
+				Images: []service.DocumentImage{
+					{Filename: "one.png", ContentType: "image/png", Data: png},
+					{Filename: "two.png", ContentType: "image/png", Data: png},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+
+	resp, err := importPattern(client, srv.URL, "pattern.json", data)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("import: expected 422, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "the pattern was not saved") {
+		t.Errorf("import: expected an error saying the pattern was not saved")
+	}
+
+	resp, _ = client.Get(srv.URL + "/patterns")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "Half Imported") {
+		t.Fatal("a pattern whose images failed to import should not be saved")
+	}
+	if len(files.saved) != 1 {
+		t.Fatalf("expected one image to be saved before the failure, got %d", len(files.saved))
+	}
+	if _, err := files.Get(context.Background(), files.saved[0]); err == nil {
+		t.Fatal("the image saved before the failure should have been removed")
+	}
+}
+
+func TestIntegration_PatternPDF(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"pdf@example.com"},
+		"display_name":     {"PDF User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"pdf@example.com"},
+		"password": {"password123"},
+	})
+
+	predefined, _ := stitches.ListPredefined(context.Background())
+	scID := ""
+	for _, s := range predefined {
+		if s.Abbreviation == "sc" {
+			scID = strconv.FormatInt(s.ID, 10)
+			break
+		}
+	}
+
+	resp, err := client.PostForm(srv.URL+"/patterns", url.Values{
+		"name":             {"Printable Pattern"},
+		"pattern_type":     {"round"},
+		"hook_size":        {"4mm"},
+		"group_label_0":    {"Round 1"},
+		"group_repeat_0":   {"1"},
+		"entry_stitch_0_0": {scID},
+		"entry_count_0_0":  {"6"},
+		"entry_repeat_0_0": {"1"},
+	})
+	if err != nil {
+		t.Fatalf("create pattern: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, _ = client.Get(srv.URL + "/patterns")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	patternID := extractPatternID(t, string(body))
+
+	resp, err = uploadImage(client, srv.URL, patternID, "0", "chart.jpg", "image/jpeg", createTestJPEG())
+	if err != nil {
+		t.Fatalf("upload image: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, _ = client.Get(srv.URL + "/patterns/" + patternID + "/pdf")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/pdf" {
+		t.Fatalf("pdf: expected 200 application/pdf, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, "printable-pattern.pdf") {
+		t.Errorf("pdf: Content-Disposition = %q", cd)
+	}
+	if !bytes.HasPrefix(body, []byte("%PDF-")) || !bytes.Contains(body, []byte("/Filter /DCTDecode")) {
+		t.Errorf("pdf: expected a PDF with the group image embedded")
+	}
+
+	resp, _ = http.Get(srv.URL + "/patterns/" + patternID + "/pdf")
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		t.Errorf("pdf without login: expected redirect or 401, got 200")
+	}
+}
+
+func TestIntegration_PatternChart(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"chart@example.com"},
+		"display_name":     {"Chart User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"chart@example.com"},
+		"password": {"password123"},
+	})
+
+	resp, err := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Chart Coaster"},
+		"text":   {"Rnd 1: MR, 6 sc in MR (6)\nRnd 2: 6 inc (12)"},
+		"action": {"save"},
+	})
+	if err != nil {
+		t.Fatalf("import text: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("import text: expected 303, got %d", resp.StatusCode)
+	}
+	patternURL := resp.Header.Get("Location")
+
+	// The view page shows the chart.
+	resp, _ = client.Get(srv.URL + patternURL)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), patternURL+"/chart.svg") {
+		t.Error("pattern view doesn't show the symbol chart")
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL + "/chart.svg")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("chart: expected 200 image/svg+xml, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if resp.Header.Get("Content-Disposition") != "" {
+		t.Error("chart: inline chart sent as a download")
+	}
+	if !strings.HasPrefix(string(body), "<svg") || !strings.Contains(string(body), "inc – Increase") {
+		t.Errorf("chart: unexpected body %.200s", body)
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL + "/chart.svg?download=1")
+	resp.Body.Close()
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, "chart-coaster-chart.svg") {
+		t.Errorf("chart download: Content-Disposition = %q", cd)
+	}
+
+	// Another user can't see the chart.
+	other, _ := cookiejar.New(nil)
+	otherClient := &http.Client{Jar: other}
+	otherClient.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"chart-other@example.com"},
+		"display_name":     {"Other"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	otherClient.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"chart-other@example.com"},
+		"password": {"password123"},
+	})
+	resp, _ = otherClient.Get(srv.URL + patternURL + "/chart.svg")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("chart for other user: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_PatternImportFromText(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"textimport@example.com"},
+		"display_name":     {"Text Importer"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"textimport@example.com"},
+		"password": {"password123"},
+	})
+
+	text := "Rnd 1: 6 sc in MR (6)\nRnd 2: 6 inc (12)\nRnd 3: *sc, inc* repeat 6 times (18)"
+
+	// 1. Preview shows the parsed pattern without saving it.
+	resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Text Ball"},
+		"text":   {text},
+		"action": {"preview"},
+	})
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("preview: expected 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "Rnd 3: *sc, inc* repeat 6 times (18)") || !strings.Contains(string(body), "24 stitches worked") {
+		t.Errorf("preview should show the rendered pattern")
+	}
+	resp, _ = client.Get(srv.URL + "/patterns")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "Text Ball") {
+		t.Fatal("preview should not save the pattern")
+	}
+
+	// 2. Unparseable lines are reported with their line and column.
+	resp, _ = client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Text Ball"},
+		"text":   {"Rnd 1: 6 sc in MR (6)\nRnd 2: sc, wibble"},
+		"action": {"save"},
+	})
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid text: expected 422, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "Line 2, column 12: unknown stitch &#34;wibble&#34;") {
+		t.Errorf("invalid text: expected line/column error in body")
+	}
+
+	// 3. Saving creates the pattern.
+	resp, _ = client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Text Ball"},
+		"text":   {text},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("save: expected 303, got %d", resp.StatusCode)
+	}
+	resp, _ = client.Get(srv.URL + resp.Header.Get("Location"))
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Text Ball") || !strings.Contains(string(body), "Rnd 2: 6 inc (12)") {
+		t.Errorf("saved pattern should show its name and text")
+	}
+}
+
+// importPattern uploads a pattern file to the import endpoint.
+func importPattern(client *http.Client, baseURL, filename string, data []byte) (*http.Response, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(data)
+	writer.Close()
+
+	req, err := http.NewRequest("POST", baseURL+"/patterns/import", &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return client.Do(req)
+}
+
+// uploadImage creates a multipart form request with the given image data.
+func uploadImage(client *http.Client, baseURL, patternID, groupIndex, filename, contentType string, data []byte) (*http.Response, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("image", filename)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(data)
+	writer.Close()
+
+	req, err := http.NewRequest("POST", baseURL+"/patterns/"+patternID+"/parts/"+groupIndex+"/images", &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return client.Do(req)
+}
+
+// createTestPNG returns a minimal valid PNG file.
+func createTestPNG() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// createTestJPEG returns a minimal valid JPEG file.
+func createTestJPEG() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{0, 255, 0, 255})
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, img, nil)
+	return buf.Bytes()
+}
+
+// extractImageURL finds the first /images/{id} URL in the page body.
+func extractImageURL(body string) string {
+	idx := strings.Index(body, "/images/")
+	if idx == -1 {
+		return ""
+	}
+	rest := body[idx:]
+	endIdx := strings.IndexAny(rest, "\"' >")
+	if endIdx == -1 {
+		return rest
+	}
+	return rest[:endIdx]
+}
+
+func TestIntegration_TerminologySetting(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"terms@example.com"},
+		"display_name":     {"Terms User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"terms@example.com"},
+		"password": {"password123"},
+	})
+
+	resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Terms Swatch"},
+		"text":   {"Row 1: ch 1, 4 sc, 2 dc (7)"},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("import text: expected 303, got %d", resp.StatusCode)
+	}
+	patternURL := resp.Header.Get("Location")
+
+	// New accounts read patterns in US terms.
+	resp, _ = client.Get(srv.URL + patternURL)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "US terms") || !strings.Contains(string(body), "4 sc, 2 dc") {
+		t.Error("pattern view should default to US terms")
+	}
+
+	resp, _ = client.PostForm(srv.URL+"/settings", url.Values{"terminology": {"au"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown terminology: expected 422, got %d", resp.StatusCode)
+	}
+
+	resp, _ = client.PostForm(srv.URL+"/settings", url.Values{"terminology": {"uk"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("update settings: expected 303, got %d", resp.StatusCode)
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "UK terms") || !strings.Contains(string(body), "4 dc, 2 tr") {
+		t.Error("pattern view should show UK terms after switching")
+	}
+
+	// The stored pattern is unchanged; only its display follows the setting.
+	resp, _ = client.Get(srv.URL + patternURL + "/export")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `"abbreviation": "sc"`) {
+		t.Error("export should keep the pattern's stored US abbreviations")
+	}
+}
+
+func TestIntegration_WrittenTextStyle(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"written@example.com"},
+		"display_name":     {"Written User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"written@example.com"},
+		"password": {"password123"},
+	})
+
+	resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Beginner Coaster"},
+		"text":   {"Rnd 1: MR, 6 sc in MR (6)\nRnd 2: *sc, inc* repeat 6 times (18)"},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("import text: expected 303, got %d", resp.StatusCode)
+	}
+	patternURL := resp.Header.Get("Location")
+
+	resp, _ = client.Get(srv.URL + patternURL)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "*sc, inc* repeat 6 times (18)") {
+		t.Error("pattern view should default to notation")
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL + "?style=written")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Repeat the sequence 6 times, for a total of 18 stitches.") {
+		t.Error("written style should spell out repeats")
+	}
+	if !strings.Contains(string(body), patternURL+"/pdf?style=written") {
+		t.Error("PDF link should keep the selected style")
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL + "/text?style=written")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("text export: expected 200 text/plain, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "Rnd 1: Work magic ring, then 6 single crochet in the magic ring, for a total of 6 stitches.") {
+		t.Errorf("text export missing written round 1:\n%s", body)
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL + "/text")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Rnd 1: MR, 6 sc in MR (6)") {
+		t.Errorf("text export should default to notation:\n%s", body)
+	}
+
+	resp, _ = client.Get(srv.URL + patternURL + "/pdf?style=written")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("written PDF: expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_PatternSearch(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"search@example.com"},
+		"display_name":     {"Search User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"search@example.com"},
+		"password": {"password123"},
+	})
+
+	for name, text := range map[string]string{
+		"Popcorn Hat":      "Rnd 1: MR, 6 sc in MR (6)\nRnd 2: *puff, sc* repeat 6 times (12)",
+		"Popcorn Hat Trim": "Rnd 1: MR, 6 sc in MR (6)",
+	} {
+		resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+			"name":   {name},
+			"text":   {text},
+			"action": {"save"},
+		})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Fatalf("import %q: expected 303, got %d", name, resp.StatusCode)
+		}
+	}
+
+	resp, _ := client.Get(srv.URL + "/patterns?q=" + url.QueryEscape("popcorn hat uses: puff"))
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("search: expected 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "Pattern: Popcorn Hat\"") {
+		t.Error("search should find the pattern using puff stitches")
+	}
+	if strings.Contains(string(body), "Popcorn Hat Trim") {
+		t.Error("uses: filter should exclude patterns without puff stitches")
+	}
+	if !strings.Contains(string(body), "<mark>Popcorn</mark>") {
+		t.Error("search results should highlight matched terms")
+	}
+	if !strings.Contains(string(body), "Best Match") {
+		t.Error("searches should offer sorting by best match")
+	}
+}
+
+func TestIntegration_PatternStitchFilters(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+	predefined, err := stitches.ListPredefined(context.Background())
+	if err != nil {
+		t.Fatalf("ListPredefined: %v", err)
+	}
+	stitchIDs := map[string]string{}
+	for _, s := range predefined {
+		stitchIDs[s.Abbreviation] = strconv.FormatInt(s.ID, 10)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"filters@example.com"},
+		"display_name":     {"Filter User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"filters@example.com"},
+		"password": {"password123"},
+	})
+
+	for name, text := range map[string]string{
+		"Beginner Ball": "Rnd 1: 6 sc (6)\nRnd 2: 6 inc (12)\nRnd 3: 6 dec (6)",
+		"Cabled Cowl":   "Row 1: 10 sc (10)\nRow 2: 10 FPdc (10)",
+	} {
+		resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+			"name":   {name},
+			"text":   {text},
+			"action": {"save"},
+		})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Fatalf("import %q: expected 303, got %d", name, resp.StatusCode)
+		}
+	}
+
+	list := func(query string) string {
+		t.Helper()
+		resp, _ := client.Get(srv.URL + "/patterns?" + query)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("list %q: expected 200, got %d", query, resp.StatusCode)
+		}
+		return string(body)
+	}
+
+	body := list("include=" + stitchIDs["FPdc"])
+	if !strings.Contains(body, "Pattern: Cabled Cowl") || strings.Contains(body, "Pattern: Beginner Ball") {
+		t.Error("include filter should only list patterns using FPdc")
+	}
+	body = list("exclude=" + stitchIDs["FPdc"])
+	if strings.Contains(body, "Pattern: Cabled Cowl") || !strings.Contains(body, "Pattern: Beginner Ball") {
+		t.Error("exclude filter should hide patterns using FPdc")
+	}
+
+	for _, abbr := range []string{"sc", "inc", "dec"} {
+		resp, _ := client.PostForm(srv.URL+"/stitches/"+stitchIDs[abbr]+"/known", url.Values{
+			"known":    {"1"},
+			"category": {"basic"},
+		})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Fatalf("mark %s known: expected 303, got %d", abbr, resp.StatusCode)
+		}
+		if loc := resp.Header.Get("Location"); loc != "/stitches?category=basic" {
+			t.Errorf("mark known: redirected to %q, want the filtered library", loc)
+		}
+	}
+
+	body = list("known=1")
+	if strings.Contains(body, "Pattern: Cabled Cowl") || !strings.Contains(body, "Pattern: Beginner Ball") {
+		t.Error("known filter should only list patterns made of known stitches")
+	}
+
+	resp, _ := client.Get(srv.URL + "/stitches")
+	libBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(libBody), "Mark sc as not known") || !strings.Contains(string(libBody), "Mark FPdc as known") {
+		t.Error("stitch library should show which stitches are known")
+	}
+
+	resp, _ = client.PostForm(srv.URL+"/stitches/99999/known", url.Values{"known": {"1"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown stitch: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_PatternListLoadMore(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"pages@example.com"},
+		"display_name":     {"Page User"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"pages@example.com"},
+		"password": {"password123"},
+	})
+
+	for _, name := range []string{"Pattern A", "Pattern B", "Pattern C"} {
+		resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+			"name":   {name},
+			"text":   {"Rnd 1: 6 sc (6)"},
+			"action": {"save"},
+		})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Fatalf("import %q: expected 303, got %d", name, resp.StatusCode)
+		}
+	}
+
+	resp, _ := client.Get(srv.URL + "/patterns?sort=name&limit=2")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	page := string(body)
+	if !strings.Contains(page, "Pattern: Pattern A") || !strings.Contains(page, "Pattern: Pattern B") || strings.Contains(page, "Pattern: Pattern C") {
+		t.Fatal("first page should hold the first two patterns by name")
+	}
+	unescaped := html.UnescapeString(page)
+	start := strings.Index(unescaped, "/patterns/more?")
+	if start == -1 {
+		t.Fatal("first page should offer to load more")
+	}
+	moreURL := unescaped[start : start+strings.IndexAny(unescaped[start:], `'"`)]
+	if !strings.Contains(moreURL, "sort=name") || !strings.Contains(moreURL, "limit=2") {
+		t.Errorf("load-more URL %q should keep the list's sort and page size", moreURL)
+	}
+
+	resp, _ = client.Get(srv.URL + moreURL)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("load more: expected an SSE response, got %q", ct)
+	}
+	more := string(body)
+	if !strings.Contains(more, "Pattern: Pattern C") || strings.Contains(more, "Pattern: Pattern A") {
+		t.Error("load more should append the remaining pattern")
+	}
+	if strings.Contains(more, "/patterns/more?") {
+		t.Error("the last page should remove the load-more control")
+	}
+
+	resp, _ = client.Get(srv.URL + "/patterns/more?section=mine&sort=name&after=bogus")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("bad cursor: expected 422, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_TagsAndCollections(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	newClient := func(email string) *http.Client {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		client.PostForm(srv.URL+"/register", url.Values{
+			"email":            {email},
+			"display_name":     {"Organizer"},
+			"password":         {"password123"},
+			"confirm_password": {"password123"},
+		})
+		client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {email},
+			"password": {"password123"},
+		})
+		return client
+	}
+	client := newClient("organize@example.com")
+
+	for _, name := range []string{"Hat", "Scarf"} {
+		resp, _ := client.PostForm(srv.URL+"/patterns/import/text", url.Values{
+			"name":   {name},
+			"text":   {"Rnd 1: 6 sc (6)"},
+			"action": {"save"},
+		})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Fatalf("import %q: expected 303, got %d", name, resp.StatusCode)
+		}
+	}
+
+	get := func(path string) string {
+		t.Helper()
+		resp, _ := client.Get(srv.URL + path)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		return html.UnescapeString(string(body))
+	}
+	post := func(path string, form url.Values) *http.Response {
+		t.Helper()
+		resp, err := client.PostForm(srv.URL+path, form)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	body := get("/patterns")
+	patternID := func(name string) string {
+		t.Helper()
+		m := regexp.MustCompile(`value="(\d+)" form="bulk-form" aria-label="Select ` + name + `"`).FindStringSubmatch(body)
+		if m == nil {
+			t.Fatalf("no selection checkbox for %s", name)
+		}
+		return m[1]
+	}
+	hatID, scarfID := patternID("Hat"), patternID("Scarf")
+
+	// Tag both patterns with a new tag, then find its ID on the tags page.
+	resp := post("/patterns/bulk", url.Values{"action": {"tag"}, "new_tag": {"winter"}, "pattern": {hatID, scarfID}, "list": {"sort=name"}})
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/patterns?sort=name" {
+		t.Fatalf("bulk tag: expected 303 back to the list, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	tags := get("/tags")
+	m := regexp.MustCompile(`href="/patterns\?tag=(\d+)">winter</a>`).FindStringSubmatch(tags)
+	if m == nil {
+		t.Fatal("tags page should link to the winter tag")
+	}
+	tagID := m[1]
+
+	resp = post("/patterns/bulk", url.Values{"action": {"untag"}, "tag": {tagID}, "pattern": {scarfID}})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("bulk untag: expected 303, got %d", resp.StatusCode)
+	}
+	body = get("/patterns?tag=" + tagID)
+	if !strings.Contains(body, "Pattern: Hat") || strings.Contains(body, "Pattern: Scarf") {
+		t.Error("tag filter should only list the tagged pattern")
+	}
+	if resp := post("/patterns/bulk", url.Values{"action": {"tag"}, "new_tag": {"gifts"}}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("bulk tag with nothing selected: expected 422, got %d", resp.StatusCode)
+	}
+
+	// Collect both patterns, then swap their order.
+	resp = post("/collections", url.Values{"name": {"Christmas 2026 gifts"}})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("create collection: expected 303, got %d", resp.StatusCode)
+	}
+	collectionURL := resp.Header.Get("Location")
+	collectionID := strings.TrimPrefix(collectionURL, "/collections/")
+	resp = post("/patterns/bulk", url.Values{"action": {"collect"}, "collection": {collectionID}, "pattern": {hatID, scarfID}})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("bulk collect: expected 303, got %d", resp.StatusCode)
+	}
+	body = get(collectionURL)
+	if strings.Index(body, ">Hat</a>") > strings.Index(body, ">Scarf</a>") {
+		t.Error("collection should list patterns in the order they were added")
+	}
+	if resp := post(collectionURL+"/order", url.Values{"pattern": {scarfID, hatID}}); resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("reorder: expected 303, got %d", resp.StatusCode)
+	}
+	body = get(collectionURL)
+	if strings.Index(body, ">Scarf</a>") > strings.Index(body, ">Hat</a>") {
+		t.Error("collection should list patterns in the new order")
+	}
+	if resp := post(collectionURL+"/order", url.Values{"pattern": {scarfID}}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("reorder missing a pattern: expected 422, got %d", resp.StatusCode)
+	}
+
+	other := newClient("not-organizer@example.com")
+	resp, _ = other.Get(srv.URL + collectionURL)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("another user's collection: expected 404, got %d", resp.StatusCode)
+	}
+
+	if resp := post(collectionURL+"/delete", nil); resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("delete collection: expected 303, got %d", resp.StatusCode)
+	}
+	if body := get("/collections"); strings.Contains(body, "Collection: Christmas 2026 gifts") {
+		t.Error("deleted collection should not be listed")
+	}
+}
+
+func TestIntegration_PublicGallery(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	newClient := func(email, name string) *http.Client {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		client.PostForm(srv.URL+"/register", url.Values{
+			"email":            {email},
+			"display_name":     {name},
+			"password":         {"password123"},
+			"confirm_password": {"password123"},
+		})
+		client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {email},
+			"password": {"password123"},
+		})
+		return client
+	}
+	author := newClient("author@example.com", "Author")
+	reader := newClient("reader@example.com", "Reader")
+
+	get := func(client *http.Client, path string) (int, string) {
+		t.Helper()
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode, html.UnescapeString(string(body))
+	}
+	post := func(client *http.Client, path string, form url.Values) *http.Response {
+		t.Helper()
+		resp, err := client.PostForm(srv.URL+path, form)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := post(author, "/patterns/import/text", url.Values{
+		"name":   {"Granny Square"},
+		"text":   {"Rnd 1: 6 sc (6)"},
+		"action": {"save"},
+	})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("import: expected 303, got %d", resp.StatusCode)
+	}
+	patternPath := resp.Header.Get("Location")
+	id := strings.TrimPrefix(patternPath, "/patterns/")
+
+	if _, body := get(reader, "/gallery"); strings.Contains(body, "Granny Square") {
+		t.Fatal("an unpublished pattern should not be in the gallery")
+	}
+	if resp := post(reader, patternPath+"/publish", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("publishing another user's pattern: expected 404, got %d", resp.StatusCode)
+	}
+
+	resp = post(author, patternPath+"/publish", nil)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != patternPath {
+		t.Fatalf("publish: expected 303 to the pattern, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if _, body := get(author, patternPath); !strings.Contains(body, "Unpublish") {
+		t.Fatal("a published pattern's page should offer to unpublish it")
+	}
+
+	status, body := get(reader, "/gallery?q=granny")
+	if status != http.StatusOK {
+		t.Fatalf("GET /gallery: expected 200, got %d", status)
+	}
+	if !strings.Contains(body, "Granny Square") || !strings.Contains(body, "By Author") {
+		t.Fatal("the gallery should list the published pattern with its author")
+	}
+	if _, body := get(reader, "/gallery?type=row"); strings.Contains(body, "Granny Square") {
+		t.Fatal("the gallery type filter should hide round patterns")
+	}
+
+	status, _ = get(reader, "/gallery/"+id)
+	if status != http.StatusSeeOther {
+		t.Fatalf("GET /gallery/{id}: expected 303 to the preview, got %d", status)
+	}
+
+	resp = post(reader, "/gallery/"+id+"/save", nil)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/patterns" {
+		t.Fatalf("save from gallery: expected 303 to /patterns, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if _, body := get(reader, "/patterns"); !strings.Contains(body, "Shared by Author") {
+		t.Fatal("the saved copy should be in the reader's library")
+	}
+
+	resp = post(author, patternPath+"/unpublish", nil)
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("unpublish: expected 303, got %d", resp.StatusCode)
+	}
+	if _, body := get(reader, "/gallery"); strings.Contains(body, "Granny Square") {
+		t.Fatal("an unpublished pattern should leave the gallery immediately")
+	}
+	if status, _ := get(reader, "/gallery/"+id); status != http.StatusNotFound {
+		t.Fatalf("GET /gallery/{id} after unpublishing: expected 404, got %d", status)
+	}
+	if resp := post(reader, "/gallery/"+id+"/save", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("save after unpublishing: expected 404, got %d", resp.StatusCode)
+	}
+	if _, body := get(reader, "/patterns"); !strings.Contains(body, "Shared by Author") {
+		t.Fatal("copies saved before unpublishing should be kept")
+	}
+}
+
+func TestIntegration_AnonymousShareLink(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	noRedirect := func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	jar, _ := cookiejar.New(nil)
+	owner := &http.Client{Jar: jar, CheckRedirect: noRedirect}
+	owner.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"sharer@example.com"},
+		"display_name":     {"Sharer"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	owner.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"sharer@example.com"},
+		"password": {"password123"},
+	})
+
+	resp, _ := owner.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Open Hat"},
+		"text":   {"Rnd 1: 6 sc (6)"},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	patternPath := resp.Header.Get("Location")
+	patternID := strings.TrimPrefix(patternPath, "/patterns/")
+
+	resp, err := uploadImage(owner, srv.URL, patternID, "0", "hat.png", "image/png", createTestPNG())
+	if err != nil {
+		t.Fatalf("upload image: %v", err)
+	}
+	resp.Body.Close()
+
+	owner.PostForm(srv.URL+patternPath+"/share", nil)
+	owner.PostForm(srv.URL+patternPath+"/share/email", url.Values{"recipient_email": {"friend@example.com"}})
+	resp, _ = owner.Get(srv.URL + patternPath)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	shareLink := func(kind string) string {
+		t.Helper()
+		m := regexp.MustCompile(`(?s)>` + kind + `</span>.*?(/s/[0-9a-f]{64})`).FindStringSubmatch(string(body))
+		if m == nil {
+			t.Fatalf("no %s share link on the pattern page", kind)
+		}
+		return m[1]
+	}
+	globalPath, emailPath := shareLink("Global"), shareLink("Email")
+
+	anon := &http.Client{CheckRedirect: noRedirect}
+
+	resp, _ = anon.Get(srv.URL + globalPath)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("anonymous global link: expected 200, got %d", resp.StatusCode)
+	}
+	page := html.UnescapeString(string(body))
+	if !strings.Contains(page, "Open Hat") || !strings.Contains(page, "Stitch Glossary") {
+		t.Fatal("the anonymous preview should show the pattern and its glossary")
+	}
+	loginURL := "/login?next=" + url.QueryEscape(globalPath)
+	if !strings.Contains(page, loginURL) || strings.Contains(page, globalPath+"/save") {
+		t.Fatal("the anonymous preview should ask to log in instead of offering to save")
+	}
+
+	imageURL := regexp.MustCompile(`/shared-images/\d+\?[^"']+`).FindString(page)
+	if imageURL == "" {
+		t.Fatal("the anonymous preview should show the pattern's images through signed URLs")
+	}
+	resp, _ = anon.Get(srv.URL + imageURL)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("anonymous shared image: expected 200 image/png, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	// The signature covers the share token, so it can't be moved to another share.
+	swapped := strings.Replace(imageURL, "token="+strings.TrimPrefix(globalPath, "/s/"), "token="+strings.TrimPrefix(emailPath, "/s/"), 1)
+	resp, _ = anon.Get(srv.URL + swapped)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("shared image with another share's token: expected 403, got %d", resp.StatusCode)
+	}
+	resp, _ = anon.Get(srv.URL + strings.Replace(imageURL, "sig=", "sig=x", 1))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("shared image with a tampered signature: expected 403, got %d", resp.StatusCode)
+	}
+
+	// Email-bound shares still need the matching account.
+	resp, _ = anon.Get(srv.URL + emailPath)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/login?next="+url.QueryEscape(emailPath) {
+		t.Fatalf("anonymous email link: expected 303 to login, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	// Signing up and logging in from the preview returns to it, ready to save.
+	jar, _ = cookiejar.New(nil)
+	visitor := &http.Client{Jar: jar, CheckRedirect: noRedirect}
+	resp, _ = visitor.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"visitor@example.com"},
+		"display_name":     {"Visitor"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+		"next":             {globalPath},
+	})
+	resp.Body.Close()
+	if resp.Header.Get("Location") != loginURL {
+		t.Fatalf("register: expected redirect to %s, got %s", loginURL, resp.Header.Get("Location"))
+	}
+	resp, _ = visitor.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"visitor@example.com"},
+		"password": {"password123"},
+		"next":     {globalPath},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != globalPath {
+		t.Fatalf("login: expected 303 back to the share, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, _ = visitor.PostForm(srv.URL+globalPath+"/save", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/patterns" {
+		t.Fatalf("save after login: expected 303 to /patterns, got %d", resp.StatusCode)
+	}
+
+	// Off-site return addresses are ignored.
+	resp, _ = visitor.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"visitor@example.com"},
+		"password": {"password123"},
+		"next":     {"//evil.example.com"},
+	})
+	resp.Body.Close()
+	if resp.Header.Get("Location") != "/" {
+		t.Fatalf("login with an off-site next: expected redirect to /, got %s", resp.Header.Get("Location"))
+	}
+}
+
+func TestIntegration_LoginRejectsOffSiteNext(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, _ := client.PostForm(srv.URL+"/register", url.Values{
+		"email":            {"next@example.com"},
+		"display_name":     {"Next"},
+		"password":         {"password123"},
+		"confirm_password": {"password123"},
+	})
+	resp.Body.Close()
+
+	// Browsers drop tabs and newlines from redirect targets and read
+	// backslashes as slashes, so each of these would lead to //evil.example.com.
+	for _, next := range []string{
+		"https://evil.example.com",
+		"/\t/evil.example.com",
+		"/\n/evil.example.com",
+		"/\\evil.example.com",
+		"/\\/evil.example.com",
+	} {
+		resp, err := client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {"next@example.com"},
+			"password": {"password123"},
+			"next":     {next},
+		})
+		if err != nil {
+			t.Errorf("login with next %q: %v", next, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/" {
+			t.Errorf("login with next %q: expected 303 to /, got %d %q", next, resp.StatusCode, resp.Header.Get("Location"))
+		}
+	}
+
+	resp, _ = client.Get(srv.URL + "/login?next=%2F%09%2Fevil.example.com")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "evil.example.com") {
+		t.Fatal("the login page should drop an off-site next")
+	}
+
+	resp, _ = client.PostForm(srv.URL+"/login", url.Values{
+		"email":    {"next@example.com"},
+		"password": {"password123"},
+		"next":     {"/patterns?q=hat"},
+	})
+	resp.Body.Close()
+	if resp.Header.Get("Location") != "/patterns?q=hat" {
+		t.Fatalf("login with a local next: expected redirect to it, got %q", resp.Header.Get("Location"))
+	}
+}
+
+func TestIntegration_ShareLimitsAndActivity(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	newClient := func(email, name string) *http.Client {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		client.PostForm(srv.URL+"/register", url.Values{
+			"email":            {email},
+			"display_name":     {name},
+			"password":         {"password123"},
+			"confirm_password": {"password123"},
+		})
+		client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {email},
+			"password": {"password123"},
+		})
+		return client
+	}
+	owner := newClient("limits-owner@example.com", "Owner")
+	first := newClient("limits-first@example.com", "First Friend")
+	second := newClient("limits-second@example.com", "Second Friend")
+
+	resp, _ := owner.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Limited Hat"},
+		"text":   {"Rnd 1: 6 sc (6)"},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	patternPath := resp.Header.Get("Location")
+	owner.PostForm(srv.URL+patternPath+"/share", nil)
+
+	ownerPage := func() string {
+		t.Helper()
+		resp, _ := owner.Get(srv.URL + patternPath)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return html.UnescapeString(string(body))
+	}
+	body := ownerPage()
+	m := regexp.MustCompile(`/share/(\d+)/limits`).FindStringSubmatch(body)
+	if m == nil {
+		t.Fatal("the pattern page should offer limits for the share")
+	}
+	limitsPath := patternPath + "/share/" + m[1] + "/limits"
+	sharePath := regexp.MustCompile(`/s/[0-9a-f]{64}`).FindString(body)
+	if !strings.Contains(body, "Not opened yet") {
+		t.Fatal("a new share should have no activity")
+	}
+
+	resp, _ = owner.PostForm(srv.URL+limitsPath, url.Values{"expires": {"2000-01-01"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expiry in the past: expected 422, got %d", resp.StatusCode)
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	resp, _ = owner.PostForm(srv.URL+limitsPath, url.Values{"expires": {tomorrow}, "max_saves": {"1"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("set limits: expected 303, got %d", resp.StatusCode)
+	}
+	body = ownerPage()
+	if !strings.Contains(body, `value="`+tomorrow+`"`) || !strings.Contains(body, `value="1"`) {
+		t.Fatal("the share's limits should be shown in its form")
+	}
+
+	resp, _ = first.Get(srv.URL + sharePath)
+	resp.Body.Close()
+	resp, _ = first.PostForm(srv.URL+sharePath+"/save", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("first save: expected 303, got %d", resp.StatusCode)
+	}
+	resp, _ = second.PostForm(srv.URL+sharePath+"/save", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGone {
+		t.Fatalf("save past the limit: expected 410, got %d", resp.StatusCode)
+	}
+
+	body = ownerPage()
+	if !strings.Contains(body, "1 views · 1 saves") || !strings.Contains(body, "Saved</strong> by First Friend") || !strings.Contains(body, "Opened by First Friend") {
+		t.Fatal("the owner should see who opened and saved the link")
+	}
+}
+
+func TestIntegration_SharePermissions(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+
+	if err := stitches.SeedPredefined(context.Background()); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	newClient := func(email, name string) *http.Client {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		client.PostForm(srv.URL+"/register", url.Values{
+			"email":            {email},
+			"display_name":     {name},
+			"password":         {"password123"},
+			"confirm_password": {"password123"},
+		})
+		client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {email},
+			"password": {"password123"},
+		})
+		return client
+	}
+	owner := newClient("perm-owner@example.com", "Owner")
+	friend := newClient("perm-friend@example.com", "Friend")
+
+	resp, _ := owner.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Fork Me Hat"},
+		"text":   {"Rnd 1: 6 sc (6)"},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	patternPath := resp.Header.Get("Location")
+
+	resp, _ = owner.PostForm(srv.URL+patternPath+"/share", url.Values{"permission": {"everything"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown permission: expected 400, got %d", resp.StatusCode)
+	}
+	owner.PostForm(srv.URL+patternPath+"/share", url.Values{"permission": {"view"}})
+	owner.PostForm(srv.URL+patternPath+"/share", url.Values{"permission": {"save_editable"}})
+
+	resp, _ = owner.Get(srv.URL + patternPath)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	linkFor := func(label string) string {
+		t.Helper()
+		m := regexp.MustCompile(`(?s)>` + label + `</td>.*?(/s/[0-9a-f]{64})`).FindStringSubmatch(string(body))
+		if m == nil {
+			t.Fatalf("no %q share link on the pattern page", label)
+		}
+		return m[1]
+	}
+	viewPath := linkFor("View only")
+	editPath := linkFor("Editable copy")
+
+	resp, _ = friend.Get(srv.URL + viewPath)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "shared for viewing only") || strings.Contains(string(body), viewPath+"/save") {
+		t.Fatal("a view-only link should not offer saving")
+	}
+	resp, _ = friend.PostForm(srv.URL+viewPath+"/save", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("saving a view-only link: expected 403, got %d", resp.StatusCode)
+	}
+
+	resp, _ = friend.PostForm(srv.URL+editPath+"/save", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("saving an editable link: expected 303, got %d", resp.StatusCode)
+	}
+	resp, _ = friend.Get(srv.URL + editPath)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	m := regexp.MustCompile(`href="(/patterns/\d+)">View Your Copy`).FindStringSubmatch(string(body))
+	if m == nil {
+		t.Fatal("the preview should link to the saved copy")
+	}
+	copyPath := m[1]
+
+	resp, _ = friend.Get(srv.URL + copyPath)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Based on a pattern by Owner") {
+		t.Fatal("an editable copy should credit its author")
+	}
+	resp, _ = friend.Get(srv.URL + copyPath + "/edit")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("editing an editable copy: expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_UpdateFromSource(t *testing.T) {
+	auth, stitches, patterns, sessions, images, shares, users := newTestServices(t)
+	ctx := context.Background()
+
+	if err := stitches.SeedPredefined(ctx); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, auth, stitches, patterns, sessions, images, shares, users, false)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	newClient := func(email, name string) *http.Client {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		client.PostForm(srv.URL+"/register", url.Values{
+			"email":            {email},
+			"display_name":     {name},
+			"password":         {"password123"},
+			"confirm_password": {"password123"},
+		})
+		client.PostForm(srv.URL+"/login", url.Values{
+			"email":    {email},
+			"password": {"password123"},
+		})
+		return client
+	}
+	owner := newClient("source-owner@example.com", "Designer")
+	friend := newClient("source-friend@example.com", "Friend")
+	get := func(client *http.Client, path string) string {
+		t.Helper()
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return string(body)
+	}
+
+	resp, _ := owner.PostForm(srv.URL+"/patterns/import/text", url.Values{
+		"name":   {"Buggy Coaster"},
+		"text":   {"Rnd 1: 6 sc (6)"},
+		"action": {"save"},
+	})
+	resp.Body.Close()
+	patternPath := resp.Header.Get("Location")
+	owner.PostForm(srv.URL+patternPath+"/share", nil)
+	sharePath := regexp.MustCompile(`/s/[0-9a-f]{64}`).FindString(get(owner, patternPath))
+
+	resp, _ = friend.PostForm(srv.URL+sharePath+"/save", nil)
+	resp.Body.Close()
+	m := regexp.MustCompile(`href="(/patterns/\d+)">View Your Copy`).FindStringSubmatch(get(friend, sharePath))
+	if m == nil {
+		t.Fatal("the preview should link to the saved copy")
+	}
+	copyPath := m[1]
+	if strings.Contains(get(friend, "/patterns?section=shared"), "Update available") {
+		t.Fatal("a fresh copy should not have an update")
+	}
+
+	// The friend gets five stitches into the round.
+	resp, _ = friend.PostForm(srv.URL+copyPath+"/start-session", nil)
+	resp.Body.Close()
+	sessionPath := resp.Header.Get("Location")
+	for range 5 {
+		resp, _ = friend.PostForm(srv.URL+sessionPath+"/next", nil)
+		resp.Body.Close()
+	}
+
+	// The designer fixes the round to four stitches.
+	ownerUser, err := users.GetByEmail(ctx, "source-owner@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	patternID, _ := strconv.ParseInt(strings.TrimPrefix(patternPath, "/patterns/"), 10, 64)
+	fixed, err := patterns.GetByID(ctx, patternID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	for i := range fixed.InstructionGroups[0].StitchEntries {
+		e := &fixed.InstructionGroups[0].StitchEntries[i]
+		for _, ps := range fixed.PatternStitches {
+			if ps.ID == e.PatternStitchID {
+				e.PatternStitchID = *ps.LibraryStitchID
+			}
+		}
+		e.Count = 4
+	}
+	if err := patterns.Update(ctx, ownerUser.ID, fixed); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if !strings.Contains(get(friend, "/patterns?section=shared"), "Update available") {
+		t.Fatal("the copy should show an update after the designer's fix")
+	}
+	if !strings.Contains(get(friend, copyPath), "Get the Latest Version") {
+		t.Fatal("the copy's page should offer the update")
+	}
+
+	resp, _ = friend.PostForm(srv.URL+copyPath+"/update-from-source", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("update from source: expected 303, got %d", resp.StatusCode)
+	}
+	body := get(friend, resp.Header.Get("Location"))
+	if !strings.Contains(body, "updated to the latest version") || !strings.Contains(body, "starts again from the beginning") {
+		t.Fatal("the copy should confirm the update and warn that the session restarted")
+	}
+	if strings.Contains(body, "Get the Latest Version") {
+		t.Fatal("an updated copy should not offer the update again")
+	}
+
+	resp, _ = friend.PostForm(srv.URL+copyPath+"/update-from-source", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("updating an up-to-date copy: expected 422, got %d", resp.StatusCode)
+	}
+	resp, _ = owner.PostForm(srv.URL+copyPath+"/update-from-source", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("updating another user's copy: expected 404, got %d", resp.StatusCode)
 	}
-	return rest[:endIdx]
 }
@@ -16,6 +16,13 @@ import (
 const testJWTSecret = "test-secret-for-handler-tests"
 
 func newTestServices(t *testing.T) (*service.AuthService, *service.StitchService, *service.PatternService, *service.WorkSessionService, *service.ImageService, *service.ShareService, domain.UserRepository) {
+	t.Helper()
+	return newTestServicesWithFiles(t, func(files domain.FileStore) domain.FileStore { return files })
+}
+
+// newTestServicesWithFiles is newTestServices with the image file store
+// passed through wrap, so a test can make storage fail.
+func newTestServicesWithFiles(t *testing.T, wrap func(domain.FileStore) domain.FileStore) (*service.AuthService, *service.StitchService, *service.PatternService, *service.WorkSessionService, *service.ImageService, *service.ShareService, domain.UserRepository) {
 	t.Helper()
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	db, err := sqlite.New(dbPath)
@@ -29,9 +36,9 @@ func newTestServices(t *testing.T) (*service.AuthService, *service.StitchService
 
 	return service.NewAuthService(db.Users(), testJWTSecret, 4),
 		service.NewStitchService(db.Stitches()),
-		service.NewPatternService(db.Patterns(), db.Stitches()),
+		service.NewPatternService(db.Patterns(), db.Stitches(), db.Versions(), db.Tags(), db.Collections(), 50),
 		service.NewWorkSessionService(db.Sessions(), db.Patterns()),
-		service.NewImageService(db.PatternImages(), db.FileStore(), db.Patterns()),
+		service.NewImageService(db.PatternImages(), wrap(db.FileStore()), db.Patterns(), service.SharedImageSigningKey(testJWTSecret)),
 		service.NewShareService(db.Shares(), db.Patterns(), db.Users()),
 		db.Users()
 }
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -37,100 +42,768 @@ func (h *PatternHandler) HandleList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse search/filter query parameters.
-	filter := domain.PatternFilter{
+	filter := patternFilterFromQuery(r)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	patterns, err := h.listOwnPatterns(r.Context(), user.ID, filter, domain.PatternPage{Limit: limit})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("list patterns", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	sharedPatterns, err := h.patterns.ListSummarySharedWithUser(r.Context(), user.ID, domain.PatternPage{Limit: limit})
+	if err != nil {
+		slog.Error("list shared patterns", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	allStitches, err := h.stitches.ListAll(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list stitches", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.patterns.ListTags(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list tags", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	collections, err := h.patterns.ListCollections(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list collections", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	sharedMap := h.shareIndicators(r.Context(), patterns.Summaries)
+	view.PatternListPage(user.DisplayName, patterns, sharedPatterns, sharedMap, filter, allStitches, tags, collections, limit).Render(r.Context(), w)
+}
+
+// HandleListMore returns an SSE response that appends the next page of a
+// pattern list section, "mine" or "shared", and replaces its load-more
+// control. The page continues from the after cursor with the list's filter.
+func (h *PatternHandler) HandleListMore(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter := patternFilterFromQuery(r)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page := domain.PatternPage{Limit: limit, After: r.URL.Query().Get("after")}
+	section := r.URL.Query().Get("section")
+
+	var result domain.PatternSummaryPage
+	var err error
+	if section == "shared" {
+		result, err = h.patterns.ListSummarySharedWithUser(r.Context(), user.ID, page)
+	} else {
+		section = "mine"
+		result, err = h.listOwnPatterns(r.Context(), user.ID, filter, page)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("list more patterns", "section", section, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	sse := datastar.NewSSE(w, r)
+	if section == "shared" {
+		sse.PatchElementTempl(
+			view.SharedPatternCards(result.Summaries),
+			datastar.WithSelectorID("shared-pattern-cards"),
+			datastar.WithModeAppend(),
+		)
+		sse.PatchElementTempl(view.LoadMorePatterns(section, result.Next, domain.PatternFilter{}, limit))
+		return
+	}
+	sse.PatchElementTempl(
+		view.PatternCards(result.Summaries, h.shareIndicators(r.Context(), result.Summaries)),
+		datastar.WithSelectorID("pattern-cards"),
+		datastar.WithModeAppend(),
+	)
+	sse.PatchElementTempl(view.LoadMorePatterns(section, result.Next, filter, limit))
+}
+
+// patternFilterFromQuery reads the pattern list's search, filter and sort
+// options from the query string.
+func patternFilterFromQuery(r *http.Request) domain.PatternFilter {
+	return domain.PatternFilter{
 		Query:      strings.TrimSpace(r.URL.Query().Get("q")),
 		Type:       r.URL.Query().Get("type"),
 		Difficulty: r.URL.Query().Get("difficulty"),
 		Sort:       r.URL.Query().Get("sort"),
+		Include:    queryInt64s(r, "include"),
+		Exclude:    queryInt64s(r, "exclude"),
+		KnownOnly:  r.URL.Query().Get("known") == "1",
+		Tags:       queryInt64s(r, "tag"),
+	}
+}
+
+// listOwnPatterns returns a page of the user's own patterns, searched when
+// the filter has any options set.
+func (h *PatternHandler) listOwnPatterns(ctx context.Context, userID int64, filter domain.PatternFilter, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	hasFilter := filter.Query != "" || filter.Type != "" || filter.Difficulty != "" || filter.Sort != "" || filter.HasStitchFilter() || len(filter.Tags) > 0
+	if hasFilter {
+		return h.patterns.SearchSummaryByUser(ctx, userID, filter, page)
+	}
+	return h.patterns.ListSummaryByUser(ctx, userID, page)
+}
+
+// shareIndicators reports which of the patterns have active share links.
+// Failures are logged and show no indicators.
+func (h *PatternHandler) shareIndicators(ctx context.Context, patterns []domain.PatternSummary) map[int64]bool {
+	var patternIDs []int64
+	for _, p := range patterns {
+		patternIDs = append(patternIDs, p.ID)
+	}
+	if len(patternIDs) == 0 {
+		return map[int64]bool{}
+	}
+	sharedMap, err := h.shares.HasSharesByPatternIDs(ctx, patternIDs)
+	if err != nil {
+		slog.Error("check share indicators", "error", err)
+		return map[int64]bool{}
+	}
+	return sharedMap
+}
+
+// queryInt64s parses every value of a repeated query parameter as an ID,
+// skipping malformed values.
+func queryInt64s(r *http.Request, key string) []int64 {
+	var ids []int64
+	for _, v := range r.URL.Query()[key] {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// HandleBulk applies an action to the patterns selected on the list page:
+// "tag" adds the chosen tag, or a new one named in new_tag; "untag" removes
+// the chosen tag; "collect" adds them to the chosen collection. It returns to
+// the list with the filter in the form's list field.
+// POST /patterns/bulk
+func (h *PatternHandler) HandleBulk(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
 
-	hasFilter := filter.Query != "" || filter.Type != "" || filter.Difficulty != "" || filter.Sort != ""
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
 
-	var patterns []domain.PatternSummary
+	patternIDs := formInt64s(r, "pattern")
+	tagID, _ := strconv.ParseInt(r.FormValue("tag"), 10, 64)
 	var err error
-	if hasFilter {
-		patterns, err = h.patterns.SearchSummaryByUser(r.Context(), user.ID, filter)
-	} else {
-		patterns, err = h.patterns.ListSummaryByUser(r.Context(), user.ID)
+	switch r.FormValue("action") {
+	case "tag":
+		if name := strings.TrimSpace(r.FormValue("new_tag")); name != "" {
+			_, err = h.patterns.TagPatternsByName(r.Context(), user.ID, name, patternIDs)
+		} else {
+			err = h.patterns.TagPatterns(r.Context(), user.ID, tagID, patternIDs)
+		}
+	case "untag":
+		err = h.patterns.UntagPatterns(r.Context(), user.ID, tagID, patternIDs)
+	case "collect":
+		collectionID, _ := strconv.ParseInt(r.FormValue("collection"), 10, 64)
+		err = h.patterns.AddToCollection(r.Context(), user.ID, collectionID, patternIDs)
+	default:
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
 	}
 	if err != nil {
-		slog.Error("list patterns", "error", err)
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("bulk pattern action", "action", r.FormValue("action"), "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	sharedPatterns, err := h.patterns.ListSummarySharedWithUser(r.Context(), user.ID)
+	target := "/patterns"
+	if list, err := url.ParseQuery(r.FormValue("list")); err == nil && len(list) > 0 {
+		target += "?" + list.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// HandleNew renders the pattern creation form.
+func (h *PatternHandler) HandleNew(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	allStitches, err := h.stitches.ListAll(r.Context(), user.ID)
 	if err != nil {
-		slog.Error("list shared patterns", "error", err)
+		slog.Error("list stitches", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.PatternEditorPage(user.DisplayName, nil, allStitches, nil, nil, "").Render(r.Context(), w)
+}
+
+// HandleCreate processes pattern creation from the form.
+func (h *PatternHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	pattern, err := parsePatternForm(r, user.ID)
+	if err != nil {
+		h.renderEditorWithError(w, r, user, nil, err.Error())
+		return
+	}
+
+	if err := h.patterns.Create(r.Context(), pattern); err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			h.renderEditorWithError(w, r, user, pattern, err.Error())
+			return
+		}
+		slog.Error("create pattern", "error", err)
+		h.renderEditorWithError(w, r, user, pattern, "An unexpected error occurred.")
+		return
+	}
+
+	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+}
+
+// HandleView renders a read-only pattern detail view.
+func (h *PatternHandler) HandleView(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if pattern.UserID != user.ID {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	groupImages, err := h.images.ListByPattern(r.Context(), pattern)
+	if err != nil {
+		slog.Error("list pattern images", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Load shares for owner-authored patterns.
+	var shares []domain.PatternShare
+	var shareEvents map[int64][]domain.ShareEvent
+	if pattern.SharedFromUserID == nil {
+		shares, err = h.shares.ListSharesForPattern(r.Context(), user.ID, pattern.ID)
+		if err != nil {
+			slog.Error("list shares for pattern", "error", err)
+			// Non-fatal — proceed without share info.
+		}
+		shareEvents, err = h.shares.ListShareEvents(r.Context(), user.ID, pattern.ID)
+		if err != nil {
+			slog.Error("list share events for pattern", "error", err)
+		}
+	}
+
+	updateAvailable, err := h.shares.SourceUpdateAvailable(r.Context(), pattern)
+	if err != nil {
+		slog.Error("check source update", "error", err)
+	}
+	// After an update from the source, "updated" holds how many work
+	// sessions on the pattern had to restart their round or row.
+	updated := r.URL.Query().Has("updated")
+	restarted, _ := strconv.Atoi(r.URL.Query().Get("updated"))
+
+	style := service.ParseTextStyle(r.URL.Query().Get("style"))
+	view.PatternViewPage(user.DisplayName, service.TranslatePattern(pattern, user.Terminology), groupImages, shares, shareEvents, updateAvailable, updated, restarted, user.Terminology, style).Render(r.Context(), w)
+}
+
+// HandleEdit renders the pattern editor for an existing pattern.
+func (h *PatternHandler) HandleEdit(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if pattern.UserID != user.ID {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	// Locked patterns cannot be edited — redirect to view page.
+	if pattern.Locked {
+		http.Redirect(w, r, "/patterns/"+strconv.FormatInt(pattern.ID, 10), http.StatusSeeOther)
+		return
+	}
+
+	allStitches, err := h.stitches.ListAll(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list stitches", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Build mapping from PatternStitchID -> LibraryStitchID for pre-selection in the editor.
+	psToLibrary := buildPSToLibraryMap(pattern.PatternStitches)
+
+	groupImages, err := h.images.ListByPattern(r.Context(), pattern)
+	if err != nil {
+		slog.Error("list pattern images", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.PatternEditorPage(user.DisplayName, pattern, allStitches, groupImages, psToLibrary, "").Render(r.Context(), w)
+}
+
+// HandleUpdate processes pattern update from the form.
+func (h *PatternHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := parsePatternForm(r, user.ID)
+	if err != nil {
+		h.renderEditorWithError(w, r, user, nil, err.Error())
+		return
+	}
+	pattern.ID = id
+
+	if err := h.patterns.Update(r.Context(), user.ID, pattern); err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			h.renderEditorWithError(w, r, user, pattern, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrPatternLocked) {
+			http.Error(w, "Pattern is locked and cannot be edited", http.StatusForbidden)
+			return
+		}
+		slog.Error("update pattern", "error", err)
+		h.renderEditorWithError(w, r, user, pattern, "An unexpected error occurred.")
+		return
+	}
+
+	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+}
+
+// HandleDelete processes pattern deletion.
+func (h *PatternHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.Delete(r.Context(), user.ID, id); err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) || errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrPatternLocked) {
+			http.Error(w, "Pattern is locked and cannot be deleted", http.StatusForbidden)
+			return
+		}
+		slog.Error("delete pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+}
+
+// HandleHistory renders the list of saved versions of a pattern.
+func (h *PatternHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	versions, err := h.patterns.ListVersions(r.Context(), user.ID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("list pattern versions", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.PatternHistoryPage(user.DisplayName, pattern, versions).Render(r.Context(), w)
+}
+
+// HandleViewVersion renders a read-only preview of a saved version.
+func (h *PatternHandler) HandleViewVersion(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	versionID, err := strconv.ParseInt(r.PathValue("versionID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.patterns.GetVersion(r.Context(), user.ID, id, versionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern version", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.PatternVersionPage(user.DisplayName, pattern, version).Render(r.Context(), w)
+}
+
+// HandleDiffVersion renders the changes from a saved version to the current pattern.
+func (h *PatternHandler) HandleDiffVersion(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	versionID, err := strconv.ParseInt(r.PathValue("versionID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.patterns.GetVersion(r.Context(), user.ID, id, versionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern version", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	diff := service.DiffPatterns(version.Snapshot, pattern)
+	view.PatternVersionDiffPage(user.DisplayName, pattern, version, diff).Render(r.Context(), w)
+}
+
+// HandleRestoreVersion makes a saved version the pattern's current content.
+func (h *PatternHandler) HandleRestoreVersion(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	versionID, err := strconv.ParseInt(r.PathValue("versionID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.RestoreVersion(r.Context(), user.ID, id, versionID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrPatternLocked) {
+			http.Error(w, "Pattern is locked and cannot be edited", http.StatusForbidden)
+			return
+		}
+		slog.Error("restore pattern version", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+// HandleDuplicate duplicates an existing pattern.
+func (h *PatternHandler) HandleDuplicate(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.patterns.Duplicate(r.Context(), user.ID, id, user.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrPatternLocked) {
+			http.Error(w, "This pattern cannot be duplicated", http.StatusForbidden)
+			return
+		}
+		slog.Error("duplicate pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+}
+
+// HandleExport downloads a pattern as a portable document. By default it is
+// a single JSON file with images embedded as base64; ?format=zip returns a
+// zip bundle with the images as separate files.
+// GET /patterns/{id}/export
+func (h *PatternHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := h.patterns.Export(r.Context(), user.ID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrPatternLocked) {
+			http.Error(w, "This pattern cannot be exported", http.StatusForbidden)
+			return
+		}
+		slog.Error("export pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.images.AddDocumentImages(r.Context(), pattern, doc); err != nil {
+		slog.Error("export pattern images", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	// Determine which user-authored patterns have active share links.
-	var patternIDs []int64
-	for _, p := range patterns {
-		patternIDs = append(patternIDs, p.ID)
-	}
-	sharedMap := map[int64]bool{}
-	if len(patternIDs) > 0 {
-		sharedMap, err = h.shares.HasSharesByPatternIDs(r.Context(), patternIDs)
-		if err != nil {
-			slog.Error("check share indicators", "error", err)
-			sharedMap = map[int64]bool{}
+	filename := exportFilename(pattern.Name)
+	if r.URL.Query().Get("format") == "zip" {
+		var buf bytes.Buffer
+		if err := service.WritePatternBundle(&buf, doc); err != nil {
+			slog.Error("write pattern bundle", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
 		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+		w.Write(buf.Bytes())
+		return
 	}
 
-	view.PatternListPage(user.DisplayName, patterns, sharedPatterns, sharedMap, filter).Render(r.Context(), w)
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		slog.Error("encode pattern document", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+	w.Write(body)
 }
 
-// HandleNew renders the pattern creation form.
-func (h *PatternHandler) HandleNew(w http.ResponseWriter, r *http.Request) {
+// HandleExportPDF downloads a printable PDF of a pattern, including its
+// group images, with instructions in the text style given by the style query
+// parameter.
+// GET /patterns/{id}/pdf
+func (h *PatternHandler) HandleExportPDF(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	allStitches, err := h.stitches.ListAll(r.Context(), user.ID)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		slog.Error("list stitches", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
 
-	view.PatternEditorPage(user.DisplayName, nil, allStitches, nil, nil, "").Render(r.Context(), w)
-}
+	pattern, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
 
-// HandleCreate processes pattern creation from the form.
-func (h *PatternHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
-	user := UserFromContext(r.Context())
-	if user == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	if pattern.UserID != user.ID {
+		http.Error(w, "Not Found", http.StatusNotFound)
 		return
 	}
 
-	pattern, err := parsePatternForm(r, user.ID)
+	images, err := h.images.LoadPatternImages(r.Context(), pattern)
 	if err != nil {
-		h.renderEditorWithError(w, r, user, nil, err.Error())
+		slog.Error("load pattern images", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	if err := h.patterns.Create(r.Context(), pattern); err != nil {
-		if errors.Is(err, domain.ErrInvalidInput) {
-			h.renderEditorWithError(w, r, user, pattern, err.Error())
-			return
-		}
-		slog.Error("create pattern", "error", err)
-		h.renderEditorWithError(w, r, user, pattern, "An unexpected error occurred.")
+	style := service.ParseTextStyle(r.URL.Query().Get("style"))
+	body, err := service.RenderPatternPDF(pattern, images, user.Terminology, style)
+	if err != nil {
+		slog.Error("render pattern pdf", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename(pattern.Name)+".pdf"))
+	w.Write(body)
 }
 
-// HandleView renders a read-only pattern detail view.
-func (h *PatternHandler) HandleView(w http.ResponseWriter, r *http.Request) {
+// HandleExportText downloads a pattern's text as a plain text file, in the
+// text style given by the style query parameter.
+// GET /patterns/{id}/text
+func (h *PatternHandler) HandleExportText(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -159,28 +832,19 @@ func (h *PatternHandler) HandleView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groupImages, err := h.images.ListByPattern(r.Context(), pattern)
-	if err != nil {
-		slog.Error("list pattern images", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	// Load shares for owner-authored patterns.
-	var shares []domain.PatternShare
-	if pattern.SharedFromUserID == nil {
-		shares, err = h.shares.ListSharesForPattern(r.Context(), user.ID, pattern.ID)
-		if err != nil {
-			slog.Error("list shares for pattern", "error", err)
-			// Non-fatal — proceed without share info.
-		}
-	}
+	style := service.ParseTextStyle(r.URL.Query().Get("style"))
+	text := service.RenderPatternTextStyle(service.TranslatePattern(pattern, user.Terminology), style)
 
-	view.PatternViewPage(user.DisplayName, pattern, groupImages, shares).Render(r.Context(), w)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename(pattern.Name)+".txt"))
+	fmt.Fprintf(w, "%s\n%s\n\n%s\n", pattern.Name, service.TerminologyLabel(user.Terminology), text)
 }
 
-// HandleEdit renders the pattern editor for an existing pattern.
-func (h *PatternHandler) HandleEdit(w http.ResponseWriter, r *http.Request) {
+// HandleChart serves a pattern's crochet symbol chart as SVG, for the size
+// given by the size query parameter on graded patterns. With download=1 it
+// is sent as a file.
+// GET /patterns/{id}/chart.svg
+func (h *PatternHandler) HandleChart(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -209,135 +873,201 @@ func (h *PatternHandler) HandleEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Locked patterns cannot be edited — redirect to view page.
-	if pattern.Locked {
-		http.Redirect(w, r, "/patterns/"+strconv.FormatInt(pattern.ID, 10), http.StatusSeeOther)
-		return
-	}
-
-	allStitches, err := h.stitches.ListAll(r.Context(), user.ID)
+	sizeIndex, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	svg, err := service.RenderPatternChart(pattern, sizeIndex)
 	if err != nil {
-		slog.Error("list stitches", "error", err)
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("render pattern chart", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	// Build mapping from PatternStitchID -> LibraryStitchID for pre-selection in the editor.
-	psToLibrary := buildPSToLibraryMap(pattern.PatternStitches)
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if r.URL.Query().Get("download") == "1" {
+		filename := exportFilename(pattern.Name)
+		if pattern.IsGraded() {
+			filename += "-" + exportFilename(pattern.Sizes[sizeIndex])
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"-chart.svg"))
+	}
+	io.WriteString(w, svg)
+}
 
-	groupImages, err := h.images.ListByPattern(r.Context(), pattern)
-	if err != nil {
-		slog.Error("list pattern images", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+// HandleShowImport renders the pattern import form.
+// GET /patterns/import
+func (h *PatternHandler) HandleShowImport(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	view.PatternEditorPage(user.DisplayName, pattern, allStitches, groupImages, psToLibrary, "").Render(r.Context(), w)
+	view.PatternImportPage(user.DisplayName, nil, "").Render(r.Context(), w)
 }
 
-// HandleUpdate processes pattern update from the form.
-func (h *PatternHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+// HandleImport creates a new pattern from an uploaded JSON document or zip
+// bundle. If some of the pattern's stitches aren't in the user's library the
+// import still succeeds, and the result page lists them.
+// POST /patterns/import
+func (h *PatternHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	renderError := func(msg string) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		view.PatternImportPage(user.DisplayName, nil, msg).Render(r.Context(), w)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+4096)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		renderError("The file is too large to import.")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		renderError("Choose a pattern file to import.")
 		return
 	}
+	defer file.Close()
 
-	pattern, err := parsePatternForm(r, user.ID)
+	data, err := io.ReadAll(file)
 	if err != nil {
-		h.renderEditorWithError(w, r, user, nil, err.Error())
+		slog.Error("read import", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-	pattern.ID = id
 
-	if err := h.patterns.Update(r.Context(), user.ID, pattern); err != nil {
+	doc, err := service.ReadPatternDocument(data)
+	if err == nil {
+		err = service.ValidateDocumentImages(doc)
+	}
+	if err != nil {
+		renderError(err.Error())
+		return
+	}
+
+	result, err := h.patterns.Import(r.Context(), user.ID, doc)
+	if err != nil {
 		if errors.Is(err, domain.ErrInvalidInput) {
-			h.renderEditorWithError(w, r, user, pattern, err.Error())
+			renderError(err.Error())
 			return
 		}
-		if errors.Is(err, domain.ErrUnauthorized) {
-			http.Error(w, "Not Found", http.StatusNotFound)
-			return
+		slog.Error("import pattern", "error", err)
+		renderError("An unexpected error occurred.")
+		return
+	}
+
+	if err := h.images.ImportDocumentImages(r.Context(), user.ID, result.Pattern, doc); err != nil {
+		// Don't leave a pattern behind without the images it came with.
+		if delErr := h.patterns.Delete(r.Context(), user.ID, result.Pattern.ID); delErr != nil {
+			slog.Error("delete partially imported pattern", "error", delErr)
 		}
-		if errors.Is(err, domain.ErrPatternLocked) {
-			http.Error(w, "Pattern is locked and cannot be edited", http.StatusForbidden)
+		if errors.Is(err, domain.ErrInvalidInput) {
+			renderError(err.Error())
 			return
 		}
-		slog.Error("update pattern", "error", err)
-		h.renderEditorWithError(w, r, user, pattern, "An unexpected error occurred.")
+		slog.Error("import pattern images", "error", err)
+		renderError("The pattern's images could not be imported, so the pattern was not saved.")
 		return
 	}
 
-	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+	if len(result.UnlinkedStitches) > 0 {
+		view.PatternImportPage(user.DisplayName, result, "").Render(r.Context(), w)
+		return
+	}
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(result.Pattern.ID, 10), http.StatusSeeOther)
 }
 
-// HandleDelete processes pattern deletion.
-func (h *PatternHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+// HandleShowTextImport renders the import from text form.
+// GET /patterns/import/text
+func (h *PatternHandler) HandleShowTextImport(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
-	if err != nil {
-		http.Error(w, "Bad Request", http.StatusBadRequest)
-		return
-	}
-
-	if err := h.patterns.Delete(r.Context(), user.ID, id); err != nil {
-		if errors.Is(err, domain.ErrUnauthorized) || errors.Is(err, domain.ErrNotFound) {
-			http.Error(w, "Not Found", http.StatusNotFound)
-			return
-		}
-		if errors.Is(err, domain.ErrPatternLocked) {
-			http.Error(w, "Pattern is locked and cannot be deleted", http.StatusForbidden)
-			return
-		}
-		slog.Error("delete pattern", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+	view.PatternTextImportPage(user.DisplayName, "", "", nil, nil, "").Render(r.Context(), w)
 }
 
-// HandleDuplicate duplicates an existing pattern.
-func (h *PatternHandler) HandleDuplicate(w http.ResponseWriter, r *http.Request) {
+// HandleTextImport parses pattern text written in crochet notation. With
+// action=preview it shows the parsed pattern, or the lines that couldn't be
+// parsed; with action=save it creates the pattern.
+// POST /patterns/import/text
+func (h *PatternHandler) HandleTextImport(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
-	if err != nil {
+	if err := r.ParseForm(); err != nil {
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	text := r.FormValue("text")
 
-	_, err = h.patterns.Duplicate(r.Context(), user.ID, id, user.ID)
-	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
-			http.Error(w, "Not Found", http.StatusNotFound)
+	if r.FormValue("action") != "save" {
+		preview, errs, err := h.patterns.ParseText(r.Context(), user.ID, text)
+		if err != nil {
+			slog.Error("parse pattern text", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
-		if errors.Is(err, domain.ErrPatternLocked) {
-			http.Error(w, "This pattern cannot be duplicated", http.StatusForbidden)
-			return
+		if len(errs) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			preview = nil
 		}
-		slog.Error("duplicate pattern", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		view.PatternTextImportPage(user.DisplayName, name, text, preview, errs, "").Render(r.Context(), w)
 		return
 	}
 
-	http.Redirect(w, r, "/patterns", http.StatusSeeOther)
+	pattern, errs, err := h.patterns.ImportText(r.Context(), user.ID, name, text)
+	if err != nil || len(errs) > 0 {
+		msg := ""
+		if err != nil {
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				slog.Error("import pattern text", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			msg = err.Error()
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		view.PatternTextImportPage(user.DisplayName, name, text, nil, errs, msg).Render(r.Context(), w)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(pattern.ID, 10), http.StatusSeeOther)
+}
+
+// maxImportSize limits pattern import uploads.
+const maxImportSize = 60 << 20
+
+// exportFilename turns a pattern name into a safe download filename.
+func exportFilename(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	filename := strings.TrimSuffix(b.String(), "-")
+	if filename == "" {
+		return "pattern"
+	}
+	return filename
 }
 
 // HandleAddPart returns an SSE response that appends a new empty part section.
@@ -440,6 +1170,77 @@ func (h *PatternHandler) HandleRemoveEntry(w http.ResponseWriter, r *http.Reques
 	sse.RemoveElementByID("entry-" + strconv.Itoa(gi) + "-" + strconv.Itoa(ei))
 }
 
+// HandleAddBlock returns an SSE response that appends a new repeat bracket row.
+func (h *PatternHandler) HandleAddBlock(w http.ResponseWriter, r *http.Request) {
+	gi, err := strconv.Atoi(r.PathValue("gi"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	bi, err := strconv.Atoi(r.URL.Query().Get("bi"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	sse := datastar.NewSSE(w, r)
+	b := domain.RepeatBlock{RepeatCount: 2, Bracket: domain.BracketParen}
+	sse.PatchElementTempl(
+		view.BlockFieldsFragment(gi, bi, b),
+		datastar.WithSelectorID("blocks-"+strconv.Itoa(gi)),
+		datastar.WithModeAppend(),
+	)
+}
+
+// HandleAddPiece returns an SSE response that appends a new piece row.
+func (h *PatternHandler) HandleAddPiece(w http.ResponseWriter, r *http.Request) {
+	pi, err := strconv.Atoi(r.URL.Query().Get("pi"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.PatchElementTempl(
+		view.PieceFieldsFragment(pi, domain.PatternPiece{MakeCount: 1}),
+		datastar.WithSelectorID("pattern-pieces"),
+		datastar.WithModeAppend(),
+	)
+}
+
+// HandleAddColor returns an SSE response that appends a new palette color row.
+func (h *PatternHandler) HandleAddColor(w http.ResponseWriter, r *http.Request) {
+	ci, err := strconv.Atoi(r.URL.Query().Get("ci"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.PatchElementTempl(
+		view.ColorFieldsFragment(ci, domain.PatternColor{}),
+		datastar.WithSelectorID("pattern-colors"),
+		datastar.WithModeAppend(),
+	)
+}
+
+// HandleRemoveBlock returns an SSE response that removes a repeat bracket row.
+func (h *PatternHandler) HandleRemoveBlock(w http.ResponseWriter, r *http.Request) {
+	gi, err := strconv.Atoi(r.PathValue("gi"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	bi, err := strconv.Atoi(r.PathValue("bi"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.RemoveElementByID("block-" + strconv.Itoa(gi) + "-" + strconv.Itoa(bi))
+}
+
 func (h *PatternHandler) renderEditorWithError(w http.ResponseWriter, r *http.Request, user *domain.User, pattern *domain.Pattern, errMsg string) {
 	allStitches, _ := h.stitches.ListAll(r.Context(), user.ID)
 	w.WriteHeader(http.StatusUnprocessableEntity)
@@ -460,8 +1261,13 @@ func buildPSToLibraryMap(patternStitches []domain.PatternStitch) map[int64]int64
 
 // parsePatternForm reads pattern data from a form submission.
 // The form uses indexed field names for nested groups and entries:
-// group_label_0, group_repeat_0, group_expected_0, group_notes_0
-// entry_stitch_0_0, entry_count_0_0, entry_repeat_0_0
+// piece_name_0, piece_make_0, piece_notes_0
+// color_label_0, color_name_0, color_hex_0
+// group_label_0, group_repeat_0, group_expected_0, group_notes_0, group_piece_0
+// entry_stitch_0_0, entry_count_0_0, entry_repeat_0_0, entry_color_0_0
+// block_start_0_0, block_end_0_0, block_repeat_0_0, block_bracket_0_0, block_into_0_0
+// Graded patterns list their sizes in "sizes" (comma-separated); counts and
+// repeats then accept one value per size, e.g. "12, 14, 16" or "12 (14, 16)".
 func parsePatternForm(r *http.Request, userID int64) (*domain.Pattern, error) {
 	if err := r.ParseForm(); err != nil {
 		return nil, err
@@ -480,6 +1286,27 @@ func parsePatternForm(r *http.Request, userID int64) (*domain.Pattern, error) {
 		HookSize:    r.FormValue("hook_size"),
 		YarnWeight:  r.FormValue("yarn_weight"),
 		Difficulty:  r.FormValue("difficulty"),
+		Sizes:       sizesFormValue(r, "sizes"),
+	}
+
+	for _, pi := range collectFormIndices(r, "piece_name_") {
+		pattern.Pieces = append(pattern.Pieces, domain.PatternPiece{
+			Name:      r.FormValue("piece_name_" + strconv.Itoa(pi)),
+			Notes:     r.FormValue("piece_notes_" + strconv.Itoa(pi)),
+			MakeCount: intFormValue(r, "piece_make_"+strconv.Itoa(pi), 1),
+		})
+	}
+
+	// Entries name their working color by palette label, e.g. "B".
+	colorIndex := map[string]int{}
+	for _, ci := range collectFormIndices(r, "color_label_") {
+		color := domain.PatternColor{
+			Label: strings.TrimSpace(r.FormValue("color_label_" + strconv.Itoa(ci))),
+			Name:  strings.TrimSpace(r.FormValue("color_name_" + strconv.Itoa(ci))),
+			Hex:   strings.TrimSpace(r.FormValue("color_hex_" + strconv.Itoa(ci))),
+		}
+		colorIndex[strings.ToLower(color.Label)] = len(pattern.Colors)
+		pattern.Colors = append(pattern.Colors, color)
 	}
 
 	// Collect all group indices from form keys (supports non-contiguous indices from dynamic add/remove).
@@ -488,20 +1315,28 @@ func parsePatternForm(r *http.Request, userID int64) (*domain.Pattern, error) {
 	for sortOrder, gi := range groupIndices {
 		label := r.FormValue("group_label_" + strconv.Itoa(gi))
 
-		repeatCount := intFormValue(r, "group_repeat_"+strconv.Itoa(gi), 1)
+		repeatCount, sizeRepeatCounts := gradedFormValue(r, "group_repeat_"+strconv.Itoa(gi), 1)
 		var expectedCount *int
-		if v := r.FormValue("group_expected_" + strconv.Itoa(gi)); v != "" {
-			if parsed, err := strconv.Atoi(v); err == nil {
-				expectedCount = &parsed
+		var sizeExpectedCounts []int
+		if values := formNumbers(r.FormValue("group_expected_" + strconv.Itoa(gi))); len(values) > 0 {
+			expectedCount = &values[0]
+			if len(values) > 1 {
+				sizeExpectedCounts = values
 			}
 		}
 
 		group := domain.InstructionGroup{
-			SortOrder:     sortOrder,
-			Label:         label,
-			RepeatCount:   repeatCount,
-			ExpectedCount: expectedCount,
-			Notes:         r.FormValue("group_notes_" + strconv.Itoa(gi)),
+			SortOrder:          sortOrder,
+			Label:              label,
+			RepeatCount:        repeatCount,
+			SizeRepeatCounts:   sizeRepeatCounts,
+			ExpectedCount:      expectedCount,
+			SizeExpectedCounts: sizeExpectedCounts,
+			Notes:              r.FormValue("group_notes_" + strconv.Itoa(gi)),
+		}
+		// Pieces are numbered from 1 in the form.
+		if len(pattern.Pieces) > 0 {
+			group.PieceIndex = intFormValue(r, "group_piece_"+strconv.Itoa(gi), 1) - 1
 		}
 
 		// Collect all entry indices for this group.
@@ -519,16 +1354,48 @@ func parsePatternForm(r *http.Request, userID int64) (*domain.Pattern, error) {
 					domain.ErrInvalidInput, group.Label, entrySortOrder+1)
 			}
 
+			count, sizeCounts := gradedFormValue(r, "entry_count_"+strconv.Itoa(gi)+"_"+strconv.Itoa(ei), 1)
+			repeat, sizeRepeats := gradedFormValue(r, "entry_repeat_"+strconv.Itoa(gi)+"_"+strconv.Itoa(ei), 1)
+
 			entry := domain.StitchEntry{
-				SortOrder:       entrySortOrder,
-				PatternStitchID: stitchID, // Temporarily holds library stitch ID; resolved by service
-				Count:           intFormValue(r, "entry_count_"+strconv.Itoa(gi)+"_"+strconv.Itoa(ei), 1),
-				RepeatCount:     intFormValue(r, "entry_repeat_"+strconv.Itoa(gi)+"_"+strconv.Itoa(ei), 1),
+				SortOrder:        entrySortOrder,
+				PatternStitchID:  stitchID, // Temporarily holds library stitch ID; resolved by service
+				Count:            count,
+				SizeCounts:       sizeCounts,
+				RepeatCount:      repeat,
+				SizeRepeatCounts: sizeRepeats,
+			}
+			if label := strings.TrimSpace(r.FormValue("entry_color_" + strconv.Itoa(gi) + "_" + strconv.Itoa(ei))); label != "" {
+				ci, ok := colorIndex[strings.ToLower(label)]
+				if !ok {
+					return nil, fmt.Errorf("%w: %s entry %d uses color %q, which is not in the palette",
+						domain.ErrInvalidInput, group.Label, entrySortOrder+1, label)
+				}
+				entry.ColorIndex = &ci
 			}
 
 			group.StitchEntries = append(group.StitchEntries, entry)
 		}
 
+		// Collect repeat blocks; positions in the form are 1-based stitch numbers.
+		blockIndices := collectFormIndices(r, "block_start_"+strconv.Itoa(gi)+"_")
+
+		for _, bi := range blockIndices {
+			suffix := strconv.Itoa(gi) + "_" + strconv.Itoa(bi)
+			bracket := domain.BracketStyle(r.FormValue("block_bracket_" + suffix))
+			if bracket == "" {
+				bracket = domain.BracketParen
+			}
+
+			group.RepeatBlocks = append(group.RepeatBlocks, domain.RepeatBlock{
+				StartEntry:  intFormValue(r, "block_start_"+suffix, 1) - 1,
+				EndEntry:    intFormValue(r, "block_end_"+suffix, 1) - 1,
+				RepeatCount: intFormValue(r, "block_repeat_"+suffix, 1),
+				Bracket:     bracket,
+				IntoStitch:  r.FormValue("block_into_" + suffix),
+			})
+		}
+
 		pattern.InstructionGroups = append(pattern.InstructionGroups, group)
 	}
 
@@ -574,3 +1441,46 @@ func intFormValue(r *http.Request, key string, defaultVal int) int {
 	}
 	return parsed
 }
+
+// gradedFormValue reads a number that may vary by size. A single value is
+// returned as the base with no per-size values (defaulting like intFormValue);
+// several values are returned as-is for the service to validate against the
+// pattern's sizes.
+func gradedFormValue(r *http.Request, key string, defaultVal int) (int, []int) {
+	values := formNumbers(r.FormValue(key))
+	switch len(values) {
+	case 0:
+		return defaultVal, nil
+	case 1:
+		if values[0] < 1 {
+			return defaultVal, nil
+		}
+		return values[0], nil
+	default:
+		return values[0], values
+	}
+}
+
+// formNumbers extracts every whole number from a form value, so graded
+// numbers can be typed as "12, 14, 16", "12 (14, 16)" or "12/14/16".
+func formNumbers(v string) []int {
+	fields := strings.FieldsFunc(v, func(r rune) bool { return r < '0' || r > '9' })
+	values := make([]int, 0, len(fields))
+	for _, f := range fields {
+		if n, err := strconv.Atoi(f); err == nil {
+			values = append(values, n)
+		}
+	}
+	return values
+}
+
+// sizesFormValue reads a comma-separated list of size names, skipping blanks.
+func sizesFormValue(r *http.Request, key string) []string {
+	var sizes []string
+	for _, name := range strings.Split(r.FormValue(key), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sizes = append(sizes, name)
+		}
+	}
+	return sizes
+}
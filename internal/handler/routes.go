@@ -16,13 +16,16 @@ func RegisterRoutes(mux *http.ServeMux, auth *service.AuthService, stitches *ser
 	sessionHandler := NewWorkSessionHandler(sessions, patterns, images)
 	dashboardHandler := NewDashboardHandler(sessions, patterns)
 	imageHandler := NewImageHandler(images, patterns)
-	shareHandler := NewShareHandler(shares, patterns, images, users)
+	shareHandler := NewShareHandler(shares, patterns, sessions, images, users)
+	tagHandler := NewTagHandler(patterns)
+	collectionHandler := NewCollectionHandler(patterns)
 
 	// Rate limiter for auth endpoints: 10 req/s capacity, refills at 1/s.
 	authLimiter := service.NewTokenBucket(1, 10)
 
 	// Public routes.
 	mux.HandleFunc("GET /healthz", HandleHealthz)
+	mux.HandleFunc("GET /shared-images/{id}", shareHandler.HandleSharedImage)
 	mux.Handle("GET /{$}", OptionalAuth(auth, http.HandlerFunc(HandleHome)))
 
 	// Auth routes (unauthenticated, rate-limited).
@@ -34,6 +37,8 @@ func RegisterRoutes(mux *http.ServeMux, auth *service.AuthService, stitches *ser
 
 	// Protected routes.
 	mux.Handle("GET /dashboard", RequireAuth(auth, http.HandlerFunc(dashboardHandler.HandleDashboard)))
+	mux.Handle("GET /settings", RequireAuth(auth, http.HandlerFunc(authHandler.ShowSettings)))
+	mux.Handle("POST /settings", RequireAuth(auth, http.HandlerFunc(authHandler.HandleUpdateSettings)))
 
 
 	// Stitch library routes (authenticated).
@@ -41,22 +46,54 @@ func RegisterRoutes(mux *http.ServeMux, auth *service.AuthService, stitches *ser
 	mux.Handle("POST /stitches", RequireAuth(auth, http.HandlerFunc(stitchHandler.HandleCreateCustom)))
 	mux.Handle("POST /stitches/{id}/edit", RequireAuth(auth, http.HandlerFunc(stitchHandler.HandleUpdateCustom)))
 	mux.Handle("POST /stitches/{id}/delete", RequireAuth(auth, http.HandlerFunc(stitchHandler.HandleDeleteCustom)))
+	mux.Handle("POST /stitches/{id}/known", RequireAuth(auth, http.HandlerFunc(stitchHandler.HandleSetKnown)))
 
 	// Pattern routes (authenticated).
 	mux.Handle("GET /patterns", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleList)))
+	mux.Handle("GET /patterns/more", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleListMore)))
+	mux.Handle("POST /patterns/bulk", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleBulk)))
 	mux.Handle("GET /patterns/new", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleNew)))
 	mux.Handle("POST /patterns", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleCreate)))
+	mux.Handle("GET /patterns/import", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleShowImport)))
+	mux.Handle("POST /patterns/import", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleImport)))
+	mux.Handle("GET /patterns/import/text", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleShowTextImport)))
+	mux.Handle("POST /patterns/import/text", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleTextImport)))
 	mux.Handle("GET /patterns/{id}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleView)))
 	mux.Handle("GET /patterns/{id}/edit", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleEdit)))
 	mux.Handle("POST /patterns/{id}/edit", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleUpdate)))
 	mux.Handle("POST /patterns/{id}/delete", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleDelete)))
 	mux.Handle("POST /patterns/{id}/duplicate", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleDuplicate)))
-
-	// Pattern editor SSE endpoints (dynamic add/remove parts and entries).
+	mux.Handle("GET /patterns/{id}/export", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleExport)))
+	mux.Handle("GET /patterns/{id}/pdf", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleExportPDF)))
+	mux.Handle("GET /patterns/{id}/text", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleExportText)))
+	mux.Handle("GET /patterns/{id}/chart.svg", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleChart)))
+	mux.Handle("GET /patterns/{id}/history", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleHistory)))
+	mux.Handle("GET /patterns/{id}/history/{versionID}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleViewVersion)))
+	mux.Handle("GET /patterns/{id}/history/{versionID}/diff", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleDiffVersion)))
+	mux.Handle("POST /patterns/{id}/history/{versionID}/restore", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleRestoreVersion)))
+
+	// Tag and collection routes (authenticated).
+	mux.Handle("GET /tags", RequireAuth(auth, http.HandlerFunc(tagHandler.HandleList)))
+	mux.Handle("POST /tags", RequireAuth(auth, http.HandlerFunc(tagHandler.HandleCreate)))
+	mux.Handle("POST /tags/{id}/edit", RequireAuth(auth, http.HandlerFunc(tagHandler.HandleRename)))
+	mux.Handle("POST /tags/{id}/delete", RequireAuth(auth, http.HandlerFunc(tagHandler.HandleDelete)))
+	mux.Handle("GET /collections", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleList)))
+	mux.Handle("POST /collections", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleCreate)))
+	mux.Handle("GET /collections/{id}", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleView)))
+	mux.Handle("POST /collections/{id}/edit", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleUpdate)))
+	mux.Handle("POST /collections/{id}/delete", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleDelete)))
+	mux.Handle("POST /collections/{id}/order", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleReorder)))
+	mux.Handle("POST /collections/{id}/patterns/{patternID}/remove", RequireAuth(auth, http.HandlerFunc(collectionHandler.HandleRemovePattern)))
+
+	// Pattern editor SSE endpoints (dynamic add/remove parts, entries, and repeat brackets).
 	mux.Handle("POST /patterns/editor/add-part", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleAddPart)))
 	mux.Handle("POST /patterns/editor/remove-part/{gi}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleRemovePart)))
 	mux.Handle("POST /patterns/editor/add-entry/{gi}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleAddEntry)))
 	mux.Handle("POST /patterns/editor/remove-entry/{gi}/{ei}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleRemoveEntry)))
+	mux.Handle("POST /patterns/editor/add-block/{gi}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleAddBlock)))
+	mux.Handle("POST /patterns/editor/remove-block/{gi}/{bi}", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleRemoveBlock)))
+	mux.Handle("POST /patterns/editor/add-piece", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleAddPiece)))
+	mux.Handle("POST /patterns/editor/add-color", RequireAuth(auth, http.HandlerFunc(patternHandler.HandleAddColor)))
 
 	// Image routes (authenticated).
 	mux.Handle("POST /patterns/{id}/parts/{groupIndex}/images", RequireAuth(auth, http.HandlerFunc(imageHandler.HandleUpload)))
@@ -72,15 +109,27 @@ func RegisterRoutes(mux *http.ServeMux, auth *service.AuthService, stitches *ser
 	mux.Handle("POST /sessions/{id}/resume", RequireAuth(auth, http.HandlerFunc(sessionHandler.HandleResume)))
 	mux.Handle("POST /sessions/{id}/abandon", RequireAuth(auth, http.HandlerFunc(sessionHandler.HandleAbandon)))
 
-	// Shared pattern viewing and saving (authenticated).
-	mux.Handle("GET /s/{token}", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleViewShared)))
+	// Shared pattern viewing (global links open without an account) and saving.
+	mux.Handle("GET /s/{token}", OptionalAuth(auth, http.HandlerFunc(shareHandler.HandleViewShared)))
 	mux.Handle("POST /s/{token}/save", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleSaveShared)))
 
+	// Public gallery (authenticated).
+	mux.Handle("GET /gallery", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleGallery)))
+	mux.Handle("GET /gallery/more", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleGalleryMore)))
+	mux.Handle("GET /gallery/{id}", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleViewPublished)))
+	mux.Handle("POST /gallery/{id}/save", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleSaveFromGallery)))
+
 	// Share management (owner, authenticated).
 	mux.Handle("POST /patterns/{id}/share", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleCreateGlobalShare)))
 	mux.Handle("POST /patterns/{id}/share/email", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleCreateEmailShare)))
 	mux.Handle("POST /patterns/{id}/share/{shareID}/revoke", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleRevokeShare)))
+	mux.Handle("POST /patterns/{id}/share/{shareID}/limits", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleUpdateShareLimits)))
 	mux.Handle("POST /patterns/{id}/share/revoke-all", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleRevokeAllShares)))
+	mux.Handle("POST /patterns/{id}/publish", RequireAuth(auth, http.HandlerFunc(shareHandler.HandlePublish)))
+	mux.Handle("POST /patterns/{id}/unpublish", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleUnpublish)))
+
+	// Received copies (recipient, authenticated).
+	mux.Handle("POST /patterns/{id}/update-from-source", RequireAuth(auth, http.HandlerFunc(shareHandler.HandleUpdateFromSource)))
 
 	// Catch-all 404 handler.
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 	"github.com/msomdec/stitch-map-2/internal/service"
@@ -15,22 +17,25 @@ import (
 type ShareHandler struct {
 	shares   *service.ShareService
 	patterns *service.PatternService
+	sessions *service.WorkSessionService
 	images   *service.ImageService
 	users    domain.UserRepository
 }
 
 // NewShareHandler creates a new ShareHandler.
-func NewShareHandler(shares *service.ShareService, patterns *service.PatternService, images *service.ImageService, users domain.UserRepository) *ShareHandler {
-	return &ShareHandler{shares: shares, patterns: patterns, images: images, users: users}
+func NewShareHandler(shares *service.ShareService, patterns *service.PatternService, sessions *service.WorkSessionService, images *service.ImageService, users domain.UserRepository) *ShareHandler {
+	return &ShareHandler{shares: shares, patterns: patterns, sessions: sessions, images: images, users: users}
 }
 
-// HandleViewShared renders the shared pattern preview page.
+// HandleViewShared renders the preview of a shared pattern. Global links
+// can be opened without an account; other shares send visitors who aren't
+// logged in to the login page, returning them here afterwards.
 // GET /s/{token}
 func (h *ShareHandler) HandleViewShared(w http.ResponseWriter, r *http.Request) {
 	user := UserFromContext(r.Context())
-	if user == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
+	var viewerID int64
+	if user != nil {
+		viewerID = user.ID
 	}
 
 	token := r.PathValue("token")
@@ -39,13 +44,22 @@ func (h *ShareHandler) HandleViewShared(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	pattern, err := h.shares.GetPatternByShareToken(r.Context(), user.ID, token)
+	pattern, err := h.shares.ViewSharedPattern(r.Context(), viewerID, token)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			http.Error(w, "Not Found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, domain.ErrShareExpired) {
+			w.WriteHeader(http.StatusGone)
+			view.ErrorPage(http.StatusGone, "Link Expired", "This share link has expired. Ask the owner for a new one.").Render(r.Context(), w)
+			return
+		}
 		if errors.Is(err, domain.ErrUnauthorized) {
+			if user == nil {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape("/s/"+token), http.StatusSeeOther)
+				return
+			}
 			w.WriteHeader(http.StatusForbidden)
 			view.ErrorPage(http.StatusForbidden, "Access Denied", "This pattern was shared with a different account.").Render(r.Context(), w)
 			return
@@ -56,11 +70,16 @@ func (h *ShareHandler) HandleViewShared(w http.ResponseWriter, r *http.Request)
 	}
 
 	// If the viewer is the pattern owner, redirect to the normal view page.
-	if pattern.UserID == user.ID {
+	if pattern.UserID == viewerID {
 		http.Redirect(w, r, "/patterns/"+strconv.FormatInt(pattern.ID, 10), http.StatusSeeOther)
 		return
 	}
 
+	permission := domain.SharePermissionSaveLocked
+	if share, err := h.shares.GetShareByToken(r.Context(), token); err == nil {
+		permission = share.Permission
+	}
+
 	// Load owner name.
 	owner, err := h.users.GetByID(r.Context(), pattern.UserID)
 	ownerName := "Unknown"
@@ -75,21 +94,106 @@ func (h *ShareHandler) HandleViewShared(w http.ResponseWriter, r *http.Request)
 		groupImages = map[int64][]domain.PatternImage{}
 	}
 
+	tags, err := h.patterns.PatternTags(r.Context(), pattern.ID)
+	if err != nil {
+		slog.Error("list pattern tags for share preview", "error", err)
+	}
+
 	// Check if the viewer has already saved this pattern.
 	alreadySaved := false
 	var savedPatternID int64
-	sharedPatterns, err := h.patterns.ListSharedWithUser(r.Context(), user.ID)
-	if err == nil {
-		for _, sp := range sharedPatterns {
-			if sp.SharedFromUserID != nil && *sp.SharedFromUserID == pattern.UserID && sp.Name == pattern.Name {
-				alreadySaved = true
-				savedPatternID = sp.ID
-				break
+	displayName := ""
+	terms := domain.TerminologyUS
+	if user != nil {
+		displayName = user.DisplayName
+		terms = user.Terminology
+		sharedPatterns, err := h.patterns.ListSharedWithUser(r.Context(), user.ID)
+		if err == nil {
+			for _, sp := range sharedPatterns {
+				if sp.SavedFrom(pattern) {
+					alreadySaved = true
+					savedPatternID = sp.ID
+					break
+				}
 			}
 		}
 	}
 
-	view.SharedPatternPreviewPage(user.DisplayName, pattern, ownerName, groupImages, alreadySaved, savedPatternID, token).Render(r.Context(), w)
+	// Images load through signed URLs, which anonymous viewers can use too.
+	imageURLs := make(map[int64]string)
+	now := time.Now()
+	for _, images := range groupImages {
+		for _, img := range images {
+			imageURLs[img.ID] = h.images.SharedImageURL(token, img.ID, now)
+		}
+	}
+
+	style := service.ParseTextStyle(r.URL.Query().Get("style"))
+	view.SharedPatternPreviewPage(displayName, service.TranslatePattern(pattern, terms), ownerName, groupImages, imageURLs, tags, alreadySaved, savedPatternID, token, permission, terms, style).Render(r.Context(), w)
+}
+
+// HandleSharedImage serves an image of a shared pattern through a signed URL
+// from the pattern's preview, without needing the viewer's session. The URL
+// stops working when it expires or the share is revoked or expires.
+// GET /shared-images/{id}
+func (h *ShareHandler) HandleSharedImage(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	token := q.Get("token")
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if err := h.images.VerifySharedImageURL(token, imageID, expires, q.Get("sig"), now); err != nil {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	share, err := h.shares.GetShareByToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get share for image", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if share.Expired(now) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	pattern, err := h.patterns.GetByID(r.Context(), share.PatternID)
+	if err != nil {
+		slog.Error("get shared pattern for image", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data, contentType, err := h.images.GetPatternFile(r.Context(), pattern, imageID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("serve shared image", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(expires-now.Unix(), 10))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
 }
 
 // HandleSaveShared saves a shared pattern to the viewer's library.
@@ -107,7 +211,7 @@ func (h *ShareHandler) HandleSaveShared(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	_, err := h.shares.SaveSharedPattern(r.Context(), user.ID, token)
+	_, err := h.shares.SaveSharedPattern(r.Context(), user.ID, token, r.FormValue("tags") == "1")
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			http.Error(w, "Not Found", http.StatusNotFound)
@@ -118,6 +222,21 @@ func (h *ShareHandler) HandleSaveShared(w http.ResponseWriter, r *http.Request)
 			view.ErrorPage(http.StatusForbidden, "Access Denied", "This pattern was shared with a different account.").Render(r.Context(), w)
 			return
 		}
+		if errors.Is(err, domain.ErrShareExpired) {
+			w.WriteHeader(http.StatusGone)
+			view.ErrorPage(http.StatusGone, "Link Expired", "This share link has expired. Ask the owner for a new one.").Render(r.Context(), w)
+			return
+		}
+		if errors.Is(err, domain.ErrShareLimitReached) {
+			w.WriteHeader(http.StatusGone)
+			view.ErrorPage(http.StatusGone, "Save Limit Reached", "This share link has been saved from as many times as its owner allows.").Render(r.Context(), w)
+			return
+		}
+		if errors.Is(err, domain.ErrShareViewOnly) {
+			w.WriteHeader(http.StatusForbidden)
+			view.ErrorPage(http.StatusForbidden, "View Only", "This pattern was shared for viewing only and can't be saved.").Render(r.Context(), w)
+			return
+		}
 		if errors.Is(err, domain.ErrAlreadySaved) {
 			http.Redirect(w, r, "/patterns", http.StatusSeeOther)
 			return
@@ -149,14 +268,14 @@ func (h *ShareHandler) HandleCreateGlobalShare(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	_, err = h.shares.CreateGlobalShare(r.Context(), user.ID, id)
+	_, err = h.shares.CreateGlobalShare(r.Context(), user.ID, id, sharePermissionFromForm(r))
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
 			http.Error(w, "Not Found", http.StatusNotFound)
 			return
 		}
 		if errors.Is(err, domain.ErrInvalidInput) {
-			http.Error(w, "This pattern cannot be shared.", http.StatusBadRequest)
+			http.Error(w, "This pattern cannot be shared with that permission.", http.StatusBadRequest)
 			return
 		}
 		slog.Error("create global share", "error", err)
@@ -188,7 +307,7 @@ func (h *ShareHandler) HandleCreateEmailShare(w http.ResponseWriter, r *http.Req
 	}
 	recipientEmail := r.FormValue("recipient_email")
 
-	_, err = h.shares.CreateEmailShare(r.Context(), user.ID, id, recipientEmail)
+	_, err = h.shares.CreateEmailShare(r.Context(), user.ID, id, recipientEmail, sharePermissionFromForm(r))
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
 			http.Error(w, "Not Found", http.StatusNotFound)
@@ -240,6 +359,65 @@ func (h *ShareHandler) HandleRevokeShare(w http.ResponseWriter, r *http.Request)
 	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
 }
 
+// HandleUpdateShareLimits sets a share's expiry date and maximum number of
+// saves. Blank fields remove the limit; a link expires at the end of its
+// expiry date (UTC).
+// POST /patterns/{id}/share/{shareID}/limits
+func (h *ShareHandler) HandleUpdateShareLimits(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	shareID, err := strconv.ParseInt(r.PathValue("shareID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt *time.Time
+	if v := r.FormValue("expires"); v != "" {
+		day, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		end := day.AddDate(0, 0, 1)
+		expiresAt = &end
+	}
+	maxSaves := 0
+	if v := r.FormValue("max_saves"); v != "" {
+		maxSaves, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.shares.SetShareLimits(r.Context(), user.ID, id, shareID, expiresAt, maxSaves); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("update share limits", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
 // HandleRevokeAllShares revokes all shares for a pattern.
 // POST /patterns/{id}/share/revoke-all
 func (h *ShareHandler) HandleRevokeAllShares(w http.ResponseWriter, r *http.Request) {
@@ -267,3 +445,65 @@ func (h *ShareHandler) HandleRevokeAllShares(w http.ResponseWriter, r *http.Requ
 
 	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
 }
+
+// HandleUpdateFromSource updates a received snapshot to the latest version of
+// the pattern it was saved from, fitting any unfinished work sessions on it
+// to the new content.
+// POST /patterns/{id}/update-from-source
+func (h *ShareHandler) HandleUpdateFromSource(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.patterns.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get pattern", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	after, err := h.shares.PullSourceUpdate(r.Context(), user.ID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		slog.Error("update pattern from source", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	restarted, err := h.sessions.RemapSessions(r.Context(), user.ID, before, after)
+	if err != nil {
+		slog.Error("remap sessions after update", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/patterns/"+strconv.FormatInt(id, 10)+"?updated="+strconv.Itoa(restarted), http.StatusSeeOther)
+}
+
+// sharePermissionFromForm reads the permission chosen for a new share link.
+// Forms that don't offer a choice create save-locked shares.
+func sharePermissionFromForm(r *http.Request) domain.SharePermission {
+	if p := r.FormValue("permission"); p != "" {
+		return domain.SharePermission(p)
+	}
+	return domain.SharePermissionSaveLocked
+}
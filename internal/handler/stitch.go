@@ -2,8 +2,10 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -47,10 +49,17 @@ func (h *StitchHandler) HandleLibrary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	known, err := h.stitches.KnownIDs(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list known stitches", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	predefined = filterStitches(predefined, category, search)
 	custom = filterStitches(custom, category, search)
 
-	view.StitchLibraryPage(user.DisplayName, predefined, custom, category, search, "").Render(r.Context(), w)
+	view.StitchLibraryPage(user.DisplayName, predefined, custom, known, category, search, "").Render(r.Context(), w)
 }
 
 // HandleCreateCustom processes custom stitch creation.
@@ -70,8 +79,14 @@ func (h *StitchHandler) HandleCreateCustom(w http.ResponseWriter, r *http.Reques
 	name := r.FormValue("name")
 	description := r.FormValue("description")
 	category := r.FormValue("category")
+	ukAbbreviation := strings.TrimSpace(r.FormValue("uk_abbreviation"))
+	consumes, produces, err := arithmeticFormValues(r)
+	if err != nil {
+		h.renderLibraryWithError(w, r, user, handleStitchError(err))
+		return
+	}
 
-	_, err := h.stitches.CreateCustom(r.Context(), user.ID, abbreviation, name, description, category)
+	_, err = h.stitches.CreateCustom(r.Context(), user.ID, abbreviation, name, description, category, ukAbbreviation, consumes, produces)
 	if err != nil {
 		errMsg := handleStitchError(err)
 		h.renderLibraryWithError(w, r, user, errMsg)
@@ -104,8 +119,14 @@ func (h *StitchHandler) HandleUpdateCustom(w http.ResponseWriter, r *http.Reques
 	name := r.FormValue("name")
 	description := r.FormValue("description")
 	category := r.FormValue("category")
+	ukAbbreviation := strings.TrimSpace(r.FormValue("uk_abbreviation"))
+	consumes, produces, err := arithmeticFormValues(r)
+	if err != nil {
+		h.renderLibraryWithError(w, r, user, handleStitchError(err))
+		return
+	}
 
-	_, err = h.stitches.UpdateCustom(r.Context(), user.ID, id, abbreviation, name, description, category)
+	_, err = h.stitches.UpdateCustom(r.Context(), user.ID, id, abbreviation, name, description, category, ukAbbreviation, consumes, produces)
 	if err != nil {
 		errMsg := handleStitchError(err)
 		h.renderLibraryWithError(w, r, user, errMsg)
@@ -138,12 +159,85 @@ func (h *StitchHandler) HandleDeleteCustom(w http.ResponseWriter, r *http.Reques
 	http.Redirect(w, r, "/stitches", http.StatusSeeOther)
 }
 
+// HandleSetKnown marks a stitch as known or not known by the user, then
+// returns to the library with the filter the form was submitted from.
+func (h *StitchHandler) HandleSetKnown(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.stitches.SetKnown(r.Context(), user.ID, id, r.FormValue("known") == "1"); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("set known stitch", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	back := url.Values{}
+	if category := r.FormValue("category"); category != "" {
+		back.Set("category", category)
+	}
+	if search := r.FormValue("search"); search != "" {
+		back.Set("search", search)
+	}
+	target := "/stitches"
+	if len(back) > 0 {
+		target += "?" + back.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
 func (h *StitchHandler) renderLibraryWithError(w http.ResponseWriter, r *http.Request, user *domain.User, errMsg string) {
 	predefined, _ := h.stitches.ListPredefined(r.Context())
 	custom, _ := h.stitches.ListByUser(r.Context(), user.ID)
+	known, _ := h.stitches.KnownIDs(r.Context(), user.ID)
 
 	w.WriteHeader(http.StatusUnprocessableEntity)
-	view.StitchLibraryPage(user.DisplayName, predefined, custom, "", "", errMsg).Render(r.Context(), w)
+	view.StitchLibraryPage(user.DisplayName, predefined, custom, known, "", "", errMsg).Render(r.Context(), w)
+}
+
+// arithmeticFormValues reads a stitch's "consumes" and "produces" fields,
+// each defaulting to 1 when left empty.
+func arithmeticFormValues(r *http.Request) (consumes, produces int, err error) {
+	if consumes, err = countFormValue(r, "consumes", "stitches used", 1); err != nil {
+		return 0, 0, err
+	}
+	if produces, err = countFormValue(r, "produces", "stitches made", 1); err != nil {
+		return 0, 0, err
+	}
+	return consumes, produces, nil
+}
+
+// countFormValue parses a non-negative integer form value, returning
+// defaultVal only when the field is empty. Malformed or negative input is
+// an ErrInvalidInput naming the field by label. Unlike intFormValue, zero
+// is a valid value.
+func countFormValue(r *http.Request, key, label string, defaultVal int) (int, error) {
+	v := strings.TrimSpace(r.FormValue(key))
+	if v == "" {
+		return defaultVal, nil
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%w: %s must be a whole number, 0 or more", domain.ErrInvalidInput, label)
+	}
+	return parsed, nil
 }
 
 func handleStitchError(err error) string {
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/service"
+	"github.com/msomdec/stitch-map-2/internal/view"
+)
+
+// TagHandler handles HTTP requests for managing pattern tags.
+type TagHandler struct {
+	patterns *service.PatternService
+}
+
+// NewTagHandler creates a new TagHandler.
+func NewTagHandler(patterns *service.PatternService) *TagHandler {
+	return &TagHandler{patterns: patterns}
+}
+
+// HandleList renders the user's tags with how many patterns have each.
+// GET /tags
+func (h *TagHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tags, err := h.patterns.ListTags(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("list tags", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	view.TagsPage(user.DisplayName, tags, "").Render(r.Context(), w)
+}
+
+// HandleCreate creates a tag.
+// POST /tags
+func (h *TagHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.patterns.CreateTag(r.Context(), user.ID, r.FormValue("name")); err != nil {
+		h.renderTagsWithError(w, r, user, handleTagError(err))
+		return
+	}
+
+	http.Redirect(w, r, "/tags", http.StatusSeeOther)
+}
+
+// HandleRename renames a tag.
+// POST /tags/{id}/edit
+func (h *TagHandler) HandleRename(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.RenameTag(r.Context(), user.ID, id, r.FormValue("name")); err != nil {
+		h.renderTagsWithError(w, r, user, handleTagError(err))
+		return
+	}
+
+	http.Redirect(w, r, "/tags", http.StatusSeeOther)
+}
+
+// HandleDelete deletes a tag, removing it from its patterns.
+// POST /tags/{id}/delete
+func (h *TagHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.patterns.DeleteTag(r.Context(), user.ID, id); err != nil {
+		h.renderTagsWithError(w, r, user, handleTagError(err))
+		return
+	}
+
+	http.Redirect(w, r, "/tags", http.StatusSeeOther)
+}
+
+func (h *TagHandler) renderTagsWithError(w http.ResponseWriter, r *http.Request, user *domain.User, errMsg string) {
+	tags, _ := h.patterns.ListTags(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	view.TagsPage(user.DisplayName, tags, errMsg).Render(r.Context(), w)
+}
+
+func handleTagError(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		return err.Error()
+	case errors.Is(err, domain.ErrDuplicateTag):
+		return "You already have a tag with that name."
+	case errors.Is(err, domain.ErrUnauthorized), errors.Is(err, domain.ErrNotFound):
+		return "Tag not found."
+	default:
+		slog.Error("tag operation", "error", err)
+		return "An unexpected error occurred. Please try again."
+	}
+}
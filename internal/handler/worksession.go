@@ -39,7 +39,10 @@ func (h *WorkSessionHandler) HandleStart(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	session, err := h.sessions.Start(r.Context(), user.ID, patternID)
+	// Graded patterns ask which size to follow; others always use size 0.
+	sizeIndex, _ := strconv.Atoi(r.FormValue("size"))
+
+	session, err := h.sessions.Start(r.Context(), user.ID, patternID, sizeIndex)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
 			http.Error(w, "Not Found", http.StatusNotFound)
@@ -94,6 +97,7 @@ func (h *WorkSessionHandler) HandleView(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	pattern = service.TranslatePattern(pattern, user.Terminology)
 	progress := service.ComputeProgress(session, pattern)
 
 	var images []domain.PatternImage
@@ -101,7 +105,7 @@ func (h *WorkSessionHandler) HandleView(w http.ResponseWriter, r *http.Request)
 		images, _ = h.images.ListByGroup(r.Context(), progress.CurrentGroupID)
 	}
 
-	view.WorkSessionPage(user.DisplayName, session, pattern, progress, images).Render(r.Context(), w)
+	view.WorkSessionPage(user.DisplayName, session, pattern, progress, images, user.Terminology).Render(r.Context(), w)
 }
 
 // HandleForward advances the session by one stitch.
@@ -242,8 +246,14 @@ func (h *WorkSessionHandler) HandleAbandon(w http.ResponseWriter, r *http.Reques
 	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 }
 
-// patchTracker sends an SSE patch to update the tracker fragment.
+// patchTracker sends an SSE patch to update the tracker fragment, with
+// stitches shown in the user's terminology.
 func (h *WorkSessionHandler) patchTracker(w http.ResponseWriter, r *http.Request, session *domain.WorkSession, pattern *domain.Pattern) {
+	terms := domain.TerminologyUS
+	if user := UserFromContext(r.Context()); user != nil {
+		terms = user.Terminology
+	}
+	pattern = service.TranslatePattern(pattern, terms)
 	progress := service.ComputeProgress(session, pattern)
 
 	var images []domain.PatternImage
@@ -253,7 +263,7 @@ func (h *WorkSessionHandler) patchTracker(w http.ResponseWriter, r *http.Request
 
 	sse := datastar.NewSSE(w, r)
 	sse.PatchElementTempl(
-		view.WorkSessionTrackerFragment(session, pattern, progress, images),
+		view.WorkSessionTrackerFragment(session, pattern, progress, images, terms),
 		datastar.WithSelectorID("tracker-content"),
 	)
 }
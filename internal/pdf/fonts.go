@@ -0,0 +1,85 @@
+package pdf
+
+// Character widths of the standard fonts for the printable ASCII range
+// (32–126), in thousandths of the font size, from the Adobe font metrics.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// winAnsi maps the non-ASCII characters pattern text commonly uses to their
+// WinAnsiEncoding codes, with their Helvetica width.
+var winAnsi = map[rune]struct {
+	code  byte
+	width int
+}{
+	'×': {0xd7, 584},
+	'·': {0xb7, 278},
+	'–': {0x96, 556},
+	'—': {0x97, 1000},
+	'…': {0x85, 1000},
+	'‘': {0x91, 222},
+	'’': {0x92, 222},
+	'“': {0x93, 333},
+	'”': {0x94, 333},
+	'•': {0x95, 350},
+	'°': {0xb0, 400},
+	'½': {0xbd, 834},
+	'¼': {0xbc, 834},
+	'¾': {0xbe, 834},
+	'é': {0xe9, 556},
+}
+
+// encode converts s to WinAnsiEncoding. Characters the encoding lacks are
+// replaced with "?".
+func encode(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 32 && r <= 126:
+			b = append(b, byte(r))
+		case r == '\t':
+			b = append(b, ' ')
+		default:
+			if c, ok := winAnsi[r]; ok {
+				b = append(b, c.code)
+			} else {
+				b = append(b, '?')
+			}
+		}
+	}
+	return b
+}
+
+// charWidth returns the width of an encoded character in the given font.
+func charWidth(font Font, c byte) int {
+	if font == Courier {
+		return 600
+	}
+	if c >= 32 && c <= 126 {
+		if font == HelveticaBold {
+			return helveticaBoldWidths[c-32]
+		}
+		return helveticaWidths[c-32]
+	}
+	for _, w := range winAnsi {
+		if w.code == c {
+			return w.width
+		}
+	}
+	return 556
+}
@@ -0,0 +1,331 @@
+// Package pdf writes simple flowing PDF documents: wrapped text in the
+// standard PDF fonts, horizontal rules and JPEG or PNG images. It needs no
+// external tools or font files, since the standard fonts are built into
+// every PDF reader.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Font is one of the standard PDF fonts.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	Courier
+)
+
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold", "Courier"}
+
+// Page size and margins, in points (1/72 inch). Pages are US Letter.
+const (
+	PageWidth  = 612.0
+	PageHeight = 792.0
+	Margin     = 54.0
+
+	lineSpacing = 1.3 // Line height as a multiple of font size
+)
+
+// Document is a PDF being written top to bottom. Content flows onto new pages
+// as each one fills up.
+type Document struct {
+	pages  []*bytes.Buffer
+	images []pdfImage
+	font   Font
+	size   float64
+	y      float64 // Baseline position of the next line, from the page bottom
+}
+
+type pdfImage struct {
+	width, height int
+	colorSpace    string
+	filter        string
+	data          []byte
+}
+
+// New creates an empty document with one page, set in 11pt Helvetica.
+func New() *Document {
+	d := &Document{font: Helvetica, size: 11}
+	d.newPage()
+	return d
+}
+
+// SetFont sets the font used by later text.
+func (d *Document) SetFont(font Font, size float64) {
+	d.font = font
+	d.size = size
+}
+
+// Text writes a paragraph, wrapping it to the page width. Newlines in s start
+// new lines.
+func (d *Document) Text(s string) {
+	d.TextIndent(s, 0)
+}
+
+// TextIndent writes a paragraph indented from the left margin by indent
+// points.
+func (d *Document) TextIndent(s string, indent float64) {
+	width := PageWidth - 2*Margin - indent
+	lineHeight := d.size * lineSpacing
+	for _, para := range strings.Split(s, "\n") {
+		for _, line := range d.wrap(para, width) {
+			d.ensure(lineHeight)
+			d.y -= d.size
+			fmt.Fprintf(d.page(), "BT /F%d %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+				int(d.font)+1, d.size, Margin+indent, d.y, escape(encode(line)))
+			d.y -= lineHeight - d.size
+		}
+	}
+}
+
+// Space moves down the page by h points.
+func (d *Document) Space(h float64) {
+	if d.y-h < Margin {
+		d.newPage()
+		return
+	}
+	d.y -= h
+}
+
+// Rule draws a thin horizontal line across the page.
+func (d *Document) Rule() {
+	d.ensure(6)
+	d.y -= 3
+	fmt.Fprintf(d.page(), "q 0.7 G 0.5 w %.2f %.2f m %.2f %.2f l S Q\n", Margin, d.y, PageWidth-Margin, d.y)
+	d.y -= 3
+}
+
+// Image places a JPEG or PNG image at the left margin, scaled down to fit
+// within maxWidth by maxHeight points if it is larger.
+func (d *Document) Image(data []byte, maxWidth, maxHeight float64) error {
+	img, err := decodeImage(data)
+	if err != nil {
+		return err
+	}
+	d.images = append(d.images, img)
+
+	w, h := float64(img.width), float64(img.height)
+	maxWidth = min(maxWidth, PageWidth-2*Margin)
+	maxHeight = min(maxHeight, PageHeight-2*Margin)
+	if scale := min(maxWidth/w, maxHeight/h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+
+	d.ensure(h)
+	d.y -= h
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, Margin, d.y, len(d.images))
+	return nil
+}
+
+// WriteTo writes the finished PDF to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+
+	obj := func(body string, stream []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\n", len(offsets), body)
+		if stream != nil {
+			out.WriteString("stream\n")
+			out.Write(stream)
+			out.WriteString("\nendstream\n")
+		}
+		out.WriteString("endobj\n")
+	}
+
+	// Object numbers: 1 catalog, 2 page tree, then fonts, images, and a
+	// content stream and page object for each page.
+	fontObj := 3
+	imageObj := fontObj + len(fontNames)
+	pageObj := imageObj + len(d.images)
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>", nil)
+
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj+2*i+1))
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)), nil)
+
+	var fonts, xobjects []string
+	for i, name := range fontNames {
+		obj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name), nil)
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, fontObj+i))
+	}
+	for i, img := range d.images {
+		obj(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s /Length %d >>",
+			img.width, img.height, img.colorSpace, img.filter, len(img.data)), img.data)
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, imageObj+i))
+	}
+	resources := fmt.Sprintf("<< /Font << %s >> /XObject << %s >> >>", strings.Join(fonts, " "), strings.Join(xobjects, " "))
+
+	for i, content := range d.pages {
+		stream, err := deflate(content.Bytes())
+		if err != nil {
+			return 0, err
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(stream)), stream)
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>",
+			PageWidth, PageHeight, resources, pageObj+2*i), nil)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// Bytes returns the finished PDF.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PageCount returns the number of pages written so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = PageHeight - Margin
+}
+
+// ensure starts a new page unless h more points fit on the current one.
+func (d *Document) ensure(h float64) {
+	if d.y-h < Margin && d.y < PageHeight-Margin {
+		d.newPage()
+	}
+}
+
+// wrap splits s into lines no wider than width in the current font. Words
+// longer than a line are broken between characters.
+func (d *Document) wrap(s string, width float64) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if d.width(candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for d.width(word) > width {
+			n := len(word)
+			for n > 1 && ((n < len(word) && !utf8.RuneStart(word[n])) || d.width(word[:n]) > width) {
+				n--
+			}
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		line = word
+	}
+	return append(lines, line)
+}
+
+// width returns the width of s in points in the current font.
+func (d *Document) width(s string) float64 {
+	total := 0
+	for _, c := range encode(s) {
+		total += charWidth(d.font, c)
+	}
+	return float64(total) * d.size / 1000
+}
+
+// escape escapes a string for use in a PDF literal string.
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '\\', '(', ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeImage prepares a JPEG or PNG for embedding. RGB and grayscale JPEGs
+// are embedded as is; everything else is decoded and stored as deflated RGB,
+// with any transparency flattened onto white.
+func decodeImage(data []byte) (pdfImage, error) {
+	if bytes.HasPrefix(data, []byte{0xff, 0xd8}) {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return pdfImage{}, fmt.Errorf("decode jpeg: %w", err)
+		}
+		switch cfg.ColorModel {
+		case color.YCbCrModel:
+			return pdfImage{width: cfg.Width, height: cfg.Height, colorSpace: "DeviceRGB", filter: "DCTDecode", data: data}, nil
+		case color.GrayModel:
+			return pdfImage{width: cfg.Width, height: cfg.Height, colorSpace: "DeviceGray", filter: "DCTDecode", data: data}, nil
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return pdfImage{}, fmt.Errorf("decode image: only JPEG and PNG images are supported: %w", err)
+	}
+	bounds := img.Bounds()
+	rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Composite premultiplied color over white.
+			white := 0xffff - a
+			rgb = append(rgb, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+	compressed, err := deflate(rgb)
+	if err != nil {
+		return pdfImage{}, err
+	}
+	return pdfImage{width: bounds.Dx(), height: bounds.Dy(), colorSpace: "DeviceRGB", filter: "FlateDecode", data: compressed}, nil
+}
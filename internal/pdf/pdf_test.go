@@ -0,0 +1,139 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// contentText returns the decompressed content of every deflated stream in a
+// PDF, so tests can look for the text operators written to it.
+func contentText(t *testing.T, data []byte) string {
+	t.Helper()
+	var sb strings.Builder
+	rest := data
+	for {
+		i := bytes.Index(rest, []byte("/FlateDecode >>\nstream\n"))
+		if i == -1 {
+			return sb.String()
+		}
+		rest = rest[i+len("/FlateDecode >>\nstream\n"):]
+		end := bytes.Index(rest, []byte("\nendstream"))
+		zr, err := zlib.NewReader(bytes.NewReader(rest[:end]))
+		if err != nil {
+			t.Fatalf("zlib: %v", err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("inflate: %v", err)
+		}
+		sb.Write(b)
+		rest = rest[end:]
+	}
+}
+
+func TestDocument_Structure(t *testing.T) {
+	doc := New()
+	doc.SetFont(HelveticaBold, 18)
+	doc.Text("Granny (Square)")
+	doc.SetFont(Courier, 10)
+	doc.Text(`Rnd 1 (×2): sc \ inc`)
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+
+	// Every xref entry must point at the start of its object.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(data[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for n := 1; n < count; n++ {
+		off, _ := strconv.Atoi(strings.Fields(lines[2+n])[0])
+		if want := strconv.Itoa(n) + " 0 obj"; !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", n, data[off:off+10])
+		}
+	}
+
+	text := contentText(t, data)
+	if !strings.Contains(text, `(Granny \(Square\)) Tj`) {
+		t.Errorf("parentheses not escaped in content: %s", text)
+	}
+	if !strings.Contains(text, "(Rnd 1 \\(\xd72\\): sc \\\\ inc) Tj") {
+		t.Errorf("× not encoded as WinAnsi or backslash not escaped: %q", text)
+	}
+}
+
+func TestDocument_WrapsAndPaginates(t *testing.T) {
+	doc := New()
+	doc.Text(strings.Repeat("stitch ", 40))
+	if got := strings.Count(contentText(t, mustBytes(t, doc)), " Tj"); got < 2 {
+		t.Errorf("long paragraph written as %d lines, want it wrapped", got)
+	}
+
+	for range 100 {
+		doc.Text("Rnd: 6 sc")
+	}
+	if doc.PageCount() < 2 {
+		t.Errorf("PageCount = %d, want content to flow onto a second page", doc.PageCount())
+	}
+}
+
+func TestDocument_Images(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	var pngData, jpegData bytes.Buffer
+	png.Encode(&pngData, img)
+	jpeg.Encode(&jpegData, img, nil)
+
+	doc := New()
+	if err := doc.Image(pngData.Bytes(), 100, 100); err != nil {
+		t.Fatalf("Image png: %v", err)
+	}
+	if err := doc.Image(jpegData.Bytes(), 100, 100); err != nil {
+		t.Fatalf("Image jpeg: %v", err)
+	}
+	if err := doc.Image([]byte("not an image"), 100, 100); err == nil {
+		t.Error("Image with invalid data: expected error")
+	}
+
+	data := mustBytes(t, doc)
+	if !bytes.Contains(data, []byte("/Filter /DCTDecode")) || !bytes.Contains(data, []byte("/Width 4 /Height 2")) {
+		t.Error("images not embedded")
+	}
+	if !strings.Contains(contentText(t, data), "/Im2 Do") {
+		t.Error("second image not drawn")
+	}
+}
+
+func TestCharWidthTables(t *testing.T) {
+	for c := byte(32); c <= 126; c++ {
+		if charWidth(Helvetica, c) == 0 || charWidth(HelveticaBold, c) == 0 {
+			t.Errorf("no width for %q", c)
+		}
+	}
+}
+
+func mustBytes(t *testing.T, doc *Document) []byte {
+	t.Helper()
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	return data
+}
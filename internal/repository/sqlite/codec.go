@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// encodeIntList serializes a list of numbers as a comma-separated string.
+// A nil or empty list is stored as an empty string.
+func encodeIntList(values []int) string {
+	parts := make([]string, len(values))
+	for i, n := range values {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeIntList parses a list written by encodeIntList.
+// Malformed values are treated as 0 so callers can recover.
+func decodeIntList(s string) []int {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	values := make([]int, len(parts))
+	for i, p := range parts {
+		values[i], _ = strconv.Atoi(p)
+	}
+	return values
+}
+
+// encodeStringList serializes a list of names as a JSON array, or an empty string when empty.
+func encodeStringList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+// decodeStringList parses a list written by encodeStringList.
+// Malformed values decode as an empty list.
+func decodeStringList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(s), &values); err != nil {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,210 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+type collectionRepo struct {
+	db *sql.DB
+}
+
+func (r *collectionRepo) Create(ctx context.Context, c *domain.Collection) error {
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO collections (user_id, name, description, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		c.UserID, c.Name, c.Description, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("insert collection: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get collection id: %w", err)
+	}
+	c.ID = id
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (r *collectionRepo) GetByID(ctx context.Context, id int64) (*domain.Collection, error) {
+	c := &domain.Collection{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, name, description, created_at, updated_at
+		 FROM collections WHERE id = ?`, id,
+	).Scan(&c.ID, &c.UserID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get collection by id: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT pattern_id FROM collection_patterns WHERE collection_id = ? ORDER BY sort_order`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list collection patterns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var patternID int64
+		if err := rows.Scan(&patternID); err != nil {
+			return nil, fmt.Errorf("scan collection pattern: %w", err)
+		}
+		c.PatternIDs = append(c.PatternIDs, patternID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	c.PatternCount = len(c.PatternIDs)
+	return c, nil
+}
+
+func (r *collectionRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Collection, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT c.id, c.user_id, c.name, c.description, c.created_at, c.updated_at, COUNT(cp.pattern_id)
+		 FROM collections c LEFT JOIN collection_patterns cp ON cp.collection_id = c.id
+		 WHERE c.user_id = ?
+		 GROUP BY c.id ORDER BY c.name COLLATE NOCASE, c.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []domain.Collection
+	for rows.Next() {
+		var c domain.Collection
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.PatternCount); err != nil {
+			return nil, fmt.Errorf("scan collection: %w", err)
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// Update writes the collection's name and description; its patterns are
+// changed with AddPatterns, RemovePattern and SetOrder.
+func (r *collectionRepo) Update(ctx context.Context, c *domain.Collection) error {
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE collections SET name = ?, description = ?, updated_at = ? WHERE id = ?`,
+		c.Name, c.Description, now, c.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update collection: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	c.UpdatedAt = now
+	return nil
+}
+
+func (r *collectionRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM collections WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AddPatterns appends the patterns to the end of the collection in the order
+// given, skipping patterns already in it.
+func (r *collectionRepo) AddPatterns(ctx context.Context, collectionID int64, patternIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range patternIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO collection_patterns (collection_id, pattern_id, sort_order)
+			 SELECT ?, ?, COALESCE(MAX(sort_order), -1) + 1 FROM collection_patterns WHERE collection_id = ?`,
+			collectionID, id, collectionID,
+		); err != nil {
+			return fmt.Errorf("add pattern to collection: %w", err)
+		}
+	}
+	if err := touchCollection(ctx, tx, collectionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *collectionRepo) RemovePattern(ctx context.Context, collectionID, patternID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM collection_patterns WHERE collection_id = ? AND pattern_id = ?`, collectionID, patternID)
+	if err != nil {
+		return fmt.Errorf("remove pattern from collection: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	if err := touchCollection(ctx, tx, collectionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetOrder renumbers the collection's patterns in the order given, which
+// should list each of its patterns once.
+func (r *collectionRepo) SetOrder(ctx context.Context, collectionID int64, patternIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range patternIDs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE collection_patterns SET sort_order = ? WHERE collection_id = ? AND pattern_id = ?`,
+			i, collectionID, id,
+		); err != nil {
+			return fmt.Errorf("order collection pattern: %w", err)
+		}
+	}
+	if err := touchCollection(ctx, tx, collectionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// touchCollection marks the collection as updated now.
+func touchCollection(ctx context.Context, tx *sql.Tx, collectionID int64) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE collections SET updated_at = ? WHERE id = ?`, time.Now().UTC(), collectionID,
+	); err != nil {
+		return fmt.Errorf("touch collection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+-- Nested repeat brackets within an instruction group, e.g. "*(sc, inc) x3, dc 2* repeat 4 times".
+-- A block covers the entries whose index (sort_order) lies between start_entry and end_entry.
+
+CREATE TABLE IF NOT EXISTS repeat_blocks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    instruction_group_id INTEGER NOT NULL REFERENCES instruction_groups(id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL,
+    start_entry INTEGER NOT NULL,
+    end_entry INTEGER NOT NULL,
+    repeat_count INTEGER NOT NULL DEFAULT 1,
+    bracket TEXT NOT NULL DEFAULT 'paren',
+    into_stitch TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_repeat_blocks_group ON repeat_blocks(instruction_group_id);
+
+-- Product of the repeat counts of all blocks enclosing an entry, denormalized
+-- so pattern summaries can total stitches without walking the block tree.
+ALTER TABLE stitch_entries ADD COLUMN block_multiplier INTEGER NOT NULL DEFAULT 1;
+
+-- Repeat counters of the blocks enclosing the current entry, comma-separated, outermost first.
+ALTER TABLE work_sessions ADD COLUMN block_repeats TEXT NOT NULL DEFAULT '';
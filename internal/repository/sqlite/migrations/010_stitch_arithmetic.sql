@@ -0,0 +1,23 @@
+-- 010_stitch_arithmetic.sql
+-- Stitches consumed from the previous round/row and produced for the next.
+-- Most stitches are worked into one stitch and make one, so 1 -> 1 is the default.
+
+ALTER TABLE stitches ADD COLUMN consumes INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE stitches ADD COLUMN produces INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE pattern_stitches ADD COLUMN consumes INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE pattern_stitches ADD COLUMN produces INTEGER NOT NULL DEFAULT 1;
+
+-- Predefined stitches that don't follow the 1 -> 1 default.
+UPDATE stitches SET consumes = 0, produces = 1 WHERE user_id IS NULL AND abbreviation IN ('ch');
+UPDATE stitches SET consumes = 1, produces = 2 WHERE user_id IS NULL AND abbreviation IN ('inc', 'v-st');
+UPDATE stitches SET consumes = 1, produces = 5 WHERE user_id IS NULL AND abbreviation IN ('sh');
+UPDATE stitches SET consumes = 2, produces = 1 WHERE user_id IS NULL AND abbreviation IN ('dec', 'sc2tog', 'hdc2tog', 'dc2tog', 'tr2tog');
+UPDATE stitches SET consumes = 3, produces = 1 WHERE user_id IS NULL AND abbreviation IN ('dc3tog');
+UPDATE stitches SET consumes = 1, produces = 0 WHERE user_id IS NULL AND abbreviation IN ('sk');
+UPDATE stitches SET consumes = 0, produces = 0 WHERE user_id IS NULL AND abbreviation IN ('BLO', 'FLO', 'yo', 'tch', 'MR');
+
+-- Existing pattern stitches take the values of the library stitch they were copied from.
+UPDATE pattern_stitches SET
+    consumes = (SELECT s.consumes FROM stitches s WHERE s.id = pattern_stitches.library_stitch_id),
+    produces = (SELECT s.produces FROM stitches s WHERE s.id = pattern_stitches.library_stitch_id)
+WHERE library_stitch_id IN (SELECT id FROM stitches);
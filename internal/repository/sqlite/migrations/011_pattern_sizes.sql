@@ -0,0 +1,14 @@
+-- Graded (multi-size) patterns. Size names are stored as a JSON array on the
+-- pattern; per-size numbers are comma-separated lists aligned with it, or ''
+-- when the number is the same for every size.
+
+ALTER TABLE patterns ADD COLUMN sizes TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE instruction_groups ADD COLUMN size_repeat_counts TEXT NOT NULL DEFAULT '';
+ALTER TABLE instruction_groups ADD COLUMN size_expected_counts TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE stitch_entries ADD COLUMN size_counts TEXT NOT NULL DEFAULT '';
+ALTER TABLE stitch_entries ADD COLUMN size_repeat_counts TEXT NOT NULL DEFAULT '';
+
+-- The pattern size a work session follows (index into patterns.sizes).
+ALTER TABLE work_sessions ADD COLUMN size_index INTEGER NOT NULL DEFAULT 0;
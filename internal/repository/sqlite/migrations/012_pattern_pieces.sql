@@ -0,0 +1,19 @@
+-- Separately made pieces of a pattern (e.g. head, body, arms x2). Groups
+-- reference their piece by its sort_order, like repeat blocks reference
+-- entries, so pieces can be re-inserted along with groups on update.
+
+CREATE TABLE IF NOT EXISTS pattern_pieces (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pattern_id INTEGER NOT NULL REFERENCES patterns(id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    make_count INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_pattern_pieces_pattern ON pattern_pieces(pattern_id);
+
+ALTER TABLE instruction_groups ADD COLUMN piece_index INTEGER NOT NULL DEFAULT 0;
+
+-- Which copy of the current piece a work session is on (0-based).
+ALTER TABLE work_sessions ADD COLUMN current_piece_copy INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Yarn color palette per pattern. Stitch entries refer to a color by its
+-- sort_order; NULL means the entry is worked in the current color.
+
+CREATE TABLE IF NOT EXISTS pattern_colors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pattern_id INTEGER NOT NULL REFERENCES patterns(id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    hex TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_pattern_colors_pattern ON pattern_colors(pattern_id);
+
+ALTER TABLE stitch_entries ADD COLUMN color_index INTEGER;
@@ -0,0 +1,14 @@
+-- Snapshots of earlier pattern versions, taken before each update. The
+-- snapshot is the full pattern (including its pattern stitches) as JSON.
+
+CREATE TABLE IF NOT EXISTS pattern_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pattern_id INTEGER NOT NULL REFERENCES patterns(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(pattern_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pattern_versions_pattern ON pattern_versions(pattern_id);
@@ -0,0 +1,7 @@
+-- Crochet terminology. Users choose whether patterns are shown with US or UK
+-- stitch names; custom stitches may give the abbreviation they go by in UK
+-- terms. An empty UK abbreviation means the stitch is written the same way.
+
+ALTER TABLE users ADD COLUMN terminology TEXT NOT NULL DEFAULT 'us';
+ALTER TABLE stitches ADD COLUMN uk_abbreviation TEXT NOT NULL DEFAULT '';
+ALTER TABLE pattern_stitches ADD COLUMN uk_abbreviation TEXT NOT NULL DEFAULT '';
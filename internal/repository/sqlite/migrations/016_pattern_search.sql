@@ -0,0 +1,26 @@
+-- Full-text index over each pattern's name, description, group labels and
+-- notes, and the abbreviations and names of its stitches. The rowid is the
+-- pattern ID. Rows are rewritten by the pattern repository whenever a pattern
+-- is created or updated, and removed by the trigger below when a pattern is
+-- deleted, including when its owner is.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS pattern_search USING fts5(
+    name,
+    description,
+    labels,
+    notes,
+    stitches,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS pattern_search_delete AFTER DELETE ON patterns
+BEGIN
+    DELETE FROM pattern_search WHERE rowid = old.id;
+END;
+
+INSERT INTO pattern_search (rowid, name, description, labels, notes, stitches)
+SELECT p.id, p.name, p.description,
+       COALESCE((SELECT group_concat(ig.label, ' ') FROM instruction_groups ig WHERE ig.pattern_id = p.id), ''),
+       COALESCE((SELECT group_concat(ig.notes, ' ') FROM instruction_groups ig WHERE ig.pattern_id = p.id AND ig.notes != ''), ''),
+       COALESCE((SELECT group_concat(ps.abbreviation || ' ' || ps.name, ' ') FROM pattern_stitches ps WHERE ps.pattern_id = p.id), '')
+FROM patterns p;
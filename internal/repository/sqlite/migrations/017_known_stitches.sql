@@ -0,0 +1,8 @@
+-- Library stitches each user has marked as ones they know, for finding
+-- patterns that only use stitches they can already work.
+
+CREATE TABLE IF NOT EXISTS user_known_stitches (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stitch_id INTEGER NOT NULL REFERENCES stitches(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, stitch_id)
+);
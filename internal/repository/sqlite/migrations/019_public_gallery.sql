@@ -0,0 +1,4 @@
+-- Patterns are published to the public gallery with a share of type
+-- 'public'; a pattern has at most one.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pattern_shares_public ON pattern_shares(pattern_id) WHERE share_type = 'public';
@@ -0,0 +1,16 @@
+-- Optional limits on share links: an expiry time and a maximum number of
+-- saves. NULL means no limit.
+ALTER TABLE pattern_shares ADD COLUMN expires_at DATETIME;
+ALTER TABLE pattern_shares ADD COLUMN max_saves INTEGER;
+
+-- Views and saves of each share link. user_id is NULL for visitors who
+-- weren't logged in.
+CREATE TABLE IF NOT EXISTS share_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    share_id INTEGER NOT NULL REFERENCES pattern_shares(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK (event_type IN ('view', 'save')),
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_events_share ON share_events(share_id, event_type);
@@ -0,0 +1,8 @@
+-- What a share link lets its recipients do: 'view' only, save a locked
+-- snapshot ('save_locked'), or save an editable fork ('save_editable').
+ALTER TABLE pattern_shares ADD COLUMN permission TEXT NOT NULL DEFAULT 'save_locked';
+
+-- The permission a received copy was saved under; '' for the user's own
+-- patterns. Copies saved before permissions existed were locked snapshots.
+ALTER TABLE patterns ADD COLUMN share_permission TEXT NOT NULL DEFAULT '';
+UPDATE patterns SET share_permission = 'save_locked' WHERE shared_from_user_id IS NOT NULL;
@@ -0,0 +1,6 @@
+-- The pattern a received copy was saved from, and that pattern's updated_at
+-- as of the copy's content, to tell when the copy is out of date. Copies
+-- saved before this was tracked are left without a source: matching them by
+-- name could link them to a pattern their recipient was never given.
+ALTER TABLE patterns ADD COLUMN shared_from_pattern_id INTEGER REFERENCES patterns(id) ON DELETE SET NULL;
+ALTER TABLE patterns ADD COLUMN shared_source_updated_at DATETIME;
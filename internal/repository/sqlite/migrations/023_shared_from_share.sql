@@ -0,0 +1,3 @@
+-- The share a received copy was saved through. Copies only follow their
+-- source while that share is still live for their recipient.
+ALTER TABLE patterns ADD COLUMN shared_from_share_id INTEGER REFERENCES pattern_shares(id) ON DELETE SET NULL;
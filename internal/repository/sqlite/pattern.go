@@ -22,13 +22,26 @@ func (r *patternRepo) Create(ctx context.Context, pattern *domain.Pattern) error
 	}
 	defer tx.Rollback()
 
+	if err := createPattern(ctx, tx, pattern); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// createPattern inserts a pattern and its stitches, pieces, colors and
+// groups within tx, setting its ID and timestamps.
+func createPattern(ctx context.Context, tx *sql.Tx, pattern *domain.Pattern) error {
 	now := time.Now().UTC()
 	result, err := tx.ExecContext(ctx,
-		`INSERT INTO patterns (user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO patterns (user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, share_permission, shared_from_pattern_id, shared_from_share_id, shared_source_updated_at, sizes, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		pattern.UserID, pattern.Name, pattern.Description, pattern.PatternType,
 		pattern.HookSize, pattern.YarnWeight, pattern.Difficulty, pattern.Locked,
-		pattern.SharedFromUserID, pattern.SharedFromName, now, now,
+		pattern.SharedFromUserID, pattern.SharedFromName, pattern.SharePermission, pattern.SharedFromPatternID, pattern.SharedFromShareID, pattern.SharedSourceUpdatedAt, encodeStringList(pattern.Sizes), now, now,
 	)
 	if err != nil {
 		return fmt.Errorf("insert pattern: %w", err)
@@ -44,12 +57,20 @@ func (r *patternRepo) Create(ctx context.Context, pattern *domain.Pattern) error
 		return err
 	}
 
+	if err := insertPieces(ctx, tx, patternID, pattern.Pieces); err != nil {
+		return err
+	}
+
+	if err := insertColors(ctx, tx, patternID, pattern.Colors); err != nil {
+		return err
+	}
+
 	if err := insertGroups(ctx, tx, patternID, pattern.InstructionGroups, psMap); err != nil {
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit: %w", err)
+	if err := indexPatternSearch(ctx, tx, patternID); err != nil {
+		return err
 	}
 
 	pattern.ID = patternID
@@ -60,17 +81,19 @@ func (r *patternRepo) Create(ctx context.Context, pattern *domain.Pattern) error
 
 func (r *patternRepo) GetByID(ctx context.Context, id int64) (*domain.Pattern, error) {
 	p := &domain.Pattern{}
+	var sizes string
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, created_at, updated_at
+		`SELECT id, user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, share_permission, shared_from_pattern_id, shared_from_share_id, shared_source_updated_at, sizes, created_at, updated_at
 		 FROM patterns WHERE id = ?`, id,
 	).Scan(&p.ID, &p.UserID, &p.Name, &p.Description, &p.PatternType,
-		&p.HookSize, &p.YarnWeight, &p.Difficulty, &p.Locked, &p.SharedFromUserID, &p.SharedFromName, &p.CreatedAt, &p.UpdatedAt)
+		&p.HookSize, &p.YarnWeight, &p.Difficulty, &p.Locked, &p.SharedFromUserID, &p.SharedFromName, &p.SharePermission, &p.SharedFromPatternID, &p.SharedFromShareID, &p.SharedSourceUpdatedAt, &sizes, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("get pattern: %w", err)
 	}
+	p.Sizes = decodeStringList(sizes)
 
 	ps, err := r.loadPatternStitches(ctx, id)
 	if err != nil {
@@ -78,6 +101,18 @@ func (r *patternRepo) GetByID(ctx context.Context, id int64) (*domain.Pattern, e
 	}
 	p.PatternStitches = ps
 
+	pieces, err := r.loadPieces(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	p.Pieces = pieces
+
+	colors, err := r.loadColors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	p.Colors = colors
+
 	groups, err := r.loadGroups(ctx, id)
 	if err != nil {
 		return nil, err
@@ -119,7 +154,7 @@ func (r *patternRepo) GetNamesByIDs(ctx context.Context, ids []int64) (map[int64
 
 func (r *patternRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Pattern, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, created_at, updated_at
+		`SELECT id, user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, share_permission, shared_from_pattern_id, shared_from_share_id, shared_source_updated_at, sizes, created_at, updated_at
 		 FROM patterns WHERE user_id = ? AND shared_from_user_id IS NULL ORDER BY updated_at DESC`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("list patterns: %w", err)
@@ -129,10 +164,12 @@ func (r *patternRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Pa
 	var patterns []domain.Pattern
 	for rows.Next() {
 		var p domain.Pattern
+		var sizes string
 		if err := rows.Scan(&p.ID, &p.UserID, &p.Name, &p.Description, &p.PatternType,
-			&p.HookSize, &p.YarnWeight, &p.Difficulty, &p.Locked, &p.SharedFromUserID, &p.SharedFromName, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			&p.HookSize, &p.YarnWeight, &p.Difficulty, &p.Locked, &p.SharedFromUserID, &p.SharedFromName, &p.SharePermission, &p.SharedFromPatternID, &p.SharedFromShareID, &p.SharedSourceUpdatedAt, &sizes, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan pattern: %w", err)
 		}
+		p.Sizes = decodeStringList(sizes)
 		patterns = append(patterns, p)
 	}
 	if err := rows.Err(); err != nil {
@@ -146,6 +183,18 @@ func (r *patternRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Pa
 		}
 		patterns[i].PatternStitches = ps
 
+		pieces, err := r.loadPieces(ctx, patterns[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("load pieces for pattern %d: %w", patterns[i].ID, err)
+		}
+		patterns[i].Pieces = pieces
+
+		colors, err := r.loadColors(ctx, patterns[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("load colors for pattern %d: %w", patterns[i].ID, err)
+		}
+		patterns[i].Colors = colors
+
 		groups, err := r.loadGroups(ctx, patterns[i].ID)
 		if err != nil {
 			return nil, fmt.Errorf("load groups for pattern %d: %w", patterns[i].ID, err)
@@ -158,7 +207,7 @@ func (r *patternRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Pa
 
 func (r *patternRepo) ListSharedWithUser(ctx context.Context, userID int64) ([]domain.Pattern, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, created_at, updated_at
+		`SELECT id, user_id, name, description, pattern_type, hook_size, yarn_weight, difficulty, locked, shared_from_user_id, shared_from_name, share_permission, shared_from_pattern_id, shared_from_share_id, shared_source_updated_at, sizes, created_at, updated_at
 		 FROM patterns WHERE user_id = ? AND shared_from_user_id IS NOT NULL ORDER BY created_at DESC`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("list shared patterns: %w", err)
@@ -168,10 +217,12 @@ func (r *patternRepo) ListSharedWithUser(ctx context.Context, userID int64) ([]d
 	var patterns []domain.Pattern
 	for rows.Next() {
 		var p domain.Pattern
+		var sizes string
 		if err := rows.Scan(&p.ID, &p.UserID, &p.Name, &p.Description, &p.PatternType,
-			&p.HookSize, &p.YarnWeight, &p.Difficulty, &p.Locked, &p.SharedFromUserID, &p.SharedFromName, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			&p.HookSize, &p.YarnWeight, &p.Difficulty, &p.Locked, &p.SharedFromUserID, &p.SharedFromName, &p.SharePermission, &p.SharedFromPatternID, &p.SharedFromShareID, &p.SharedSourceUpdatedAt, &sizes, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan shared pattern: %w", err)
 		}
+		p.Sizes = decodeStringList(sizes)
 		patterns = append(patterns, p)
 	}
 	if err := rows.Err(); err != nil {
@@ -185,6 +236,18 @@ func (r *patternRepo) ListSharedWithUser(ctx context.Context, userID int64) ([]d
 		}
 		patterns[i].PatternStitches = ps
 
+		pieces, err := r.loadPieces(ctx, patterns[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("load pieces for pattern %d: %w", patterns[i].ID, err)
+		}
+		patterns[i].Pieces = pieces
+
+		colors, err := r.loadColors(ctx, patterns[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("load colors for pattern %d: %w", patterns[i].ID, err)
+		}
+		patterns[i].Colors = colors
+
 		groups, err := r.loadGroups(ctx, patterns[i].ID)
 		if err != nil {
 			return nil, fmt.Errorf("load groups for pattern %d: %w", patterns[i].ID, err)
@@ -195,78 +258,135 @@ func (r *patternRepo) ListSharedWithUser(ctx context.Context, userID int64) ([]d
 	return patterns, nil
 }
 
-const patternSummaryQuery = `
+const patternSummaryColumns = `
 SELECT p.id, p.user_id, p.name, p.description, p.pattern_type, p.hook_size, p.yarn_weight,
-       p.difficulty, p.locked, p.shared_from_user_id, p.shared_from_name,
+       p.difficulty, p.locked, p.shared_from_user_id, p.shared_from_name, p.share_permission,
+       p.shared_source_updated_at, src.updated_at AS source_updated_at, sh.id AS source_share_id, sh.expires_at AS source_share_expires_at,
        p.created_at, p.updated_at,
        COUNT(DISTINCT ig.id) as group_count,
-       COALESCE(SUM(se.count * se.repeat_count * ig.repeat_count), 0) as stitch_count
+       COALESCE(SUM(se.count * se.repeat_count * se.block_multiplier * ig.repeat_count * COALESCE(pp.make_count, 1)), 0) as stitches_worked`
+
+const patternSummaryFrom = `
 FROM patterns p
+LEFT JOIN patterns src ON src.id = p.shared_from_pattern_id
+LEFT JOIN pattern_shares sh ON sh.id = p.shared_from_share_id
 LEFT JOIN instruction_groups ig ON ig.pattern_id = p.id
+LEFT JOIN pattern_pieces pp ON pp.pattern_id = p.id AND pp.sort_order = ig.piece_index
 LEFT JOIN stitch_entries se ON se.instruction_group_id = ig.id
 `
 
-func scanPatternSummary(rows *sql.Rows) (domain.PatternSummary, error) {
+// scanPatternSummary scans a row of patternSummaryColumns, followed by any
+// extra columns into extra.
+func scanPatternSummary(rows *sql.Rows, extra ...any) (domain.PatternSummary, error) {
 	var s domain.PatternSummary
-	err := rows.Scan(&s.ID, &s.UserID, &s.Name, &s.Description, &s.PatternType,
+	var copied, sourceUpdatedAt *time.Time
+	var share domain.PatternShare
+	var shareID *int64
+	dest := []any{&s.ID, &s.UserID, &s.Name, &s.Description, &s.PatternType,
 		&s.HookSize, &s.YarnWeight, &s.Difficulty, &s.Locked,
-		&s.SharedFromUserID, &s.SharedFromName, &s.CreatedAt, &s.UpdatedAt,
-		&s.GroupCount, &s.StitchCount)
-	return s, err
+		&s.SharedFromUserID, &s.SharedFromName, &s.SharePermission,
+		&copied, &sourceUpdatedAt, &shareID, &share.ExpiresAt, &s.CreatedAt, &s.UpdatedAt,
+		&s.GroupCount, &s.StitchesWorked}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return s, err
+	}
+	// Updates are only offered while the share the copy came through is live.
+	if sourceUpdatedAt != nil && shareID != nil && !share.Expired(time.Now()) {
+		p := domain.Pattern{SharedFromUserID: s.SharedFromUserID, SharePermission: s.SharePermission, SharedSourceUpdatedAt: copied}
+		s.UpdateAvailable = p.SourceUpdated(*sourceUpdatedAt)
+	}
+	return s, nil
 }
 
-func (r *patternRepo) ListSummaryByUser(ctx context.Context, userID int64) ([]domain.PatternSummary, error) {
-	query := patternSummaryQuery + `WHERE p.user_id = ? AND p.shared_from_user_id IS NULL
-GROUP BY p.id
-ORDER BY p.updated_at DESC`
+func (r *patternRepo) ListSummaryByUser(ctx context.Context, userID int64, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	query := patternSummaryColumns + `, '' AS snippet, 0 AS rank` + patternSummaryFrom +
+		`WHERE p.user_id = ? AND p.shared_from_user_id IS NULL`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	result, err := r.querySummaryPage(ctx, query, []any{userID}, sortByUpdated, page)
 	if err != nil {
-		return nil, fmt.Errorf("list pattern summaries: %w", err)
+		return domain.PatternSummaryPage{}, fmt.Errorf("list pattern summaries: %w", err)
 	}
-	defer rows.Close()
+	return result, nil
+}
 
-	var summaries []domain.PatternSummary
-	for rows.Next() {
-		s, err := scanPatternSummary(rows)
-		if err != nil {
-			return nil, fmt.Errorf("scan pattern summary: %w", err)
-		}
-		summaries = append(summaries, s)
+func (r *patternRepo) ListSummarySharedWithUser(ctx context.Context, userID int64, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	query := patternSummaryColumns + `, '' AS snippet, 0 AS rank` + patternSummaryFrom +
+		`WHERE p.user_id = ? AND p.shared_from_user_id IS NOT NULL`
+
+	result, err := r.querySummaryPage(ctx, query, []any{userID}, sortByCreated, page)
+	if err != nil {
+		return domain.PatternSummaryPage{}, fmt.Errorf("list shared pattern summaries: %w", err)
 	}
-	return summaries, rows.Err()
+	return result, nil
 }
 
-func (r *patternRepo) ListSummarySharedWithUser(ctx context.Context, userID int64) ([]domain.PatternSummary, error) {
-	query := patternSummaryQuery + `WHERE p.user_id = ? AND p.shared_from_user_id IS NOT NULL
-GROUP BY p.id
-ORDER BY p.created_at DESC`
-
-	rows, err := r.db.QueryContext(ctx, query, userID)
+func (r *patternRepo) SearchSummaryByUser(ctx context.Context, userID int64, filter domain.PatternFilter, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	result, err := r.searchSummaries(ctx, `p.user_id = ? AND p.shared_from_user_id IS NULL`, []any{userID}, userID, filter, page)
 	if err != nil {
-		return nil, fmt.Errorf("list shared pattern summaries: %w", err)
+		return domain.PatternSummaryPage{}, fmt.Errorf("search pattern summaries: %w", err)
 	}
-	defer rows.Close()
+	return result, nil
+}
 
-	var summaries []domain.PatternSummary
-	for rows.Next() {
-		s, err := scanPatternSummary(rows)
-		if err != nil {
-			return nil, fmt.Errorf("scan shared pattern summary: %w", err)
-		}
-		summaries = append(summaries, s)
+// SearchPublishedSummaries searches the patterns published to the gallery,
+// from every user. viewerID is whose known stitches KnownOnly refers to.
+func (r *patternRepo) SearchPublishedSummaries(ctx context.Context, viewerID int64, filter domain.PatternFilter, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	result, err := r.searchSummaries(ctx, patternPublishedCondition, []any{domain.ShareTypePublic}, viewerID, filter, page)
+	if err != nil {
+		return domain.PatternSummaryPage{}, fmt.Errorf("search published pattern summaries: %w", err)
 	}
-	return summaries, rows.Err()
+	return result, nil
 }
 
-func (r *patternRepo) SearchSummaryByUser(ctx context.Context, userID int64, filter domain.PatternFilter) ([]domain.PatternSummary, error) {
-	query := patternSummaryQuery + `WHERE p.user_id = ? AND p.shared_from_user_id IS NULL`
-	args := []interface{}{userID}
+// patternPublishedCondition matches patterns with a share of the type given
+// as its parameter, ShareTypePublic.
+const patternPublishedCondition = `EXISTS (SELECT 1 FROM pattern_shares s WHERE s.pattern_id = p.id AND s.share_type = ?)`
+
+// searchSummaries returns a page of the summaries of patterns matching both
+// where, with its parameters args, and the filter. viewerID is the user whose
+// known stitches KnownOnly refers to.
+func (r *patternRepo) searchSummaries(ctx context.Context, where string, whereArgs []any, viewerID int64, filter domain.PatternFilter, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	query := patternSummaryColumns + `, '' AS snippet, 0 AS rank` + patternSummaryFrom
+	var args []interface{}
+
+	match := ftsMatchQuery(filter.Query)
+	if match != "" {
+		query = patternSearchMatches + patternSummaryColumns + `, m.snippet, m.rank` + patternSummaryFrom +
+			`JOIN matches m ON m.pattern_id = p.id
+`
+		args = append(args, domain.SnippetMatchStart, domain.SnippetMatchEnd, match)
+	}
+
+	query += `WHERE ` + where
+	args = append(args, whereArgs...)
 
-	if filter.Query != "" {
-		query += ` AND (p.name LIKE ? OR p.description LIKE ?)`
-		like := "%" + filter.Query + "%"
-		args = append(args, like, like)
+	if filter.Query != "" && match == "" {
+		// Nothing searchable was typed, e.g. only punctuation.
+		query += ` AND 0`
+	}
+	for _, stitch := range filter.Uses {
+		query += ` AND ` + patternUsesCondition
+		args = append(args, stitch, stitch, escapeLike(stitch)+` %`)
+	}
+	for _, id := range filter.Include {
+		query += ` AND ` + patternUsesLibraryStitchCondition
+		args = append(args, id, id)
+	}
+	for _, id := range filter.Exclude {
+		query += ` AND NOT ` + patternUsesLibraryStitchCondition
+		args = append(args, id, id)
+	}
+	if filter.KnownOnly {
+		query += ` AND ` + patternKnownStitchesCondition
+		args = append(args, viewerID)
+	}
+	for _, id := range filter.Tags {
+		query += ` AND EXISTS (SELECT 1 FROM pattern_tags pt WHERE pt.pattern_id = p.id AND pt.tag_id = ?)`
+		args = append(args, id)
+	}
+	for _, name := range filter.TagNames {
+		query += ` AND EXISTS (SELECT 1 FROM pattern_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.pattern_id = p.id AND t.name = ?)`
+		args = append(args, name)
 	}
 	if filter.Type != "" {
 		query += ` AND p.pattern_type = ?`
@@ -277,49 +397,101 @@ func (r *patternRepo) SearchSummaryByUser(ctx context.Context, userID int64, fil
 		args = append(args, filter.Difficulty)
 	}
 
-	query += ` GROUP BY p.id`
-
+	sort := sortByUpdated
 	switch filter.Sort {
 	case "name":
-		query += ` ORDER BY p.name ASC`
+		sort = sortByName
 	case "created":
-		query += ` ORDER BY p.created_at DESC`
+		sort = sortByCreated
 	case "stitches":
-		query += ` ORDER BY stitch_count DESC`
+		sort = sortByStitches
+	case "updated":
 	default:
-		query += ` ORDER BY p.updated_at DESC`
+		if match != "" {
+			sort = sortByRelevance
+		}
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	return r.querySummaryPage(ctx, query, args, sort, page)
+}
+
+func (r *patternRepo) Update(ctx context.Context, pattern *domain.Pattern) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("search pattern summaries: %w", err)
+		return fmt.Errorf("begin tx: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var summaries []domain.PatternSummary
-	for rows.Next() {
-		s, err := scanPatternSummary(rows)
-		if err != nil {
-			return nil, fmt.Errorf("scan pattern summary: %w", err)
-		}
-		summaries = append(summaries, s)
+	if err := updateKeepingImages(ctx, tx, pattern); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
 	}
-	return summaries, rows.Err()
+	return nil
 }
 
-func (r *patternRepo) Update(ctx context.Context, pattern *domain.Pattern) error {
+// UpdateWithVersion updates a pattern as Update does, first saving version,
+// the content it replaces, and pruning all but the newest keepVersions
+// versions (0 keeps all). Nothing is saved if the update fails.
+func (r *patternRepo) UpdateWithVersion(ctx context.Context, pattern *domain.Pattern, version *domain.PatternVersion, keepVersions int) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := insertVersion(ctx, tx, version); err != nil {
+		return fmt.Errorf("save version: %w", err)
+	}
+	if keepVersions > 0 {
+		if err := pruneVersions(ctx, tx, pattern.ID, keepVersions); err != nil {
+			return err
+		}
+	}
+
+	if err := updateKeepingImages(ctx, tx, pattern); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// updateKeepingImages updates a pattern within tx, keeping its images on the
+// groups with the same sort_order.
+func updateKeepingImages(ctx context.Context, tx *sql.Tx, pattern *domain.Pattern) error {
+	// Preserve images across group delete/re-insert.
+	// Load existing images keyed by their group's sort_order before deletion.
+	savedImages, err := loadImagesForPattern(ctx, tx, pattern.ID)
+	if err != nil {
+		return fmt.Errorf("preserve images: %w", err)
+	}
+
+	if err := updatePattern(ctx, tx, pattern); err != nil {
+		return err
+	}
+
+	// Re-insert preserved images with new group IDs (matched by sort_order).
+	if err := restoreImages(ctx, tx, pattern.InstructionGroups, savedImages); err != nil {
+		return fmt.Errorf("restore images: %w", err)
+	}
+	return nil
+}
+
+// updatePattern replaces a pattern's details, stitches, pieces, colors and
+// groups within tx, setting its UpdatedAt. Deleting the groups deletes their
+// images too; callers restore or replace them.
+func updatePattern(ctx context.Context, tx *sql.Tx, pattern *domain.Pattern) error {
 	now := time.Now().UTC()
 	result, err := tx.ExecContext(ctx,
-		`UPDATE patterns SET name = ?, description = ?, pattern_type = ?, hook_size = ?, yarn_weight = ?, difficulty = ?, updated_at = ?
+		`UPDATE patterns SET name = ?, description = ?, pattern_type = ?, hook_size = ?, yarn_weight = ?, difficulty = ?, sizes = ?, updated_at = ?
 		 WHERE id = ?`,
 		pattern.Name, pattern.Description, pattern.PatternType,
-		pattern.HookSize, pattern.YarnWeight, pattern.Difficulty, now, pattern.ID,
+		pattern.HookSize, pattern.YarnWeight, pattern.Difficulty, encodeStringList(pattern.Sizes), now, pattern.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update pattern: %w", err)
@@ -333,13 +505,6 @@ func (r *patternRepo) Update(ctx context.Context, pattern *domain.Pattern) error
 		return domain.ErrNotFound
 	}
 
-	// Preserve images across group delete/re-insert.
-	// Load existing images keyed by their group's sort_order before deletion.
-	savedImages, err := loadImagesForPattern(ctx, tx, pattern.ID)
-	if err != nil {
-		return fmt.Errorf("preserve images: %w", err)
-	}
-
 	// Delete existing groups (cascades to stitch_entries and pattern_images) and pattern_stitches, then re-insert.
 	if _, err := tx.ExecContext(ctx, "DELETE FROM instruction_groups WHERE pattern_id = ?", pattern.ID); err != nil {
 		return fmt.Errorf("delete groups: %w", err)
@@ -347,23 +512,32 @@ func (r *patternRepo) Update(ctx context.Context, pattern *domain.Pattern) error
 	if _, err := tx.ExecContext(ctx, "DELETE FROM pattern_stitches WHERE pattern_id = ?", pattern.ID); err != nil {
 		return fmt.Errorf("delete pattern stitches: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM pattern_pieces WHERE pattern_id = ?", pattern.ID); err != nil {
+		return fmt.Errorf("delete pattern pieces: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM pattern_colors WHERE pattern_id = ?", pattern.ID); err != nil {
+		return fmt.Errorf("delete pattern colors: %w", err)
+	}
 
 	psMap, err := insertPatternStitches(ctx, tx, pattern.ID, pattern.PatternStitches)
 	if err != nil {
 		return err
 	}
 
-	if err := insertGroups(ctx, tx, pattern.ID, pattern.InstructionGroups, psMap); err != nil {
+	if err := insertPieces(ctx, tx, pattern.ID, pattern.Pieces); err != nil {
 		return err
 	}
 
-	// Re-insert preserved images with new group IDs (matched by sort_order).
-	if err := restoreImages(ctx, tx, pattern.InstructionGroups, savedImages); err != nil {
-		return fmt.Errorf("restore images: %w", err)
+	if err := insertColors(ctx, tx, pattern.ID, pattern.Colors); err != nil {
+		return err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit: %w", err)
+	if err := insertGroups(ctx, tx, pattern.ID, pattern.InstructionGroups, psMap); err != nil {
+		return err
+	}
+
+	if err := indexPatternSearch(ctx, tx, pattern.ID); err != nil {
+		return err
 	}
 
 	pattern.UpdatedAt = now
@@ -400,113 +574,192 @@ func (r *patternRepo) Duplicate(ctx context.Context, id int64, newUserID int64)
 		HookSize:          original.HookSize,
 		YarnWeight:        original.YarnWeight,
 		Difficulty:        original.Difficulty,
+		Sizes:             original.Sizes,
+		Pieces:            original.Pieces,
+		Colors:            original.Colors,
 		Locked:            false, // copies are always unlocked
 		PatternStitches:   original.PatternStitches,
 		InstructionGroups: original.InstructionGroups,
 	}
 
-	if err := r.Create(ctx, dup); err != nil {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createPattern(ctx, tx, dup); err != nil {
 		return nil, fmt.Errorf("create duplicate: %w", err)
 	}
 
+	if err := copyPatternTags(ctx, tx, original.ID, dup.ID, newUserID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
 	return dup, nil
 }
 
-func (r *patternRepo) DuplicateAsShared(ctx context.Context, id int64, newUserID int64, sharedFromUserID int64, sharedFromName string) (*domain.Pattern, error) {
-	original, err := r.GetByID(ctx, id)
+// DuplicateAsShared copies the pattern shared by share into another user's
+// library as a copy shared from its owner, locked unless the share allows
+// editing. With withTags, the copy keeps the original's tags, created as tags
+// of the new user. The save is recorded against the share in the same
+// transaction, and fails with ErrShareLimitReached once the share has been
+// saved from MaxSaves times.
+func (r *patternRepo) DuplicateAsShared(ctx context.Context, share *domain.PatternShare, newUserID int64, sharedFromUserID int64, sharedFromName string, withTags bool) (*domain.Pattern, error) {
+	original, err := r.GetByID(ctx, share.PatternID)
 	if err != nil {
 		return nil, fmt.Errorf("get original: %w", err)
 	}
 
 	dup := &domain.Pattern{
-		UserID:            newUserID,
-		Name:              original.Name,
-		Description:       original.Description,
-		PatternType:       original.PatternType,
-		HookSize:          original.HookSize,
-		YarnWeight:        original.YarnWeight,
-		Difficulty:        original.Difficulty,
-		Locked:            true,
-		SharedFromUserID:  &sharedFromUserID,
-		SharedFromName:    sharedFromName,
-		PatternStitches:   original.PatternStitches,
-		InstructionGroups: original.InstructionGroups,
+		UserID:                newUserID,
+		Name:                  original.Name,
+		Description:           original.Description,
+		PatternType:           original.PatternType,
+		HookSize:              original.HookSize,
+		YarnWeight:            original.YarnWeight,
+		Difficulty:            original.Difficulty,
+		Sizes:                 original.Sizes,
+		Pieces:                original.Pieces,
+		Colors:                original.Colors,
+		Locked:                share.Permission != domain.SharePermissionSaveEditable,
+		SharedFromUserID:      &sharedFromUserID,
+		SharedFromName:        sharedFromName,
+		SharePermission:       share.Permission,
+		SharedFromPatternID:   &original.ID,
+		SharedFromShareID:     &share.ID,
+		SharedSourceUpdatedAt: &original.UpdatedAt,
+		PatternStitches:       original.PatternStitches,
+		InstructionGroups:     original.InstructionGroups,
 	}
 
-	if err := r.Create(ctx, dup); err != nil {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Count and record the save in one statement, so concurrent saves can't
+	// all pass the limit.
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO share_events (share_id, event_type, user_id, created_at)
+		 SELECT ?, ?, ?, ?
+		 WHERE ? = 0 OR (SELECT COUNT(*) FROM share_events WHERE share_id = ? AND event_type = ?) < ?`,
+		share.ID, domain.ShareEventSave, newUserID, time.Now().UTC(),
+		share.MaxSaves, share.ID, domain.ShareEventSave, share.MaxSaves)
+	if err != nil {
+		return nil, fmt.Errorf("record share save: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, domain.ErrShareLimitReached
+	}
+
+	if err := createPattern(ctx, tx, dup); err != nil {
 		return nil, fmt.Errorf("create shared duplicate: %w", err)
 	}
 
 	// Copy images from the original pattern to the duplicate.
-	if err := r.copyImages(ctx, original, dup); err != nil {
+	if err := copyImages(ctx, tx, original.ID, dup); err != nil {
 		return nil, fmt.Errorf("copy images: %w", err)
 	}
 
+	if withTags {
+		if err := copyPatternTags(ctx, tx, original.ID, dup.ID, newUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
 	return dup, nil
 }
 
-// copyImages duplicates all pattern_images and their file_blobs from the
-// original pattern to the duplicate, mapping groups by sort_order.
-func (r *patternRepo) copyImages(ctx context.Context, original, dup *domain.Pattern) error {
-	// Build sort_order -> new group ID mapping from the duplicate.
+// UpdateFromSource replaces a received copy's content, including its images,
+// with that of source, and records that the copy is now up to date with it.
+func (r *patternRepo) UpdateFromSource(ctx context.Context, pattern *domain.Pattern, source *domain.Pattern) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	oldImages, err := loadImagesForPattern(ctx, tx, pattern.ID)
+	if err != nil {
+		return fmt.Errorf("load images: %w", err)
+	}
+
+	if err := updatePattern(ctx, tx, pattern); err != nil {
+		return err
+	}
+
+	// The copy's images went with its groups; replace their blobs with
+	// copies of the source's images.
+	for _, img := range oldImages {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM file_blobs WHERE storage_key = ?", img.StorageKey); err != nil {
+			return fmt.Errorf("delete image blob: %w", err)
+		}
+	}
+	if err := copyImages(ctx, tx, source.ID, pattern); err != nil {
+		return fmt.Errorf("copy images: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE patterns SET shared_source_updated_at = ? WHERE id = ?`, source.UpdatedAt, pattern.ID); err != nil {
+		return fmt.Errorf("set shared source updated at: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	pattern.SharedSourceUpdatedAt = &source.UpdatedAt
+	return nil
+}
+
+// copyImages duplicates the images of the pattern sourceID and their
+// file_blobs onto dup, mapping groups by sort_order. The images are loaded by
+// pattern rather than from the source's groups, whose IDs are overwritten
+// when dup shares their slice.
+func copyImages(ctx context.Context, tx *sql.Tx, sourceID int64, dup *domain.Pattern) error {
+	images, err := loadImagesForPattern(ctx, tx, sourceID)
+	if err != nil {
+		return err
+	}
+
 	dupGroupBySort := make(map[int]int64, len(dup.InstructionGroups))
 	for _, g := range dup.InstructionGroups {
 		dupGroupBySort[g.SortOrder] = g.ID
 	}
 
-	for _, g := range original.InstructionGroups {
-		newGroupID, ok := dupGroupBySort[g.SortOrder]
+	for _, img := range images {
+		newGroupID, ok := dupGroupBySort[img.GroupSortOrder]
 		if !ok {
 			continue
 		}
 
-		rows, err := r.db.QueryContext(ctx,
-			`SELECT pi.filename, pi.content_type, pi.size, pi.storage_key, pi.sort_order, pi.created_at
-			 FROM pattern_images pi WHERE pi.instruction_group_id = ? ORDER BY pi.sort_order`, g.ID)
+		// Copy the file blob with a new storage key.
+		newKey := img.StorageKey + "-copy-" + fmt.Sprintf("%d", dup.ID)
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO file_blobs (storage_key, data)
+			 SELECT ?, data FROM file_blobs WHERE storage_key = ?`,
+			newKey, img.StorageKey)
 		if err != nil {
-			return fmt.Errorf("load images for group %d: %w", g.ID, err)
-		}
-
-		type imgRow struct {
-			Filename    string
-			ContentType string
-			Size        int64
-			StorageKey  string
-			SortOrder   int
-			CreatedAt   time.Time
-		}
-		var imgs []imgRow
-		for rows.Next() {
-			var img imgRow
-			if err := rows.Scan(&img.Filename, &img.ContentType, &img.Size, &img.StorageKey, &img.SortOrder, &img.CreatedAt); err != nil {
-				rows.Close()
-				return fmt.Errorf("scan image: %w", err)
-			}
-			imgs = append(imgs, img)
-		}
-		rows.Close()
-		if err := rows.Err(); err != nil {
-			return err
+			return fmt.Errorf("copy file blob: %w", err)
 		}
 
-		for _, img := range imgs {
-			// Copy the file blob with a new storage key.
-			newKey := img.StorageKey + "-copy-" + fmt.Sprintf("%d", dup.ID)
-			_, err := r.db.ExecContext(ctx,
-				`INSERT INTO file_blobs (storage_key, data)
-				 SELECT ?, data FROM file_blobs WHERE storage_key = ?`,
-				newKey, img.StorageKey)
-			if err != nil {
-				return fmt.Errorf("copy file blob: %w", err)
-			}
-
-			_, err = r.db.ExecContext(ctx,
-				`INSERT INTO pattern_images (instruction_group_id, filename, content_type, size, storage_key, sort_order, created_at)
-				 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-				newGroupID, img.Filename, img.ContentType, img.Size, newKey, img.SortOrder, img.CreatedAt)
-			if err != nil {
-				return fmt.Errorf("insert copied image: %w", err)
-			}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO pattern_images (instruction_group_id, filename, content_type, size, storage_key, sort_order, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			newGroupID, img.Filename, img.ContentType, img.Size, newKey, img.SortOrder, img.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert copied image: %w", err)
 		}
 	}
 
@@ -523,9 +776,9 @@ func insertPatternStitches(ctx context.Context, tx *sql.Tx, patternID int64, sti
 	for i := range stitches {
 		ps := &stitches[i]
 		result, err := tx.ExecContext(ctx,
-			`INSERT INTO pattern_stitches (pattern_id, abbreviation, name, description, category, library_stitch_id)
-			 VALUES (?, ?, ?, ?, ?, ?)`,
-			patternID, ps.Abbreviation, ps.Name, ps.Description, ps.Category, ps.LibraryStitchID,
+			`INSERT INTO pattern_stitches (pattern_id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, library_stitch_id)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			patternID, ps.Abbreviation, ps.Name, ps.Description, ps.Category, ps.Consumes, ps.Produces, ps.UKAbbreviation, ps.LibraryStitchID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("insert pattern stitch %d: %w", i, err)
@@ -549,13 +802,64 @@ func insertPatternStitches(ctx context.Context, tx *sql.Tx, patternID int64, sti
 	return psMap, nil
 }
 
+// insertPieces inserts a pattern's pieces. Groups refer to pieces by index,
+// which is stored as the piece's sort_order.
+func insertPieces(ctx context.Context, tx *sql.Tx, patternID int64, pieces []domain.PatternPiece) error {
+	for i := range pieces {
+		pc := &pieces[i]
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO pattern_pieces (pattern_id, sort_order, name, notes, make_count)
+			 VALUES (?, ?, ?, ?, ?)`,
+			patternID, i, pc.Name, pc.Notes, pc.MakeCount,
+		)
+		if err != nil {
+			return fmt.Errorf("insert piece %d: %w", i, err)
+		}
+
+		pieceID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("get piece id: %w", err)
+		}
+		pc.ID = pieceID
+		pc.PatternID = patternID
+		pc.SortOrder = i
+	}
+	return nil
+}
+
+// insertColors inserts a pattern's color palette. Stitch entries refer to
+// colors by index, which is stored as the color's sort_order.
+func insertColors(ctx context.Context, tx *sql.Tx, patternID int64, colors []domain.PatternColor) error {
+	for i := range colors {
+		c := &colors[i]
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO pattern_colors (pattern_id, sort_order, label, name, hex)
+			 VALUES (?, ?, ?, ?, ?)`,
+			patternID, i, c.Label, c.Name, c.Hex,
+		)
+		if err != nil {
+			return fmt.Errorf("insert color %d: %w", i, err)
+		}
+
+		colorID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("get color id: %w", err)
+		}
+		c.ID = colorID
+		c.PatternID = patternID
+		c.SortOrder = i
+	}
+	return nil
+}
+
 func insertGroups(ctx context.Context, tx *sql.Tx, patternID int64, groups []domain.InstructionGroup, psMap map[int64]int64) error {
 	for i := range groups {
 		g := &groups[i]
 		result, err := tx.ExecContext(ctx,
-			`INSERT INTO instruction_groups (pattern_id, sort_order, label, repeat_count, expected_count, notes)
-			 VALUES (?, ?, ?, ?, ?, ?)`,
-			patternID, g.SortOrder, g.Label, g.RepeatCount, g.ExpectedCount, g.Notes,
+			`INSERT INTO instruction_groups (pattern_id, sort_order, piece_index, label, repeat_count, size_repeat_counts, expected_count, size_expected_counts, notes)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			patternID, g.SortOrder, g.PieceIndex, g.Label, g.RepeatCount, encodeIntList(g.SizeRepeatCounts),
+			g.ExpectedCount, encodeIntList(g.SizeExpectedCounts), g.Notes,
 		)
 		if err != nil {
 			return fmt.Errorf("insert group %d: %w", i, err)
@@ -578,9 +882,10 @@ func insertGroups(ctx context.Context, tx *sql.Tx, patternID int64, groups []dom
 			}
 
 			res, err := tx.ExecContext(ctx,
-				`INSERT INTO stitch_entries (instruction_group_id, sort_order, pattern_stitch_id, count, into_stitch, repeat_count)
-				 VALUES (?, ?, ?, ?, ?, ?)`,
-				groupID, e.SortOrder, mappedID, e.Count, e.IntoStitch, e.RepeatCount,
+				`INSERT INTO stitch_entries (instruction_group_id, sort_order, pattern_stitch_id, count, size_counts, into_stitch, repeat_count, size_repeat_counts, block_multiplier, color_index)
+				 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				groupID, e.SortOrder, mappedID, e.Count, encodeIntList(e.SizeCounts),
+				e.IntoStitch, e.RepeatCount, encodeIntList(e.SizeRepeatCounts), g.BlockMultiplier(j), e.ColorIndex,
 			)
 			if err != nil {
 				return fmt.Errorf("insert entry %d/%d: %w", i, j, err)
@@ -594,13 +899,32 @@ func insertGroups(ctx context.Context, tx *sql.Tx, patternID int64, groups []dom
 			e.InstructionGroupID = groupID
 			e.PatternStitchID = mappedID
 		}
+
+		for k := range g.RepeatBlocks {
+			b := &g.RepeatBlocks[k]
+			res, err := tx.ExecContext(ctx,
+				`INSERT INTO repeat_blocks (instruction_group_id, sort_order, start_entry, end_entry, repeat_count, bracket, into_stitch)
+				 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				groupID, k, b.StartEntry, b.EndEntry, b.RepeatCount, b.Bracket, b.IntoStitch,
+			)
+			if err != nil {
+				return fmt.Errorf("insert repeat block %d/%d: %w", i, k, err)
+			}
+
+			blockID, err := res.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("get repeat block id: %w", err)
+			}
+			b.ID = blockID
+			b.InstructionGroupID = groupID
+		}
 	}
 	return nil
 }
 
 func (r *patternRepo) loadPatternStitches(ctx context.Context, patternID int64) ([]domain.PatternStitch, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, pattern_id, abbreviation, name, description, category, library_stitch_id
+		`SELECT id, pattern_id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, library_stitch_id
 		 FROM pattern_stitches WHERE pattern_id = ? ORDER BY id`, patternID)
 	if err != nil {
 		return nil, fmt.Errorf("load pattern stitches: %w", err)
@@ -611,7 +935,7 @@ func (r *patternRepo) loadPatternStitches(ctx context.Context, patternID int64)
 	for rows.Next() {
 		var ps domain.PatternStitch
 		if err := rows.Scan(&ps.ID, &ps.PatternID, &ps.Abbreviation, &ps.Name,
-			&ps.Description, &ps.Category, &ps.LibraryStitchID); err != nil {
+			&ps.Description, &ps.Category, &ps.Consumes, &ps.Produces, &ps.UKAbbreviation, &ps.LibraryStitchID); err != nil {
 			return nil, fmt.Errorf("scan pattern stitch: %w", err)
 		}
 		stitches = append(stitches, ps)
@@ -619,9 +943,49 @@ func (r *patternRepo) loadPatternStitches(ctx context.Context, patternID int64)
 	return stitches, rows.Err()
 }
 
+func (r *patternRepo) loadPieces(ctx context.Context, patternID int64) ([]domain.PatternPiece, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, pattern_id, sort_order, name, notes, make_count
+		 FROM pattern_pieces WHERE pattern_id = ? ORDER BY sort_order`, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("load pieces: %w", err)
+	}
+	defer rows.Close()
+
+	var pieces []domain.PatternPiece
+	for rows.Next() {
+		var pc domain.PatternPiece
+		if err := rows.Scan(&pc.ID, &pc.PatternID, &pc.SortOrder, &pc.Name, &pc.Notes, &pc.MakeCount); err != nil {
+			return nil, fmt.Errorf("scan piece: %w", err)
+		}
+		pieces = append(pieces, pc)
+	}
+	return pieces, rows.Err()
+}
+
+func (r *patternRepo) loadColors(ctx context.Context, patternID int64) ([]domain.PatternColor, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, pattern_id, sort_order, label, name, hex
+		 FROM pattern_colors WHERE pattern_id = ? ORDER BY sort_order`, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("load colors: %w", err)
+	}
+	defer rows.Close()
+
+	var colors []domain.PatternColor
+	for rows.Next() {
+		var c domain.PatternColor
+		if err := rows.Scan(&c.ID, &c.PatternID, &c.SortOrder, &c.Label, &c.Name, &c.Hex); err != nil {
+			return nil, fmt.Errorf("scan color: %w", err)
+		}
+		colors = append(colors, c)
+	}
+	return colors, rows.Err()
+}
+
 func (r *patternRepo) loadGroups(ctx context.Context, patternID int64) ([]domain.InstructionGroup, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, pattern_id, sort_order, label, repeat_count, expected_count, notes
+		`SELECT id, pattern_id, sort_order, piece_index, label, repeat_count, size_repeat_counts, expected_count, size_expected_counts, notes
 		 FROM instruction_groups WHERE pattern_id = ? ORDER BY sort_order`, patternID)
 	if err != nil {
 		return nil, fmt.Errorf("load groups: %w", err)
@@ -631,9 +995,13 @@ func (r *patternRepo) loadGroups(ctx context.Context, patternID int64) ([]domain
 	var groups []domain.InstructionGroup
 	for rows.Next() {
 		var g domain.InstructionGroup
-		if err := rows.Scan(&g.ID, &g.PatternID, &g.SortOrder, &g.Label, &g.RepeatCount, &g.ExpectedCount, &g.Notes); err != nil {
+		var sizeRepeats, sizeExpected string
+		if err := rows.Scan(&g.ID, &g.PatternID, &g.SortOrder, &g.PieceIndex, &g.Label, &g.RepeatCount, &sizeRepeats,
+			&g.ExpectedCount, &sizeExpected, &g.Notes); err != nil {
 			return nil, fmt.Errorf("scan group: %w", err)
 		}
+		g.SizeRepeatCounts = decodeIntList(sizeRepeats)
+		g.SizeExpectedCounts = decodeIntList(sizeExpected)
 		groups = append(groups, g)
 	}
 	if err := rows.Err(); err != nil {
@@ -646,13 +1014,19 @@ func (r *patternRepo) loadGroups(ctx context.Context, patternID int64) ([]domain
 			return nil, err
 		}
 		groups[i].StitchEntries = entries
+
+		blocks, err := r.loadRepeatBlocks(ctx, groups[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		groups[i].RepeatBlocks = blocks
 	}
 	return groups, nil
 }
 
 func (r *patternRepo) loadEntries(ctx context.Context, groupID int64) ([]domain.StitchEntry, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, instruction_group_id, sort_order, pattern_stitch_id, count, into_stitch, repeat_count
+		`SELECT id, instruction_group_id, sort_order, pattern_stitch_id, count, size_counts, into_stitch, repeat_count, size_repeat_counts, color_index
 		 FROM stitch_entries WHERE instruction_group_id = ? ORDER BY sort_order`, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("load entries: %w", err)
@@ -662,15 +1036,39 @@ func (r *patternRepo) loadEntries(ctx context.Context, groupID int64) ([]domain.
 	var entries []domain.StitchEntry
 	for rows.Next() {
 		var e domain.StitchEntry
+		var sizeCounts, sizeRepeats string
 		if err := rows.Scan(&e.ID, &e.InstructionGroupID, &e.SortOrder, &e.PatternStitchID,
-			&e.Count, &e.IntoStitch, &e.RepeatCount); err != nil {
+			&e.Count, &sizeCounts, &e.IntoStitch, &e.RepeatCount, &sizeRepeats, &e.ColorIndex); err != nil {
 			return nil, fmt.Errorf("scan entry: %w", err)
 		}
+		e.SizeCounts = decodeIntList(sizeCounts)
+		e.SizeRepeatCounts = decodeIntList(sizeRepeats)
 		entries = append(entries, e)
 	}
 	return entries, rows.Err()
 }
 
+func (r *patternRepo) loadRepeatBlocks(ctx context.Context, groupID int64) ([]domain.RepeatBlock, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, instruction_group_id, start_entry, end_entry, repeat_count, bracket, into_stitch
+		 FROM repeat_blocks WHERE instruction_group_id = ? ORDER BY sort_order`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("load repeat blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []domain.RepeatBlock
+	for rows.Next() {
+		var b domain.RepeatBlock
+		if err := rows.Scan(&b.ID, &b.InstructionGroupID, &b.StartEntry, &b.EndEntry,
+			&b.RepeatCount, &b.Bracket, &b.IntoStitch); err != nil {
+			return nil, fmt.Errorf("scan repeat block: %w", err)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
 // savedImage holds a pattern_images row along with the sort_order of the
 // instruction group it belonged to, so it can be reassociated after groups
 // are deleted and re-inserted.
@@ -0,0 +1,168 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// summarySort is an order for pattern summary queries. Rows with the same
+// key are ordered by pattern ID in the same direction, so a page can resume
+// exactly after its last row.
+type summarySort struct {
+	name      string // Identifies the sort in cursors
+	key       string // SQL expression ordered by
+	desc      bool
+	aggregate bool // key is an aggregate, so paging conditions go in HAVING
+
+	// format writes a row's key for a cursor and parse reads it back as a
+	// query parameter.
+	format func(s *domain.PatternSummary, rank float64) string
+	parse  func(string) (any, error)
+}
+
+var (
+	sortByUpdated = summarySort{
+		name: "updated", key: "p.updated_at", desc: true,
+		format: func(s *domain.PatternSummary, _ float64) string { return s.UpdatedAt.UTC().Format(time.RFC3339Nano) },
+		parse:  parseCursorTime,
+	}
+	sortByCreated = summarySort{
+		name: "created", key: "p.created_at", desc: true,
+		format: func(s *domain.PatternSummary, _ float64) string { return s.CreatedAt.UTC().Format(time.RFC3339Nano) },
+		parse:  parseCursorTime,
+	}
+	sortByName = summarySort{
+		name: "name", key: "p.name",
+		format: func(s *domain.PatternSummary, _ float64) string { return s.Name },
+		parse:  func(v string) (any, error) { return v, nil },
+	}
+	sortByStitches = summarySort{
+		name: "stitches", key: "stitches_worked", desc: true, aggregate: true,
+		format: func(s *domain.PatternSummary, _ float64) string { return strconv.Itoa(s.StitchesWorked) },
+		parse:  func(v string) (any, error) { return strconv.Atoi(v) },
+	}
+	// sortByRelevance orders full-text matches best first; it needs the
+	// matches CTE of patternSearchMatches.
+	sortByRelevance = summarySort{
+		name: "relevance", key: "m.rank",
+		format: func(_ *domain.PatternSummary, rank float64) string { return strconv.FormatFloat(rank, 'g', -1, 64) },
+		parse:  func(v string) (any, error) { return strconv.ParseFloat(v, 64) },
+	}
+)
+
+func parseCursorTime(v string) (any, error) {
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+// querySummaryPage runs a summary query for one page. query selects
+// patternSummaryColumns followed by a snippet and a rank from
+// patternSummaryFrom, ending with its WHERE clause; it is grouped by pattern,
+// ordered and limited here, and each summary's tags are loaded with it.
+func (r *patternRepo) querySummaryPage(ctx context.Context, query string, args []any, sort summarySort, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	var after string
+	if page.After != "" {
+		key, id, err := decodeSummaryCursor(page.After, sort)
+		if err != nil {
+			return domain.PatternSummaryPage{}, err
+		}
+		op := ">"
+		if sort.desc {
+			op = "<"
+		}
+		after = fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND p.id %[2]s ?))", sort.key, op)
+		// The condition's parameters follow the WHERE clause's either way.
+		args = append(args, key, key, id)
+		if !sort.aggregate {
+			query += "\nAND " + after
+		}
+	}
+
+	query += "\nGROUP BY p.id"
+	if after != "" && sort.aggregate {
+		query += "\nHAVING " + after
+	}
+	dir := "ASC"
+	if sort.desc {
+		dir = "DESC"
+	}
+	limit := page.PageSize()
+	query += fmt.Sprintf("\nORDER BY %s %s, p.id %s\nLIMIT ?", sort.key, dir, dir)
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return domain.PatternSummaryPage{}, fmt.Errorf("query pattern summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var result domain.PatternSummaryPage
+	var ranks []float64
+	for rows.Next() {
+		var snippet string
+		var rank float64
+		s, err := scanPatternSummary(rows, &snippet, &rank)
+		if err != nil {
+			return domain.PatternSummaryPage{}, fmt.Errorf("scan pattern summary: %w", err)
+		}
+		s.Snippet = snippet
+		result.Summaries = append(result.Summaries, s)
+		ranks = append(ranks, rank)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.PatternSummaryPage{}, err
+	}
+
+	if len(result.Summaries) > limit {
+		result.Summaries = result.Summaries[:limit]
+		last := &result.Summaries[limit-1]
+		result.Next = encodeSummaryCursor(sort, last.ID, sort.format(last, ranks[limit-1]))
+	}
+
+	ids := make([]int64, len(result.Summaries))
+	for i, s := range result.Summaries {
+		ids[i] = s.ID
+	}
+	tags, err := loadPatternTags(ctx, r.db, ids)
+	if err != nil {
+		return domain.PatternSummaryPage{}, err
+	}
+	for i := range result.Summaries {
+		result.Summaries[i].Tags = tags[result.Summaries[i].ID]
+	}
+	return result, nil
+}
+
+// encodeSummaryCursor writes the position after a row as an opaque cursor.
+func encodeSummaryCursor(sort summarySort, id int64, key string) string {
+	raw := sort.name + ":" + strconv.FormatInt(id, 10) + ":" + key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSummaryCursor reads a cursor written for sort, returning its key as
+// a query parameter and the pattern ID.
+func decodeSummaryCursor(cursor string, sort summarySort) (any, int64, error) {
+	invalid := fmt.Errorf("%w: invalid page cursor", domain.ErrInvalidInput)
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || parts[0] != sort.name {
+		return nil, 0, invalid
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	key, err := sort.parse(parts[2])
+	if err != nil {
+		return nil, 0, invalid
+	}
+	return key, id, nil
+}
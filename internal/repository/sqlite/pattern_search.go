@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// patternSearchIndexQuery builds a pattern's row in the pattern_search index
+// from its stored name, description, groups and stitches. Migration
+// 016_pattern_search.sql backfills the index the same way.
+const patternSearchIndexQuery = `
+INSERT INTO pattern_search (rowid, name, description, labels, notes, stitches)
+SELECT p.id, p.name, p.description,
+       COALESCE((SELECT group_concat(ig.label, ' ') FROM instruction_groups ig WHERE ig.pattern_id = p.id), ''),
+       COALESCE((SELECT group_concat(ig.notes, ' ') FROM instruction_groups ig WHERE ig.pattern_id = p.id AND ig.notes != ''), ''),
+       COALESCE((SELECT group_concat(ps.abbreviation || ' ' || ps.name, ' ') FROM pattern_stitches ps WHERE ps.pattern_id = p.id), '')
+FROM patterns p WHERE p.id = ?`
+
+// patternSearchMatches selects the patterns matching a full-text query, with
+// each match's rank (lower is better) and a snippet of the best-matching
+// column. Its parameters are the snippet's start and end markers and the
+// MATCH expression. Matches in the name count most, then the description and
+// stitches, then group labels and notes. It is materialized so that the
+// ranking functions run against the index rather than the summary's join.
+const patternSearchMatches = `
+WITH matches AS MATERIALIZED (
+    SELECT rowid AS pattern_id,
+           bm25(pattern_search, 10.0, 4.0, 2.0, 1.0, 3.0) AS rank,
+           snippet(pattern_search, -1, ?, ?, '…', 12) AS snippet
+    FROM pattern_search WHERE pattern_search MATCH ?
+)`
+
+// patternUsedStitches selects the pattern stitches of p that at least one
+// stitch entry is worked in, as ps.
+const patternUsedStitches = `SELECT 1 FROM pattern_stitches ps
+    JOIN stitch_entries use_se ON use_se.pattern_stitch_id = ps.id
+    WHERE ps.pattern_id = p.id`
+
+// patternUsesCondition matches patterns with an entry worked in a stitch
+// whose abbreviation or name is the parameter, or whose name starts with it
+// as a word: "puff" matches "Puff Stitch". Its parameters are the stitch
+// twice, then the stitch escaped for LIKE followed by " %".
+const patternUsesCondition = `EXISTS (` + patternUsedStitches + `
+      AND (ps.abbreviation = ? COLLATE NOCASE OR ps.name = ? COLLATE NOCASE OR ps.name LIKE ? ESCAPE '\')
+)`
+
+// patternUsesLibraryStitchCondition matches patterns with an entry worked in
+// the library stitch whose ID is given twice as its parameters: copied from
+// it, or with the same abbreviation, as patterns shared from other users
+// refer to their owners' library.
+const patternUsesLibraryStitchCondition = `EXISTS (` + patternUsedStitches + `
+      AND (ps.library_stitch_id = ? OR ps.abbreviation = (SELECT s.abbreviation FROM stitches s WHERE s.id = ?))
+)`
+
+// patternKnownStitchesCondition matches patterns whose every worked stitch is
+// one the user whose ID is the parameter has marked as known, matched the
+// same way as patternUsesLibraryStitchCondition.
+const patternKnownStitchesCondition = `NOT EXISTS (` + patternUsedStitches + `
+      AND NOT EXISTS (
+          SELECT 1 FROM user_known_stitches k JOIN stitches s ON s.id = k.stitch_id
+          WHERE k.user_id = ? AND (s.id = ps.library_stitch_id OR s.abbreviation = ps.abbreviation)
+      )
+)`
+
+// indexPatternSearch rewrites the pattern's row in the search index from
+// what has been written for it in tx.
+func indexPatternSearch(ctx context.Context, tx *sql.Tx, patternID int64) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM pattern_search WHERE rowid = ?", patternID); err != nil {
+		return fmt.Errorf("clear search index: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, patternSearchIndexQuery, patternID); err != nil {
+		return fmt.Errorf("index pattern for search: %w", err)
+	}
+	return nil
+}
+
+// ftsMatchQuery turns free text into an FTS5 MATCH expression requiring every
+// word, each as a prefix so "pop hat" finds "Popcorn Hat". Words are quoted so
+// FTS5 operators and punctuation are taken literally. It returns "" when the
+// text has nothing to search for.
+func ftsMatchQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		if !strings.ContainsFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// escapeLike escapes LIKE wildcards in s for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -3,7 +3,11 @@ package sqlite_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 	"github.com/msomdec/stitch-map-2/internal/repository/sqlite"
@@ -18,6 +22,12 @@ func seedTestUser(t *testing.T, db *sqlite.DB) int64 {
 	return u.ID
 }
 
+// pageSummaries returns the summaries of a page, for tests that list fewer
+// patterns than fit on one.
+func pageSummaries(page domain.PatternSummaryPage, err error) ([]domain.PatternSummary, error) {
+	return page.Summaries, err
+}
+
 func makeTestPattern(userID int64) *domain.Pattern {
 	return &domain.Pattern{
 		UserID:      userID,
@@ -428,6 +438,64 @@ func TestPatternRepository_MultipleGroupsAndEntries(t *testing.T) {
 	}
 }
 
+func TestPatternRepository_RepeatBlocks(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+
+	p := makeTestPattern(userID)
+	p.InstructionGroups[0].StitchEntries = []domain.StitchEntry{
+		{SortOrder: 0, PatternStitchID: 0, Count: 1, RepeatCount: 1},
+		{SortOrder: 1, PatternStitchID: 0, Count: 2, RepeatCount: 1},
+		{SortOrder: 2, PatternStitchID: 0, Count: 1, RepeatCount: 1},
+	}
+	p.InstructionGroups[0].RepeatBlocks = []domain.RepeatBlock{
+		{StartEntry: 0, EndEntry: 2, RepeatCount: 3, Bracket: domain.BracketAsterisk},
+		{StartEntry: 1, EndEntry: 1, RepeatCount: 2, Bracket: domain.BracketSquare, IntoStitch: "in next sp"},
+	}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	found, err := repo.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+
+	blocks := found.InstructionGroups[0].RepeatBlocks
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 repeat blocks, got %d", len(blocks))
+	}
+	if blocks[0].ID == 0 || blocks[0].InstructionGroupID != found.InstructionGroups[0].ID {
+		t.Fatal("expected repeat block IDs to be set")
+	}
+	if blocks[0].StartEntry != 0 || blocks[0].EndEntry != 2 || blocks[0].RepeatCount != 3 || blocks[0].Bracket != domain.BracketAsterisk {
+		t.Fatalf("unexpected outer block: %+v", blocks[0])
+	}
+	if blocks[1].StartEntry != 1 || blocks[1].EndEntry != 1 || blocks[1].Bracket != domain.BracketSquare || blocks[1].IntoStitch != "in next sp" {
+		t.Fatalf("unexpected inner block: %+v", blocks[1])
+	}
+
+	// 3 × (1 + 2 × 2 + 1) = 18
+	summaries, err := pageSummaries(repo.ListSummaryByUser(ctx, userID, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("ListSummaryByUser: %v", err)
+	}
+	if summaries[0].StitchesWorked != 18 {
+		t.Fatalf("expected 18 stitches, got %d", summaries[0].StitchesWorked)
+	}
+
+	dup, err := repo.Duplicate(ctx, p.ID, userID)
+	if err != nil {
+		t.Fatalf("Duplicate: %v", err)
+	}
+	if len(dup.InstructionGroups[0].RepeatBlocks) != 2 {
+		t.Fatalf("expected duplicate to keep 2 repeat blocks, got %d", len(dup.InstructionGroups[0].RepeatBlocks))
+	}
+}
+
 func TestPatternRepository_GetNamesByIDs(t *testing.T) {
 	db := newTestDB(t)
 	repo := db.Patterns()
@@ -484,7 +552,7 @@ func TestPatternRepository_ListSummaryByUser(t *testing.T) {
 		t.Fatalf("Create: %v", err)
 	}
 
-	summaries, err := repo.ListSummaryByUser(ctx, userID)
+	summaries, err := pageSummaries(repo.ListSummaryByUser(ctx, userID, domain.PatternPage{}))
 	if err != nil {
 		t.Fatalf("ListSummaryByUser: %v", err)
 	}
@@ -499,8 +567,8 @@ func TestPatternRepository_ListSummaryByUser(t *testing.T) {
 		t.Fatalf("expected 1 group, got %d", s.GroupCount)
 	}
 	// makeTestPattern creates 1 group with 1 entry: sc 6 x1 repeat = 6 stitches
-	if s.StitchCount != 6 {
-		t.Fatalf("expected 6 stitches, got %d", s.StitchCount)
+	if s.StitchesWorked != 6 {
+		t.Fatalf("expected 6 stitches, got %d", s.StitchesWorked)
 	}
 }
 
@@ -528,7 +596,7 @@ func TestPatternRepository_SearchSummaryByUser(t *testing.T) {
 	}
 
 	// Search by query.
-	results, err := repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "cat"})
+	results, err := pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "cat"}, domain.PatternPage{}))
 	if err != nil {
 		t.Fatalf("SearchSummaryByUser query: %v", err)
 	}
@@ -537,7 +605,7 @@ func TestPatternRepository_SearchSummaryByUser(t *testing.T) {
 	}
 
 	// Filter by type.
-	results, err = repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Type: "row"})
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Type: "row"}, domain.PatternPage{}))
 	if err != nil {
 		t.Fatalf("SearchSummaryByUser type: %v", err)
 	}
@@ -546,7 +614,7 @@ func TestPatternRepository_SearchSummaryByUser(t *testing.T) {
 	}
 
 	// Filter by difficulty.
-	results, err = repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Difficulty: "Beginner"})
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Difficulty: "Beginner"}, domain.PatternPage{}))
 	if err != nil {
 		t.Fatalf("SearchSummaryByUser difficulty: %v", err)
 	}
@@ -555,7 +623,7 @@ func TestPatternRepository_SearchSummaryByUser(t *testing.T) {
 	}
 
 	// Sort by name.
-	results, err = repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Sort: "name"})
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Sort: "name"}, domain.PatternPage{}))
 	if err != nil {
 		t.Fatalf("SearchSummaryByUser sort: %v", err)
 	}
@@ -567,7 +635,7 @@ func TestPatternRepository_SearchSummaryByUser(t *testing.T) {
 	}
 
 	// No results.
-	results, err = repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "nonexistent"})
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "nonexistent"}, domain.PatternPage{}))
 	if err != nil {
 		t.Fatalf("SearchSummaryByUser no results: %v", err)
 	}
@@ -575,3 +643,627 @@ func TestPatternRepository_SearchSummaryByUser(t *testing.T) {
 		t.Fatalf("expected 0 results, got %d", len(results))
 	}
 }
+
+func TestPatternRepository_SearchSummaryByUser_FullText(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+
+	hat := makeTestPattern(userID)
+	hat.Name = "Popcorn Hat"
+	hat.PatternStitches = append(hat.PatternStitches, domain.PatternStitch{Abbreviation: "puff", Name: "Puff Stitch", Category: "specialty"})
+	hat.InstructionGroups[0].StitchEntries = append(hat.InstructionGroups[0].StitchEntries,
+		domain.StitchEntry{SortOrder: 1, PatternStitchID: 1, Count: 6, RepeatCount: 1})
+	if err := repo.Create(ctx, hat); err != nil {
+		t.Fatalf("Create hat: %v", err)
+	}
+
+	blanket := makeTestPattern(userID)
+	blanket.Name = "Granny Blanket"
+	blanket.Description = "Bobbles with a popcorn border"
+	blanket.InstructionGroups[0].Notes = "Block the finished hat-shaped motifs"
+	if err := repo.Create(ctx, blanket); err != nil {
+		t.Fatalf("Create blanket: %v", err)
+	}
+
+	// Every word must match, as a prefix, in any indexed column; a match in
+	// the name ranks above one in the description.
+	results, err := pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "popcorn"}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("search popcorn: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "Popcorn Hat" {
+		t.Fatalf("expected hat ranked first of 2, got %+v", results)
+	}
+	if want := domain.SnippetMatchStart + "popcorn" + domain.SnippetMatchEnd; !strings.Contains(results[1].Snippet, want) {
+		t.Errorf("blanket snippet = %q, want it to highlight popcorn", results[1].Snippet)
+	}
+
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "pop hat"}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("search pop hat: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results for 'pop hat' (name and notes), got %d", len(results))
+	}
+
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "puff"}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("search puff: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Popcorn Hat" {
+		t.Fatalf("expected stitch names to be searchable, got %+v", results)
+	}
+
+	// Operators and stray punctuation are taken literally.
+	for _, q := range []string{`popcorn OR "`, "-", "NOT*"} {
+		if _, err := pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: q}, domain.PatternPage{})); err != nil {
+			t.Errorf("search %q: %v", q, err)
+		}
+	}
+
+	// Stitch filters match an abbreviation, a name, or the start of a name.
+	for _, stitch := range []string{"puff", "PUFF", "Puff Stitch"} {
+		results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "popcorn", Uses: []string{stitch}}, domain.PatternPage{}))
+		if err != nil {
+			t.Fatalf("search uses %q: %v", stitch, err)
+		}
+		if len(results) != 1 || results[0].Name != "Popcorn Hat" {
+			t.Errorf("uses %q: expected only the hat, got %+v", stitch, results)
+		}
+	}
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Uses: []string{"pu%"}}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("search uses wildcard: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected LIKE wildcards to be literal, got %d results", len(results))
+	}
+
+	// Updates re-index the pattern and deletes remove it.
+	blanket.Name = "Ripple Throw"
+	blanket.Description = ""
+	if err := repo.Update(ctx, blanket); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	results, err = pageSummaries(repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Query: "ripple"}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("search ripple: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected updated name to be found, got %d results", len(results))
+	}
+	if err := repo.Delete(ctx, blanket.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	var indexed int
+	if err := db.SqlDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM pattern_search").Scan(&indexed); err != nil {
+		t.Fatalf("count index: %v", err)
+	}
+	if indexed != 1 {
+		t.Errorf("expected 1 indexed pattern after delete, got %d", indexed)
+	}
+}
+
+func TestPatternRepository_SearchSummaryByUser_StitchFilters(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+
+	library := map[string]*domain.Stitch{}
+	for _, abbr := range []string{"sc", "inc", "FPdc"} {
+		s := &domain.Stitch{Abbreviation: abbr, Name: abbr + " stitch", Category: "basic"}
+		if err := db.Stitches().Create(ctx, s); err != nil {
+			t.Fatalf("Create stitch %s: %v", abbr, err)
+		}
+		library[abbr] = s
+	}
+
+	// makePattern creates a pattern working one entry of each stitch. Stitches
+	// named with a "*" suffix aren't linked to the library, as in patterns
+	// shared from other users.
+	makePattern := func(name string, abbrs ...string) {
+		t.Helper()
+		p := makeTestPattern(userID)
+		p.Name = name
+		p.PatternStitches = nil
+		p.InstructionGroups[0].StitchEntries = nil
+		for i, abbr := range abbrs {
+			ps := domain.PatternStitch{Abbreviation: strings.TrimSuffix(abbr, "*"), Name: abbr, Category: "basic"}
+			if !strings.HasSuffix(abbr, "*") {
+				ps.LibraryStitchID = &library[abbr].ID
+			}
+			p.PatternStitches = append(p.PatternStitches, ps)
+			p.InstructionGroups[0].StitchEntries = append(p.InstructionGroups[0].StitchEntries,
+				domain.StitchEntry{SortOrder: i, PatternStitchID: int64(i), Count: 1, RepeatCount: 1})
+		}
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+	}
+	makePattern("Ball", "sc", "inc")
+	makePattern("Cabled Hat", "sc", "FPdc")
+	makePattern("Shared Cables", "FPdc*")
+
+	names := func(filter domain.PatternFilter) []string {
+		t.Helper()
+		filter.Sort = "name"
+		results, err := pageSummaries(repo.SearchSummaryByUser(ctx, userID, filter, domain.PatternPage{}))
+		if err != nil {
+			t.Fatalf("SearchSummaryByUser %+v: %v", filter, err)
+		}
+		var got []string
+		for _, s := range results {
+			got = append(got, s.Name)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.PatternFilter
+		want   []string
+	}{
+		{"include", domain.PatternFilter{Include: []int64{library["FPdc"].ID}}, []string{"Cabled Hat", "Shared Cables"}},
+		{"include all of", domain.PatternFilter{Include: []int64{library["sc"].ID, library["FPdc"].ID}}, []string{"Cabled Hat"}},
+		{"exclude", domain.PatternFilter{Exclude: []int64{library["FPdc"].ID}}, []string{"Ball"}},
+		{"known none", domain.PatternFilter{KnownOnly: true}, nil},
+	}
+	for _, tt := range tests {
+		if got := names(tt.filter); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, abbr := range []string{"sc", "inc"} {
+		if err := db.Stitches().SetKnown(ctx, userID, library[abbr].ID, true); err != nil {
+			t.Fatalf("SetKnown %s: %v", abbr, err)
+		}
+	}
+	if got := names(domain.PatternFilter{KnownOnly: true}); !slices.Equal(got, []string{"Ball"}) {
+		t.Errorf("known sc and inc: got %q, want [Ball]", got)
+	}
+
+	// Stitches not linked to the library are known by their abbreviation.
+	if err := db.Stitches().SetKnown(ctx, userID, library["FPdc"].ID, true); err != nil {
+		t.Fatalf("SetKnown FPdc: %v", err)
+	}
+	if got := names(domain.PatternFilter{KnownOnly: true}); len(got) != 3 {
+		t.Errorf("known all: got %q, want every pattern", got)
+	}
+}
+
+func TestPatternRepository_SummaryPagination(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+
+	// Duplicate names, stitch counts and timestamps make sure pages split
+	// ties by ID without skipping or repeating patterns.
+	for i, name := range []string{"Bear", "Amigurumi Bear", "Bear", "Cowl", "Bear", "Dish Cloth", "Bear"} {
+		p := makeTestPattern(userID)
+		p.Name = name
+		p.Description = "bear"
+		p.InstructionGroups[0].StitchEntries[0].Count = 6 * (i%3 + 1)
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+	}
+	if _, err := db.SqlDB.ExecContext(ctx, "UPDATE patterns SET updated_at = created_at, created_at = (SELECT MIN(created_at) FROM patterns) WHERE id % 2 = 0"); err != nil {
+		t.Fatalf("tie timestamps: %v", err)
+	}
+
+	for _, filter := range []domain.PatternFilter{
+		{Sort: "updated"},
+		{Sort: "name"},
+		{Sort: "created"},
+		{Sort: "stitches"},
+		{Query: "bear"},
+	} {
+		all, err := repo.SearchSummaryByUser(ctx, userID, filter, domain.PatternPage{Limit: 100})
+		if err != nil {
+			t.Fatalf("%+v: all: %v", filter, err)
+		}
+		if all.Next != "" {
+			t.Errorf("%+v: a page holding every pattern should have no next cursor", filter)
+		}
+
+		var paged []int64
+		page := domain.PatternPage{Limit: 3}
+		for range 10 {
+			result, err := repo.SearchSummaryByUser(ctx, userID, filter, page)
+			if err != nil {
+				t.Fatalf("%+v: page after %q: %v", filter, page.After, err)
+			}
+			if len(result.Summaries) > 3 {
+				t.Fatalf("%+v: page of %d, want at most 3", filter, len(result.Summaries))
+			}
+			for _, s := range result.Summaries {
+				paged = append(paged, s.ID)
+			}
+			if result.Next == "" {
+				break
+			}
+			page.After = result.Next
+		}
+
+		var want []int64
+		for _, s := range all.Summaries {
+			want = append(want, s.ID)
+		}
+		if len(want) == 0 || !slices.Equal(paged, want) {
+			t.Errorf("%+v: paged IDs %v, want %v", filter, paged, want)
+		}
+	}
+
+	mine, err := repo.ListSummaryByUser(ctx, userID, domain.PatternPage{Limit: 4})
+	if err != nil {
+		t.Fatalf("ListSummaryByUser: %v", err)
+	}
+	if len(mine.Summaries) != 4 || mine.Next == "" {
+		t.Fatalf("expected a first page of 4 with a next cursor, got %d %q", len(mine.Summaries), mine.Next)
+	}
+	rest, err := repo.ListSummaryByUser(ctx, userID, domain.PatternPage{Limit: 4, After: mine.Next})
+	if err != nil {
+		t.Fatalf("ListSummaryByUser next: %v", err)
+	}
+	if len(rest.Summaries) != 3 || rest.Next != "" {
+		t.Fatalf("expected a last page of 3, got %d %q", len(rest.Summaries), rest.Next)
+	}
+
+	// Cursors are only valid for the sort they came from.
+	for _, after := range []string{"not a cursor", mine.Next} {
+		_, err := repo.SearchSummaryByUser(ctx, userID, domain.PatternFilter{Sort: "name"}, domain.PatternPage{After: after})
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("cursor %q: expected ErrInvalidInput, got %v", after, err)
+		}
+	}
+}
+
+func TestPatternRepository_SearchPublishedSummaries(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	ownerID := seedTestUser(t, db)
+	viewer := &domain.User{Email: "viewer@example.com", DisplayName: "Viewer", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, viewer); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	byName := map[string]*domain.Pattern{}
+	for _, name := range []string{"Granny Square", "Beanie", "Private Draft"} {
+		p := makeTestPattern(ownerID)
+		p.Name = name
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+		byName[name] = p
+	}
+	for _, name := range []string{"Granny Square", "Beanie"} {
+		share := &domain.PatternShare{PatternID: byName[name].ID, Token: "public-" + name, ShareType: domain.ShareTypePublic}
+		if err := db.Shares().Create(ctx, share); err != nil {
+			t.Fatalf("Create share: %v", err)
+		}
+	}
+	// Other kinds of share don't publish a pattern.
+	if err := db.Shares().Create(ctx, &domain.PatternShare{PatternID: byName["Private Draft"].ID, Token: "global", ShareType: domain.ShareTypeGlobal}); err != nil {
+		t.Fatalf("Create share: %v", err)
+	}
+	if err := db.Shares().Create(ctx, &domain.PatternShare{PatternID: byName["Beanie"].ID, Token: "public-again", ShareType: domain.ShareTypePublic}); err == nil {
+		t.Fatal("expected a second public share for the same pattern to be rejected")
+	}
+
+	tag := &domain.Tag{UserID: ownerID, Name: "Winter"}
+	if err := db.Tags().Create(ctx, tag); err != nil {
+		t.Fatalf("Create tag: %v", err)
+	}
+	if err := db.Tags().AddToPatterns(ctx, tag.ID, []int64{byName["Beanie"].ID}); err != nil {
+		t.Fatalf("AddToPatterns: %v", err)
+	}
+
+	all, err := pageSummaries(repo.SearchPublishedSummaries(ctx, viewer.ID, domain.PatternFilter{Sort: "name"}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("SearchPublishedSummaries: %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "Beanie" || all[1].Name != "Granny Square" {
+		t.Fatalf("expected Beanie, Granny Square, got %+v", all)
+	}
+	if len(all[0].Tags) != 1 || all[0].Tags[0].Name != "Winter" {
+		t.Errorf("expected the owner's tags on the summary, got %+v", all[0].Tags)
+	}
+
+	tagged, err := pageSummaries(repo.SearchPublishedSummaries(ctx, viewer.ID, domain.PatternFilter{TagNames: []string{"winter"}}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("SearchPublishedSummaries by tag: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].Name != "Beanie" {
+		t.Fatalf("expected only Beanie tagged winter, got %+v", tagged)
+	}
+
+	found, err := pageSummaries(repo.SearchPublishedSummaries(ctx, viewer.ID, domain.PatternFilter{Query: "granny"}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("SearchPublishedSummaries by query: %v", err)
+	}
+	if len(found) != 1 || found[0].Name != "Granny Square" {
+		t.Fatalf("expected only Granny Square matching granny, got %+v", found)
+	}
+}
+
+func TestPatternRepository_SharedSource(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	ownerID := seedTestUser(t, db)
+	recipient := &domain.User{Email: "recipient@example.com", DisplayName: "Recipient", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, recipient); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	source := makeTestPattern(ownerID)
+	if err := repo.Create(ctx, source); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	share := &domain.PatternShare{PatternID: source.ID, Token: "source", ShareType: domain.ShareTypeGlobal, Permission: domain.SharePermissionSaveLocked}
+	if err := db.Shares().Create(ctx, share); err != nil {
+		t.Fatalf("Create share: %v", err)
+	}
+	copied, err := repo.DuplicateAsShared(ctx, share, recipient.ID, ownerID, "Owner", false)
+	if err != nil {
+		t.Fatalf("DuplicateAsShared: %v", err)
+	}
+	got, err := repo.GetByID(ctx, copied.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.SharedFromPatternID == nil || *got.SharedFromPatternID != source.ID {
+		t.Fatalf("SharedFromPatternID = %v, want %d", got.SharedFromPatternID, source.ID)
+	}
+	if got.SharedFromShareID == nil || *got.SharedFromShareID != share.ID {
+		t.Fatalf("SharedFromShareID = %v, want %d", got.SharedFromShareID, share.ID)
+	}
+	if got.SharedSourceUpdatedAt == nil || !got.SharedSourceUpdatedAt.Equal(source.UpdatedAt) {
+		t.Fatalf("SharedSourceUpdatedAt = %v, want %v", got.SharedSourceUpdatedAt, source.UpdatedAt)
+	}
+
+	shared, err := pageSummaries(repo.ListSummarySharedWithUser(ctx, recipient.ID, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("ListSummarySharedWithUser: %v", err)
+	}
+	if len(shared) != 1 || shared[0].UpdateAvailable {
+		t.Fatalf("expected a copy without an update, got %+v", shared)
+	}
+
+	source.Name = "Fixed Pattern"
+	if err := repo.Update(ctx, source); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	shared, err = pageSummaries(repo.ListSummarySharedWithUser(ctx, recipient.ID, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("ListSummarySharedWithUser: %v", err)
+	}
+	if len(shared) != 1 || !shared[0].UpdateAvailable {
+		t.Fatalf("expected an update after the source changed, got %+v", shared)
+	}
+
+	// Expired shares don't offer updates.
+	past := time.Now().Add(-time.Hour)
+	if err := db.Shares().UpdateLimits(ctx, share.ID, &past, 0); err != nil {
+		t.Fatalf("UpdateLimits: %v", err)
+	}
+	shared, err = pageSummaries(repo.ListSummarySharedWithUser(ctx, recipient.ID, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("ListSummarySharedWithUser: %v", err)
+	}
+	if shared[0].UpdateAvailable {
+		t.Fatal("expected no update through an expired share")
+	}
+	if err := db.Shares().UpdateLimits(ctx, share.ID, nil, 0); err != nil {
+		t.Fatalf("UpdateLimits: %v", err)
+	}
+
+	if err := repo.UpdateFromSource(ctx, got, source); err != nil {
+		t.Fatalf("UpdateFromSource: %v", err)
+	}
+	shared, err = pageSummaries(repo.ListSummarySharedWithUser(ctx, recipient.ID, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("ListSummarySharedWithUser: %v", err)
+	}
+	if shared[0].UpdateAvailable {
+		t.Fatal("expected no update once the copy has caught up")
+	}
+
+	// Deleting the source leaves the copy without one.
+	if err := repo.Delete(ctx, source.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	got, err = repo.GetByID(ctx, copied.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.SharedFromPatternID != nil {
+		t.Errorf("expected no source after deleting it, got %d", *got.SharedFromPatternID)
+	}
+}
+
+func TestPatternRepository_DuplicateAsSharedSaveLimit(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	ownerID := seedTestUser(t, db)
+	recipient := &domain.User{Email: "recipient@example.com", DisplayName: "Recipient", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, recipient); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	source := makeTestPattern(ownerID)
+	if err := repo.Create(ctx, source); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	share := &domain.PatternShare{PatternID: source.ID, Token: "limited", ShareType: domain.ShareTypeGlobal, Permission: domain.SharePermissionSaveLocked, MaxSaves: 1}
+	if err := db.Shares().Create(ctx, share); err != nil {
+		t.Fatalf("Create share: %v", err)
+	}
+
+	if _, err := repo.DuplicateAsShared(ctx, share, recipient.ID, ownerID, "Owner", false); err != nil {
+		t.Fatalf("DuplicateAsShared: %v", err)
+	}
+	if _, err := repo.DuplicateAsShared(ctx, share, recipient.ID, ownerID, "Owner", false); !errors.Is(err, domain.ErrShareLimitReached) {
+		t.Fatalf("expected ErrShareLimitReached past the limit, got %v", err)
+	}
+
+	// The refused save leaves neither a copy nor an event behind.
+	copies, err := repo.ListSharedWithUser(ctx, recipient.ID)
+	if err != nil {
+		t.Fatalf("ListSharedWithUser: %v", err)
+	}
+	if len(copies) != 1 {
+		t.Fatalf("expected one copy, got %d", len(copies))
+	}
+	saves, err := db.Shares().CountEvents(ctx, share.ID, domain.ShareEventSave)
+	if err != nil {
+		t.Fatalf("CountEvents: %v", err)
+	}
+	if saves != 1 {
+		t.Fatalf("expected one recorded save, got %d", saves)
+	}
+}
+
+func TestPatternRepository_UpdateFromSourceImages(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Patterns()
+	ctx := context.Background()
+
+	ownerID := seedTestUser(t, db)
+	recipient := &domain.User{Email: "recipient@example.com", DisplayName: "Recipient", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, recipient); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	// group refers to the pattern's stitch by index until it is saved, and
+	// by ID after.
+	group := func(sortOrder int, label string, stitchID int64) domain.InstructionGroup {
+		return domain.InstructionGroup{SortOrder: sortOrder, Label: label, RepeatCount: 1,
+			StitchEntries: []domain.StitchEntry{{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1}}}
+	}
+	addImage := func(p *domain.Pattern, label, name string) {
+		t.Helper()
+		for _, g := range p.InstructionGroups {
+			if g.Label != label {
+				continue
+			}
+			key := fmt.Sprintf("key-%d-%s", p.ID, name)
+			if err := db.FileStore().Save(ctx, key, []byte(name)); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			img := &domain.PatternImage{InstructionGroupID: g.ID, Filename: name, ContentType: "image/png", Size: int64(len(name)), StorageKey: key}
+			if err := db.PatternImages().Create(ctx, img); err != nil {
+				t.Fatalf("Create image: %v", err)
+			}
+			return
+		}
+		t.Fatalf("no group %q", label)
+	}
+	// imagesByLabel returns the filenames and storage keys of a pattern's
+	// images, by group label.
+	imagesByLabel := func(id int64) (map[string][]string, []string) {
+		t.Helper()
+		p, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		names := make(map[string][]string)
+		var keys []string
+		for _, g := range p.InstructionGroups {
+			imgs, err := db.PatternImages().ListByGroup(ctx, g.ID)
+			if err != nil {
+				t.Fatalf("ListByGroup: %v", err)
+			}
+			for _, img := range imgs {
+				names[g.Label] = append(names[g.Label], img.Filename)
+				keys = append(keys, img.StorageKey)
+			}
+		}
+		return names, keys
+	}
+
+	source := makeTestPattern(ownerID)
+	source.InstructionGroups = []domain.InstructionGroup{group(0, "Round A", 0), group(1, "Round B", 0), group(2, "Round C", 0)}
+	if err := repo.Create(ctx, source); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	addImage(source, "Round A", "a.png")
+	addImage(source, "Round C", "c.png")
+
+	share := &domain.PatternShare{PatternID: source.ID, Token: "images", ShareType: domain.ShareTypeGlobal, Permission: domain.SharePermissionSaveLocked}
+	if err := db.Shares().Create(ctx, share); err != nil {
+		t.Fatalf("Create share: %v", err)
+	}
+	copied, err := repo.DuplicateAsShared(ctx, share, recipient.ID, ownerID, "Owner", false)
+	if err != nil {
+		t.Fatalf("DuplicateAsShared: %v", err)
+	}
+	names, oldKeys := imagesByLabel(copied.ID)
+	if !slices.Equal(names["Round A"], []string{"a.png"}) || !slices.Equal(names["Round C"], []string{"c.png"}) || len(names["Round B"]) != 0 {
+		t.Fatalf("copy images = %v, want a.png on Round A and c.png on Round C", names)
+	}
+
+	// The owner drops Round A and moves Round C first, with a new image.
+	stitchID := source.PatternStitches[0].ID
+	source.InstructionGroups = []domain.InstructionGroup{group(0, "Round C", stitchID), group(1, "Round B", stitchID)}
+	if err := repo.Update(ctx, source); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	addImage(source, "Round B", "b.png")
+	want, _ := imagesByLabel(source.ID)
+
+	updated, err := repo.GetByID(ctx, copied.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	fresh, err := repo.GetByID(ctx, source.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	updated.Name = fresh.Name
+	updated.PatternStitches = fresh.PatternStitches
+	updated.InstructionGroups = fresh.InstructionGroups
+	if err := repo.UpdateFromSource(ctx, updated, fresh); err != nil {
+		t.Fatalf("UpdateFromSource: %v", err)
+	}
+
+	got, newKeys := imagesByLabel(copied.ID)
+	if len(got) != len(want) {
+		t.Fatalf("copy images = %v, want the source's %v", got, want)
+	}
+	for label, names := range want {
+		if !slices.Equal(got[label], names) {
+			t.Fatalf("copy images = %v, want the source's %v", got, want)
+		}
+	}
+	if !slices.Equal(got["Round B"], []string{"b.png"}) {
+		t.Fatalf("expected the source's new image on Round B, got %v", got)
+	}
+	// Blobs of the copy's old images that weren't copied again are deleted.
+	deleted := 0
+	for _, key := range oldKeys {
+		if slices.Contains(newKeys, key) {
+			continue
+		}
+		if _, err := db.FileStore().Get(ctx, key); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected the old blob %q to be deleted, got %v", key, err)
+		}
+		deleted++
+	}
+	if deleted == 0 {
+		t.Fatal("expected some of the copy's old images to be dropped")
+	}
+}
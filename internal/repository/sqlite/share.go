@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 )
@@ -15,9 +16,9 @@ type shareRepo struct {
 
 func (r *shareRepo) Create(ctx context.Context, share *domain.PatternShare) error {
 	result, err := r.db.ExecContext(ctx,
-		`INSERT INTO pattern_shares (pattern_id, token, share_type, recipient_email)
-		 VALUES (?, ?, ?, ?)`,
-		share.PatternID, share.Token, share.ShareType, share.RecipientEmail,
+		`INSERT INTO pattern_shares (pattern_id, token, share_type, recipient_email, permission, expires_at, max_saves)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		share.PatternID, share.Token, share.ShareType, share.RecipientEmail, share.Permission, share.ExpiresAt, nullableLimit(share.MaxSaves),
 	)
 	if err != nil {
 		return fmt.Errorf("insert pattern share: %w", err)
@@ -34,9 +35,9 @@ func (r *shareRepo) Create(ctx context.Context, share *domain.PatternShare) erro
 func (r *shareRepo) GetByID(ctx context.Context, id int64) (*domain.PatternShare, error) {
 	s := &domain.PatternShare{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, pattern_id, token, share_type, recipient_email, created_at
+		`SELECT id, pattern_id, token, share_type, recipient_email, permission, expires_at, COALESCE(max_saves, 0), created_at
 		 FROM pattern_shares WHERE id = ?`, id,
-	).Scan(&s.ID, &s.PatternID, &s.Token, &s.ShareType, &s.RecipientEmail, &s.CreatedAt)
+	).Scan(&s.ID, &s.PatternID, &s.Token, &s.ShareType, &s.RecipientEmail, &s.Permission, &s.ExpiresAt, &s.MaxSaves, &s.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
@@ -49,9 +50,9 @@ func (r *shareRepo) GetByID(ctx context.Context, id int64) (*domain.PatternShare
 func (r *shareRepo) GetByToken(ctx context.Context, token string) (*domain.PatternShare, error) {
 	s := &domain.PatternShare{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, pattern_id, token, share_type, recipient_email, created_at
+		`SELECT id, pattern_id, token, share_type, recipient_email, permission, expires_at, COALESCE(max_saves, 0), created_at
 		 FROM pattern_shares WHERE token = ?`, token,
-	).Scan(&s.ID, &s.PatternID, &s.Token, &s.ShareType, &s.RecipientEmail, &s.CreatedAt)
+	).Scan(&s.ID, &s.PatternID, &s.Token, &s.ShareType, &s.RecipientEmail, &s.Permission, &s.ExpiresAt, &s.MaxSaves, &s.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
@@ -63,7 +64,7 @@ func (r *shareRepo) GetByToken(ctx context.Context, token string) (*domain.Patte
 
 func (r *shareRepo) ListByPattern(ctx context.Context, patternID int64) ([]domain.PatternShare, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, pattern_id, token, share_type, recipient_email, created_at
+		`SELECT id, pattern_id, token, share_type, recipient_email, permission, expires_at, COALESCE(max_saves, 0), created_at
 		 FROM pattern_shares WHERE pattern_id = ? ORDER BY created_at DESC`, patternID)
 	if err != nil {
 		return nil, fmt.Errorf("list shares: %w", err)
@@ -73,7 +74,7 @@ func (r *shareRepo) ListByPattern(ctx context.Context, patternID int64) ([]domai
 	var shares []domain.PatternShare
 	for rows.Next() {
 		var s domain.PatternShare
-		if err := rows.Scan(&s.ID, &s.PatternID, &s.Token, &s.ShareType, &s.RecipientEmail, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.PatternID, &s.Token, &s.ShareType, &s.RecipientEmail, &s.Permission, &s.ExpiresAt, &s.MaxSaves, &s.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan share: %w", err)
 		}
 		shares = append(shares, s)
@@ -97,10 +98,11 @@ func (r *shareRepo) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (r *shareRepo) DeleteAllByPattern(ctx context.Context, patternID int64) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM pattern_shares WHERE pattern_id = ?", patternID)
+func (r *shareRepo) DeleteNonPublicByPattern(ctx context.Context, patternID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM pattern_shares WHERE pattern_id = ? AND share_type != ?",
+		patternID, string(domain.ShareTypePublic))
 	if err != nil {
-		return fmt.Errorf("delete all shares: %w", err)
+		return fmt.Errorf("delete shares: %w", err)
 	}
 	return nil
 }
@@ -138,3 +140,84 @@ func (r *shareRepo) HasSharesByPatternIDs(ctx context.Context, patternIDs []int6
 	}
 	return result, rows.Err()
 }
+
+func (r *shareRepo) UpdateLimits(ctx context.Context, id int64, expiresAt *time.Time, maxSaves int) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE pattern_shares SET expires_at = ?, max_saves = ? WHERE id = ?",
+		expiresAt, nullableLimit(maxSaves), id)
+	if err != nil {
+		return fmt.Errorf("update share limits: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *shareRepo) RecordEvent(ctx context.Context, event *domain.ShareEvent) error {
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO share_events (share_id, event_type, user_id, created_at) VALUES (?, ?, ?, ?)",
+		event.ShareID, event.Type, event.UserID, now)
+	if err != nil {
+		return fmt.Errorf("insert share event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get share event id: %w", err)
+	}
+	event.ID = id
+	event.CreatedAt = now
+	return nil
+}
+
+func (r *shareRepo) CountEvents(ctx context.Context, shareID int64, eventType domain.ShareEventType) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM share_events WHERE share_id = ? AND event_type = ?",
+		shareID, eventType).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count share events: %w", err)
+	}
+	return count, nil
+}
+
+// ListEventsByPattern returns the events of every share of a pattern, newest
+// first, keyed by share ID.
+func (r *shareRepo) ListEventsByPattern(ctx context.Context, patternID int64) (map[int64][]domain.ShareEvent, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT e.id, e.share_id, e.event_type, e.user_id, COALESCE(u.display_name, ''), e.created_at
+		 FROM share_events e
+		 JOIN pattern_shares s ON s.id = e.share_id
+		 LEFT JOIN users u ON u.id = e.user_id
+		 WHERE s.pattern_id = ?
+		 ORDER BY e.created_at DESC, e.id DESC`, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("list share events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make(map[int64][]domain.ShareEvent)
+	for rows.Next() {
+		var e domain.ShareEvent
+		if err := rows.Scan(&e.ID, &e.ShareID, &e.Type, &e.UserID, &e.UserName, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan share event: %w", err)
+		}
+		events[e.ShareID] = append(events[e.ShareID], e)
+	}
+	return events, rows.Err()
+}
+
+// nullableLimit stores a limit of 0, meaning none, as NULL.
+func nullableLimit(n int) any {
+	if n <= 0 {
+		return nil
+	}
+	return n
+}
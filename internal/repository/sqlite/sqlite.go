@@ -20,14 +20,17 @@ type DB struct {
 
 // Compile-time interface compliance checks.
 var (
-	_ domain.Database               = (*DB)(nil)
-	_ domain.UserRepository         = (*userRepo)(nil)
-	_ domain.StitchRepository       = (*stitchRepo)(nil)
-	_ domain.PatternRepository      = (*patternRepo)(nil)
-	_ domain.WorkSessionRepository  = (*workSessionRepo)(nil)
-	_ domain.PatternImageRepository = (*patternImageRepo)(nil)
-	_ domain.FileStore              = (*fileStore)(nil)
-	_ domain.PatternShareRepository = (*shareRepo)(nil)
+	_ domain.Database                 = (*DB)(nil)
+	_ domain.UserRepository           = (*userRepo)(nil)
+	_ domain.StitchRepository         = (*stitchRepo)(nil)
+	_ domain.PatternRepository        = (*patternRepo)(nil)
+	_ domain.WorkSessionRepository    = (*workSessionRepo)(nil)
+	_ domain.PatternImageRepository   = (*patternImageRepo)(nil)
+	_ domain.FileStore                = (*fileStore)(nil)
+	_ domain.PatternShareRepository   = (*shareRepo)(nil)
+	_ domain.PatternVersionRepository = (*versionRepo)(nil)
+	_ domain.TagRepository            = (*tagRepo)(nil)
+	_ domain.CollectionRepository     = (*collectionRepo)(nil)
 )
 
 // Users returns a domain.UserRepository backed by this database.
@@ -51,6 +54,15 @@ func (db *DB) FileStore() domain.FileStore { return &fileStore{db: db.SqlDB} }
 // Shares returns a domain.PatternShareRepository backed by this database.
 func (db *DB) Shares() domain.PatternShareRepository { return &shareRepo{db: db.SqlDB} }
 
+// Versions returns a domain.PatternVersionRepository backed by this database.
+func (db *DB) Versions() domain.PatternVersionRepository { return &versionRepo{db: db.SqlDB} }
+
+// Tags returns a domain.TagRepository backed by this database.
+func (db *DB) Tags() domain.TagRepository { return &tagRepo{db: db.SqlDB} }
+
+// Collections returns a domain.CollectionRepository backed by this database.
+func (db *DB) Collections() domain.CollectionRepository { return &collectionRepo{db: db.SqlDB} }
+
 // New opens a SQLite database at the given path and configures it for use.
 // It enables WAL mode and foreign keys.
 func New(dbPath string) (*DB, error) {
@@ -17,7 +17,7 @@ type stitchRepo struct {
 
 func (r *stitchRepo) ListPredefined(ctx context.Context) ([]domain.Stitch, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, abbreviation, name, description, category, is_custom, user_id, created_at
+		`SELECT id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, is_custom, user_id, created_at
 		 FROM stitches WHERE is_custom = FALSE ORDER BY category, abbreviation`)
 	if err != nil {
 		return nil, fmt.Errorf("list predefined stitches: %w", err)
@@ -28,7 +28,7 @@ func (r *stitchRepo) ListPredefined(ctx context.Context) ([]domain.Stitch, error
 
 func (r *stitchRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Stitch, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, abbreviation, name, description, category, is_custom, user_id, created_at
+		`SELECT id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, is_custom, user_id, created_at
 		 FROM stitches WHERE is_custom = TRUE AND user_id = ? ORDER BY abbreviation`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("list user stitches: %w", err)
@@ -40,9 +40,9 @@ func (r *stitchRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Sti
 func (r *stitchRepo) GetByID(ctx context.Context, id int64) (*domain.Stitch, error) {
 	s := &domain.Stitch{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, abbreviation, name, description, category, is_custom, user_id, created_at
+		`SELECT id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, is_custom, user_id, created_at
 		 FROM stitches WHERE id = ?`, id,
-	).Scan(&s.ID, &s.Abbreviation, &s.Name, &s.Description, &s.Category, &s.IsCustom, &s.UserID, &s.CreatedAt)
+	).Scan(&s.ID, &s.Abbreviation, &s.Name, &s.Description, &s.Category, &s.Consumes, &s.Produces, &s.UKAbbreviation, &s.IsCustom, &s.UserID, &s.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -56,16 +56,16 @@ func (r *stitchRepo) GetByAbbreviation(ctx context.Context, abbreviation string,
 	var row *sql.Row
 	if userID == nil {
 		row = r.db.QueryRowContext(ctx,
-			`SELECT id, abbreviation, name, description, category, is_custom, user_id, created_at
+			`SELECT id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, is_custom, user_id, created_at
 			 FROM stitches WHERE abbreviation = ? AND user_id IS NULL`, abbreviation)
 	} else {
 		row = r.db.QueryRowContext(ctx,
-			`SELECT id, abbreviation, name, description, category, is_custom, user_id, created_at
+			`SELECT id, abbreviation, name, description, category, consumes, produces, uk_abbreviation, is_custom, user_id, created_at
 			 FROM stitches WHERE abbreviation = ? AND user_id = ?`, abbreviation, *userID)
 	}
 
 	s := &domain.Stitch{}
-	err := row.Scan(&s.ID, &s.Abbreviation, &s.Name, &s.Description, &s.Category, &s.IsCustom, &s.UserID, &s.CreatedAt)
+	err := row.Scan(&s.ID, &s.Abbreviation, &s.Name, &s.Description, &s.Category, &s.Consumes, &s.Produces, &s.UKAbbreviation, &s.IsCustom, &s.UserID, &s.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -78,10 +78,10 @@ func (r *stitchRepo) GetByAbbreviation(ctx context.Context, abbreviation string,
 func (r *stitchRepo) Create(ctx context.Context, stitch *domain.Stitch) error {
 	now := time.Now().UTC()
 	result, err := r.db.ExecContext(ctx,
-		`INSERT INTO stitches (abbreviation, name, description, category, is_custom, user_id, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO stitches (abbreviation, name, description, category, consumes, produces, uk_abbreviation, is_custom, user_id, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		stitch.Abbreviation, stitch.Name, stitch.Description, stitch.Category,
-		stitch.IsCustom, stitch.UserID, now,
+		stitch.Consumes, stitch.Produces, stitch.UKAbbreviation, stitch.IsCustom, stitch.UserID, now,
 	)
 	if err != nil {
 		if isUniqueConstraintError(err) {
@@ -102,9 +102,10 @@ func (r *stitchRepo) Create(ctx context.Context, stitch *domain.Stitch) error {
 
 func (r *stitchRepo) Update(ctx context.Context, stitch *domain.Stitch) error {
 	result, err := r.db.ExecContext(ctx,
-		`UPDATE stitches SET abbreviation = ?, name = ?, description = ?, category = ?
+		`UPDATE stitches SET abbreviation = ?, name = ?, description = ?, category = ?, consumes = ?, produces = ?, uk_abbreviation = ?
 		 WHERE id = ?`,
-		stitch.Abbreviation, stitch.Name, stitch.Description, stitch.Category, stitch.ID,
+		stitch.Abbreviation, stitch.Name, stitch.Description, stitch.Category,
+		stitch.Consumes, stitch.Produces, stitch.UKAbbreviation, stitch.ID,
 	)
 	if err != nil {
 		if isUniqueConstraintError(err) {
@@ -139,11 +140,45 @@ func (r *stitchRepo) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (r *stitchRepo) ListKnownIDs(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT stitch_id FROM user_known_stitches WHERE user_id = ? ORDER BY stitch_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list known stitches: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan known stitch: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *stitchRepo) SetKnown(ctx context.Context, userID, stitchID int64, known bool) error {
+	if !known {
+		if _, err := r.db.ExecContext(ctx,
+			`DELETE FROM user_known_stitches WHERE user_id = ? AND stitch_id = ?`, userID, stitchID); err != nil {
+			return fmt.Errorf("unmark known stitch: %w", err)
+		}
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO user_known_stitches (user_id, stitch_id) VALUES (?, ?)`, userID, stitchID); err != nil {
+		return fmt.Errorf("mark known stitch: %w", err)
+	}
+	return nil
+}
+
 func scanStitches(rows *sql.Rows) ([]domain.Stitch, error) {
 	var stitches []domain.Stitch
 	for rows.Next() {
 		var s domain.Stitch
-		if err := rows.Scan(&s.ID, &s.Abbreviation, &s.Name, &s.Description, &s.Category, &s.IsCustom, &s.UserID, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Abbreviation, &s.Name, &s.Description, &s.Category, &s.Consumes, &s.Produces, &s.UKAbbreviation, &s.IsCustom, &s.UserID, &s.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan stitch: %w", err)
 		}
 		stitches = append(stitches, s)
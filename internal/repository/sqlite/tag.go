@@ -0,0 +1,215 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+type tagRepo struct {
+	db *sql.DB
+}
+
+func (r *tagRepo) Create(ctx context.Context, tag *domain.Tag) error {
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO tags (user_id, name) VALUES (?, ?)`,
+		tag.UserID, tag.Name,
+	)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return domain.ErrDuplicateTag
+		}
+		return fmt.Errorf("insert tag: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get tag id: %w", err)
+	}
+	tag.ID = id
+	return nil
+}
+
+func (r *tagRepo) GetByID(ctx context.Context, id int64) (*domain.Tag, error) {
+	t := &domain.Tag{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, name, created_at FROM tags WHERE id = ?`, id,
+	).Scan(&t.ID, &t.UserID, &t.Name, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get tag by id: %w", err)
+	}
+	return t, nil
+}
+
+// GetByName finds one of the user's tags by name, ignoring case.
+func (r *tagRepo) GetByName(ctx context.Context, userID int64, name string) (*domain.Tag, error) {
+	t := &domain.Tag{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, name, created_at FROM tags WHERE user_id = ? AND name = ?`, userID, name,
+	).Scan(&t.ID, &t.UserID, &t.Name, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get tag by name: %w", err)
+	}
+	return t, nil
+}
+
+func (r *tagRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Tag, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT t.id, t.user_id, t.name, t.created_at, COUNT(pt.pattern_id)
+		 FROM tags t LEFT JOIN pattern_tags pt ON pt.tag_id = t.id
+		 WHERE t.user_id = ?
+		 GROUP BY t.id ORDER BY t.name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []domain.Tag
+	for rows.Next() {
+		var t domain.Tag
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.CreatedAt, &t.PatternCount); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *tagRepo) ListByPatternIDs(ctx context.Context, patternIDs []int64) (map[int64][]domain.Tag, error) {
+	return loadPatternTags(ctx, r.db, patternIDs)
+}
+
+func (r *tagRepo) Rename(ctx context.Context, id int64, name string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE tags SET name = ? WHERE id = ?`, name, id)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return domain.ErrDuplicateTag
+		}
+		return fmt.Errorf("rename tag: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *tagRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AddToPatterns tags each pattern, skipping patterns that already have the tag.
+func (r *tagRepo) AddToPatterns(ctx context.Context, tagID int64, patternIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range patternIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO pattern_tags (pattern_id, tag_id) VALUES (?, ?)`, id, tagID,
+		); err != nil {
+			return fmt.Errorf("tag pattern: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *tagRepo) RemoveFromPatterns(ctx context.Context, tagID int64, patternIDs []int64) error {
+	if len(patternIDs) == 0 {
+		return nil
+	}
+	placeholders := make([]string, len(patternIDs))
+	args := []any{tagID}
+	for i, id := range patternIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	query := `DELETE FROM pattern_tags WHERE tag_id = ? AND pattern_id IN (` + strings.Join(placeholders, ",") + `)`
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("untag patterns: %w", err)
+	}
+	return nil
+}
+
+// loadPatternTags returns the tags of each of the patterns that has any,
+// sorted by name.
+func loadPatternTags(ctx context.Context, db *sql.DB, patternIDs []int64) (map[int64][]domain.Tag, error) {
+	result := make(map[int64][]domain.Tag)
+	if len(patternIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(patternIDs))
+	args := make([]any, len(patternIDs))
+	for i, id := range patternIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	query := `SELECT pt.pattern_id, t.id, t.user_id, t.name, t.created_at
+		FROM pattern_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.pattern_id IN (` + strings.Join(placeholders, ",") + `)
+		ORDER BY t.name`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("load pattern tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var patternID int64
+		var t domain.Tag
+		if err := rows.Scan(&patternID, &t.ID, &t.UserID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan pattern tag: %w", err)
+		}
+		result[patternID] = append(result[patternID], t)
+	}
+	return result, rows.Err()
+}
+
+// copyPatternTags gives the pattern toID the tags of the pattern fromID, as
+// tags of the user whose ID is given, creating any the user doesn't have.
+func copyPatternTags(ctx context.Context, tx *sql.Tx, fromID, toID, userID int64) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO tags (user_id, name)
+		 SELECT ?, t.name FROM pattern_tags pt JOIN tags t ON t.id = pt.tag_id
+		 WHERE pt.pattern_id = ?`, userID, fromID,
+	); err != nil {
+		return fmt.Errorf("create copied tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO pattern_tags (pattern_id, tag_id)
+		 SELECT ?, ut.id FROM pattern_tags pt
+		 JOIN tags t ON t.id = pt.tag_id
+		 JOIN tags ut ON ut.user_id = ? AND ut.name = t.name
+		 WHERE pt.pattern_id = ?`, toID, userID, fromID,
+	); err != nil {
+		return fmt.Errorf("copy pattern tags: %w", err)
+	}
+	return nil
+}
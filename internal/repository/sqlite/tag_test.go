@@ -0,0 +1,250 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func TestTagRepository_CreateAndList(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userID := seedTestUser(t, db)
+	repo := db.Tags()
+
+	gifts := &domain.Tag{UserID: userID, Name: "gifts"}
+	if err := repo.Create(ctx, gifts); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.Tag{UserID: userID, Name: "Amigurumi"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.Tag{UserID: userID, Name: "GIFTS"}); !errors.Is(err, domain.ErrDuplicateTag) {
+		t.Fatalf("expected ErrDuplicateTag for a name differing only in case, got %v", err)
+	}
+
+	p := makeTestPattern(userID)
+	if err := db.Patterns().Create(ctx, p); err != nil {
+		t.Fatalf("Create pattern: %v", err)
+	}
+	if err := repo.AddToPatterns(ctx, gifts.ID, []int64{p.ID, p.ID}); err != nil {
+		t.Fatalf("AddToPatterns: %v", err)
+	}
+
+	tags, err := repo.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Name != "Amigurumi" || tags[1].Name != "gifts" {
+		t.Fatalf("expected Amigurumi, gifts, got %+v", tags)
+	}
+	if tags[0].PatternCount != 0 || tags[1].PatternCount != 1 {
+		t.Errorf("pattern counts = %d, %d, want 0, 1", tags[0].PatternCount, tags[1].PatternCount)
+	}
+
+	got, err := repo.GetByName(ctx, userID, "Gifts")
+	if err != nil || got.ID != gifts.ID {
+		t.Fatalf("GetByName = %+v, %v", got, err)
+	}
+
+	if err := repo.Rename(ctx, gifts.ID, "amigurumi"); !errors.Is(err, domain.ErrDuplicateTag) {
+		t.Fatalf("expected ErrDuplicateTag renaming onto another tag, got %v", err)
+	}
+	if err := repo.RemoveFromPatterns(ctx, gifts.ID, []int64{p.ID}); err != nil {
+		t.Fatalf("RemoveFromPatterns: %v", err)
+	}
+	byPattern, err := repo.ListByPatternIDs(ctx, []int64{p.ID})
+	if err != nil {
+		t.Fatalf("ListByPatternIDs: %v", err)
+	}
+	if len(byPattern[p.ID]) != 0 {
+		t.Fatalf("expected no tags after removal, got %+v", byPattern[p.ID])
+	}
+
+	if err := repo.Delete(ctx, gifts.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, gifts.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestTagRepository_SummariesAndFilter(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userID := seedTestUser(t, db)
+	repo := db.Tags()
+
+	var ids []int64
+	for _, name := range []string{"Hat", "Scarf", "Mittens"} {
+		p := makeTestPattern(userID)
+		p.Name = name
+		if err := db.Patterns().Create(ctx, p); err != nil {
+			t.Fatalf("Create pattern: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+	winter := &domain.Tag{UserID: userID, Name: "winter"}
+	gifts := &domain.Tag{UserID: userID, Name: "gifts"}
+	for _, tag := range []*domain.Tag{winter, gifts} {
+		if err := repo.Create(ctx, tag); err != nil {
+			t.Fatalf("Create tag: %v", err)
+		}
+	}
+	if err := repo.AddToPatterns(ctx, winter.ID, ids); err != nil {
+		t.Fatalf("AddToPatterns: %v", err)
+	}
+	if err := repo.AddToPatterns(ctx, gifts.ID, ids[1:2]); err != nil {
+		t.Fatalf("AddToPatterns: %v", err)
+	}
+
+	summaries, err := pageSummaries(db.Patterns().SearchSummaryByUser(ctx, userID,
+		domain.PatternFilter{Tags: []int64{winter.ID, gifts.ID}}, domain.PatternPage{}))
+	if err != nil {
+		t.Fatalf("SearchSummaryByUser: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Name != "Scarf" {
+		t.Fatalf("expected only Scarf with both tags, got %+v", summaries)
+	}
+	if tags := summaries[0].Tags; len(tags) != 2 || tags[0].Name != "gifts" || tags[1].Name != "winter" {
+		t.Fatalf("expected summary tags gifts, winter, got %+v", tags)
+	}
+}
+
+func TestTagRepository_CopiedWithPattern(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	ownerID := seedTestUser(t, db)
+	other := &domain.User{Email: "other@example.com", DisplayName: "Other", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, other); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	p := makeTestPattern(ownerID)
+	if err := db.Patterns().Create(ctx, p); err != nil {
+		t.Fatalf("Create pattern: %v", err)
+	}
+	tag := &domain.Tag{UserID: ownerID, Name: "Baby"}
+	if err := db.Tags().Create(ctx, tag); err != nil {
+		t.Fatalf("Create tag: %v", err)
+	}
+	if err := db.Tags().AddToPatterns(ctx, tag.ID, []int64{p.ID}); err != nil {
+		t.Fatalf("AddToPatterns: %v", err)
+	}
+	// The other user already has the tag under different case.
+	existing := &domain.Tag{UserID: other.ID, Name: "baby"}
+	if err := db.Tags().Create(ctx, existing); err != nil {
+		t.Fatalf("Create tag: %v", err)
+	}
+
+	dup, err := db.Patterns().Duplicate(ctx, p.ID, ownerID)
+	if err != nil {
+		t.Fatalf("Duplicate: %v", err)
+	}
+	share := &domain.PatternShare{PatternID: p.ID, Token: "tagged", ShareType: domain.ShareTypeGlobal, Permission: domain.SharePermissionSaveLocked}
+	if err := db.Shares().Create(ctx, share); err != nil {
+		t.Fatalf("Create share: %v", err)
+	}
+	shared, err := db.Patterns().DuplicateAsShared(ctx, share, other.ID, ownerID, "Patt", true)
+	if err != nil {
+		t.Fatalf("DuplicateAsShared: %v", err)
+	}
+	untagged, err := db.Patterns().DuplicateAsShared(ctx, share, other.ID, ownerID, "Patt", false)
+	if err != nil {
+		t.Fatalf("DuplicateAsShared: %v", err)
+	}
+
+	tags, err := db.Tags().ListByPatternIDs(ctx, []int64{dup.ID, shared.ID, untagged.ID})
+	if err != nil {
+		t.Fatalf("ListByPatternIDs: %v", err)
+	}
+	if len(tags[dup.ID]) != 1 || tags[dup.ID][0].ID != tag.ID {
+		t.Errorf("duplicate tags = %+v, want the original tag", tags[dup.ID])
+	}
+	if len(tags[shared.ID]) != 1 || tags[shared.ID][0].ID != existing.ID {
+		t.Errorf("shared copy tags = %+v, want the recipient's own tag", tags[shared.ID])
+	}
+	if len(tags[untagged.ID]) != 0 {
+		t.Errorf("expected no tags without withTags, got %+v", tags[untagged.ID])
+	}
+}
+
+func TestCollectionRepository(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userID := seedTestUser(t, db)
+	repo := db.Collections()
+
+	var ids []int64
+	for range 3 {
+		p := makeTestPattern(userID)
+		if err := db.Patterns().Create(ctx, p); err != nil {
+			t.Fatalf("Create pattern: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+
+	c := &domain.Collection{UserID: userID, Name: "Christmas 2026 gifts"}
+	if err := repo.Create(ctx, c); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.AddPatterns(ctx, c.ID, []int64{ids[2], ids[0]}); err != nil {
+		t.Fatalf("AddPatterns: %v", err)
+	}
+	// Patterns already in the collection keep their place.
+	if err := repo.AddPatterns(ctx, c.ID, []int64{ids[1], ids[2]}); err != nil {
+		t.Fatalf("AddPatterns: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	want := []int64{ids[2], ids[0], ids[1]}
+	if len(got.PatternIDs) != 3 || got.PatternIDs[0] != want[0] || got.PatternIDs[1] != want[1] || got.PatternIDs[2] != want[2] {
+		t.Fatalf("PatternIDs = %v, want %v", got.PatternIDs, want)
+	}
+
+	if err := repo.SetOrder(ctx, c.ID, ids); err != nil {
+		t.Fatalf("SetOrder: %v", err)
+	}
+	if err := repo.RemovePattern(ctx, c.ID, ids[0]); err != nil {
+		t.Fatalf("RemovePattern: %v", err)
+	}
+	if err := repo.RemovePattern(ctx, c.ID, ids[0]); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound removing a pattern twice, got %v", err)
+	}
+	// Deleting a pattern takes it out of its collections.
+	if err := db.Patterns().Delete(ctx, ids[2]); err != nil {
+		t.Fatalf("Delete pattern: %v", err)
+	}
+
+	list, err := repo.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(list) != 1 || list[0].PatternCount != 1 || list[0].PatternIDs != nil {
+		t.Fatalf("expected one collection with one pattern, got %+v", list)
+	}
+	got, err = repo.GetByID(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(got.PatternIDs) != 1 || got.PatternIDs[0] != ids[1] {
+		t.Fatalf("PatternIDs = %v, want [%d]", got.PatternIDs, ids[1])
+	}
+
+	c.Name = "Gifts"
+	c.Description = "For the family"
+	if err := repo.Update(ctx, c); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.Delete(ctx, c.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, c.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -18,9 +18,9 @@ type userRepo struct {
 func (r *userRepo) Create(ctx context.Context, user *domain.User) error {
 	now := time.Now().UTC()
 	result, err := r.db.ExecContext(ctx,
-		`INSERT INTO users (email, display_name, password_hash, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?)`,
-		user.Email, user.DisplayName, user.PasswordHash, now, now,
+		`INSERT INTO users (email, display_name, password_hash, terminology, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		user.Email, user.DisplayName, user.PasswordHash, user.Terminology, now, now,
 	)
 	if err != nil {
 		if isUniqueConstraintError(err) {
@@ -43,9 +43,9 @@ func (r *userRepo) Create(ctx context.Context, user *domain.User) error {
 func (r *userRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	user := &domain.User{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, email, display_name, password_hash, created_at, updated_at
+		`SELECT id, email, display_name, password_hash, terminology, created_at, updated_at
 		 FROM users WHERE id = ?`, id,
-	).Scan(&user.ID, &user.Email, &user.DisplayName, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Email, &user.DisplayName, &user.PasswordHash, &user.Terminology, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -58,9 +58,9 @@ func (r *userRepo) GetByID(ctx context.Context, id int64) (*domain.User, error)
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	user := &domain.User{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, email, display_name, password_hash, created_at, updated_at
+		`SELECT id, email, display_name, password_hash, terminology, created_at, updated_at
 		 FROM users WHERE email = ?`, email,
-	).Scan(&user.ID, &user.Email, &user.DisplayName, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Email, &user.DisplayName, &user.PasswordHash, &user.Terminology, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -70,6 +70,25 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User,
 	return user, nil
 }
 
+func (r *userRepo) UpdateTerminology(ctx context.Context, id int64, terminology domain.Terminology) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET terminology = ?, updated_at = ? WHERE id = ?`,
+		terminology, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("update user terminology: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 // isUniqueConstraintError checks if the error is a SQLite unique constraint violation.
 func isUniqueConstraintError(err error) bool {
 	return err != nil && (errors.Is(err, sql.ErrNoRows) == false) &&
@@ -145,3 +145,29 @@ func TestUserRepository_GetByEmail_NotFound(t *testing.T) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestUserRepository_UpdateTerminology(t *testing.T) {
+	db := newTestDB(t)
+	repo := db.Users()
+	ctx := context.Background()
+
+	user := &domain.User{Email: "terms@example.com", DisplayName: "Terms", PasswordHash: "hash", Terminology: domain.TerminologyUS}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.UpdateTerminology(ctx, user.ID, domain.TerminologyUK); err != nil {
+		t.Fatalf("UpdateTerminology: %v", err)
+	}
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Terminology != domain.TerminologyUK {
+		t.Errorf("Terminology = %q, want uk", got.Terminology)
+	}
+
+	if err := repo.UpdateTerminology(ctx, 9999, domain.TerminologyUK); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("UpdateTerminology missing user: err = %v, want ErrNotFound", err)
+	}
+}
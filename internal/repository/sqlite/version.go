@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+type versionRepo struct {
+	db *sql.DB
+}
+
+// Create stores a snapshot as the pattern's next version number.
+func (r *versionRepo) Create(ctx context.Context, version *domain.PatternVersion) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertVersion(ctx, tx, version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// insertVersion stores a snapshot as the pattern's next version number
+// within tx.
+func insertVersion(ctx context.Context, tx *sql.Tx, version *domain.PatternVersion) error {
+	snapshot, err := json.Marshal(version.Snapshot)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO pattern_versions (pattern_id, version, name, snapshot, created_at)
+		 SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?
+		 FROM pattern_versions WHERE pattern_id = ?`,
+		version.PatternID, version.Name, string(snapshot), now, version.PatternID,
+	)
+	if err != nil {
+		return fmt.Errorf("insert pattern version: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get version id: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx,
+		"SELECT version FROM pattern_versions WHERE id = ?", id,
+	).Scan(&version.Version); err != nil {
+		return fmt.Errorf("get version number: %w", err)
+	}
+	version.ID = id
+	version.CreatedAt = now
+	return nil
+}
+
+func (r *versionRepo) GetByID(ctx context.Context, id int64) (*domain.PatternVersion, error) {
+	v := &domain.PatternVersion{}
+	var snapshot string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, pattern_id, version, name, snapshot, created_at
+		 FROM pattern_versions WHERE id = ?`, id,
+	).Scan(&v.ID, &v.PatternID, &v.Version, &v.Name, &snapshot, &v.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get version by id: %w", err)
+	}
+
+	v.Snapshot = &domain.Pattern{}
+	if err := json.Unmarshal([]byte(snapshot), v.Snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	return v, nil
+}
+
+// ListByPattern returns a pattern's versions, newest first, without their
+// snapshots.
+func (r *versionRepo) ListByPattern(ctx context.Context, patternID int64) ([]domain.PatternVersion, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, pattern_id, version, name, created_at
+		 FROM pattern_versions WHERE pattern_id = ? ORDER BY version DESC`, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("list versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []domain.PatternVersion
+	for rows.Next() {
+		var v domain.PatternVersion
+		if err := rows.Scan(&v.ID, &v.PatternID, &v.Version, &v.Name, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// Prune deletes all but the newest keep versions of a pattern.
+func (r *versionRepo) Prune(ctx context.Context, patternID int64, keep int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := pruneVersions(ctx, tx, patternID, keep); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// pruneVersions deletes all but the newest keep versions of a pattern
+// within tx.
+func pruneVersions(ctx context.Context, tx *sql.Tx, patternID int64, keep int) error {
+	_, err := tx.ExecContext(ctx,
+		`DELETE FROM pattern_versions
+		 WHERE pattern_id = ? AND version <= (
+		     SELECT COALESCE(MAX(version), 0) - ? FROM pattern_versions WHERE pattern_id = ?
+		 )`,
+		patternID, keep, patternID,
+	)
+	if err != nil {
+		return fmt.Errorf("prune versions: %w", err)
+	}
+	return nil
+}
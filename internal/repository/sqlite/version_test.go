@@ -0,0 +1,154 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func TestVersionRepository_CreateAndGet(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+	p := makeTestPattern(userID)
+	if err := db.Patterns().Create(ctx, p); err != nil {
+		t.Fatalf("Create pattern: %v", err)
+	}
+
+	repo := db.Versions()
+	for i := 1; i <= 2; i++ {
+		v := &domain.PatternVersion{PatternID: p.ID, Name: p.Name, Snapshot: p}
+		if err := repo.Create(ctx, v); err != nil {
+			t.Fatalf("Create version: %v", err)
+		}
+		if v.Version != i {
+			t.Fatalf("expected version %d, got %d", i, v.Version)
+		}
+	}
+
+	versions, err := repo.ListByPattern(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListByPattern: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 1 {
+		t.Fatalf("expected versions 2, 1, got %+v", versions)
+	}
+	if versions[0].Snapshot != nil {
+		t.Fatal("expected list results without snapshots")
+	}
+
+	got, err := repo.GetByID(ctx, versions[1].ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Snapshot == nil || got.Snapshot.Name != "Test Pattern" {
+		t.Fatalf("expected snapshot of Test Pattern, got %+v", got.Snapshot)
+	}
+	entries := got.Snapshot.InstructionGroups[0].StitchEntries
+	if len(entries) != 1 || entries[0].PatternStitchID != p.PatternStitches[0].ID {
+		t.Fatalf("expected snapshot entry to reference the pattern stitch, got %+v", entries)
+	}
+
+	if _, err := repo.GetByID(ctx, 99999); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestVersionRepository_Prune(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+	p := makeTestPattern(userID)
+	if err := db.Patterns().Create(ctx, p); err != nil {
+		t.Fatalf("Create pattern: %v", err)
+	}
+
+	repo := db.Versions()
+	for range 5 {
+		if err := repo.Create(ctx, &domain.PatternVersion{PatternID: p.ID, Name: p.Name, Snapshot: p}); err != nil {
+			t.Fatalf("Create version: %v", err)
+		}
+	}
+	if err := repo.Prune(ctx, p.ID, 2); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	versions, err := repo.ListByPattern(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListByPattern: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 5 || versions[1].Version != 4 {
+		t.Fatalf("expected versions 5 and 4 to be kept, got %+v", versions)
+	}
+
+	// Numbering continues after pruning.
+	v := &domain.PatternVersion{PatternID: p.ID, Name: p.Name, Snapshot: p}
+	if err := repo.Create(ctx, v); err != nil {
+		t.Fatalf("Create version: %v", err)
+	}
+	if v.Version != 6 {
+		t.Fatalf("expected version 6, got %d", v.Version)
+	}
+}
+
+func TestPatternRepository_UpdateWithVersion(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	userID := seedTestUser(t, db)
+	p := makeTestPattern(userID)
+	if err := db.Patterns().Create(ctx, p); err != nil {
+		t.Fatalf("Create pattern: %v", err)
+	}
+
+	for _, name := range []string{"Second", "Third"} {
+		before, err := db.Patterns().GetByID(ctx, p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		p.Name = name
+		if err := db.Patterns().UpdateWithVersion(ctx, p, &domain.PatternVersion{PatternID: p.ID, Name: before.Name, Snapshot: before}, 1); err != nil {
+			t.Fatalf("UpdateWithVersion: %v", err)
+		}
+	}
+	versions, err := db.Versions().ListByPattern(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListByPattern: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Version != 2 || versions[0].Name != "Second" {
+		t.Fatalf("expected only version 2 kept, got %+v", versions)
+	}
+
+	// An update that fails leaves neither a version nor pruning behind. The
+	// stitch entry refers to a stitch index after the pattern has IDs, which
+	// fails its foreign key.
+	before, err := db.Patterns().GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	broken := *before
+	broken.Name = "Broken"
+	broken.InstructionGroups = []domain.InstructionGroup{{SortOrder: 0, Label: "Round 1", RepeatCount: 1,
+		StitchEntries: []domain.StitchEntry{{SortOrder: 0, PatternStitchID: 0, Count: 6, RepeatCount: 1}}}}
+	if err := db.Patterns().UpdateWithVersion(ctx, &broken, &domain.PatternVersion{PatternID: p.ID, Name: before.Name, Snapshot: before}, 1); err == nil {
+		t.Fatal("expected the update to fail")
+	}
+	after, err := db.Versions().ListByPattern(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListByPattern: %v", err)
+	}
+	if len(after) != 1 || after[0].ID != versions[0].ID {
+		t.Fatalf("expected the failed update to leave versions unchanged, got %+v", after)
+	}
+	got, err := db.Patterns().GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Name != "Third" {
+		t.Fatalf("expected the pattern unchanged, got %q", got.Name)
+	}
+}
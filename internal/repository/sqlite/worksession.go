@@ -17,12 +17,13 @@ type workSessionRepo struct {
 func (r *workSessionRepo) Create(ctx context.Context, session *domain.WorkSession) error {
 	now := time.Now().UTC()
 	result, err := r.db.ExecContext(ctx,
-		`INSERT INTO work_sessions (pattern_id, user_id, current_group_index, current_group_repeat,
-		 current_stitch_index, current_stitch_repeat, current_stitch_count, status, started_at, last_activity_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		session.PatternID, session.UserID,
+		`INSERT INTO work_sessions (pattern_id, user_id, size_index, current_piece_copy, current_group_index, current_group_repeat,
+		 current_stitch_index, current_stitch_repeat, current_stitch_count, block_repeats, status, started_at, last_activity_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.PatternID, session.UserID, session.SizeIndex, session.CurrentPieceCopy,
 		session.CurrentGroupIndex, session.CurrentGroupRepeat,
 		session.CurrentStitchIndex, session.CurrentStitchRepeat, session.CurrentStitchCount,
+		encodeIntList(session.BlockRepeats),
 		session.Status, now, now,
 	)
 	if err != nil {
@@ -42,14 +43,15 @@ func (r *workSessionRepo) Create(ctx context.Context, session *domain.WorkSessio
 
 func (r *workSessionRepo) GetByID(ctx context.Context, id int64) (*domain.WorkSession, error) {
 	s := &domain.WorkSession{}
+	var blockRepeats string
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, pattern_id, user_id, current_group_index, current_group_repeat,
-		 current_stitch_index, current_stitch_repeat, current_stitch_count,
+		`SELECT id, pattern_id, user_id, size_index, current_piece_copy, current_group_index, current_group_repeat,
+		 current_stitch_index, current_stitch_repeat, current_stitch_count, block_repeats,
 		 status, started_at, last_activity_at, completed_at
 		 FROM work_sessions WHERE id = ?`, id,
-	).Scan(&s.ID, &s.PatternID, &s.UserID,
+	).Scan(&s.ID, &s.PatternID, &s.UserID, &s.SizeIndex, &s.CurrentPieceCopy,
 		&s.CurrentGroupIndex, &s.CurrentGroupRepeat,
-		&s.CurrentStitchIndex, &s.CurrentStitchRepeat, &s.CurrentStitchCount,
+		&s.CurrentStitchIndex, &s.CurrentStitchRepeat, &s.CurrentStitchCount, &blockRepeats,
 		&s.Status, &s.StartedAt, &s.LastActivityAt, &s.CompletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -57,13 +59,14 @@ func (r *workSessionRepo) GetByID(ctx context.Context, id int64) (*domain.WorkSe
 		}
 		return nil, fmt.Errorf("get work session: %w", err)
 	}
+	s.BlockRepeats = decodeIntList(blockRepeats)
 	return s, nil
 }
 
 func (r *workSessionRepo) GetActiveByUser(ctx context.Context, userID int64) ([]domain.WorkSession, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT ws.id, ws.pattern_id, ws.user_id, ws.current_group_index, ws.current_group_repeat,
-		 ws.current_stitch_index, ws.current_stitch_repeat, ws.current_stitch_count,
+		`SELECT ws.id, ws.pattern_id, ws.user_id, ws.size_index, ws.current_piece_copy, ws.current_group_index, ws.current_group_repeat,
+		 ws.current_stitch_index, ws.current_stitch_repeat, ws.current_stitch_count, ws.block_repeats,
 		 ws.status, ws.started_at, ws.last_activity_at, ws.completed_at
 		 FROM work_sessions ws
 		 WHERE ws.user_id = ? AND ws.status IN ('active', 'paused')
@@ -76,12 +79,14 @@ func (r *workSessionRepo) GetActiveByUser(ctx context.Context, userID int64) ([]
 	var sessions []domain.WorkSession
 	for rows.Next() {
 		var s domain.WorkSession
-		if err := rows.Scan(&s.ID, &s.PatternID, &s.UserID,
+		var blockRepeats string
+		if err := rows.Scan(&s.ID, &s.PatternID, &s.UserID, &s.SizeIndex, &s.CurrentPieceCopy,
 			&s.CurrentGroupIndex, &s.CurrentGroupRepeat,
-			&s.CurrentStitchIndex, &s.CurrentStitchRepeat, &s.CurrentStitchCount,
+			&s.CurrentStitchIndex, &s.CurrentStitchRepeat, &s.CurrentStitchCount, &blockRepeats,
 			&s.Status, &s.StartedAt, &s.LastActivityAt, &s.CompletedAt); err != nil {
 			return nil, fmt.Errorf("scan work session: %w", err)
 		}
+		s.BlockRepeats = decodeIntList(blockRepeats)
 		sessions = append(sessions, s)
 	}
 	return sessions, rows.Err()
@@ -89,8 +94,8 @@ func (r *workSessionRepo) GetActiveByUser(ctx context.Context, userID int64) ([]
 
 func (r *workSessionRepo) GetCompletedByUser(ctx context.Context, userID int64, limit, offset int) ([]domain.WorkSession, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, pattern_id, user_id, current_group_index, current_group_repeat,
-		 current_stitch_index, current_stitch_repeat, current_stitch_count,
+		`SELECT id, pattern_id, user_id, size_index, current_piece_copy, current_group_index, current_group_repeat,
+		 current_stitch_index, current_stitch_repeat, current_stitch_count, block_repeats,
 		 status, started_at, last_activity_at, completed_at
 		 FROM work_sessions
 		 WHERE user_id = ? AND status = 'completed'
@@ -104,12 +109,14 @@ func (r *workSessionRepo) GetCompletedByUser(ctx context.Context, userID int64,
 	var sessions []domain.WorkSession
 	for rows.Next() {
 		var s domain.WorkSession
-		if err := rows.Scan(&s.ID, &s.PatternID, &s.UserID,
+		var blockRepeats string
+		if err := rows.Scan(&s.ID, &s.PatternID, &s.UserID, &s.SizeIndex, &s.CurrentPieceCopy,
 			&s.CurrentGroupIndex, &s.CurrentGroupRepeat,
-			&s.CurrentStitchIndex, &s.CurrentStitchRepeat, &s.CurrentStitchCount,
+			&s.CurrentStitchIndex, &s.CurrentStitchRepeat, &s.CurrentStitchCount, &blockRepeats,
 			&s.Status, &s.StartedAt, &s.LastActivityAt, &s.CompletedAt); err != nil {
 			return nil, fmt.Errorf("scan completed session: %w", err)
 		}
+		s.BlockRepeats = decodeIntList(blockRepeats)
 		sessions = append(sessions, s)
 	}
 	return sessions, rows.Err()
@@ -132,12 +139,13 @@ func (r *workSessionRepo) Update(ctx context.Context, session *domain.WorkSessio
 
 	result, err := r.db.ExecContext(ctx,
 		`UPDATE work_sessions SET
-		 current_group_index = ?, current_group_repeat = ?,
-		 current_stitch_index = ?, current_stitch_repeat = ?, current_stitch_count = ?,
+		 current_piece_copy = ?, current_group_index = ?, current_group_repeat = ?,
+		 current_stitch_index = ?, current_stitch_repeat = ?, current_stitch_count = ?, block_repeats = ?,
 		 status = ?, last_activity_at = ?, completed_at = ?
 		 WHERE id = ?`,
-		session.CurrentGroupIndex, session.CurrentGroupRepeat,
+		session.CurrentPieceCopy, session.CurrentGroupIndex, session.CurrentGroupRepeat,
 		session.CurrentStitchIndex, session.CurrentStitchRepeat, session.CurrentStitchCount,
+		encodeIntList(session.BlockRepeats),
 		session.Status, now, session.CompletedAt, session.ID,
 	)
 	if err != nil {
@@ -0,0 +1,106 @@
+package service
+
+import "github.com/msomdec/stitch-map-2/internal/domain"
+
+// GroupArithmetic describes how a single repeat of an instruction group
+// changes the stitch count, based on each stitch's consumed and produced
+// counts (e.g. inc 1 → 2, sc2tog 2 → 1).
+type GroupArithmetic struct {
+	Consumed    int  // Stitches of the previous round/row worked into
+	Produced    int  // Stitches made for the next round/row to work into
+	Previous    int  // Stitches produced by the preceding round/row; valid when HasPrevious
+	HasPrevious bool // False for the first group that makes stitches
+	// PreviousFoundation is set when the preceding group only made stitches
+	// without working into any, like a starting chain. The next round may
+	// work into it more than once (e.g. 12 dc in the 4th ch from hook).
+	PreviousFoundation bool
+}
+
+// ComputeStitchArithmetic computes the consumed and produced stitch counts of
+// every instruction group, along with what the round before it left to work
+// into. Groups that make no stitches, such as an empty step or a lone MR, are
+// skipped when chaining rounds together, and the first group of each piece
+// starts afresh. The result has one element per group, in order.
+func ComputeStitchArithmetic(pattern *domain.Pattern) []GroupArithmetic {
+	stitches := buildPatternStitchByID(pattern.PatternStitches)
+	result := make([]GroupArithmetic, len(pattern.InstructionGroups))
+
+	hasPrevious := false
+	previous := 0
+	previousFoundation := false
+	previousPiece := 0
+	for i := range pattern.InstructionGroups {
+		g := &pattern.InstructionGroups[i]
+		if len(pattern.Pieces) > 0 && g.PieceIndex != previousPiece {
+			hasPrevious = false
+			previous = 0
+			previousFoundation = false
+			previousPiece = g.PieceIndex
+		}
+		ga := GroupArithmetic{
+			Consumed:           groupConsumedCount(g, stitches),
+			Produced:           groupProducedCount(g, stitches),
+			Previous:           previous,
+			HasPrevious:        hasPrevious,
+			PreviousFoundation: previousFoundation,
+		}
+		result[i] = ga
+		if ga.Produced == 0 {
+			continue
+		}
+
+		hasPrevious = true
+		previous = ga.Produced
+		previousFoundation = ga.Consumed == 0
+	}
+
+	return result
+}
+
+// GroupProducedCount computes the stitches a single repeat of a group leaves
+// for the next round/row to work into.
+func GroupProducedCount(g *domain.InstructionGroup, patternStitches []domain.PatternStitch) int {
+	return groupProducedCount(g, buildPatternStitchByID(patternStitches))
+}
+
+// GroupConsumedCount computes the stitches of the previous round/row a single
+// repeat of a group works into.
+func GroupConsumedCount(g *domain.InstructionGroup, patternStitches []domain.PatternStitch) int {
+	return groupConsumedCount(g, buildPatternStitchByID(patternStitches))
+}
+
+func groupProducedCount(g *domain.InstructionGroup, stitches map[int64]domain.PatternStitch) int {
+	count := 0
+	for i, e := range g.StitchEntries {
+		_, produces := stitchArithmetic(stitches, e.PatternStitchID)
+		count += e.Count * e.RepeatCount * g.BlockMultiplier(i) * produces
+	}
+	return count
+}
+
+func groupConsumedCount(g *domain.InstructionGroup, stitches map[int64]domain.PatternStitch) int {
+	count := 0
+	for i, e := range g.StitchEntries {
+		consumes, _ := stitchArithmetic(stitches, e.PatternStitchID)
+		count += e.Count * e.RepeatCount * g.BlockMultiplier(i) * consumes
+	}
+	return count
+}
+
+// stitchArithmetic returns the consumed and produced counts of a pattern
+// stitch. Unknown stitches are treated as a plain 1 → 1 stitch.
+func stitchArithmetic(stitches map[int64]domain.PatternStitch, id int64) (consumes, produces int) {
+	ps, ok := stitches[id]
+	if !ok {
+		return 1, 1
+	}
+	return ps.Consumes, ps.Produces
+}
+
+func buildPatternStitchByID(stitches []domain.PatternStitch) map[int64]domain.PatternStitch {
+	lookup := make(map[int64]domain.PatternStitch, len(stitches))
+	for _, s := range stitches {
+		lookup[s.ID] = s
+	}
+	return lookup
+}
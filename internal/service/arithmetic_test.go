@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func TestComputeStitchArithmetic_ShapingRounds(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: append(testPatternStitches(),
+			domain.PatternStitch{ID: 7, Abbreviation: "sc2tog", Name: "Single Crochet 2 Together", Consumes: 2, Produces: 1},
+		),
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label: "Round 1", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 4, Count: 1, RepeatCount: 1}, // MR
+					{PatternStitchID: 1, Count: 6, RepeatCount: 1}, // 6 sc
+				},
+			},
+			{
+				Label: "Round 2", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 5, Count: 1, RepeatCount: 6}, // inc ×6
+				},
+			},
+			{Label: "Stuff the piece", RepeatCount: 1},
+			{
+				Label: "Round 3", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 7, Count: 1, RepeatCount: 6}, // sc2tog ×6
+				},
+			},
+			{
+				Label: "Round 4", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 1, Count: 8, RepeatCount: 1}, // 8 sc
+				},
+			},
+		},
+	}
+
+	got := ComputeStitchArithmetic(pattern)
+	want := []GroupArithmetic{
+		{Consumed: 6, Produced: 6},
+		{Consumed: 6, Produced: 12, Previous: 6, HasPrevious: true},
+		{Previous: 12, HasPrevious: true},
+		{Consumed: 12, Produced: 6, Previous: 12, HasPrevious: true},
+		{Consumed: 8, Produced: 8, Previous: 6, HasPrevious: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("group %d: expected %+v, got %+v", i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestComputeStitchArithmetic_SkipsGroupsThatMakeNothing(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: append(testPatternStitches(),
+			domain.PatternStitch{ID: 8, Abbreviation: "ch", Name: "Chain", Consumes: 0, Produces: 1},
+		),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Start", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 4, Count: 1, RepeatCount: 1}, // MR
+			}},
+			{Label: "Foundation", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 8, Count: 4, RepeatCount: 1}, // ch 4
+			}},
+			{Label: "Rnd 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 12, RepeatCount: 1}, // 12 sc
+			}},
+		},
+	}
+
+	got := ComputeStitchArithmetic(pattern)
+	want := []GroupArithmetic{
+		{},
+		{Produced: 4},
+		{Consumed: 12, Produced: 12, Previous: 4, HasPrevious: true, PreviousFoundation: true},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("group %d: expected %+v, got %+v", i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestComputeStitchArithmetic_PiecesStartAfresh(t *testing.T) {
+	pattern := &domain.Pattern{
+		Pieces: []domain.PatternPiece{
+			{Name: "Head", MakeCount: 1},
+			{Name: "Ear", MakeCount: 2},
+		},
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rnd 1", PieceIndex: 0, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+			}},
+			{Label: "Rnd 1", PieceIndex: 1, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 3, Count: 4, RepeatCount: 1},
+			}},
+		},
+	}
+	result := ComputeStitchArithmetic(pattern)
+	if result[1].HasPrevious {
+		t.Fatalf("expected the first round of a piece to start afresh, got %+v", result[1])
+	}
+}
@@ -65,6 +65,7 @@ func (s *AuthService) Register(ctx context.Context, email, displayName, password
 		Email:        email,
 		DisplayName:  displayName,
 		PasswordHash: string(hash),
+		Terminology:  domain.TerminologyUS,
 	}
 
 	if err := s.users.Create(ctx, user); err != nil {
@@ -142,6 +143,14 @@ func (s *AuthService) GetUserByID(ctx context.Context, id int64) (*domain.User,
 	return s.users.GetByID(ctx, id)
 }
 
+// SetTerminology changes the stitch terms a user's patterns are shown in.
+func (s *AuthService) SetTerminology(ctx context.Context, userID int64, terminology domain.Terminology) error {
+	if !IsTerminology(terminology) {
+		return fmt.Errorf("%w: unknown terminology %q", domain.ErrInvalidInput, terminology)
+	}
+	return s.users.UpdateTerminology(ctx, userID, terminology)
+}
+
 func (s *AuthService) generateJWT(user *domain.User) (string, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
@@ -0,0 +1,531 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// Symbol chart geometry, in SVG user units.
+const (
+	chartUnit        = 14.0 // Width of one stitch along a round or row
+	chartRowHeight   = 32.0 // Distance between successive rounds or rows
+	chartCenter      = 20.0 // Radius of the first round
+	chartLabelMargin = 56.0 // Room for row labels either side of a row chart
+	chartMargin      = 24.0
+	chartTitleHeight = 40.0
+	chartLegendRow   = 30.0
+	chartInk         = "#222222"
+
+	// maxChartStitches bounds the size of a chart so a pattern with huge
+	// counts can't produce an unbounded document.
+	maxChartStitches = 20000
+)
+
+// chartMark is one stitch symbol placed on a chart.
+type chartMark struct {
+	stitch   int64 // Pattern stitch ID
+	slots    int   // Stitch positions the symbol spans along its round or row
+	modifier int64 // Pattern stitch ID of a loop modifier (BLO, FLO) drawn with it, or 0
+	color    string
+}
+
+// chartRound is one round or row of a chart: a single pass of an instruction
+// group.
+type chartRound struct {
+	label string
+	marks []chartMark
+	slots int
+}
+
+// chartSection is the rounds or rows of one piece of a pattern, drawn as a
+// separate chart.
+type chartSection struct {
+	heading   string
+	rounds    []chartRound
+	magicRing bool
+	w, h      float64
+	draw      func(sb *strings.Builder, x, y float64) // Draws the section with its top-left corner at x, y
+}
+
+// RenderPatternChart draws a pattern as an SVG crochet symbol chart. Round
+// patterns are drawn as concentric rings worked counterclockwise from the
+// top, row patterns as rows worked alternately right to left and left to
+// right, starting at the bottom. Each piece gets its own chart. Predefined
+// stitches use their standard CYC symbol; custom stitches are drawn as a
+// circle labelled with their abbreviation. A legend of the symbols used
+// follows the chart. Graded patterns are charted for the size at index
+// sizeIndex.
+func RenderPatternChart(pattern *domain.Pattern, sizeIndex int) (string, error) {
+	title := pattern.Name
+	if pattern.IsGraded() {
+		if sizeIndex < 0 || sizeIndex >= len(pattern.Sizes) {
+			return "", fmt.Errorf("%w: size %d does not exist", domain.ErrInvalidInput, sizeIndex)
+		}
+		title += " – Size " + pattern.Sizes[sizeIndex]
+	}
+	sized := pattern.ForSize(sizeIndex)
+
+	byID := buildPatternStitchByID(sized.PatternStitches)
+	sections, used, err := buildChartSections(sized, byID)
+	if err != nil {
+		return "", err
+	}
+
+	width := 2 * chartMargin
+	height := chartMargin + chartTitleHeight
+	for _, s := range sections {
+		width = max(width, s.w+2*chartMargin)
+		height += s.h + chartMargin
+	}
+	legendCols := max(1, int((width-2*chartMargin)/200))
+	legendRows := (len(used) + legendCols - 1) / legendCols
+	height += float64(legendRows)*chartLegendRow + chartMargin
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(title))
+
+	sb.WriteString("<defs>\n")
+	for _, ps := range sized.PatternStitches {
+		fmt.Fprintf(&sb, `<g id="ps-%d">%s</g>`+"\n", ps.ID, chartGlyph(ps))
+	}
+	sb.WriteString("</defs>\n")
+
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	fmt.Fprintf(&sb, `<text x="%.0f" y="%.0f" font-size="18" font-weight="bold" fill="%s">%s</text>`+"\n",
+		chartMargin, chartMargin+18, chartInk, html.EscapeString(title))
+
+	y := chartMargin + chartTitleHeight
+	for _, s := range sections {
+		s.draw(&sb, chartMargin, y)
+		y += s.h + chartMargin
+	}
+
+	colWidth := (width - 2*chartMargin) / float64(legendCols)
+	for i, ps := range used {
+		x := chartMargin + float64(i%legendCols)*colWidth
+		ly := y + float64(i/legendCols)*chartLegendRow
+		fmt.Fprintf(&sb, `<use href="#ps-%d" transform="translate(%.1f %.1f)" stroke="%s" color="%s"/>`+"\n",
+			ps.ID, x+12, ly+24, chartInk, chartInk)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" font-size="11" fill="%s">%s – %s</text>`+"\n",
+			x+32, ly+18, chartInk, html.EscapeString(ps.Abbreviation), html.EscapeString(ps.Name))
+	}
+
+	sb.WriteString("</svg>\n")
+	return sb.String(), nil
+}
+
+// buildChartSections lays out each piece of a single-size pattern and
+// returns the pattern stitches the chart uses, in pattern order.
+func buildChartSections(pattern *domain.Pattern, byID map[int64]domain.PatternStitch) ([]chartSection, []domain.PatternStitch, error) {
+	st := &chartState{colors: pattern.Colors, byID: byID, color: chartInk}
+	if len(pattern.Colors) > 0 && pattern.Colors[0].Hex != "" {
+		st.color = pattern.Colors[0].Hex
+	}
+
+	usedIDs := make(map[int64]bool)
+	total := 0
+	var sections []chartSection
+	for gi := 0; gi < len(pattern.InstructionGroups); {
+		last := len(pattern.InstructionGroups) - 1
+		if len(pattern.Pieces) > 0 {
+			_, last = pattern.PieceSpan(gi)
+		}
+		var section chartSection
+		st.magicRing = false
+		if piece := pattern.PieceAt(gi); piece != nil {
+			section.heading = renderPieceHeading(piece)
+		}
+
+		for ; gi <= last; gi++ {
+			g := &pattern.InstructionGroups[gi]
+			tree := buildEntryTree(g)
+			for r := range max(g.RepeatCount, 1) {
+				var marks []chartMark
+				st.modifier = 0
+				for _, n := range tree {
+					marks = st.appendMarks(marks, n)
+				}
+				total += len(marks)
+				if total > maxChartStitches {
+					return nil, nil, fmt.Errorf("%w: pattern has too many stitches to chart", domain.ErrInvalidInput)
+				}
+
+				round := chartRound{label: g.Label, marks: marks}
+				if g.RepeatCount > 1 {
+					round.label = fmt.Sprintf("%s (%d/%d)", g.Label, r+1, g.RepeatCount)
+				}
+				for _, m := range marks {
+					round.slots += m.slots
+					usedIDs[m.stitch] = true
+					if m.modifier != 0 {
+						usedIDs[m.modifier] = true
+					}
+				}
+				section.rounds = append(section.rounds, round)
+			}
+		}
+
+		section.magicRing = st.magicRing
+		if pattern.PatternType == domain.PatternTypeRow {
+			layoutRowSection(&section)
+		} else {
+			layoutRoundSection(&section)
+		}
+		sections = append(sections, section)
+	}
+
+	var used []domain.PatternStitch
+	for _, ps := range pattern.PatternStitches {
+		if usedIDs[ps.ID] {
+			used = append(used, ps)
+		}
+	}
+	return sections, used, nil
+}
+
+// chartState carries what one stitch passes on to the next while a pattern
+// is charted.
+type chartState struct {
+	colors    []domain.PatternColor
+	byID      map[int64]domain.PatternStitch
+	color     string // Working color
+	modifier  int64  // Loop modifier for the rest of the group, or 0
+	magicRing bool   // Whether the piece starts in a magic ring
+}
+
+// appendMarks appends the symbols for a node of a group's entry tree,
+// repeating entries and blocks as many times as they're worked.
+func (st *chartState) appendMarks(marks []chartMark, n entryNode) []chartMark {
+	if n.block != nil {
+		for range max(n.block.RepeatCount, 1) {
+			if len(marks) > maxChartStitches {
+				break
+			}
+			for _, c := range n.children {
+				marks = st.appendMarks(marks, c)
+			}
+		}
+		return marks
+	}
+
+	e := n.entry
+	if c := ColorAt(st.colors, e.ColorIndex); c != nil {
+		st.color = chartInk
+		if c.Hex != "" {
+			st.color = c.Hex
+		}
+	}
+
+	ps, ok := st.byID[e.PatternStitchID]
+	if !ok {
+		return marks
+	}
+	switch stitchChartRole(ps) {
+	case chartRoleModifier:
+		st.modifier = ps.ID
+		return marks
+	case chartRoleMagicRing:
+		st.magicRing = true
+		return marks
+	case chartRoleHidden:
+		return marks
+	}
+
+	mark := chartMark{stitch: ps.ID, slots: max(ps.Produces, 1), modifier: st.modifier, color: st.color}
+	for range max(e.Count, 1) * max(e.RepeatCount, 1) {
+		if len(marks) > maxChartStitches {
+			break
+		}
+		marks = append(marks, mark)
+	}
+	return marks
+}
+
+// layoutRoundSection sizes a section of concentric rounds and sets how it is
+// drawn. Each round is at least one row height outside the last and large
+// enough to fit its stitches, with one position left free at the top for
+// its label.
+func layoutRoundSection(s *chartSection) {
+	radii := make([]float64, len(s.rounds))
+	r := chartCenter
+	for i, round := range s.rounds {
+		if i > 0 {
+			r += chartRowHeight
+		}
+		r = max(r, float64(round.slots+1)*chartUnit/(2*math.Pi))
+		radii[i] = r
+	}
+	outer := r + chartRowHeight
+	headingHeight := 0.0
+	if s.heading != "" {
+		headingHeight = 24
+	}
+	s.w = 2 * outer
+	s.h = 2*outer + headingHeight
+
+	s.draw = func(sb *strings.Builder, x, y float64) {
+		drawChartHeading(sb, s.heading, x, y)
+		cx, cy := x+outer, y+headingHeight+outer
+		if s.magicRing {
+			fmt.Fprintf(sb, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="%s" stroke-width="1.2"/>`+"\n", cx, cy, chartCenter-8, chartInk)
+		}
+		for i, round := range s.rounds {
+			r := radii[i]
+			fmt.Fprintf(sb, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="#dddddd" stroke-width="0.5"/>`+"\n", cx, cy, r)
+			fmt.Fprintf(sb, `<text x="%.1f" y="%.1f" font-size="8" text-anchor="middle" fill="#888888">%d</text>`+"\n", cx, cy-r-2, i+1)
+
+			step := 2 * math.Pi / float64(round.slots+1)
+			pos := 1.0 // Position 0, at the top, holds the label
+			for _, m := range round.marks {
+				// Counterclockwise from the top, which is a decreasing angle
+				// in SVG coordinates.
+				angle := -math.Pi/2 - step*(pos+float64(m.slots-1)/2)
+				transform := fmt.Sprintf("translate(%.1f %.1f) rotate(%.1f)",
+					cx+r*math.Cos(angle), cy+r*math.Sin(angle), angle*180/math.Pi+90)
+				drawChartMark(sb, m, transform)
+				pos += float64(m.slots)
+			}
+		}
+	}
+}
+
+// layoutRowSection sizes a section of rows and sets how it is drawn. Row 1
+// is at the bottom and read right to left; each row is labelled at the end
+// it starts from.
+func layoutRowSection(s *chartSection) {
+	maxSlots := 0
+	for _, round := range s.rounds {
+		maxSlots = max(maxSlots, round.slots)
+	}
+	headingHeight := 0.0
+	if s.heading != "" {
+		headingHeight = 24
+	}
+	rowsWidth := float64(maxSlots) * chartUnit
+	s.w = rowsWidth + 2*chartLabelMargin
+	s.h = float64(len(s.rounds))*chartRowHeight + headingHeight + 8
+
+	s.draw = func(sb *strings.Builder, x, y float64) {
+		drawChartHeading(sb, s.heading, x, y)
+		left := x + chartLabelMargin
+		for i, round := range s.rounds {
+			base := y + s.h - 4 - float64(i)*chartRowHeight
+			rightToLeft := i%2 == 0
+
+			labelX, anchor := left+rowsWidth+6, "start"
+			if !rightToLeft {
+				labelX, anchor = left-6, "end"
+			}
+			fmt.Fprintf(sb, `<text x="%.1f" y="%.1f" font-size="9" text-anchor="%s" fill="#888888">%s</text>`+"\n",
+				labelX, base-8, anchor, html.EscapeString(round.label))
+
+			pos := 0.0
+			for _, m := range round.marks {
+				offset := (pos + float64(m.slots)/2) * chartUnit
+				mx := left + offset
+				if rightToLeft {
+					mx = left + rowsWidth - offset
+				}
+				drawChartMark(sb, m, fmt.Sprintf("translate(%.1f %.1f)", mx, base))
+				pos += float64(m.slots)
+			}
+		}
+	}
+}
+
+func drawChartHeading(sb *strings.Builder, heading string, x, y float64) {
+	if heading == "" {
+		return
+	}
+	fmt.Fprintf(sb, `<text x="%.1f" y="%.1f" font-size="14" font-weight="bold" fill="%s">%s</text>`+"\n",
+		x, y+14, chartInk, html.EscapeString(heading))
+}
+
+func drawChartMark(sb *strings.Builder, m chartMark, transform string) {
+	fmt.Fprintf(sb, `<use href="#ps-%d" transform="%s" stroke="%s" color="%s"/>`+"\n", m.stitch, transform, m.color, m.color)
+	if m.modifier != 0 {
+		fmt.Fprintf(sb, `<use href="#ps-%d" transform="%s" stroke="%s" color="%s"/>`+"\n", m.modifier, transform, m.color, m.color)
+	}
+}
+
+// chartRole says how a stitch appears on a chart.
+type chartRole int
+
+const (
+	chartRoleSymbol    chartRole = iota // Drawn as a symbol in its round or row
+	chartRoleModifier                   // Marks the loop the rest of the group is worked in
+	chartRoleMagicRing                  // Drawn once as a ring at the center
+	chartRoleHidden                     // Not drawn, e.g. skips and yarn overs
+)
+
+func stitchChartRole(ps domain.PatternStitch) chartRole {
+	if !hasChartSymbol(ps) {
+		return chartRoleSymbol
+	}
+	switch ps.Abbreviation {
+	case "BLO", "FLO":
+		return chartRoleModifier
+	case "MR":
+		return chartRoleMagicRing
+	case "sk", "yo", "tch":
+		return chartRoleHidden
+	}
+	return chartRoleSymbol
+}
+
+// hasChartSymbol reports whether a pattern stitch is a predefined stitch
+// with a standard symbol: its abbreviation and category both match one.
+func hasChartSymbol(ps domain.PatternStitch) bool {
+	return isPredefinedStitch(ps.Abbreviation, ps.Category)
+}
+
+// chartGlyph returns the SVG for a stitch's symbol, drawn with its base at
+// the origin and growing upward. Strokes and fills take their color from the
+// element that uses the symbol.
+func chartGlyph(ps domain.PatternStitch) string {
+	var sb strings.Builder
+	sb.WriteString(`<g fill="none" stroke-width="1.2" stroke-linecap="round">`)
+
+	abbr := ps.Abbreviation
+	if !hasChartSymbol(ps) {
+		abbr = ""
+	}
+	switch abbr {
+	case "ch":
+		sb.WriteString(`<ellipse cx="0" cy="-4" rx="5.5" ry="2.8"/>`)
+	case "sl st":
+		sb.WriteString(`<circle cx="0" cy="-3" r="2.2" fill="currentColor"/>`)
+	case "sc":
+		chartSingle(&sb, 0, -5)
+	case "hdc", "dc", "tr", "dtr":
+		chartLeg(&sb, abbr, 0, 0)
+	case "inc":
+		chartFan(&sb, "sc", 2, true)
+	case "v-st":
+		chartFan(&sb, "dc", 2, true)
+	case "sh":
+		chartFan(&sb, "dc", max(ps.Produces, 2), true)
+	case "dec", "sc2tog":
+		chartFan(&sb, "sc", 2, false)
+	case "hdc2tog":
+		chartFan(&sb, "hdc", 2, false)
+	case "dc2tog":
+		chartFan(&sb, "dc", 2, false)
+	case "dc3tog":
+		chartFan(&sb, "dc", 3, false)
+	case "tr2tog":
+		chartFan(&sb, "tr", 2, false)
+	case "FPsc", "BPsc", "FPdc", "BPdc", "FPtr", "BPtr":
+		base := abbr[2:]
+		if base == "sc" {
+			sb.WriteString(`<path d="M0 0 V-3"/>`)
+			chartSingle(&sb, 0, -7)
+		} else {
+			chartLeg(&sb, base, 0, 0)
+		}
+		// The hook around the post points forward for front post stitches
+		// and back for back post ones.
+		if abbr[0] == 'F' {
+			sb.WriteString(`<path d="M0 -1 C5 -1 5 4 0 4"/>`)
+		} else {
+			sb.WriteString(`<path d="M0 -1 C-5 -1 -5 4 0 4"/>`)
+		}
+	case "BLO":
+		sb.WriteString(`<path d="M-5 5 Q0 0 5 5"/>`)
+	case "FLO":
+		sb.WriteString(`<path d="M-5 2 Q0 7 5 2"/>`)
+	case "pc":
+		sb.WriteString(`<path d="M0 0 V-4"/><ellipse cx="0" cy="-13" rx="5" ry="9"/><path d="M-5 -22 H5"/>`)
+	case "puff":
+		sb.WriteString(`<ellipse cx="0" cy="-11" rx="4" ry="10"/>`)
+	case "bob":
+		sb.WriteString(`<path d="M0 0 V-4"/><ellipse cx="0" cy="-13" rx="4" ry="8" fill="currentColor"/>`)
+	case "cl":
+		sb.WriteString(`<path d="M0 0 Q-7 -11 0 -22 M0 0 V-22 M0 0 Q7 -11 0 -22 M-4 -22 H4"/>`)
+	case "crab st":
+		chartSingle(&sb, 0, -5)
+		sb.WriteString(`<path d="M-4 -13 q2 -2 4 0 t4 0"/>`)
+	case "lp st":
+		chartSingle(&sb, 0, -6)
+		sb.WriteString(`<path d="M0 -1 C-5 6 5 6 0 -1"/>`)
+	case "MR":
+		sb.WriteString(`<circle cx="0" cy="-8" r="6"/>`)
+	default:
+		label := ps.Abbreviation
+		size := 7.0
+		if n := len([]rune(label)); n > 3 {
+			size = max(4, 21/float64(n))
+		}
+		fmt.Fprintf(&sb, `<circle cx="0" cy="-8" r="7"/><text x="0" y="%.1f" font-size="%.1f" text-anchor="middle" stroke="none" fill="currentColor">%s</text>`,
+			-8+size/3, size, html.EscapeString(label))
+	}
+
+	sb.WriteString("</g>")
+	return sb.String()
+}
+
+// chartSingle draws the "x" of a single crochet centered at x, y.
+func chartSingle(sb *strings.Builder, x, y float64) {
+	fmt.Fprintf(sb, `<path d="M%.1f %.1f l8 -8 M%.1f %.1f l8 8"/>`, x-4, y+4, x-4, y-4)
+}
+
+// chartLeg draws one taller stitch (hdc, dc, tr, dtr) as a post with a bar on
+// top and a slash through it for each wrap, running from x0 at the base to
+// x1 at the top.
+func chartLeg(sb *strings.Builder, base string, x0, x1 float64) {
+	height, slashes := chartLegHeight(base)
+	y0, y1 := 0.0, -height
+	fmt.Fprintf(sb, `<path d="M%.1f %.1f L%.1f %.1f M%.1f %.1f H%.1f"/>`, x0, y0, x1, y1, x1-4, y1, x1+4)
+	for k := range slashes {
+		t := float64(k+1) / float64(slashes+1)
+		mx, my := x0+(x1-x0)*t, y0+(y1-y0)*t
+		fmt.Fprintf(sb, `<path d="M%.1f %.1f L%.1f %.1f"/>`, mx-3, my+2, mx+3, my-2)
+	}
+}
+
+// chartFan draws n stitches of the given base worked together: spread from
+// one stitch at the base for increases, or joined at the top for decreases.
+func chartFan(sb *strings.Builder, base string, n int, increase bool) {
+	height, _ := chartLegHeight(base)
+	for i := range n {
+		off := (float64(i) - float64(n-1)/2) * chartUnit * 0.7
+		switch {
+		case base == "sc" && increase:
+			fmt.Fprintf(sb, `<path d="M0 0 L%.1f %.1f"/>`, off, -height+5)
+			chartSingle(sb, off, -height)
+		case base == "sc":
+			fmt.Fprintf(sb, `<path d="M%.1f 0 L0 %.1f"/>`, off, -height+5)
+		case increase:
+			chartLeg(sb, base, 0, off)
+		default:
+			chartLeg(sb, base, off, 0)
+		}
+	}
+	if base == "sc" && !increase {
+		chartSingle(sb, 0, -height)
+	}
+}
+
+// chartLegHeight returns how tall a stitch of the given base is drawn and
+// how many wraps it has.
+func chartLegHeight(base string) (float64, int) {
+	switch base {
+	case "sc":
+		return 12, 0
+	case "hdc":
+		return 16, 0
+	case "dc":
+		return 22, 1
+	case "tr":
+		return 26, 2
+	case "dtr":
+		return 28, 3
+	}
+	return 22, 0
+}
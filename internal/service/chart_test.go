@@ -0,0 +1,246 @@
+package service
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func chartTestStitches() []domain.PatternStitch {
+	return []domain.PatternStitch{
+		{ID: 1, Abbreviation: "sc", Name: "Single Crochet", Category: "basic", Consumes: 1, Produces: 1},
+		{ID: 2, Abbreviation: "dc", Name: "Double Crochet", Category: "basic", Consumes: 1, Produces: 1},
+		{ID: 3, Abbreviation: "ch", Name: "Chain", Category: "basic", Consumes: 0, Produces: 1},
+		{ID: 4, Abbreviation: "MR", Name: "Magic Ring", Category: "action", Consumes: 0, Produces: 0},
+		{ID: 5, Abbreviation: "inc", Name: "Increase (2 stitches in one)", Category: "increase", Consumes: 1, Produces: 2},
+		{ID: 6, Abbreviation: "BLO", Name: "Back Loop Only", Category: "advanced", Consumes: 0, Produces: 0},
+		{ID: 7, Abbreviation: "bst", Name: "Berry Stitch", Category: "custom", Consumes: 1, Produces: 1},
+	}
+}
+
+// checkWellFormed fails the test unless svg parses as XML.
+func checkWellFormed(t *testing.T, svg string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("chart is not well-formed XML: %v\n%s", err, svg)
+		}
+	}
+}
+
+func TestRenderPatternChart_Rounds(t *testing.T) {
+	colorB := 1
+	pattern := &domain.Pattern{
+		Name:            "Berry <Coaster>",
+		PatternType:     domain.PatternTypeRound,
+		PatternStitches: chartTestStitches(),
+		Colors:          []domain.PatternColor{{Label: "A"}, {Label: "B", Hex: "#aa0000"}},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rnd 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+			}},
+			{Label: "Rnd 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 5, Count: 6, RepeatCount: 1},
+			}},
+			{Label: "Rnd 3", RepeatCount: 2, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 6, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 7, Count: 1, RepeatCount: 1, ColorIndex: &colorB},
+			}, RepeatBlocks: []domain.RepeatBlock{
+				{StartEntry: 1, EndEntry: 2, RepeatCount: 6},
+			}},
+		},
+	}
+
+	svg, err := RenderPatternChart(pattern, 0)
+	if err != nil {
+		t.Fatalf("RenderPatternChart: %v", err)
+	}
+	checkWellFormed(t, svg)
+
+	if !strings.Contains(svg, "<title>Berry &lt;Coaster&gt;</title>") {
+		t.Error("chart title missing or unescaped")
+	}
+
+	// 6 sc in round 1, then 6 in each of the two passes of round 3.
+	if n := strings.Count(svg, `href="#ps-1" transform="translate(`) - 1; n != 18 {
+		t.Errorf("sc symbols = %d, want 18", n)
+	}
+	if n := strings.Count(svg, `href="#ps-5" transform="translate(`) - 1; n != 6 {
+		t.Errorf("inc symbols = %d, want 6", n)
+	}
+	// Everything from the first berry stitch on is worked in color B, and
+	// BLO is drawn with every stitch after it in round 3.
+	if n := strings.Count(svg, `href="#ps-7" transform="translate(`); n != 13 {
+		t.Errorf("berry symbols = %d, want 12 plus the legend", n)
+	}
+	if n := strings.Count(svg, `stroke="#aa0000"`); n != 2*23 {
+		t.Errorf("color B strokes = %d, want 46", n)
+	}
+	if n := strings.Count(svg, `href="#ps-6"`); n != 25 {
+		t.Errorf("BLO marks = %d, want 24 plus the legend", n)
+	}
+
+	// The magic ring is drawn once in the middle rather than as a symbol.
+	if strings.Contains(svg, `href="#ps-4"`) {
+		t.Error("magic ring drawn as a stitch symbol")
+	}
+
+	// Custom stitches are a circle labelled with their abbreviation.
+	if !regexp.MustCompile(`<g id="ps-7"><g [^>]*><circle [^>]*/><text [^>]*>bst</text>`).MatchString(svg) {
+		t.Error("custom stitch glyph is not a labelled circle")
+	}
+	for _, want := range []string{"sc – Single Crochet", "inc – Increase (2 stitches in one)", "BLO – Back Loop Only", "bst – Berry Stitch"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("legend missing %q", want)
+		}
+	}
+	if strings.Contains(svg, "dc – Double Crochet") {
+		t.Error("legend lists a stitch the chart doesn't use")
+	}
+}
+
+func TestRenderPatternChart_Pieces(t *testing.T) {
+	round := func(piece int) domain.InstructionGroup {
+		return domain.InstructionGroup{Label: "Rnd 1", PieceIndex: piece, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+			{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+			{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+		}}
+	}
+	pattern := &domain.Pattern{
+		Name:              "Bear",
+		PatternType:       domain.PatternTypeRound,
+		PatternStitches:   chartTestStitches(),
+		Pieces:            []domain.PatternPiece{{Name: "Head", MakeCount: 1}, {Name: "Arm", MakeCount: 2}},
+		InstructionGroups: []domain.InstructionGroup{round(0), round(1)},
+	}
+
+	svg, err := RenderPatternChart(pattern, 0)
+	if err != nil {
+		t.Fatalf("RenderPatternChart: %v", err)
+	}
+	checkWellFormed(t, svg)
+
+	for _, want := range []string{">Head</text>", ">Arm (make 2)</text>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("chart missing piece heading %q", want)
+		}
+	}
+	if n := strings.Count(svg, `stroke-width="1.2"/>`); n != 2 {
+		t.Errorf("magic rings = %d, want one per piece", n)
+	}
+}
+
+func TestRenderPatternChart_Rows(t *testing.T) {
+	pattern := &domain.Pattern{
+		Name:            "Dishcloth",
+		PatternType:     domain.PatternTypeRow,
+		PatternStitches: chartTestStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Row 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 3, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 4, RepeatCount: 1},
+			}},
+			{Label: "Row 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 3, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 2, Count: 4, RepeatCount: 1},
+			}},
+		},
+	}
+
+	svg, err := RenderPatternChart(pattern, 0)
+	if err != nil {
+		t.Fatalf("RenderPatternChart: %v", err)
+	}
+	checkWellFormed(t, svg)
+
+	// Each row starts with its turning chain: row 1 at the right, row 2 at
+	// the left.
+	xs := chartSymbolXs(t, svg, 3)
+	if len(xs) != 2 || xs[0] <= xs[1] {
+		t.Errorf("chain x positions = %v, want row 1 right of row 2", xs)
+	}
+	sc := chartSymbolXs(t, svg, 1)
+	if len(sc) != 4 || sc[0] >= xs[0] || sc[3] >= sc[0] {
+		t.Errorf("row 1 sc x positions = %v, want decreasing from the chain at %v", sc, xs[0])
+	}
+}
+
+func TestRenderPatternChart_Graded(t *testing.T) {
+	pattern := &domain.Pattern{
+		Name:            "Hat",
+		PatternType:     domain.PatternTypeRound,
+		Sizes:           []string{"S", "L"},
+		PatternStitches: chartTestStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rnd 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 6, SizeCounts: []int{6, 8}, RepeatCount: 1},
+			}},
+		},
+	}
+
+	svg, err := RenderPatternChart(pattern, 1)
+	if err != nil {
+		t.Fatalf("RenderPatternChart: %v", err)
+	}
+	if !strings.Contains(svg, "<title>Hat – Size L</title>") {
+		t.Error("chart title doesn't name the size")
+	}
+	if n := len(chartSymbolXs(t, svg, 1)); n != 8 {
+		t.Errorf("size L sc symbols = %d, want 8", n)
+	}
+
+	if _, err := RenderPatternChart(pattern, 2); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("missing size: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestRenderPatternChart_TooLarge(t *testing.T) {
+	pattern := &domain.Pattern{
+		Name:            "Blanket",
+		PatternType:     domain.PatternTypeRow,
+		PatternStitches: chartTestStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rows 1-1000", RepeatCount: 1000, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 200, RepeatCount: 1},
+			}},
+		},
+	}
+	if _, err := RenderPatternChart(pattern, 0); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("err = %v, want ErrInvalidInput", err)
+	}
+}
+
+var chartTranslate = regexp.MustCompile(`<use href="#ps-(\d+)" transform="translate\(([-\d.]+) `)
+
+// chartSymbolXs returns the x positions of the chart symbols for a pattern
+// stitch, in drawing order, leaving out the legend.
+func chartSymbolXs(t *testing.T, svg string, id int64) []float64 {
+	t.Helper()
+	var xs []float64
+	for _, m := range chartTranslate.FindAllStringSubmatch(svg, -1) {
+		if m[1] != strconv.FormatInt(id, 10) {
+			continue
+		}
+		x, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			t.Fatalf("parse x %q: %v", m[2], err)
+		}
+		xs = append(xs, x)
+	}
+	// The legend entry is drawn last.
+	if len(xs) > 0 {
+		xs = xs[:len(xs)-1]
+	}
+	return xs
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// ListCollections returns the user's collections by name, with how many
+// patterns each holds.
+func (s *PatternService) ListCollections(ctx context.Context, userID int64) ([]domain.Collection, error) {
+	return s.collections.ListByUser(ctx, userID)
+}
+
+// GetCollection returns one of the user's collections with its patterns in
+// order.
+func (s *PatternService) GetCollection(ctx context.Context, userID, collectionID int64) (*domain.Collection, error) {
+	c, err := s.collections.GetByID(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+	return c, nil
+}
+
+// CreateCollection creates an empty collection.
+func (s *PatternService) CreateCollection(ctx context.Context, c *domain.Collection) error {
+	if err := validateCollection(c); err != nil {
+		return err
+	}
+	if err := s.collections.Create(ctx, c); err != nil {
+		return fmt.Errorf("create collection: %w", err)
+	}
+	return nil
+}
+
+// UpdateCollection changes a collection's name and description.
+func (s *PatternService) UpdateCollection(ctx context.Context, userID int64, c *domain.Collection) error {
+	if _, err := s.GetCollection(ctx, userID, c.ID); err != nil {
+		return err
+	}
+	if err := validateCollection(c); err != nil {
+		return err
+	}
+	return s.collections.Update(ctx, c)
+}
+
+// DeleteCollection deletes a collection. Its patterns are kept.
+func (s *PatternService) DeleteCollection(ctx context.Context, userID, collectionID int64) error {
+	if _, err := s.GetCollection(ctx, userID, collectionID); err != nil {
+		return err
+	}
+	return s.collections.Delete(ctx, collectionID)
+}
+
+// AddToCollection appends the user's patterns to the end of a collection,
+// skipping any it already holds.
+func (s *PatternService) AddToCollection(ctx context.Context, userID, collectionID int64, patternIDs []int64) error {
+	if _, err := s.GetCollection(ctx, userID, collectionID); err != nil {
+		return err
+	}
+	if err := s.checkPatternsOwned(ctx, userID, patternIDs); err != nil {
+		return err
+	}
+	return s.collections.AddPatterns(ctx, collectionID, patternIDs)
+}
+
+// RemoveFromCollection takes a pattern out of a collection.
+func (s *PatternService) RemoveFromCollection(ctx context.Context, userID, collectionID, patternID int64) error {
+	if _, err := s.GetCollection(ctx, userID, collectionID); err != nil {
+		return err
+	}
+	return s.collections.RemovePattern(ctx, collectionID, patternID)
+}
+
+// ReorderCollection puts a collection's patterns in the order given, which
+// must list each of them exactly once.
+func (s *PatternService) ReorderCollection(ctx context.Context, userID, collectionID int64, patternIDs []int64) error {
+	c, err := s.GetCollection(ctx, userID, collectionID)
+	if err != nil {
+		return err
+	}
+	current := slices.Sorted(slices.Values(c.PatternIDs))
+	proposed := slices.Sorted(slices.Values(patternIDs))
+	if !slices.Equal(current, proposed) {
+		return fmt.Errorf("%w: the new order must list each of the collection's patterns once", domain.ErrInvalidInput)
+	}
+	return s.collections.SetOrder(ctx, collectionID, patternIDs)
+}
+
+func validateCollection(c *domain.Collection) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("%w: collection name is required", domain.ErrInvalidInput)
+	}
+	if len(c.Name) > 200 {
+		return fmt.Errorf("%w: collection name must be 200 characters or fewer", domain.ErrInvalidInput)
+	}
+	if len(c.Description) > 5000 {
+		return fmt.Errorf("%w: description must be 5000 characters or fewer", domain.ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// maxPatternColors limits the size of a pattern's color palette.
+const maxPatternColors = 20
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateColors checks a pattern's color palette and that every stitch entry
+// with a working color refers to a color in it.
+func validateColors(pattern *domain.Pattern) error {
+	if len(pattern.Colors) > maxPatternColors {
+		return fmt.Errorf("%w: a pattern can have at most %d colors", domain.ErrInvalidInput, maxPatternColors)
+	}
+	seen := make(map[string]bool, len(pattern.Colors))
+	for i, c := range pattern.Colors {
+		if c.Label == "" {
+			return fmt.Errorf("%w: color %d label is required", domain.ErrInvalidInput, i+1)
+		}
+		if len(c.Label) > 10 {
+			return fmt.Errorf("%w: color %d label must be 10 characters or fewer", domain.ErrInvalidInput, i+1)
+		}
+		key := strings.ToLower(c.Label)
+		if seen[key] {
+			return fmt.Errorf("%w: color label %q is used more than once", domain.ErrInvalidInput, c.Label)
+		}
+		seen[key] = true
+		if len(c.Name) > 50 {
+			return fmt.Errorf("%w: color %d name must be 50 characters or fewer", domain.ErrInvalidInput, i+1)
+		}
+		if c.Hex != "" && !hexColorPattern.MatchString(c.Hex) {
+			return fmt.Errorf("%w: color %d swatch must be a hex color like #f5e6c8", domain.ErrInvalidInput, i+1)
+		}
+	}
+
+	for gi, g := range pattern.InstructionGroups {
+		for ei, e := range g.StitchEntries {
+			if e.ColorIndex == nil {
+				continue
+			}
+			if *e.ColorIndex < 0 || *e.ColorIndex >= len(pattern.Colors) {
+				return fmt.Errorf("%w: group %d stitch %d uses a color that is not in the palette", domain.ErrInvalidInput, gi+1, ei+1)
+			}
+		}
+	}
+	return nil
+}
+
+// ColorAt returns the palette color at index ci, or nil if there is none.
+func ColorAt(colors []domain.PatternColor, ci *int) *domain.PatternColor {
+	if ci == nil || *ci < 0 || *ci >= len(colors) {
+		return nil
+	}
+	return &colors[*ci]
+}
+
+// colorTracker follows the working color while a pattern is rendered, so a
+// "with B:" marker is only written where the color actually changes.
+type colorTracker struct {
+	colors  []domain.PatternColor
+	current *int
+}
+
+// marker returns the "with B: " prefix for an entry that changes the working
+// color, or empty if the entry is worked in the current color. A nil tracker
+// never writes markers.
+func (t *colorTracker) marker(e *domain.StitchEntry) string {
+	if c := t.change(e); c != nil {
+		return fmt.Sprintf("with %s: ", c.Label)
+	}
+	return ""
+}
+
+// change returns the color an entry switches to, or nil if it is worked in
+// the current color. A nil tracker never reports changes.
+func (t *colorTracker) change(e *domain.StitchEntry) *domain.PatternColor {
+	if t == nil {
+		return nil
+	}
+	c := ColorAt(t.colors, e.ColorIndex)
+	if c == nil || (t.current != nil && *t.current == *e.ColorIndex) {
+		return nil
+	}
+	ci := *e.ColorIndex
+	t.current = &ci
+	return c
+}
+
+// workingColor returns the index of the color the stitch at the session's
+// position is worked in: the color of the most recently worked entry that
+// set one, looking back through earlier passes of repeat blocks, earlier
+// group repeats, earlier copies of the piece and earlier groups. Patterns with
+// a palette start in the first color; without one the result is nil.
+func workingColor(session *domain.WorkSession, pattern *domain.Pattern) *int {
+	if len(pattern.Colors) == 0 {
+		return nil
+	}
+	groups := pattern.InstructionGroups
+	gi := session.CurrentGroupIndex
+	if gi >= len(groups) {
+		return nil
+	}
+
+	group := &groups[gi]
+	entries := group.StitchEntries
+	if ei := session.CurrentStitchIndex; ei < len(entries) {
+		enclosing := enclosingBlocks(group, ei)
+		repeats := syncBlockRepeats(session.BlockRepeats, len(enclosing))
+		hi := ei
+		for k := len(enclosing) - 1; k >= 0; k-- {
+			b := enclosing[k]
+			if ci := lastColor(entries, b.StartEntry, hi); ci != nil {
+				return ci
+			}
+			// The rest of the block was worked in its previous pass.
+			if repeats[k] > 0 {
+				if ci := lastColor(entries, b.StartEntry, b.EndEntry); ci != nil {
+					return ci
+				}
+			}
+			hi = b.StartEntry - 1
+		}
+		if ci := lastColor(entries, 0, hi); ci != nil {
+			return ci
+		}
+	}
+	if session.CurrentGroupRepeat > 0 {
+		if ci := lastColor(entries, 0, len(entries)-1); ci != nil {
+			return ci
+		}
+	}
+
+	first, last := pattern.PieceSpan(gi)
+	for g := gi - 1; g >= first; g-- {
+		if ci := lastColor(groups[g].StitchEntries, 0, len(groups[g].StitchEntries)-1); ci != nil {
+			return ci
+		}
+	}
+	if session.CurrentPieceCopy > 0 {
+		for g := last; g >= first; g-- {
+			if ci := lastColor(groups[g].StitchEntries, 0, len(groups[g].StitchEntries)-1); ci != nil {
+				return ci
+			}
+		}
+	}
+	for g := first - 1; g >= 0; g-- {
+		if ci := lastColor(groups[g].StitchEntries, 0, len(groups[g].StitchEntries)-1); ci != nil {
+			return ci
+		}
+	}
+
+	initial := 0
+	return &initial
+}
+
+// lastColor returns the color of the last entry between indices lo and hi
+// (inclusive) that sets one, or nil if none do.
+func lastColor(entries []domain.StitchEntry, lo, hi int) *int {
+	for j := hi; j >= lo; j-- {
+		if entries[j].ColorIndex != nil {
+			return entries[j].ColorIndex
+		}
+	}
+	return nil
+}
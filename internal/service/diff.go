@@ -0,0 +1,349 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// DiffKind says how something differs between two versions of a pattern.
+type DiffKind string
+
+const (
+	DiffSame     DiffKind = "same"     // Unchanged; only used for text lines
+	DiffAdded    DiffKind = "added"    // Only in the newer version
+	DiffRemoved  DiffKind = "removed"  // Only in the older version
+	DiffModified DiffKind = "modified" // In both versions, with differences
+	DiffMoved    DiffKind = "moved"    // A group in both versions at a different position
+)
+
+// PatternChange is a single difference between two versions of a pattern.
+type PatternChange struct {
+	Kind       DiffKind
+	GroupLabel string // Label of the group changed, or empty for pattern details
+	Message    string
+}
+
+// DiffLine is one row of a side-by-side text diff. Old or New is empty when
+// the line was added or removed.
+type DiffLine struct {
+	Kind DiffKind
+	Old  string
+	New  string
+}
+
+// PatternDiff describes what changed from one version of a pattern to another,
+// both as a list of changes and as a side-by-side diff of the pattern text.
+type PatternDiff struct {
+	Changes []PatternChange
+	Lines   []DiffLine
+}
+
+// HasChanges reports whether the two versions differ.
+func (d PatternDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// DiffPatterns compares two versions of a pattern. Groups are aligned by
+// label and order: groups with the same label are matched in order, a group
+// found at a different position is reported as moved, and groups left over
+// between two matches are compared position by position. Stitch entries
+// within matched groups are aligned the same way by stitch.
+func DiffPatterns(old, new *domain.Pattern) PatternDiff {
+	var d PatternDiff
+	diffDetails(&d, old, new)
+	diffGroups(&d, old, new)
+
+	// Rounds are compared one line each, so a change to one round of a run
+	// doesn't show up as a change to the whole range.
+	oldLines := renderPatternLines(old, TextStyleNotation, false)
+	newLines := renderPatternLines(new, TextStyleNotation, false)
+	for _, p := range align(len(oldLines), len(newLines), func(i, j int) bool { return oldLines[i] == newLines[j] }) {
+		switch {
+		case p.old < 0:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffAdded, New: newLines[p.new]})
+		case p.new < 0:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffRemoved, Old: oldLines[p.old]})
+		case oldLines[p.old] == newLines[p.new]:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffSame, Old: oldLines[p.old], New: newLines[p.new]})
+		default:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffModified, Old: oldLines[p.old], New: newLines[p.new]})
+		}
+	}
+	return d
+}
+
+// diffDetails compares the pattern's own fields, sizes, pieces and colors.
+func diffDetails(d *PatternDiff, old, new *domain.Pattern) {
+	field := func(what, before, after string) {
+		if before != after {
+			d.Changes = append(d.Changes, PatternChange{
+				Kind:    DiffModified,
+				Message: fmt.Sprintf("%s changed from %s to %s", what, quoteOrNone(before), quoteOrNone(after)),
+			})
+		}
+	}
+
+	field("name", old.Name, new.Name)
+	if old.Description != new.Description {
+		d.Changes = append(d.Changes, PatternChange{Kind: DiffModified, Message: "description changed"})
+	}
+	field("pattern type", string(old.PatternType), string(new.PatternType))
+	field("hook size", old.HookSize, new.HookSize)
+	field("yarn weight", old.YarnWeight, new.YarnWeight)
+	field("difficulty", old.Difficulty, new.Difficulty)
+	field("sizes", strings.Join(old.Sizes, ", "), strings.Join(new.Sizes, ", "))
+	field("pieces", piecesText(old.Pieces), piecesText(new.Pieces))
+	field("colors", colorsText(old.Colors), colorsText(new.Colors))
+}
+
+// diffGroups aligns the groups of both versions and compares matched pairs.
+func diffGroups(d *PatternDiff, old, new *domain.Pattern) {
+	oldKeys := groupKeys(old)
+	newKeys := groupKeys(new)
+	pairs := align(len(oldKeys), len(newKeys), func(i, j int) bool { return oldKeys[i] == newKeys[j] })
+
+	// A group removed in one place and added in another was moved.
+	removedAt := make(map[string][]int)
+	for k, p := range pairs {
+		if p.new < 0 {
+			removedAt[oldKeys[p.old]] = append(removedAt[oldKeys[p.old]], k)
+		}
+	}
+	moved := make(map[int]bool)
+	for k, p := range pairs {
+		if p.old >= 0 {
+			continue
+		}
+		if candidates := removedAt[newKeys[p.new]]; len(candidates) > 0 {
+			removedAt[newKeys[p.new]] = candidates[1:]
+			pairs[k].old = pairs[candidates[0]].old
+			pairs[candidates[0]].old = -1 // Dropped below.
+			moved[k] = true
+		}
+	}
+
+	for k, p := range pairs {
+		switch {
+		case p.old < 0 && p.new < 0:
+			continue
+		case p.old < 0:
+			g := &new.InstructionGroups[p.new]
+			d.Changes = append(d.Changes, PatternChange{
+				Kind:       DiffAdded,
+				GroupLabel: g.Label,
+				Message:    fmt.Sprintf("added at position %d", p.new+1),
+			})
+		case p.new < 0:
+			g := &old.InstructionGroups[p.old]
+			d.Changes = append(d.Changes, PatternChange{
+				Kind:       DiffRemoved,
+				GroupLabel: g.Label,
+				Message:    fmt.Sprintf("removed from position %d", p.old+1),
+			})
+		default:
+			if moved[k] {
+				d.Changes = append(d.Changes, PatternChange{
+					Kind:       DiffMoved,
+					GroupLabel: new.InstructionGroups[p.new].Label,
+					Message:    fmt.Sprintf("moved from position %d to %d", p.old+1, p.new+1),
+				})
+			}
+			diffGroup(d, old, new, p.old, p.new)
+		}
+	}
+}
+
+// diffGroup compares a matched pair of groups and their stitch entries.
+func diffGroup(d *PatternDiff, old, new *domain.Pattern, oi, ni int) {
+	og := &old.InstructionGroups[oi]
+	ng := &new.InstructionGroups[ni]
+	changed := func(format string, args ...any) {
+		d.Changes = append(d.Changes, PatternChange{
+			Kind:       DiffModified,
+			GroupLabel: ng.Label,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if og.Label != ng.Label {
+		changed("renamed from %q", og.Label)
+	}
+	if pieceName(old, oi) != pieceName(new, ni) {
+		changed("moved from piece %s to %s", quoteOrNone(pieceName(old, oi)), quoteOrNone(pieceName(new, ni)))
+	}
+	if before, after := GradedText(og.RepeatCount, og.SizeRepeatCounts), GradedText(ng.RepeatCount, ng.SizeRepeatCounts); before != after {
+		changed("repeat changed from %s to %s", before, after)
+	}
+	if before, after := expectedText(og), expectedText(ng); before != after {
+		changed("expected count changed from %s to %s", quoteOrNone(before), quoteOrNone(after))
+	}
+	if og.Notes != ng.Notes {
+		changed("notes changed")
+	}
+
+	oldLookup := buildPatternStitchLookup(old.PatternStitches)
+	newLookup := buildPatternStitchLookup(new.PatternStitches)
+	oldEntries := make([]string, len(og.StitchEntries))
+	oldAbbrs := make([]string, len(og.StitchEntries))
+	for i := range og.StitchEntries {
+		oldEntries[i] = entryText(&og.StitchEntries[i], oldLookup, old.Colors)
+		oldAbbrs[i] = oldLookup[og.StitchEntries[i].PatternStitchID]
+	}
+	newEntries := make([]string, len(ng.StitchEntries))
+	newAbbrs := make([]string, len(ng.StitchEntries))
+	for j := range ng.StitchEntries {
+		newEntries[j] = entryText(&ng.StitchEntries[j], newLookup, new.Colors)
+		newAbbrs[j] = newLookup[ng.StitchEntries[j].PatternStitchID]
+	}
+
+	entriesChanged := false
+	for _, p := range align(len(oldAbbrs), len(newAbbrs), func(i, j int) bool { return oldAbbrs[i] == newAbbrs[j] }) {
+		switch {
+		case p.old < 0:
+			changed("stitch %d added: %s", p.new+1, newEntries[p.new])
+		case p.new < 0:
+			changed("stitch %d removed: %s", p.old+1, oldEntries[p.old])
+		case oldEntries[p.old] != newEntries[p.new]:
+			changed("stitch %d changed from %s to %s", p.new+1, oldEntries[p.old], newEntries[p.new])
+		default:
+			continue
+		}
+		entriesChanged = true
+	}
+
+	// Brackets only show up in the rendered stitches.
+	if !entriesChanged && renderNodes(buildEntryTree(og), oldLookup, nil) != renderNodes(buildEntryTree(ng), newLookup, nil) {
+		changed("repeat brackets changed")
+	}
+}
+
+// alignedPair pairs the index of an item in the older sequence with one in
+// the newer sequence. Either is -1 when the item is only on one side.
+type alignedPair struct {
+	old, new int
+}
+
+// align lines up two sequences the way a line diff does: the longest common
+// subsequence of items that are the same are matched, and the unmatched items
+// between two matches are paired by position. Items left over after pairing
+// appear on one side only.
+func align(n, m int, same func(i, j int) bool) []alignedPair {
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if same(i, j) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs []alignedPair
+	var gapOld, gapNew []int
+	flush := func() {
+		k := 0
+		for ; k < len(gapOld) && k < len(gapNew); k++ {
+			pairs = append(pairs, alignedPair{gapOld[k], gapNew[k]})
+		}
+		for _, i := range gapOld[k:] {
+			pairs = append(pairs, alignedPair{i, -1})
+		}
+		for _, j := range gapNew[k:] {
+			pairs = append(pairs, alignedPair{-1, j})
+		}
+		gapOld, gapNew = gapOld[:0], gapNew[:0]
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case same(i, j):
+			flush()
+			pairs = append(pairs, alignedPair{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			gapOld = append(gapOld, i)
+			i++
+		default:
+			gapNew = append(gapNew, j)
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		gapOld = append(gapOld, i)
+	}
+	for ; j < m; j++ {
+		gapNew = append(gapNew, j)
+	}
+	flush()
+	return pairs
+}
+
+// groupKeys returns the key groups are matched by: the label, qualified by
+// the piece name so that e.g. "Rnd 1" of the head and of an arm differ.
+func groupKeys(p *domain.Pattern) []string {
+	keys := make([]string, len(p.InstructionGroups))
+	for gi, g := range p.InstructionGroups {
+		keys[gi] = pieceName(p, gi) + "\x00" + g.Label
+	}
+	return keys
+}
+
+// pieceName returns the name of the piece the group at index gi belongs to.
+func pieceName(p *domain.Pattern, gi int) string {
+	if pc := p.PieceAt(gi); pc != nil {
+		return pc.Name
+	}
+	return ""
+}
+
+// entryText describes a stitch entry as it reads in pattern text, including
+// its repeat and any change of color.
+func entryText(e *domain.StitchEntry, lookup map[int64]string, colors []domain.PatternColor) string {
+	text := renderEntry(e, lookup)
+	if e.RepeatCount > 1 || len(e.SizeRepeatCounts) > 0 {
+		text += " ×" + GradedText(e.RepeatCount, e.SizeRepeatCounts)
+	}
+	if c := ColorAt(colors, e.ColorIndex); c != nil {
+		text = "with " + c.Label + ": " + text
+	}
+	return fmt.Sprintf("%q", text)
+}
+
+func expectedText(g *domain.InstructionGroup) string {
+	if g.ExpectedCount == nil {
+		return ""
+	}
+	return GradedText(*g.ExpectedCount, g.SizeExpectedCounts)
+}
+
+func piecesText(pieces []domain.PatternPiece) string {
+	parts := make([]string, len(pieces))
+	for i := range pieces {
+		parts[i] = renderPieceHeading(&pieces[i])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func colorsText(colors []domain.PatternColor) string {
+	parts := make([]string, len(colors))
+	for i, c := range colors {
+		parts[i] = strings.TrimSpace(strings.Join([]string{c.Label, c.Name, c.Hex}, " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// quoteOrNone quotes a value for a change message, or says "none" if empty.
+func quoteOrNone(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%q", v)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func diffTestPattern(groups ...domain.InstructionGroup) *domain.Pattern {
+	return &domain.Pattern{
+		Name:              "Coaster",
+		PatternType:       domain.PatternTypeRound,
+		PatternStitches:   testPatternStitches(),
+		InstructionGroups: groups,
+	}
+}
+
+func diffTestGroup(label string, entries ...domain.StitchEntry) domain.InstructionGroup {
+	return domain.InstructionGroup{Label: label, RepeatCount: 1, StitchEntries: entries}
+}
+
+func scEntry(count int) domain.StitchEntry {
+	return domain.StitchEntry{PatternStitchID: 1, Count: count, RepeatCount: 1}
+}
+
+func diffMessages(d PatternDiff) []string {
+	var messages []string
+	for _, c := range d.Changes {
+		msg := string(c.Kind) + ": "
+		if c.GroupLabel != "" {
+			msg += c.GroupLabel + " "
+		}
+		messages = append(messages, msg+c.Message)
+	}
+	return messages
+}
+
+func TestDiffPatterns_NoChanges(t *testing.T) {
+	p := diffTestPattern(diffTestGroup("Rnd 1", scEntry(6)))
+	d := DiffPatterns(p, p)
+	if d.HasChanges() {
+		t.Fatalf("expected no changes, got %v", diffMessages(d))
+	}
+	if len(d.Lines) != 1 || d.Lines[0].Kind != DiffSame {
+		t.Fatalf("expected one unchanged line, got %+v", d.Lines)
+	}
+}
+
+func TestDiffPatterns_DetailsAndEntries(t *testing.T) {
+	old := diffTestPattern(
+		diffTestGroup("Rnd 1", scEntry(6)),
+		diffTestGroup("Rnd 2", scEntry(6), domain.StitchEntry{PatternStitchID: 5, Count: 1, RepeatCount: 6}),
+	)
+	new := diffTestPattern(
+		diffTestGroup("Rnd 1", scEntry(8)),
+		diffTestGroup("Rnd 2", scEntry(6), domain.StitchEntry{PatternStitchID: 2, Count: 1, RepeatCount: 6}),
+	)
+	new.Name = "Round Coaster"
+	new.HookSize = "4mm"
+
+	got := diffMessages(DiffPatterns(old, new))
+	expected := []string{
+		`modified: name changed from "Coaster" to "Round Coaster"`,
+		`modified: hook size changed from none to "4mm"`,
+		`modified: Rnd 1 stitch 1 changed from "6 sc" to "8 sc"`,
+		`modified: Rnd 2 stitch 2 changed from "inc ×6" to "dc ×6"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected changes:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestDiffPatterns_GroupsAddedRemovedMoved(t *testing.T) {
+	old := diffTestPattern(
+		diffTestGroup("Rnd 1", scEntry(6)),
+		diffTestGroup("Rnd 2", scEntry(6)),
+		diffTestGroup("Border", scEntry(6)),
+		diffTestGroup("Rnd 3", scEntry(6)),
+	)
+	new := diffTestPattern(
+		diffTestGroup("Rnd 1", scEntry(6)),
+		diffTestGroup("Rnd 3", scEntry(6)),
+		diffTestGroup("Rnd 4", scEntry(6)),
+		diffTestGroup("Rnd 2", scEntry(6)),
+	)
+
+	got := diffMessages(DiffPatterns(old, new))
+	expected := []string{
+		"removed: Border removed from position 3",
+		"moved: Rnd 2 moved from position 2 to 4",
+		"added: Rnd 4 added at position 3",
+	}
+	for _, e := range expected {
+		if !slices.Contains(got, e) {
+			t.Errorf("expected change %q, got %v", e, got)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), got)
+	}
+}
+
+func TestDiffPatterns_TextLines(t *testing.T) {
+	old := diffTestPattern(
+		diffTestGroup("Rnd 1", scEntry(6)),
+		diffTestGroup("Rnd 2", scEntry(6)),
+	)
+	new := diffTestPattern(
+		diffTestGroup("Rnd 1", scEntry(6)),
+		diffTestGroup("Rnd 2", scEntry(12)),
+		diffTestGroup("Rnd 3", scEntry(12)),
+	)
+
+	d := DiffPatterns(old, new)
+	expected := []DiffLine{
+		{Kind: DiffSame, Old: "Rnd 1: 6 sc (6)", New: "Rnd 1: 6 sc (6)"},
+		{Kind: DiffModified, Old: "Rnd 2: 6 sc (6)", New: "Rnd 2: 12 sc (12)"},
+		{Kind: DiffAdded, New: "Rnd 3: 12 sc (12)"},
+	}
+	if len(d.Lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %+v", len(expected), d.Lines)
+	}
+	for i, e := range expected {
+		if d.Lines[i] != e {
+			t.Errorf("line %d: expected %+v, got %+v", i, e, d.Lines[i])
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+const (
+	// PatternDocumentFormat identifies a portable pattern document.
+	PatternDocumentFormat = "stitch-map-pattern"
+	// PatternDocumentVersion is the version of the document format written by
+	// this build. Documents with a newer version are rejected on import.
+	PatternDocumentVersion = 1
+
+	patternBundleDocument  = "pattern.json"
+	maxPatternDocumentSize = 60 * 1024 * 1024 // Room for a few embedded images
+)
+
+// PatternDocument is a portable, versioned JSON form of a pattern. It holds
+// no database IDs: entries refer to stitches by their index in Stitches, and
+// groups refer to pieces and colors by index, so a document can be imported
+// into any account.
+type PatternDocument struct {
+	Format     string          `json:"format"`
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Pattern    DocumentPattern `json:"pattern"`
+}
+
+// DocumentPattern holds a pattern's metadata and content.
+type DocumentPattern struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	PatternType string           `json:"pattern_type"`
+	HookSize    string           `json:"hook_size,omitempty"`
+	YarnWeight  string           `json:"yarn_weight,omitempty"`
+	Difficulty  string           `json:"difficulty,omitempty"`
+	Sizes       []string         `json:"sizes,omitempty"`
+	Pieces      []DocumentPiece  `json:"pieces,omitempty"`
+	Colors      []DocumentColor  `json:"colors,omitempty"`
+	Stitches    []DocumentStitch `json:"stitches"`
+	Groups      []DocumentGroup  `json:"groups"`
+}
+
+// DocumentStitch is a pattern's own copy of a stitch definition.
+type DocumentStitch struct {
+	Abbreviation   string `json:"abbreviation"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	Category       string `json:"category,omitempty"`
+	Consumes       int    `json:"consumes"`
+	Produces       int    `json:"produces"`
+	UKAbbreviation string `json:"uk_abbreviation,omitempty"`
+}
+
+// DocumentPiece is a separately made piece of the pattern.
+type DocumentPiece struct {
+	Name      string `json:"name"`
+	Notes     string `json:"notes,omitempty"`
+	MakeCount int    `json:"make_count"`
+}
+
+// DocumentColor is a yarn color in the pattern's palette.
+type DocumentColor struct {
+	Label string `json:"label"`
+	Name  string `json:"name,omitempty"`
+	Hex   string `json:"hex,omitempty"`
+}
+
+// DocumentGroup is an instruction group with its entries, repeat blocks and
+// images.
+type DocumentGroup struct {
+	Label              string          `json:"label"`
+	Piece              int             `json:"piece,omitempty"`
+	RepeatCount        int             `json:"repeat_count"`
+	SizeRepeatCounts   []int           `json:"size_repeat_counts,omitempty"`
+	ExpectedCount      *int            `json:"expected_count,omitempty"`
+	SizeExpectedCounts []int           `json:"size_expected_counts,omitempty"`
+	Notes              string          `json:"notes,omitempty"`
+	Entries            []DocumentEntry `json:"entries"`
+	Blocks             []DocumentBlock `json:"blocks,omitempty"`
+	Images             []DocumentImage `json:"images,omitempty"`
+}
+
+// DocumentEntry is a stitch entry. Stitch is an index into the pattern's
+// Stitches and Color an index into its Colors.
+type DocumentEntry struct {
+	Stitch           int    `json:"stitch"`
+	Count            int    `json:"count"`
+	SizeCounts       []int  `json:"size_counts,omitempty"`
+	IntoStitch       string `json:"into_stitch,omitempty"`
+	RepeatCount      int    `json:"repeat_count"`
+	SizeRepeatCounts []int  `json:"size_repeat_counts,omitempty"`
+	Color            *int   `json:"color,omitempty"`
+}
+
+// DocumentBlock is a repeat block over the group's entries, by index.
+type DocumentBlock struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	RepeatCount int    `json:"repeat_count"`
+	Bracket     string `json:"bracket,omitempty"`
+	IntoStitch  string `json:"into_stitch,omitempty"`
+}
+
+// DocumentImage is an image attached to a group. In a plain JSON document
+// the bytes are embedded as base64 in Data; in a zip bundle they are stored
+// as a separate file named by Path.
+type DocumentImage struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data,omitempty"`
+	Path        string `json:"path,omitempty"`
+}
+
+// ImportResult describes a pattern created from a document.
+type ImportResult struct {
+	Pattern *domain.Pattern
+	// UnlinkedStitches lists the abbreviations of stitches that have no
+	// match in the importer's stitch library. They are still imported as
+	// pattern stitches, just without a library link.
+	UnlinkedStitches []string
+}
+
+// NewPatternDocument builds a document from a pattern. Images are not
+// included; see ImageService.AddDocumentImages.
+func NewPatternDocument(pattern *domain.Pattern) *PatternDocument {
+	doc := &PatternDocument{
+		Format:     PatternDocumentFormat,
+		Version:    PatternDocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Pattern: DocumentPattern{
+			Name:        pattern.Name,
+			Description: pattern.Description,
+			PatternType: string(pattern.PatternType),
+			HookSize:    pattern.HookSize,
+			YarnWeight:  pattern.YarnWeight,
+			Difficulty:  pattern.Difficulty,
+			Sizes:       pattern.Sizes,
+			Stitches:    []DocumentStitch{},
+			Groups:      []DocumentGroup{},
+		},
+	}
+	dp := &doc.Pattern
+
+	for _, pc := range pattern.Pieces {
+		dp.Pieces = append(dp.Pieces, DocumentPiece{Name: pc.Name, Notes: pc.Notes, MakeCount: pc.MakeCount})
+	}
+	for _, c := range pattern.Colors {
+		dp.Colors = append(dp.Colors, DocumentColor{Label: c.Label, Name: c.Name, Hex: c.Hex})
+	}
+
+	stitchIndex := make(map[int64]int, len(pattern.PatternStitches))
+	for i, ps := range pattern.PatternStitches {
+		stitchIndex[ps.ID] = i
+		dp.Stitches = append(dp.Stitches, DocumentStitch{
+			Abbreviation:   ps.Abbreviation,
+			Name:           ps.Name,
+			Description:    ps.Description,
+			Category:       ps.Category,
+			Consumes:       ps.Consumes,
+			Produces:       ps.Produces,
+			UKAbbreviation: ps.UKAbbreviation,
+		})
+	}
+
+	for _, g := range pattern.InstructionGroups {
+		dg := DocumentGroup{
+			Label:              g.Label,
+			RepeatCount:        g.RepeatCount,
+			SizeRepeatCounts:   g.SizeRepeatCounts,
+			ExpectedCount:      g.ExpectedCount,
+			SizeExpectedCounts: g.SizeExpectedCounts,
+			Notes:              g.Notes,
+			Entries:            []DocumentEntry{},
+		}
+		if len(pattern.Pieces) > 0 {
+			dg.Piece = g.PieceIndex
+		}
+		for _, e := range g.StitchEntries {
+			dg.Entries = append(dg.Entries, DocumentEntry{
+				Stitch:           stitchIndex[e.PatternStitchID],
+				Count:            e.Count,
+				SizeCounts:       e.SizeCounts,
+				IntoStitch:       e.IntoStitch,
+				RepeatCount:      e.RepeatCount,
+				SizeRepeatCounts: e.SizeRepeatCounts,
+				Color:            e.ColorIndex,
+			})
+		}
+		for _, b := range g.RepeatBlocks {
+			dg.Blocks = append(dg.Blocks, DocumentBlock{
+				Start:       b.StartEntry,
+				End:         b.EndEntry,
+				RepeatCount: b.RepeatCount,
+				Bracket:     string(b.Bracket),
+				IntoStitch:  b.IntoStitch,
+			})
+		}
+		dp.Groups = append(dp.Groups, dg)
+	}
+
+	return doc
+}
+
+// ToPattern converts the document into a new, unsaved pattern. Pattern
+// stitches get temporary IDs 1..n that entries refer to; they are replaced
+// with real IDs when the pattern is created. Structural problems, such as an
+// entry referring to a stitch that isn't in the document, are reported as
+// ErrInvalidInput; everything else is left to pattern validation.
+func (d *PatternDocument) ToPattern() (*domain.Pattern, error) {
+	if d.Format != PatternDocumentFormat {
+		return nil, fmt.Errorf("%w: not a pattern document", domain.ErrInvalidInput)
+	}
+	if d.Version < 1 || d.Version > PatternDocumentVersion {
+		return nil, fmt.Errorf("%w: unsupported pattern document version %d", domain.ErrInvalidInput, d.Version)
+	}
+
+	dp := &d.Pattern
+	pattern := &domain.Pattern{
+		Name:        dp.Name,
+		Description: dp.Description,
+		PatternType: domain.PatternType(dp.PatternType),
+		HookSize:    dp.HookSize,
+		YarnWeight:  dp.YarnWeight,
+		Difficulty:  dp.Difficulty,
+		Sizes:       dp.Sizes,
+	}
+
+	for _, pc := range dp.Pieces {
+		pattern.Pieces = append(pattern.Pieces, domain.PatternPiece{Name: pc.Name, Notes: pc.Notes, MakeCount: pc.MakeCount})
+	}
+	for _, c := range dp.Colors {
+		pattern.Colors = append(pattern.Colors, domain.PatternColor{Label: c.Label, Name: c.Name, Hex: c.Hex})
+	}
+
+	for i, s := range dp.Stitches {
+		if strings.TrimSpace(s.Abbreviation) == "" {
+			return nil, fmt.Errorf("%w: stitch %d has no abbreviation", domain.ErrInvalidInput, i+1)
+		}
+		if len(s.Abbreviation) > 20 || len(s.UKAbbreviation) > 20 || len(s.Name) > 100 || len(s.Description) > 1000 || len(s.Category) > 50 {
+			return nil, fmt.Errorf("%w: stitch %q has fields that are too long", domain.ErrInvalidInput, s.Abbreviation)
+		}
+		name := s.Name
+		if name == "" {
+			name = s.Abbreviation
+		}
+		if err := validateStitchArithmetic(s.Consumes, s.Produces); err != nil {
+			return nil, fmt.Errorf("stitch %q: %w", s.Abbreviation, err)
+		}
+		pattern.PatternStitches = append(pattern.PatternStitches, domain.PatternStitch{
+			ID:             int64(i + 1),
+			Abbreviation:   s.Abbreviation,
+			Name:           name,
+			Description:    s.Description,
+			Category:       s.Category,
+			Consumes:       s.Consumes,
+			Produces:       s.Produces,
+			UKAbbreviation: s.UKAbbreviation,
+		})
+	}
+
+	for gi, dg := range dp.Groups {
+		g := domain.InstructionGroup{
+			SortOrder:          gi,
+			PieceIndex:         dg.Piece,
+			Label:              dg.Label,
+			RepeatCount:        dg.RepeatCount,
+			SizeRepeatCounts:   dg.SizeRepeatCounts,
+			ExpectedCount:      dg.ExpectedCount,
+			SizeExpectedCounts: dg.SizeExpectedCounts,
+			Notes:              dg.Notes,
+		}
+		for ei, de := range dg.Entries {
+			if de.Stitch < 0 || de.Stitch >= len(dp.Stitches) {
+				return nil, fmt.Errorf("%w: group %d entry %d refers to stitch %d, which is not in the document", domain.ErrInvalidInput, gi+1, ei+1, de.Stitch)
+			}
+			g.StitchEntries = append(g.StitchEntries, domain.StitchEntry{
+				SortOrder:        ei,
+				PatternStitchID:  int64(de.Stitch + 1),
+				Count:            de.Count,
+				SizeCounts:       de.SizeCounts,
+				IntoStitch:       de.IntoStitch,
+				RepeatCount:      de.RepeatCount,
+				SizeRepeatCounts: de.SizeRepeatCounts,
+				ColorIndex:       de.Color,
+			})
+		}
+		for _, db := range dg.Blocks {
+			g.RepeatBlocks = append(g.RepeatBlocks, domain.RepeatBlock{
+				StartEntry:  db.Start,
+				EndEntry:    db.End,
+				RepeatCount: db.RepeatCount,
+				Bracket:     domain.BracketStyle(db.Bracket),
+				IntoStitch:  db.IntoStitch,
+			})
+		}
+		pattern.InstructionGroups = append(pattern.InstructionGroups, g)
+	}
+
+	return pattern, nil
+}
+
+// ReadPatternDocument parses a document from either plain JSON or a zip
+// bundle written by WritePatternBundle.
+func ReadPatternDocument(data []byte) (*PatternDocument, error) {
+	if len(data) > maxPatternDocumentSize {
+		return nil, fmt.Errorf("%w: pattern file is too large", domain.ErrInvalidInput)
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return readPatternBundle(data)
+	}
+
+	var doc PatternDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: pattern file is not valid JSON: %v", domain.ErrInvalidInput, err)
+	}
+	return &doc, nil
+}
+
+func readPatternBundle(data []byte) (*PatternDocument, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: pattern bundle is not a valid zip file", domain.ErrInvalidInput)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	readFile := func(name string) ([]byte, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: pattern bundle is missing %s", domain.ErrInvalidInput, name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: open %s: %v", domain.ErrInvalidInput, name, err)
+		}
+		defer rc.Close()
+		// Guard against zip bombs: never read past the overall size limit.
+		b, err := io.ReadAll(io.LimitReader(rc, maxPatternDocumentSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: read %s: %v", domain.ErrInvalidInput, name, err)
+		}
+		if len(b) > maxPatternDocumentSize {
+			return nil, fmt.Errorf("%w: %s is too large", domain.ErrInvalidInput, name)
+		}
+		return b, nil
+	}
+
+	raw, err := readFile(patternBundleDocument)
+	if err != nil {
+		return nil, err
+	}
+	var doc PatternDocument
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s is not valid JSON: %v", domain.ErrInvalidInput, patternBundleDocument, err)
+	}
+
+	for gi := range doc.Pattern.Groups {
+		for ii := range doc.Pattern.Groups[gi].Images {
+			img := &doc.Pattern.Groups[gi].Images[ii]
+			if img.Path == "" {
+				continue
+			}
+			if img.Data, err = readFile(img.Path); err != nil {
+				return nil, err
+			}
+			img.Path = ""
+		}
+	}
+
+	return &doc, nil
+}
+
+// WritePatternBundle writes the document as a zip bundle: pattern.json plus
+// one file per image under images/. The document's embedded image bytes are
+// moved into the bundle files.
+func WritePatternBundle(w io.Writer, doc *PatternDocument) error {
+	zw := zip.NewWriter(w)
+
+	bundled := *doc
+	bundled.Pattern.Groups = make([]DocumentGroup, len(doc.Pattern.Groups))
+	for gi, g := range doc.Pattern.Groups {
+		g.Images = append([]DocumentImage(nil), g.Images...)
+		for ii := range g.Images {
+			img := &g.Images[ii]
+			img.Path = fmt.Sprintf("images/%d-%d-%s", gi+1, ii+1, path.Base(img.Filename))
+			f, err := zw.Create(img.Path)
+			if err != nil {
+				return fmt.Errorf("create %s: %w", img.Path, err)
+			}
+			if _, err := f.Write(img.Data); err != nil {
+				return fmt.Errorf("write %s: %w", img.Path, err)
+			}
+			img.Data = nil
+		}
+		bundled.Pattern.Groups[gi] = g
+	}
+
+	f, err := zw.Create(patternBundleDocument)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", patternBundleDocument, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(&bundled); err != nil {
+		return fmt.Errorf("encode %s: %w", patternBundleDocument, err)
+	}
+
+	return zw.Close()
+}
+
+// Export returns a document for one of the user's patterns, without images.
+// Like duplicating, exporting a received snapshot is not allowed.
+func (s *PatternService) Export(ctx context.Context, userID int64, patternID int64) (*PatternDocument, error) {
+	pattern, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+	if pattern.IsReceivedSnapshot() {
+		return nil, fmt.Errorf("%w: cannot export a received pattern", domain.ErrPatternLocked)
+	}
+	return NewPatternDocument(pattern), nil
+}
+
+// Import creates a new pattern for the user from a document. The document's
+// stitches become the pattern's own stitches; each is linked to the stitch
+// with the same abbreviation in the user's library (custom stitches first,
+// then predefined) when there is one. Stitches without a match are kept but
+// left unlinked and listed in the result. Images are not imported; see
+// ImageService.ImportDocumentImages.
+func (s *PatternService) Import(ctx context.Context, userID int64, doc *PatternDocument) (*ImportResult, error) {
+	pattern, err := doc.ToPattern()
+	if err != nil {
+		return nil, err
+	}
+	pattern.UserID = userID
+
+	result := &ImportResult{Pattern: pattern}
+	for i := range pattern.PatternStitches {
+		ps := &pattern.PatternStitches[i]
+		stitch, err := s.findLibraryStitch(ctx, userID, ps.Abbreviation)
+		if err != nil {
+			return nil, err
+		}
+		if stitch == nil {
+			result.UnlinkedStitches = append(result.UnlinkedStitches, ps.Abbreviation)
+			continue
+		}
+		libID := stitch.ID
+		ps.LibraryStitchID = &libID
+	}
+
+	if err := s.createImported(ctx, pattern); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// createImported validates and creates a pattern that already carries its
+// own PatternStitches, with entries referring to them by temporary ID.
+func (s *PatternService) createImported(ctx context.Context, pattern *domain.Pattern) error {
+	if err := s.validate(pattern); err != nil {
+		return err
+	}
+	normalizeSizes(pattern)
+	normalizePieces(pattern)
+
+	if err := s.patterns.Create(ctx, pattern); err != nil {
+		return fmt.Errorf("create pattern: %w", err)
+	}
+	return nil
+}
+
+// findLibraryStitch returns the user's custom stitch or the predefined stitch
+// with the given abbreviation, or nil if there is neither.
+func (s *PatternService) findLibraryStitch(ctx context.Context, userID int64, abbreviation string) (*domain.Stitch, error) {
+	for _, owner := range []*int64{&userID, nil} {
+		stitch, err := s.stitches.GetByAbbreviation(ctx, abbreviation, owner)
+		if err == nil {
+			return stitch, nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("look up stitch %q: %w", abbreviation, err)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/service"
+)
+
+func TestPatternService_ExportImport_RoundTrip(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	authorID := seedUserForTest(t, db, "author@example.com")
+	importerID := seedUserForTest(t, db, "importer@example.com")
+	scID := seedStitchForTest(t, db)
+
+	// The author has a custom stitch the importer's library doesn't.
+	puff := &domain.Stitch{Abbreviation: "puff", Name: "Puff Stitch", Category: "custom", Consumes: 1, Produces: 1, IsCustom: true, UserID: &authorID}
+	if err := db.Stitches().Create(ctx, puff); err != nil {
+		t.Fatalf("create custom stitch: %v", err)
+	}
+
+	colorB := 1
+	original := &domain.Pattern{
+		UserID:      authorID,
+		Name:        "Bobble Hat",
+		PatternType: domain.PatternTypeRound,
+		HookSize:    "5mm",
+		Colors:      []domain.PatternColor{{Label: "A"}, {Label: "B", Hex: "#112233"}},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Round 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: scID, Count: 6, RepeatCount: 1},
+			}},
+			{Label: "Round 2", SortOrder: 1, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: scID, Count: 1, RepeatCount: 1},
+				{PatternStitchID: puff.ID, SortOrder: 1, Count: 1, RepeatCount: 1, ColorIndex: &colorB},
+			}, RepeatBlocks: []domain.RepeatBlock{
+				{StartEntry: 0, EndEntry: 1, RepeatCount: 6, Bracket: domain.BracketSquare},
+			}},
+		},
+	}
+	if err := svc.Create(ctx, original); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	doc, err := svc.Export(ctx, authorID, original.ID)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	// Round-trip through JSON as a real export would.
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	parsed, err := service.ReadPatternDocument(raw)
+	if err != nil {
+		t.Fatalf("ReadPatternDocument: %v", err)
+	}
+
+	result, err := svc.Import(ctx, importerID, parsed)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.UnlinkedStitches) != 1 || result.UnlinkedStitches[0] != "puff" {
+		t.Errorf("UnlinkedStitches = %v, want [puff]", result.UnlinkedStitches)
+	}
+
+	imported, err := svc.GetByID(ctx, result.Pattern.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if imported.ID == original.ID || imported.UserID != importerID {
+		t.Fatalf("imported pattern ID %d user %d, want a new pattern for user %d", imported.ID, imported.UserID, importerID)
+	}
+	if imported.Name != "Bobble Hat" || imported.HookSize != "5mm" || len(imported.Colors) != 2 {
+		t.Errorf("imported metadata = %q %q %d colors", imported.Name, imported.HookSize, len(imported.Colors))
+	}
+
+	byID := make(map[int64]domain.PatternStitch)
+	for _, ps := range imported.PatternStitches {
+		if ps.PatternID != imported.ID {
+			t.Errorf("pattern stitch %q belongs to pattern %d, want %d", ps.Abbreviation, ps.PatternID, imported.ID)
+		}
+		byID[ps.ID] = ps
+	}
+	g := imported.InstructionGroups[1]
+	if len(g.StitchEntries) != 2 || len(g.RepeatBlocks) != 1 {
+		t.Fatalf("round 2 has %d entries and %d blocks, want 2 and 1", len(g.StitchEntries), len(g.RepeatBlocks))
+	}
+	sc, puffPS := byID[g.StitchEntries[0].PatternStitchID], byID[g.StitchEntries[1].PatternStitchID]
+	if sc.Abbreviation != "sc" || sc.LibraryStitchID == nil || *sc.LibraryStitchID != scID {
+		t.Errorf("sc entry stitch = %+v, want sc linked to library stitch %d", sc, scID)
+	}
+	if puffPS.Abbreviation != "puff" || puffPS.LibraryStitchID != nil {
+		t.Errorf("puff entry stitch = %+v, want unlinked puff", puffPS)
+	}
+	if ci := g.StitchEntries[1].ColorIndex; ci == nil || *ci != 1 {
+		t.Errorf("puff entry color = %v, want 1", ci)
+	}
+	if g.RepeatBlocks[0].RepeatCount != 6 || g.RepeatBlocks[0].Bracket != domain.BracketSquare {
+		t.Errorf("repeat block = %+v", g.RepeatBlocks[0])
+	}
+}
+
+func TestPatternService_Export_OtherUser(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	ownerID := seedUserForTest(t, db, "owner@example.com")
+	otherID := seedUserForTest(t, db, "other@example.com")
+	scID := seedStitchForTest(t, db)
+
+	pattern := &domain.Pattern{
+		UserID:      ownerID,
+		Name:        "Private",
+		PatternType: domain.PatternTypeRow,
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Row 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{{PatternStitchID: scID, Count: 10, RepeatCount: 1}}},
+		},
+	}
+	if err := svc.Create(ctx, pattern); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := svc.Export(ctx, otherID, pattern.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Export by other user: err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestPatternService_Import_Invalid(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+	userID := seedUserForTest(t, db, "import@example.com")
+
+	valid := func() *service.PatternDocument {
+		return &service.PatternDocument{
+			Format:  service.PatternDocumentFormat,
+			Version: service.PatternDocumentVersion,
+			Pattern: service.DocumentPattern{
+				Name:        "Scarf",
+				PatternType: "row",
+				Stitches:    []service.DocumentStitch{{Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1}},
+				Groups: []service.DocumentGroup{
+					{Label: "Row 1", RepeatCount: 1, Entries: []service.DocumentEntry{{Stitch: 0, Count: 20, RepeatCount: 1}}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(doc *service.PatternDocument)
+	}{
+		{"wrong format", func(doc *service.PatternDocument) { doc.Format = "something-else" }},
+		{"newer version", func(doc *service.PatternDocument) { doc.Version = service.PatternDocumentVersion + 1 }},
+		{"missing stitch", func(doc *service.PatternDocument) { doc.Pattern.Groups[0].Entries[0].Stitch = 3 }},
+		{"no name", func(doc *service.PatternDocument) { doc.Pattern.Name = "" }},
+		{"zero count", func(doc *service.PatternDocument) { doc.Pattern.Groups[0].Entries[0].Count = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := valid()
+			tt.modify(doc)
+			if _, err := svc.Import(ctx, userID, doc); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("Import: err = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+
+	// The unmodified document imports, with its stitch left unlinked because
+	// this database has no stitch library.
+	result, err := svc.Import(ctx, userID, valid())
+	if err != nil {
+		t.Fatalf("Import valid: %v", err)
+	}
+	if len(result.UnlinkedStitches) != 1 {
+		t.Errorf("UnlinkedStitches = %v, want [sc]", result.UnlinkedStitches)
+	}
+}
+
+func TestWritePatternBundle_RoundTrip(t *testing.T) {
+	doc := &service.PatternDocument{
+		Format:  service.PatternDocumentFormat,
+		Version: service.PatternDocumentVersion,
+		Pattern: service.DocumentPattern{
+			Name: "Coaster",
+			Groups: []service.DocumentGroup{
+				{Label: "Round 1", Images: []service.DocumentImage{
+					{Filename: "chart.png", ContentType: "image/png", Data: []byte("png bytes")},
+				}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := service.WritePatternBundle(&buf, doc); err != nil {
+		t.Fatalf("WritePatternBundle: %v", err)
+	}
+	if len(doc.Pattern.Groups[0].Images[0].Data) == 0 {
+		t.Error("WritePatternBundle modified the document's image data")
+	}
+
+	parsed, err := service.ReadPatternDocument(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadPatternDocument: %v", err)
+	}
+	images := parsed.Pattern.Groups[0].Images
+	if len(images) != 1 || string(images[0].Data) != "png bytes" || images[0].Filename != "chart.png" {
+		t.Errorf("bundle images = %+v", images)
+	}
+}
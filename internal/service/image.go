@@ -2,9 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 )
@@ -12,18 +19,32 @@ import (
 const (
 	maxImageSize     = 10 * 1024 * 1024 // 10MB
 	maxImagesPerPart = 5
+
+	// Signed image URLs expire on the hour after next, so a preview's URLs
+	// stay the same, and cacheable, for an hour and last at least that long.
+	sharedImageURLPeriod = time.Hour
 )
 
 // ImageService orchestrates image uploads, retrieval, and deletion.
 type ImageService struct {
-	images   domain.PatternImageRepository
-	files    domain.FileStore
-	patterns domain.PatternRepository
+	images     domain.PatternImageRepository
+	files      domain.FileStore
+	patterns   domain.PatternRepository
+	signingKey []byte
+}
+
+// NewImageService creates a new ImageService. signingKey signs the image URLs
+// embedded in shared pattern previews; see SharedImageSigningKey.
+func NewImageService(images domain.PatternImageRepository, files domain.FileStore, patterns domain.PatternRepository, signingKey []byte) *ImageService {
+	return &ImageService{images: images, files: files, patterns: patterns, signingKey: signingKey}
 }
 
-// NewImageService creates a new ImageService.
-func NewImageService(images domain.PatternImageRepository, files domain.FileStore, patterns domain.PatternRepository) *ImageService {
-	return &ImageService{images: images, files: files, patterns: patterns}
+// SharedImageSigningKey derives the key that signs shared image URLs from the
+// app's JWT secret, so the secret isn't used directly for two purposes.
+func SharedImageSigningKey(jwtSecret string) []byte {
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte("shared-image-url"))
+	return mac.Sum(nil)
 }
 
 // Upload validates and stores an image for an instruction group.
@@ -100,6 +121,61 @@ func (s *ImageService) GetFile(ctx context.Context, userID, imageID int64) ([]by
 	return data, image.ContentType, nil
 }
 
+// GetPatternFile returns the bytes and content type of one of a pattern's
+// images, for viewers who can see the pattern without owning it. It returns
+// ErrNotFound if the image belongs to another pattern.
+func (s *ImageService) GetPatternFile(ctx context.Context, pattern *domain.Pattern, imageID int64) ([]byte, string, error) {
+	image, err := s.images.GetByID(ctx, imageID)
+	if err != nil {
+		return nil, "", fmt.Errorf("get image: %w", err)
+	}
+	if !slices.ContainsFunc(pattern.InstructionGroups, func(g domain.InstructionGroup) bool {
+		return g.ID == image.InstructionGroupID
+	}) {
+		return nil, "", domain.ErrNotFound
+	}
+
+	data, err := s.files.Get(ctx, image.StorageKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("get file: %w", err)
+	}
+
+	return data, image.ContentType, nil
+}
+
+// SharedImageURL returns a signed, time-limited URL for one of the images of
+// the pattern shared by token, which loads without logging in. Callers must
+// have checked that the viewer can open the share.
+func (s *ImageService) SharedImageURL(token string, imageID int64, now time.Time) string {
+	expires := now.Truncate(sharedImageURLPeriod).Add(2 * sharedImageURLPeriod).Unix()
+	q := url.Values{}
+	q.Set("token", token)
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("sig", s.sharedImageSignature(token, imageID, expires))
+	return "/shared-images/" + strconv.FormatInt(imageID, 10) + "?" + q.Encode()
+}
+
+// VerifySharedImageURL checks the signature of a URL from SharedImageURL and
+// that it hasn't expired, returning ErrUnauthorized otherwise.
+func (s *ImageService) VerifySharedImageURL(token string, imageID, expires int64, signature string, now time.Time) error {
+	want := s.sharedImageSignature(token, imageID, expires)
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return fmt.Errorf("%w: invalid image signature", domain.ErrUnauthorized)
+	}
+	if now.Unix() >= expires {
+		return fmt.Errorf("%w: image link has expired", domain.ErrUnauthorized)
+	}
+	return nil
+}
+
+// sharedImageSignature is the HMAC-SHA256 of an image ID, share token and
+// expiry. The prefix keeps it from matching anything else signed with the key.
+func (s *ImageService) sharedImageSignature(token string, imageID, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "shared-image:%d:%s:%d", imageID, token, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
 // Delete removes an image and its stored bytes after ownership check.
 func (s *ImageService) Delete(ctx context.Context, userID, imageID int64) error {
 	ownerID, err := s.images.GetOwnerUserID(ctx, imageID)
@@ -147,6 +223,103 @@ func (s *ImageService) ListByPattern(ctx context.Context, pattern *domain.Patter
 	return result, nil
 }
 
+// ImageFile is an image's metadata together with its bytes.
+type ImageFile struct {
+	Image domain.PatternImage
+	Data  []byte
+}
+
+// LoadPatternImages returns the images of every group of a pattern with
+// their bytes from the file store, keyed by instruction group ID.
+func (s *ImageService) LoadPatternImages(ctx context.Context, pattern *domain.Pattern) (map[int64][]ImageFile, error) {
+	byGroup, err := s.ListByPattern(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int64][]ImageFile, len(byGroup))
+	for groupID, images := range byGroup {
+		for _, img := range images {
+			data, err := s.files.Get(ctx, img.StorageKey)
+			if err != nil {
+				return nil, fmt.Errorf("get file for image %d: %w", img.ID, err)
+			}
+			result[groupID] = append(result[groupID], ImageFile{Image: img, Data: data})
+		}
+	}
+	return result, nil
+}
+
+// AddDocumentImages embeds the images of each of the pattern's groups into
+// the matching group of an exported document.
+func (s *ImageService) AddDocumentImages(ctx context.Context, pattern *domain.Pattern, doc *PatternDocument) error {
+	files, err := s.LoadPatternImages(ctx, pattern)
+	if err != nil {
+		return err
+	}
+	for gi, g := range pattern.InstructionGroups {
+		if gi >= len(doc.Pattern.Groups) {
+			break
+		}
+		for _, f := range files[g.ID] {
+			doc.Pattern.Groups[gi].Images = append(doc.Pattern.Groups[gi].Images, DocumentImage{
+				Filename:    f.Image.Filename,
+				ContentType: f.Image.ContentType,
+				Data:        f.Data,
+			})
+		}
+	}
+	return nil
+}
+
+// ValidateDocumentImages checks a document's images against the same limits
+// as uploads, so an import can be rejected before anything is created.
+func ValidateDocumentImages(doc *PatternDocument) error {
+	for gi, g := range doc.Pattern.Groups {
+		if len(g.Images) > maxImagesPerPart {
+			return fmt.Errorf("%w: group %d has more than %d images", domain.ErrInvalidInput, gi+1, maxImagesPerPart)
+		}
+		for ii, img := range g.Images {
+			if img.ContentType != "image/jpeg" && img.ContentType != "image/png" {
+				return fmt.Errorf("%w: group %d image %d: only JPEG and PNG images are accepted", domain.ErrInvalidInput, gi+1, ii+1)
+			}
+			if len(img.Data) == 0 {
+				return fmt.Errorf("%w: group %d image %d has no data", domain.ErrInvalidInput, gi+1, ii+1)
+			}
+			if len(img.Data) > maxImageSize {
+				return fmt.Errorf("%w: group %d image %d exceeds 10MB limit", domain.ErrInvalidInput, gi+1, ii+1)
+			}
+		}
+	}
+	return nil
+}
+
+// ImportDocumentImages uploads a document's images to the matching groups of
+// a pattern that was just imported from it. If any image fails, the ones
+// already uploaded are removed again, so the caller can discard the pattern
+// without leaving stored files behind.
+func (s *ImageService) ImportDocumentImages(ctx context.Context, userID int64, pattern *domain.Pattern, doc *PatternDocument) error {
+	var uploaded []*domain.PatternImage
+	for gi, g := range doc.Pattern.Groups {
+		if gi >= len(pattern.InstructionGroups) {
+			break
+		}
+		groupID := pattern.InstructionGroups[gi].ID
+		for _, img := range g.Images {
+			image, err := s.Upload(ctx, userID, groupID, img.Filename, img.ContentType, img.Data)
+			if err != nil {
+				// Best-effort cleanup of the images uploaded so far.
+				for _, u := range uploaded {
+					s.files.Delete(ctx, u.StorageKey)
+					s.images.Delete(ctx, u.ID)
+				}
+				return fmt.Errorf("import image %q: %w", img.Filename, err)
+			}
+			uploaded = append(uploaded, image)
+		}
+	}
+	return nil
+}
+
 func generateStorageKey() (string, error) {
 	b := make([]byte, 16)
 	if _, err := rand.Read(b); err != nil {
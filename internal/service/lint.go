@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// LintCode identifies the kind of problem a lint warning describes.
+type LintCode string
+
+const (
+	LintExpectedCount     LintCode = "expected_count"      // ExpectedCount disagrees with the computed count
+	LintOverConsumed      LintCode = "over_consumed"       // Round works into more stitches than the previous one made
+	LintUnusedStitch      LintCode = "unused_stitch"       // Pattern stitch not referenced by any entry
+	LintEmptyGroup        LintCode = "empty_group"         // Group has no stitch entries
+	LintMagicRingDecrease LintCode = "magic_ring_decrease" // Decrease worked in a magic ring round
+	LintCountJump         LintCode = "count_jump"          // Stitch count more than doubles or halves
+)
+
+// LintWarning is a single problem found in a pattern. Unlike validation
+// errors, warnings don't prevent saving.
+type LintWarning struct {
+	Code       LintCode
+	GroupIndex int // 0-based index of the group, or -1 for pattern-wide warnings
+	GroupLabel string
+	Message    string
+}
+
+// LintReport collects all warnings for a pattern, in group order.
+type LintReport struct {
+	Warnings []LintWarning
+}
+
+// HasWarnings reports whether the lint pass found anything.
+func (r LintReport) HasWarnings() bool {
+	return len(r.Warnings) > 0
+}
+
+// ForGroup returns the warnings attached to the group at index gi.
+func (r LintReport) ForGroup(gi int) []LintWarning {
+	var warnings []LintWarning
+	for _, w := range r.Warnings {
+		if w.GroupIndex == gi {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// LintPattern checks a pattern for likely mistakes: expected counts that
+// disagree with the stitches, rounds that work into more stitches than the
+// previous round made, unused pattern stitches, empty groups, decreases in a
+// magic ring round, and suspicious jumps in stitch count. It reports every
+// problem found rather than stopping at the first. Graded patterns are
+// checked once per size, and count warnings name the size they apply to.
+func LintPattern(pattern *domain.Pattern) LintReport {
+	var report LintReport
+	if pattern == nil {
+		return report
+	}
+
+	if pattern.IsGraded() {
+		for si, size := range pattern.Sizes {
+			lintGroups(&report, pattern.ForSize(si), size, si == 0)
+		}
+	} else {
+		lintGroups(&report, pattern, "", true)
+	}
+
+	used := make(map[int64]bool)
+	for _, g := range pattern.InstructionGroups {
+		for _, e := range g.StitchEntries {
+			used[e.PatternStitchID] = true
+		}
+	}
+	for _, ps := range pattern.PatternStitches {
+		if !used[ps.ID] {
+			report.Warnings = append(report.Warnings, LintWarning{
+				Code:       LintUnusedStitch,
+				GroupIndex: -1,
+				Message:    fmt.Sprintf("stitch %q is not used in any group", ps.Abbreviation),
+			})
+		}
+	}
+
+	return report
+}
+
+// lintGroups adds the per-group warnings for a single-size pattern. When size
+// is set, warnings that depend on stitch counts are suffixed with it. Checks
+// that don't depend on counts only run when first is set, so graded patterns
+// report them once.
+func lintGroups(report *LintReport, pattern *domain.Pattern, size string, first bool) {
+	byID := buildPatternStitchByID(pattern.PatternStitches)
+	arithmetic := ComputeStitchArithmetic(pattern)
+
+	add := func(code LintCode, gi int, format string, args ...any) {
+		w := LintWarning{
+			Code:       code,
+			GroupIndex: gi,
+			GroupLabel: pattern.InstructionGroups[gi].Label,
+			Message:    fmt.Sprintf(format, args...),
+		}
+		if size != "" && code != LintEmptyGroup && code != LintMagicRingDecrease {
+			w.Message += fmt.Sprintf(" (size %s)", size)
+		}
+		report.Warnings = append(report.Warnings, w)
+	}
+
+	for gi := range pattern.InstructionGroups {
+		g := &pattern.InstructionGroups[gi]
+		ga := arithmetic[gi]
+
+		if len(g.StitchEntries) == 0 {
+			if first {
+				add(LintEmptyGroup, gi, "has no stitches")
+			}
+			continue
+		}
+
+		if g.ExpectedCount != nil && *g.ExpectedCount != ga.Produced {
+			add(LintExpectedCount, gi, "expected count is %d but the stitches make %d", *g.ExpectedCount, ga.Produced)
+		}
+
+		// A round worked into a foundation chain may work into the same
+		// chain more than once, so only later rounds are held to it.
+		if ga.HasPrevious && !ga.PreviousFoundation && ga.Consumed > ga.Previous {
+			add(LintOverConsumed, gi, "works into %d stitches but the previous round only has %d", ga.Consumed, ga.Previous)
+		}
+		if g.RepeatCount > 1 && ga.Consumed > ga.Produced {
+			add(LintOverConsumed, gi, "each repeat works into %d stitches but the repeat before it only makes %d", ga.Consumed, ga.Produced)
+		}
+
+		if ga.HasPrevious && !ga.PreviousFoundation && (ga.Produced > ga.Previous*2 || ga.Produced*2 < ga.Previous) {
+			add(LintCountJump, gi, "stitch count jumps from %d to %d", ga.Previous, ga.Produced)
+		}
+
+		if first {
+			if decrease := magicRingDecrease(g, byID); decrease != "" {
+				add(LintMagicRingDecrease, gi, "decreases (%s) in a magic ring round", decrease)
+			}
+		}
+	}
+}
+
+// magicRingDecrease returns the abbreviation of the first decrease in a group
+// that is worked into a magic ring, or empty if there is none.
+func magicRingDecrease(g *domain.InstructionGroup, byID map[int64]domain.PatternStitch) string {
+	hasRing := false
+	for _, e := range g.StitchEntries {
+		ps := byID[e.PatternStitchID]
+		if strings.EqualFold(ps.Abbreviation, "MR") || strings.EqualFold(ps.Name, "Magic Ring") {
+			hasRing = true
+			break
+		}
+	}
+	if !hasRing {
+		return ""
+	}
+
+	for _, e := range g.StitchEntries {
+		ps := byID[e.PatternStitchID]
+		if ps.Category == "decrease" || (ps.Consumes > ps.Produces && ps.Produces > 0) {
+			return ps.Abbreviation
+		}
+	}
+	return ""
+}
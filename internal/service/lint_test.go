@@ -0,0 +1,174 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func lintCodes(report LintReport) []LintCode {
+	var codes []LintCode
+	for _, w := range report.Warnings {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func hasLintCode(report LintReport, code LintCode, gi int) bool {
+	for _, w := range report.Warnings {
+		if w.Code == code && w.GroupIndex == gi {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLintPattern_CleanPattern(t *testing.T) {
+	twelve := 12
+	pattern := &domain.Pattern{
+		PatternStitches: []domain.PatternStitch{
+			{ID: 1, Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1},
+			{ID: 4, Abbreviation: "MR", Name: "Magic Ring", Consumes: 0, Produces: 0},
+			{ID: 5, Abbreviation: "inc", Name: "Increase", Consumes: 1, Produces: 2},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Round 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+			}},
+			{Label: "Round 2", RepeatCount: 1, ExpectedCount: &twelve, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 5, Count: 1, RepeatCount: 6},
+			}},
+			{Label: "Rounds 3-5", RepeatCount: 3, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 12, RepeatCount: 1},
+			}},
+		},
+	}
+
+	report := LintPattern(pattern)
+	if report.HasWarnings() {
+		t.Fatalf("expected no warnings, got %v", lintCodes(report))
+	}
+}
+
+func TestLintPattern_Warnings(t *testing.T) {
+	ten := 10
+	pattern := &domain.Pattern{
+		PatternStitches: []domain.PatternStitch{
+			{ID: 1, Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1},
+			{ID: 2, Abbreviation: "dc", Name: "Double Crochet", Consumes: 1, Produces: 1},
+			{ID: 4, Abbreviation: "MR", Name: "Magic Ring", Consumes: 0, Produces: 0},
+			{ID: 5, Abbreviation: "inc", Name: "Increase", Consumes: 1, Produces: 2},
+			{ID: 7, Abbreviation: "sc2tog", Name: "Single Crochet 2 Together", Category: "decrease", Consumes: 2, Produces: 1},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			// MR, 6 sc, sc2tog: decrease in a magic ring round.
+			{Label: "Round 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+				{PatternStitchID: 7, Count: 1, RepeatCount: 1},
+			}},
+			// Works into 9 of 7 stitches, makes 18 (more than double), expected 10.
+			{Label: "Round 2", RepeatCount: 1, ExpectedCount: &ten, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 5, Count: 1, RepeatCount: 9},
+			}},
+			{Label: "Stuff", RepeatCount: 1},
+		},
+	}
+
+	report := LintPattern(pattern)
+
+	checks := []struct {
+		code LintCode
+		gi   int
+	}{
+		{LintMagicRingDecrease, 0},
+		{LintOverConsumed, 1},
+		{LintCountJump, 1},
+		{LintExpectedCount, 1},
+		{LintEmptyGroup, 2},
+		{LintUnusedStitch, -1},
+	}
+	for _, c := range checks {
+		if !hasLintCode(report, c.code, c.gi) {
+			t.Fatalf("expected %s warning for group %d, got %v", c.code, c.gi, lintCodes(report))
+		}
+	}
+	if len(report.ForGroup(1)) != 3 {
+		t.Fatalf("expected 3 warnings for Round 2, got %d", len(report.ForGroup(1)))
+	}
+	if report.Warnings[0].GroupLabel != "Round 1" {
+		t.Fatalf("expected first warning for Round 1, got %q", report.Warnings[0].GroupLabel)
+	}
+}
+
+func TestLintPattern_StartingGroups(t *testing.T) {
+	sc := domain.PatternStitch{ID: 1, Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1}
+	dc := domain.PatternStitch{ID: 2, Abbreviation: "dc", Name: "Double Crochet", Consumes: 1, Produces: 1}
+	ch := domain.PatternStitch{ID: 3, Abbreviation: "ch", Name: "Chain", Consumes: 0, Produces: 1}
+	mr := domain.PatternStitch{ID: 4, Abbreviation: "MR", Name: "Magic Ring", Consumes: 0, Produces: 0}
+	inc := domain.PatternStitch{ID: 5, Abbreviation: "inc", Name: "Increase", Consumes: 1, Produces: 2}
+
+	tests := []struct {
+		name     string
+		stitches []domain.PatternStitch
+		groups   []domain.InstructionGroup
+	}{
+		{
+			name:     "magic ring on its own",
+			stitches: []domain.PatternStitch{sc, mr, inc},
+			groups: []domain.InstructionGroup{
+				{Label: "Start", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+				}},
+				{Label: "Rnd 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+				}},
+				{Label: "Rnd 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 5, Count: 1, RepeatCount: 6},
+				}},
+			},
+		},
+		{
+			name:     "chain foundation",
+			stitches: []domain.PatternStitch{dc, ch, inc},
+			groups: []domain.InstructionGroup{
+				{Label: "Foundation", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 3, Count: 4, RepeatCount: 1},
+				}},
+				{Label: "Rnd 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 2, Count: 12, RepeatCount: 1},
+				}},
+				{Label: "Rnd 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 5, Count: 1, RepeatCount: 12},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := LintPattern(&domain.Pattern{PatternStitches: tt.stitches, InstructionGroups: tt.groups})
+			if report.HasWarnings() {
+				t.Fatalf("expected no warnings, got %v", report.Warnings)
+			}
+		})
+	}
+}
+
+func TestLintPattern_RepeatedDecreaseRound(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: []domain.PatternStitch{
+			{ID: 7, Abbreviation: "sc2tog", Name: "Single Crochet 2 Together", Category: "decrease", Consumes: 2, Produces: 1},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rounds 1-3", RepeatCount: 3, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 7, Count: 1, RepeatCount: 6},
+			}},
+		},
+	}
+
+	report := LintPattern(pattern)
+	if !hasLintCode(report, LintOverConsumed, 0) {
+		t.Fatalf("expected a repeated decrease round to be flagged, got %v", lintCodes(report))
+	}
+}
@@ -0,0 +1,508 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// NotationError is a problem in a line of pattern text. Line and Column are
+// 1-based, and Column counts characters rather than bytes.
+type NotationError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e NotationError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+var (
+	notationLabelRepeat = regexp.MustCompile(`^(.*\S)\s*\([×xX]\s*(\d+)\)$`)
+	notationExpected    = regexp.MustCompile(`\s*\((\d+)(?:\s*sts?)?\)$`)
+	notationPiece       = regexp.MustCompile(`^(.*?)\s*\(make (\d+)\)$`)
+	notationColor       = regexp.MustCompile(`^with ([^\s:,]+):\s*`)
+	notationCount       = regexp.MustCompile(`^(\d+)\s+`)
+	notationBlockRepeat = regexp.MustCompile(`(?:^|\s)[×xX]\s*(\d+)$`)
+	notationStarRepeat  = regexp.MustCompile(`(?:^|\s)repeat (\d+) times$`)
+	notationRepeatFrom  = regexp.MustCompile(`,\s*repeat from\s*$`)
+	notationTimes       = regexp.MustCompile(`^(\d+) times$`)
+	notationGraded      = regexp.MustCompile(`^\(\d+(?:,\s*\d+)+\)`)
+)
+
+// ParsePatternText parses pattern text written in standard US crochet
+// notation, such as "Rnd 3: *sc, inc* repeat 6 times (18)", into a pattern's
+// instruction groups. It reads the notation RenderPatternText writes (except
+// graded sizes): group repeats "(×3)" or round ranges "Rnds 8–15", stitch
+// counts "6 sc", into-stitch text, repeat blocks in (), [] and *…*, entry
+// repeats "*sc, repeat from * 3 times", color changes "with B:" and piece
+// headings "Arm (make 2)". A trailing "(N)"
+// is kept as the expected count when it differs from the count the stitches
+// make.
+//
+// Abbreviations are resolved against stitches, preferring custom stitches
+// over predefined ones, and become the pattern's own PatternStitches with
+// temporary IDs 1..n. Every line that can't be parsed is reported; the
+// pattern is only usable when there are no errors.
+func ParsePatternText(text string, stitches []domain.Stitch) (*domain.Pattern, []NotationError) {
+	p := newNotationParser(stitches)
+	for i, line := range strings.Split(text, "\n") {
+		p.parseLine(i+1, strings.TrimRight(line, "\r"))
+	}
+
+	if len(p.errs) == 0 && len(p.pattern.InstructionGroups) == 0 {
+		p.errs = append(p.errs, NotationError{Line: 1, Column: 1, Message: "no instructions found"})
+	}
+
+	p.pattern.PatternType = domain.PatternTypeRound
+	if len(p.pattern.InstructionGroups) > 0 && strings.HasPrefix(strings.ToLower(p.pattern.InstructionGroups[0].Label), "row") {
+		p.pattern.PatternType = domain.PatternTypeRow
+	}
+	return p.pattern, p.errs
+}
+
+// ParseText parses pattern text for the user, resolving abbreviations against
+// the predefined stitches and the user's custom stitches.
+func (s *PatternService) ParseText(ctx context.Context, userID int64, text string) (*domain.Pattern, []NotationError, error) {
+	predefined, err := s.stitches.ListPredefined(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list predefined stitches: %w", err)
+	}
+	custom, err := s.stitches.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list custom stitches: %w", err)
+	}
+
+	pattern, errs := ParsePatternText(text, append(predefined, custom...))
+	pattern.UserID = userID
+	return pattern, errs, nil
+}
+
+// ImportText creates a new pattern for the user from pattern text. If the
+// text has errors nothing is created and they are returned instead.
+func (s *PatternService) ImportText(ctx context.Context, userID int64, name, text string) (*domain.Pattern, []NotationError, error) {
+	pattern, errs, err := s.ParseText(ctx, userID, text)
+	if err != nil || len(errs) > 0 {
+		return nil, errs, err
+	}
+	pattern.Name = name
+	if err := s.createImported(ctx, pattern); err != nil {
+		return nil, nil, err
+	}
+	return pattern, nil, nil
+}
+
+type notationParser struct {
+	stitches map[string]domain.Stitch // Keyed by lower-case abbreviation
+	abbrs    []string                 // Lower-case abbreviations, longest first
+	pattern  *domain.Pattern
+	psIDs    map[int64]int64 // Library stitch ID -> temporary PatternStitch ID
+	colors   map[string]int  // Lower-case color label -> index into Pattern.Colors
+
+	line  int    // Current line number
+	src   string // Current line
+	color *int   // Color to give the next entry, from a "with B:" marker
+	errs  []NotationError
+}
+
+func newNotationParser(stitches []domain.Stitch) *notationParser {
+	p := &notationParser{
+		stitches: make(map[string]domain.Stitch),
+		pattern:  &domain.Pattern{},
+		psIDs:    make(map[int64]int64),
+		colors:   make(map[string]int),
+	}
+	for _, st := range stitches {
+		key := strings.ToLower(st.Abbreviation)
+		if existing, ok := p.stitches[key]; ok {
+			// Custom stitches shadow predefined ones; otherwise the first wins.
+			if existing.IsCustom || !st.IsCustom {
+				continue
+			}
+		} else {
+			p.abbrs = append(p.abbrs, key)
+		}
+		p.stitches[key] = st
+	}
+	sort.Slice(p.abbrs, func(i, j int) bool {
+		if len(p.abbrs[i]) != len(p.abbrs[j]) {
+			return len(p.abbrs[i]) > len(p.abbrs[j])
+		}
+		return p.abbrs[i] < p.abbrs[j]
+	})
+	return p
+}
+
+// fail records an error at byte offset off of the current line.
+func (p *notationParser) fail(off int, format string, args ...any) {
+	off = max(0, min(off, len(p.src)))
+	p.errs = append(p.errs, NotationError{
+		Line:    p.line,
+		Column:  utf8.RuneCountInString(p.src[:off]) + 1,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *notationParser) parseLine(n int, line string) {
+	p.line, p.src, p.color = n, line, nil
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	text := strings.TrimSpace(line)
+	if text == "" {
+		return
+	}
+
+	colon := strings.Index(text, ":")
+	if colon == -1 {
+		p.parsePieceHeading(start, text)
+		return
+	}
+
+	g := domain.InstructionGroup{
+		SortOrder:   len(p.pattern.InstructionGroups),
+		Label:       strings.TrimSpace(text[:colon]),
+		RepeatCount: 1,
+	}
+	if m := notationLabelRepeat.FindStringSubmatch(g.Label); m != nil {
+		g.Label = m[1]
+		g.RepeatCount, _ = strconv.Atoi(m[2])
+	} else if r, ok := parseRoundRange(g.Label); ok {
+		// "Rnds 8–15" is worked once for each round in the range.
+		g.RepeatCount = r.count()
+	}
+	if g.Label == "" {
+		p.fail(start, "missing round or row label before %q", ":")
+		return
+	}
+	if len(p.pattern.Pieces) > 0 {
+		g.PieceIndex = len(p.pattern.Pieces) - 1
+	}
+
+	bodyOff := start + colon + 1
+	body := text[colon+1:]
+	var expected *int
+	if loc := notationExpected.FindStringSubmatchIndex(body); loc != nil {
+		n, _ := strconv.Atoi(body[loc[2]:loc[3]])
+		expected = &n
+		body = body[:loc[0]]
+	}
+
+	if !p.parseItems(&g, body, bodyOff) {
+		return
+	}
+	if expected != nil && len(g.StitchEntries) > 0 && *expected != groupProducedCount(&g, buildPatternStitchByID(p.pattern.PatternStitches)) {
+		g.ExpectedCount = expected
+	}
+	p.pattern.InstructionGroups = append(p.pattern.InstructionGroups, g)
+}
+
+// parsePieceHeading handles a line without a colon, which starts a new piece.
+func (p *notationParser) parsePieceHeading(off int, text string) {
+	if strings.Contains(text, ",") {
+		p.fail(off, "expected a round or row like %q", "Rnd 1: 6 sc in MR (6)")
+		return
+	}
+	if len(p.pattern.InstructionGroups) > 0 && len(p.pattern.Pieces) == 0 {
+		p.fail(off, "piece heading %q comes after rounds that don't belong to a piece", text)
+		return
+	}
+
+	piece := domain.PatternPiece{SortOrder: len(p.pattern.Pieces), Name: text, MakeCount: 1}
+	if m := notationPiece.FindStringSubmatch(text); m != nil {
+		piece.Name = m[1]
+		piece.MakeCount, _ = strconv.Atoi(m[2])
+	}
+	p.pattern.Pieces = append(p.pattern.Pieces, piece)
+}
+
+// parseItems parses a comma-separated list of entries and blocks into g. off
+// is the byte offset of s in the current line. It reports whether parsing
+// succeeded; on failure an error has been recorded.
+func (p *notationParser) parseItems(g *domain.InstructionGroup, s string, off int) bool {
+	spans, ok := p.splitItems(s, off)
+	if !ok {
+		return false
+	}
+	for _, sp := range spans {
+		item := s[sp[0]:sp[1]]
+		trimmed := strings.TrimSpace(item)
+		itemOff := off + sp[0] + strings.Index(item, trimmed)
+		if trimmed == "" {
+			if strings.TrimSpace(s) == "" {
+				return true
+			}
+			p.fail(off+sp[0], "missing stitch between commas")
+			return false
+		}
+		if !p.parseItem(g, trimmed, itemOff) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitItems splits s at commas that aren't inside brackets or a *…* pair,
+// returning the byte spans of the items.
+func (p *notationParser) splitItems(s string, off int) ([][2]int, bool) {
+	var spans [][2]int
+	var stack []int // Offsets of open brackets
+	star := -1      // Offset of an unclosed '*', or -1
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[':
+			stack = append(stack, i)
+		case ')', ']':
+			if len(stack) == 0 || s[stack[len(stack)-1]] != matchingBracket(s[i]) {
+				p.fail(off+i, "unexpected %q", string(s[i]))
+				return nil, false
+			}
+			stack = stack[:len(stack)-1]
+		case '*':
+			if len(stack) == 0 {
+				if star == -1 {
+					star = i
+				} else {
+					star = -1
+				}
+			}
+		case ',':
+			if len(stack) == 0 && star == -1 {
+				spans = append(spans, [2]int{start, i})
+				start = i + 1
+			}
+		}
+	}
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+		p.fail(off+open, "%q is never closed", string(s[open]))
+		return nil, false
+	}
+	if star != -1 {
+		p.fail(off+star, "%q has no matching %q", "*", "*")
+		return nil, false
+	}
+	return append(spans, [2]int{start, len(s)}), true
+}
+
+func matchingBracket(c byte) byte {
+	if c == ')' {
+		return '('
+	}
+	return '['
+}
+
+// parseItem parses one entry or block. s is trimmed and starts at byte
+// offset off of the current line.
+func (p *notationParser) parseItem(g *domain.InstructionGroup, s string, off int) bool {
+	if m := notationColor.FindStringSubmatch(s); m != nil {
+		ci, ok := p.colors[strings.ToLower(m[1])]
+		if !ok {
+			ci = len(p.pattern.Colors)
+			p.colors[strings.ToLower(m[1])] = ci
+			p.pattern.Colors = append(p.pattern.Colors, domain.PatternColor{SortOrder: ci, Label: m[1]})
+		}
+		p.color = &ci
+		off += len(m[0])
+		s = s[len(m[0]):]
+		if s == "" {
+			p.fail(off, "missing stitch after color change")
+			return false
+		}
+	}
+
+	switch s[0] {
+	case '(', '[':
+		return p.parseBracketBlock(g, s, off)
+	case '*':
+		return p.parseStarItem(g, s, off)
+	}
+
+	e, ok := p.parseEntry(s, off)
+	if !ok {
+		return false
+	}
+	e.SortOrder = len(g.StitchEntries)
+	g.StitchEntries = append(g.StitchEntries, e)
+	return true
+}
+
+// parseBracketBlock parses "(sc, inc) x3" or "[2 dc, ch 2, 2 dc] in next sp".
+func (p *notationParser) parseBracketBlock(g *domain.InstructionGroup, s string, off int) bool {
+	end := closingBracket(s)
+	block := domain.RepeatBlock{RepeatCount: 1, Bracket: domain.BracketParen}
+	if s[0] == '[' {
+		block.Bracket = domain.BracketSquare
+	}
+
+	tail := strings.TrimSpace(s[end+1:])
+	if m := notationBlockRepeat.FindStringSubmatchIndex(tail); m != nil {
+		block.RepeatCount, _ = strconv.Atoi(tail[m[2]:m[3]])
+		tail = strings.TrimSpace(tail[:m[0]])
+	}
+	block.IntoStitch = tail
+
+	return p.parseBlock(g, block, s[1:end], off+1)
+}
+
+// closingBracket returns the index of the bracket that closes the one at
+// s[0]. splitItems has already checked that brackets are balanced.
+func closingBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s) - 1
+}
+
+// parseStarItem parses "*sc, inc* repeat 6 times" as a block, and
+// "*sc, repeat from * 3 times" as a repeated entry.
+func (p *notationParser) parseStarItem(g *domain.InstructionGroup, s string, off int) bool {
+	end := strings.Index(s[1:], "*") + 1
+	inner := s[1:end]
+	tail := strings.TrimSpace(s[end+1:])
+
+	if loc := notationRepeatFrom.FindStringIndex(inner); loc != nil {
+		m := notationTimes.FindStringSubmatch(tail)
+		if m == nil {
+			p.fail(off+end+1, "expected %q after %q", "N times", "repeat from *")
+			return false
+		}
+		repeat, _ := strconv.Atoi(m[1])
+		inner = inner[:loc[0]]
+
+		// A single stitch repeats as an entry; several become a block.
+		spans, ok := p.splitItems(inner, off+1)
+		if !ok {
+			return false
+		}
+		if len(spans) == 1 && !strings.ContainsAny(inner, "([*") {
+			e, ok := p.parseEntry(strings.TrimSpace(inner), off+1+strings.Index(inner, strings.TrimSpace(inner)))
+			if !ok {
+				return false
+			}
+			e.RepeatCount = repeat
+			e.SortOrder = len(g.StitchEntries)
+			g.StitchEntries = append(g.StitchEntries, e)
+			return true
+		}
+		return p.parseBlock(g, domain.RepeatBlock{RepeatCount: repeat, Bracket: domain.BracketAsterisk}, inner, off+1)
+	}
+
+	block := domain.RepeatBlock{RepeatCount: 1, Bracket: domain.BracketAsterisk}
+	if m := notationStarRepeat.FindStringSubmatchIndex(tail); m != nil {
+		block.RepeatCount, _ = strconv.Atoi(tail[m[2]:m[3]])
+		tail = strings.TrimSpace(tail[:m[0]])
+	}
+	block.IntoStitch = tail
+	return p.parseBlock(g, block, inner, off+1)
+}
+
+// parseBlock parses the items inside a block and adds the block over them.
+func (p *notationParser) parseBlock(g *domain.InstructionGroup, block domain.RepeatBlock, inner string, off int) bool {
+	block.StartEntry = len(g.StitchEntries)
+	if strings.TrimSpace(inner) == "" {
+		p.fail(off, "empty repeat")
+		return false
+	}
+	if !p.parseItems(g, inner, off) {
+		return false
+	}
+	block.EndEntry = len(g.StitchEntries) - 1
+	g.RepeatBlocks = append(g.RepeatBlocks, block)
+	return true
+}
+
+// parseEntry parses a single stitch entry such as "sc", "6 sc", "ch 3" or
+// "2 dc in next st".
+func (p *notationParser) parseEntry(s string, off int) (domain.StitchEntry, bool) {
+	e := domain.StitchEntry{Count: 1, RepeatCount: 1}
+	rest := s
+	if m := notationCount.FindStringSubmatch(rest); m != nil {
+		e.Count, _ = strconv.Atoi(m[1])
+		rest = rest[len(m[0]):]
+	}
+	restOff := off + len(s) - len(rest)
+	if graded := notationGraded.FindString(rest); graded != "" {
+		p.fail(off, "graded sizes like %q can't be imported from text", s[:restOff-off]+graded)
+		return e, false
+	}
+
+	stitch, n := p.matchStitch(rest)
+	if n == 0 {
+		word, _, _ := strings.Cut(rest, " ")
+		p.fail(restOff, "unknown stitch %q", word)
+		return e, false
+	}
+
+	into := strings.TrimSpace(rest[n:])
+	intoOff := restOff + len(rest) - len(into)
+	if m := notationCount.FindStringSubmatch(into + " "); m != nil && len(s) == len(rest) {
+		// A count after the stitch, as in "ch 3".
+		after := strings.TrimSpace(into[len(m[1]):])
+		if notationGraded.MatchString(after) {
+			p.fail(intoOff, "graded sizes like %q can't be imported from text", rest[:n]+" "+m[1]+" "+after)
+			return e, false
+		}
+		e.Count, _ = strconv.Atoi(m[1])
+		into = after
+	} else if into != "" && into[0] >= '0' && into[0] <= '9' {
+		p.fail(intoOff, "unexpected number after %q", rest[:n])
+		return e, false
+	}
+	e.IntoStitch = into
+	e.PatternStitchID = p.patternStitchID(stitch)
+	e.ColorIndex = p.color
+	p.color = nil
+	return e, true
+}
+
+// matchStitch finds the longest known abbreviation at the start of s that is
+// followed by the end of s or a space. It returns the stitch and the length
+// of the match, which is 0 if nothing matched.
+func (p *notationParser) matchStitch(s string) (domain.Stitch, int) {
+	lower := strings.ToLower(s)
+	for _, abbr := range p.abbrs {
+		if strings.HasPrefix(lower, abbr) && (len(lower) == len(abbr) || lower[len(abbr)] == ' ') {
+			return p.stitches[abbr], len(abbr)
+		}
+	}
+	return domain.Stitch{}, 0
+}
+
+// patternStitchID returns the temporary ID of the pattern's copy of a library
+// stitch, adding the copy on first use.
+func (p *notationParser) patternStitchID(st domain.Stitch) int64 {
+	if id, ok := p.psIDs[st.ID]; ok {
+		return id
+	}
+	id := int64(len(p.pattern.PatternStitches) + 1)
+	libID := st.ID
+	p.pattern.PatternStitches = append(p.pattern.PatternStitches, domain.PatternStitch{
+		ID:              id,
+		Abbreviation:    st.Abbreviation,
+		Name:            st.Name,
+		Description:     st.Description,
+		Category:        st.Category,
+		Consumes:        st.Consumes,
+		Produces:        st.Produces,
+		UKAbbreviation:  st.UKAbbreviation,
+		LibraryStitchID: &libID,
+	})
+	p.psIDs[st.ID] = id
+	return id
+}
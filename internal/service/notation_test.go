@@ -0,0 +1,176 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func notationTestLibrary() []domain.Stitch {
+	return []domain.Stitch{
+		{ID: 11, Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1},
+		{ID: 12, Abbreviation: "dc", Name: "Double Crochet", Consumes: 1, Produces: 1},
+		{ID: 13, Abbreviation: "inc", Name: "Increase", Consumes: 1, Produces: 2},
+		{ID: 14, Abbreviation: "MR", Name: "Magic Ring", Consumes: 0, Produces: 0},
+		{ID: 15, Abbreviation: "ch", Name: "Chain", Consumes: 0, Produces: 1},
+		{ID: 16, Abbreviation: "sl st", Name: "Slip Stitch", Consumes: 1, Produces: 0},
+		{ID: 17, Abbreviation: "sc2tog", Name: "Single Crochet 2 Together", Consumes: 2, Produces: 1},
+	}
+}
+
+func TestParsePatternText_RoundTrip(t *testing.T) {
+	text := `Head
+Rnd 1: MR, 6 sc in MR (6)
+Rnd 2: 6 inc (12)
+Rnd 3: *sc, inc* repeat 6 times (18)
+Rnds 4-6 (×3): 18 sc (18)
+Rnd 7: (sc, sc2tog) x6 (12)
+Rnd 8: [2 dc, 2 ch, 2 dc] in next sp, *sc, repeat from * 3 times (20)
+Rnd 9: with B: 6 sc, with A: 6 sc, with B: sl st (14)
+Rnd 10:
+
+Arm (make 2)
+Rnd 1: ch, 5 sc (6)`
+
+	pattern, errs := ParsePatternText(text, notationTestLibrary())
+	if len(errs) > 0 {
+		t.Fatalf("ParsePatternText errors: %v", errs)
+	}
+
+	if got := RenderPatternText(pattern); got != text {
+		t.Errorf("round trip mismatch.\ngot:\n%s\nwant:\n%s", got, text)
+	}
+
+	if len(pattern.Pieces) != 2 || pattern.Pieces[1].Name != "Arm" || pattern.Pieces[1].MakeCount != 2 {
+		t.Errorf("pieces = %+v", pattern.Pieces)
+	}
+	if len(pattern.Colors) != 2 || pattern.Colors[0].Label != "B" || pattern.Colors[1].Label != "A" {
+		t.Errorf("colors = %+v", pattern.Colors)
+	}
+	if pattern.PatternType != domain.PatternTypeRound {
+		t.Errorf("PatternType = %q, want round", pattern.PatternType)
+	}
+
+	// Rnd 8 says 20 but makes 9, so the stated count is kept.
+	rnd8 := pattern.InstructionGroups[5]
+	if rnd8.ExpectedCount == nil || *rnd8.ExpectedCount != 20 {
+		t.Errorf("Rnd 8 expected count = %v, want 20", rnd8.ExpectedCount)
+	}
+	if pattern.InstructionGroups[2].ExpectedCount != nil {
+		t.Errorf("Rnd 3 expected count = %d, want nil since it matches", *pattern.InstructionGroups[2].ExpectedCount)
+	}
+
+	// Each library stitch is copied once, linked to the library.
+	seen := make(map[string]bool)
+	for _, ps := range pattern.PatternStitches {
+		if seen[ps.Abbreviation] {
+			t.Errorf("stitch %q copied more than once", ps.Abbreviation)
+		}
+		seen[ps.Abbreviation] = true
+		if ps.LibraryStitchID == nil {
+			t.Errorf("stitch %q has no library link", ps.Abbreviation)
+		}
+	}
+}
+
+func TestParsePatternText_Structure(t *testing.T) {
+	pattern, errs := ParsePatternText("Rnd 3: *sc, inc* repeat 6 times (18)", notationTestLibrary())
+	if len(errs) > 0 {
+		t.Fatalf("ParsePatternText errors: %v", errs)
+	}
+	if len(pattern.InstructionGroups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(pattern.InstructionGroups))
+	}
+
+	g := pattern.InstructionGroups[0]
+	if g.Label != "Rnd 3" || g.RepeatCount != 1 {
+		t.Errorf("group = %q ×%d", g.Label, g.RepeatCount)
+	}
+	if len(g.StitchEntries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(g.StitchEntries))
+	}
+	want := domain.RepeatBlock{StartEntry: 0, EndEntry: 1, RepeatCount: 6, Bracket: domain.BracketAsterisk}
+	if len(g.RepeatBlocks) != 1 || g.RepeatBlocks[0] != want {
+		t.Errorf("blocks = %+v, want [%+v]", g.RepeatBlocks, want)
+	}
+	if got := GroupProducedCount(&g, pattern.PatternStitches); got != 18 {
+		t.Errorf("produced = %d, want 18", got)
+	}
+}
+
+func TestParsePatternText_CommonVariants(t *testing.T) {
+	pattern, errs := ParsePatternText("Row 1: ch 3, SC in next st, 2 dc (18 sts)", notationTestLibrary())
+	if len(errs) > 0 {
+		t.Fatalf("ParsePatternText errors: %v", errs)
+	}
+	if pattern.PatternType != domain.PatternTypeRow {
+		t.Errorf("PatternType = %q, want row", pattern.PatternType)
+	}
+
+	entries := pattern.InstructionGroups[0].StitchEntries
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Count != 3 {
+		t.Errorf("ch count = %d, want 3", entries[0].Count)
+	}
+	if entries[1].IntoStitch != "in next st" {
+		t.Errorf("sc into = %q, want %q", entries[1].IntoStitch, "in next st")
+	}
+	if g := pattern.InstructionGroups[0]; g.ExpectedCount == nil || *g.ExpectedCount != 18 {
+		t.Errorf("expected count = %v, want 18", g.ExpectedCount)
+	}
+}
+
+func TestParsePatternText_CustomStitchShadowsPredefined(t *testing.T) {
+	userID := int64(7)
+	library := append(notationTestLibrary(), domain.Stitch{ID: 99, Abbreviation: "inc", Name: "My Increase", Consumes: 1, Produces: 3, IsCustom: true, UserID: &userID})
+
+	pattern, errs := ParsePatternText("Rnd 1: inc", library)
+	if len(errs) > 0 {
+		t.Fatalf("ParsePatternText errors: %v", errs)
+	}
+	if ps := pattern.PatternStitches[0]; ps.LibraryStitchID == nil || *ps.LibraryStitchID != 99 {
+		t.Errorf("inc linked to %v, want custom stitch 99", ps.LibraryStitchID)
+	}
+}
+
+func TestParsePatternText_Errors(t *testing.T) {
+	text := `Rnd 1: 6 sc in MR (6)
+Rnd 2: sc, bogus, inc
+Rnd 3: (sc, inc x6
+Rnd 4: sc 12 (14, 16)
+sc, inc
+Rnd 5: *sc, inc repeat 6 times
+Rnd 6: 12 (14, 16) sc`
+
+	_, errs := ParsePatternText(text, notationTestLibrary())
+
+	want := []NotationError{
+		{Line: 2, Column: 12, Message: `unknown stitch "bogus"`},
+		{Line: 3, Column: 8},
+		{Line: 4, Column: 11},
+		{Line: 5, Column: 1},
+		{Line: 6, Column: 8},
+		{Line: 7, Column: 8, Message: `graded sizes like "12 (14, 16)" can't be imported from text`},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		got := errs[i]
+		if got.Line != w.Line || got.Column != w.Column {
+			t.Errorf("error %d at %d:%d (%s), want %d:%d", i, got.Line, got.Column, got.Message, w.Line, w.Column)
+		}
+		if w.Message != "" && got.Message != w.Message {
+			t.Errorf("error %d message = %q, want %q", i, got.Message, w.Message)
+		}
+	}
+}
+
+func TestParsePatternText_Empty(t *testing.T) {
+	_, errs := ParsePatternText("\n  \n", notationTestLibrary())
+	if len(errs) != 1 || errs[0].Message != "no instructions found" {
+		t.Errorf("errs = %v, want no instructions found", errs)
+	}
+}
@@ -3,26 +3,37 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 )
 
 // PatternService handles pattern CRUD and validation.
 type PatternService struct {
-	patterns domain.PatternRepository
-	stitches domain.StitchRepository
+	patterns     domain.PatternRepository
+	stitches     domain.StitchRepository
+	versions     domain.PatternVersionRepository
+	tags         domain.TagRepository
+	collections  domain.CollectionRepository
+	keepVersions int
 }
 
-// NewPatternService creates a new PatternService.
-func NewPatternService(patterns domain.PatternRepository, stitches domain.StitchRepository) *PatternService {
-	return &PatternService{patterns: patterns, stitches: stitches}
+// NewPatternService creates a new PatternService. Each update snapshots the
+// pattern's previous content as a version; keepVersions limits how many
+// versions are kept per pattern, with 0 keeping every version.
+func NewPatternService(patterns domain.PatternRepository, stitches domain.StitchRepository, versions domain.PatternVersionRepository, tags domain.TagRepository, collections domain.CollectionRepository, keepVersions int) *PatternService {
+	return &PatternService{patterns: patterns, stitches: stitches, versions: versions, tags: tags, collections: collections, keepVersions: keepVersions}
 }
 
-// Create creates a new pattern with validation.
+// Create creates a new pattern with validation. Groups left without a label
+// are numbered as rounds or rows.
 func (s *PatternService) Create(ctx context.Context, pattern *domain.Pattern) error {
+	numberGroupLabels(pattern)
 	if err := s.validate(pattern); err != nil {
 		return err
 	}
+	normalizeSizes(pattern)
+	normalizePieces(pattern)
 
 	if err := s.resolvePatternStitches(ctx, pattern); err != nil {
 		return err
@@ -54,22 +65,31 @@ func (s *PatternService) ListSharedWithUser(ctx context.Context, userID int64) (
 	return s.patterns.ListSharedWithUser(ctx, userID)
 }
 
-// ListSummaryByUser returns lightweight pattern summaries for a user's own patterns.
-func (s *PatternService) ListSummaryByUser(ctx context.Context, userID int64) ([]domain.PatternSummary, error) {
-	return s.patterns.ListSummaryByUser(ctx, userID)
+// ListSummaryByUser returns a page of lightweight pattern summaries for a
+// user's own patterns, most recently updated first.
+func (s *PatternService) ListSummaryByUser(ctx context.Context, userID int64, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	return s.patterns.ListSummaryByUser(ctx, userID, page)
 }
 
-// ListSummarySharedWithUser returns lightweight pattern summaries for patterns shared with a user.
-func (s *PatternService) ListSummarySharedWithUser(ctx context.Context, userID int64) ([]domain.PatternSummary, error) {
-	return s.patterns.ListSummarySharedWithUser(ctx, userID)
+// ListSummarySharedWithUser returns a page of lightweight pattern summaries
+// for patterns shared with a user, most recently received first.
+func (s *PatternService) ListSummarySharedWithUser(ctx context.Context, userID int64, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	return s.patterns.ListSummarySharedWithUser(ctx, userID, page)
 }
 
-// SearchSummaryByUser returns filtered and sorted pattern summaries for a user's own patterns.
-func (s *PatternService) SearchSummaryByUser(ctx context.Context, userID int64, filter domain.PatternFilter) ([]domain.PatternSummary, error) {
-	return s.patterns.SearchSummaryByUser(ctx, userID, filter)
+// SearchSummaryByUser returns a page of filtered and sorted pattern summaries for a user's own patterns.
+// "uses:" terms in the filter's query, e.g. "popcorn hat uses: puff", restrict
+// the results to patterns using those stitches.
+func (s *PatternService) SearchSummaryByUser(ctx context.Context, userID int64, filter domain.PatternFilter, page domain.PatternPage) (domain.PatternSummaryPage, error) {
+	var uses []string
+	filter.Query, uses = parseSearchQuery(filter.Query)
+	filter.Uses = append(slices.Clip(filter.Uses), uses...)
+	return s.patterns.SearchSummaryByUser(ctx, userID, filter, page)
 }
 
-// Update updates a pattern with validation and ownership check.
+// Update updates a pattern with validation and ownership check. The previous
+// content is kept as a version first. Groups left without a label are
+// numbered as rounds or rows.
 func (s *PatternService) Update(ctx context.Context, userID int64, pattern *domain.Pattern) error {
 	existing, err := s.patterns.GetByID(ctx, pattern.ID)
 	if err != nil {
@@ -78,29 +98,32 @@ func (s *PatternService) Update(ctx context.Context, userID int64, pattern *doma
 	if existing.UserID != userID {
 		return domain.ErrUnauthorized
 	}
-	if existing.SharedFromUserID != nil {
+	if existing.IsReceivedSnapshot() {
 		return domain.ErrPatternLocked
 	}
 	if existing.Locked {
 		return domain.ErrPatternLocked
 	}
 
+	numberGroupLabels(pattern)
 	if err := s.validate(pattern); err != nil {
 		return err
 	}
+	normalizeSizes(pattern)
+	normalizePieces(pattern)
 
 	if err := s.resolvePatternStitches(ctx, pattern); err != nil {
 		return err
 	}
 
-	if err := s.patterns.Update(ctx, pattern); err != nil {
+	if err := s.patterns.UpdateWithVersion(ctx, pattern, versionOf(existing), s.keepVersions); err != nil {
 		return fmt.Errorf("update pattern: %w", err)
 	}
 	return nil
 }
 
 // Delete deletes a pattern with ownership check.
-// Received patterns (SharedFromUserID != nil) cannot be deleted.
+// Received snapshots cannot be deleted.
 func (s *PatternService) Delete(ctx context.Context, userID int64, id int64) error {
 	existing, err := s.patterns.GetByID(ctx, id)
 	if err != nil {
@@ -109,7 +132,7 @@ func (s *PatternService) Delete(ctx context.Context, userID int64, id int64) err
 	if existing.UserID != userID {
 		return domain.ErrUnauthorized
 	}
-	if existing.SharedFromUserID != nil {
+	if existing.IsReceivedSnapshot() {
 		return domain.ErrPatternLocked
 	}
 	if existing.Locked {
@@ -119,8 +142,8 @@ func (s *PatternService) Delete(ctx context.Context, userID int64, id int64) err
 	return s.patterns.Delete(ctx, id)
 }
 
-// Duplicate creates a copy of a pattern for a user.
-// Received patterns (SharedFromUserID != nil) cannot be duplicated.
+// Duplicate creates a copy of a pattern for a user, with the pattern's tags.
+// Received snapshots cannot be duplicated.
 func (s *PatternService) Duplicate(ctx context.Context, userID int64, id int64, newUserID int64) (*domain.Pattern, error) {
 	existing, err := s.patterns.GetByID(ctx, id)
 	if err != nil {
@@ -129,28 +152,31 @@ func (s *PatternService) Duplicate(ctx context.Context, userID int64, id int64,
 	if existing.UserID != userID {
 		return nil, domain.ErrUnauthorized
 	}
-	if existing.SharedFromUserID != nil {
+	if existing.IsReceivedSnapshot() {
 		return nil, fmt.Errorf("%w: cannot duplicate a received pattern", domain.ErrPatternLocked)
 	}
 	return s.patterns.Duplicate(ctx, id, newUserID)
 }
 
-// StitchCount computes the total number of individual stitches in a pattern,
-// accounting for stitch counts, stitch repeats, and group repeats.
+// StitchCount computes the total number of individual stitches worked in a
+// pattern, accounting for stitch counts, stitch repeats, group repeats, and
+// how many of each piece are made. Unlike the per-round counts, which are
+// stitches produced, an inc or a cluster counts as one stitch worked.
 func StitchCount(pattern *domain.Pattern) int {
 	total := 0
-	for _, g := range pattern.InstructionGroups {
-		total += GroupStitchCount(&g) * g.RepeatCount
+	for gi, g := range pattern.InstructionGroups {
+		total += GroupStitchCount(&g) * g.RepeatCount * pattern.MakeCount(gi)
 	}
 	return total
 }
 
 // GroupStitchCount computes the total stitches in a single instruction group
-// (one iteration, not multiplied by group repeat).
+// (one iteration, not multiplied by group repeat). Entries inside repeat
+// blocks are multiplied by the repeat counts of every enclosing block.
 func GroupStitchCount(g *domain.InstructionGroup) int {
 	count := 0
-	for _, e := range g.StitchEntries {
-		count += e.Count * e.RepeatCount
+	for i, e := range g.StitchEntries {
+		count += e.Count * e.RepeatCount * g.BlockMultiplier(i)
 	}
 	return count
 }
@@ -183,6 +209,9 @@ func (s *PatternService) resolvePatternStitches(ctx context.Context, pattern *do
 				Name:            stitch.Name,
 				Description:     stitch.Description,
 				Category:        stitch.Category,
+				Consumes:        stitch.Consumes,
+				Produces:        stitch.Produces,
+				UKAbbreviation:  stitch.UKAbbreviation,
 				LibraryStitchID: &libID,
 			})
 		}
@@ -230,6 +259,16 @@ func (s *PatternService) validate(pattern *domain.Pattern) error {
 		return fmt.Errorf("%w: at least one instruction group is required", domain.ErrInvalidInput)
 	}
 
+	if err := validateSizes(pattern); err != nil {
+		return err
+	}
+	if err := validatePieces(pattern); err != nil {
+		return err
+	}
+	if err := validateColors(pattern); err != nil {
+		return err
+	}
+
 	for i, g := range pattern.InstructionGroups {
 		if g.Label == "" {
 			return fmt.Errorf("%w: group %d label is required", domain.ErrInvalidInput, i+1)
@@ -266,6 +305,9 @@ func (s *PatternService) validate(pattern *domain.Pattern) error {
 				return fmt.Errorf("%w: group %d entry %d into-stitch must be 200 characters or fewer", domain.ErrInvalidInput, i+1, j+1)
 			}
 		}
+		if err := validateRepeatBlocks(i, &g); err != nil {
+			return err
+		}
 	}
 
 	return nil
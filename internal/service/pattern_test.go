@@ -15,7 +15,7 @@ func newTestPatternService(t *testing.T) (*service.PatternService, *service.Stit
 	_, db := newTestAuthService(t)
 	stitchRepo := db.Stitches()
 	patternRepo := db.Patterns()
-	return service.NewPatternService(patternRepo, stitchRepo), service.NewStitchService(stitchRepo), db
+	return service.NewPatternService(patternRepo, stitchRepo, db.Versions(), db.Tags(), db.Collections(), 50), service.NewStitchService(stitchRepo), db
 }
 
 func seedStitchForTest(t *testing.T, db *sqlite.DB) int64 {
@@ -77,6 +77,36 @@ func TestPatternService_Create_Success(t *testing.T) {
 	}
 }
 
+func TestPatternService_Create_NumbersBlankLabels(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "numbered@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	entries := []domain.StitchEntry{{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1}}
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        "Numbered",
+		PatternType: domain.PatternTypeRow,
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "", RepeatCount: 1, StitchEntries: entries},
+			{SortOrder: 1, Label: "", RepeatCount: 4, StitchEntries: entries},
+		},
+	}
+
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := db.Patterns().GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.InstructionGroups[0].Label != "Row 1" || got.InstructionGroups[1].Label != "Rows 2–5" {
+		t.Fatalf("labels = %q, %q, want Row 1, Rows 2–5", got.InstructionGroups[0].Label, got.InstructionGroups[1].Label)
+	}
+}
+
 func TestPatternService_Create_EmptyName(t *testing.T) {
 	svc, _, db := newTestPatternService(t)
 	ctx := context.Background()
@@ -102,6 +132,268 @@ func TestPatternService_Create_EmptyName(t *testing.T) {
 	}
 }
 
+func TestPatternService_Create_OverlappingRepeatBlocks(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "overlap@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        "Overlap",
+		PatternType: domain.PatternTypeRound,
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Round 1", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{SortOrder: 0, PatternStitchID: stitchID, Count: 1, RepeatCount: 1},
+					{SortOrder: 1, PatternStitchID: stitchID, Count: 1, RepeatCount: 1},
+					{SortOrder: 2, PatternStitchID: stitchID, Count: 1, RepeatCount: 1},
+				},
+				RepeatBlocks: []domain.RepeatBlock{
+					{StartEntry: 0, EndEntry: 1, RepeatCount: 2},
+					{StartEntry: 1, EndEntry: 2, RepeatCount: 2},
+				}},
+		},
+	}
+
+	err := svc.Create(ctx, p)
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for overlapping blocks, got %v", err)
+	}
+}
+
+func TestPatternService_Create_GradedSizes(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "graded@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        "Graded",
+		PatternType: domain.PatternTypeRow,
+		Sizes:       []string{" S", "M ", "L"},
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Row 1", RepeatCount: 1, SizeRepeatCounts: []int{2, 2, 2},
+				StitchEntries: []domain.StitchEntry{
+					{SortOrder: 0, PatternStitchID: stitchID, Count: 1, SizeCounts: []int{12, 14, 16}, RepeatCount: 1},
+				}},
+		},
+	}
+
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := db.Patterns().GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(got.Sizes) != 3 || got.Sizes[0] != "S" || got.Sizes[1] != "M" {
+		t.Fatalf("expected trimmed sizes [S M L], got %q", got.Sizes)
+	}
+	g := got.InstructionGroups[0]
+	// Repeat counts equal across sizes are stored ungraded.
+	if g.RepeatCount != 2 || g.SizeRepeatCounts != nil {
+		t.Fatalf("expected ungraded repeat count 2, got %d %v", g.RepeatCount, g.SizeRepeatCounts)
+	}
+	e := g.StitchEntries[0]
+	if e.Count != 12 || len(e.SizeCounts) != 3 || e.SizeCounts[2] != 16 {
+		t.Fatalf("expected count 12 with sizes [12 14 16], got %d %v", e.Count, e.SizeCounts)
+	}
+}
+
+func TestPatternService_Create_GradedSizeMismatch(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "mismatch@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	tests := []struct {
+		name  string
+		sizes []string
+		entry domain.StitchEntry
+	}{
+		{"too few values", []string{"S", "M", "L"}, domain.StitchEntry{PatternStitchID: stitchID, Count: 12, SizeCounts: []int{12, 14}, RepeatCount: 1}},
+		{"no sizes", nil, domain.StitchEntry{PatternStitchID: stitchID, Count: 12, SizeCounts: []int{12, 14}, RepeatCount: 1}},
+		{"zero for a size", []string{"S", "M"}, domain.StitchEntry{PatternStitchID: stitchID, Count: 12, SizeCounts: []int{12, 0}, RepeatCount: 1}},
+		{"duplicate size", []string{"S", "s"}, domain.StitchEntry{PatternStitchID: stitchID, Count: 12, RepeatCount: 1}},
+	}
+	for _, tt := range tests {
+		p := &domain.Pattern{
+			UserID:      userID,
+			Name:        "Mismatch",
+			PatternType: domain.PatternTypeRow,
+			Sizes:       tt.sizes,
+			InstructionGroups: []domain.InstructionGroup{
+				{SortOrder: 0, Label: "Row 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{tt.entry}},
+			},
+		}
+		if err := svc.Create(ctx, p); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestPatternService_Create_Pieces(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "pieces@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        "Bunny",
+		PatternType: domain.PatternTypeRound,
+		Pieces: []domain.PatternPiece{
+			{Name: "Head", MakeCount: 1},
+			{Name: "Ear", Notes: "Do not stuff", MakeCount: 2},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Rnd 1", PieceIndex: 0, RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1}}},
+			{SortOrder: 1, Label: "Rnd 1", PieceIndex: 1, RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{{SortOrder: 0, PatternStitchID: stitchID, Count: 4, RepeatCount: 1}}},
+		},
+	}
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := db.Patterns().GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(got.Pieces) != 2 || got.Pieces[1].Name != "Ear" || got.Pieces[1].MakeCount != 2 || got.Pieces[1].Notes != "Do not stuff" {
+		t.Fatalf("expected pieces Head and Ear ×2, got %+v", got.Pieces)
+	}
+	if got.InstructionGroups[1].PieceIndex != 1 {
+		t.Fatalf("expected second group in piece 2, got piece index %d", got.InstructionGroups[1].PieceIndex)
+	}
+	if n := service.StitchCount(got); n != 14 {
+		t.Fatalf("expected 6 + 2×4 = 14 stitches, got %d", n)
+	}
+
+	page, err := svc.ListSummaryByUser(ctx, userID, domain.PatternPage{})
+	if err != nil {
+		t.Fatalf("ListSummaryByUser: %v", err)
+	}
+	if summaries := page.Summaries; len(summaries) != 1 || summaries[0].StitchesWorked != 14 {
+		t.Fatalf("expected summary stitch count 14, got %+v", summaries)
+	}
+}
+
+func TestPatternService_Create_PieceGroupsOutOfOrder(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "piece-order@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        "Out of order",
+		PatternType: domain.PatternTypeRound,
+		Pieces:      []domain.PatternPiece{{Name: "Head", MakeCount: 1}, {Name: "Arm", MakeCount: 2}},
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Arm Rnd 1", PieceIndex: 1, RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{{SortOrder: 0, PatternStitchID: stitchID, Count: 4, RepeatCount: 1}}},
+			{SortOrder: 1, Label: "Head Rnd 1", PieceIndex: 0, RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1}}},
+		},
+	}
+	if err := svc.Create(ctx, p); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for groups out of piece order, got %v", err)
+	}
+}
+
+func TestPatternService_Create_Colors(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "colors@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	b := 1
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        "Striped Coaster",
+		PatternType: domain.PatternTypeRound,
+		Colors: []domain.PatternColor{
+			{Label: "A", Name: "Cream", Hex: "#f5e6c8"},
+			{Label: "B", Name: "Navy"},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Rnd 1", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1},
+					{SortOrder: 1, PatternStitchID: stitchID, Count: 6, RepeatCount: 1, ColorIndex: &b},
+				}},
+		},
+	}
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := db.Patterns().GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(got.Colors) != 2 || got.Colors[0].Hex != "#f5e6c8" || got.Colors[1].Name != "Navy" {
+		t.Fatalf("expected colors A (Cream) and B (Navy), got %+v", got.Colors)
+	}
+	entries := got.InstructionGroups[0].StitchEntries
+	if entries[0].ColorIndex != nil {
+		t.Fatalf("expected first entry to keep the current color, got %d", *entries[0].ColorIndex)
+	}
+	if entries[1].ColorIndex == nil || *entries[1].ColorIndex != 1 {
+		t.Fatalf("expected second entry to change to color index 1, got %v", entries[1].ColorIndex)
+	}
+}
+
+func TestPatternService_Create_InvalidColors(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "badcolors@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	missing := 2
+	tests := []struct {
+		name    string
+		colors  []domain.PatternColor
+		colorIx *int
+	}{
+		{"duplicate label", []domain.PatternColor{{Label: "A"}, {Label: "a"}}, nil},
+		{"missing label", []domain.PatternColor{{Name: "Cream"}}, nil},
+		{"bad hex", []domain.PatternColor{{Label: "A", Hex: "cream"}}, nil},
+		{"color not in palette", []domain.PatternColor{{Label: "A"}, {Label: "B"}}, &missing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &domain.Pattern{
+				UserID:      userID,
+				Name:        "Bad Colors",
+				PatternType: domain.PatternTypeRound,
+				Colors:      tt.colors,
+				InstructionGroups: []domain.InstructionGroup{
+					{SortOrder: 0, Label: "Rnd 1", RepeatCount: 1,
+						StitchEntries: []domain.StitchEntry{
+							{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1, ColorIndex: tt.colorIx},
+						}},
+				},
+			}
+			if err := svc.Create(ctx, p); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
+
 func TestPatternService_Create_InvalidType(t *testing.T) {
 	svc, _, db := newTestPatternService(t)
 	ctx := context.Background()
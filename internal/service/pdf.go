@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/pdf"
+)
+
+// Largest size of a group image in the PDF, in points.
+const (
+	pdfImageMaxWidth  = 300
+	pdfImageMaxHeight = 240
+)
+
+// RenderPatternPDF lays out a pattern as a printable PDF: its name and
+// metadata, description, color palette, a glossary of the pattern's stitches,
+// and every instruction group as text with its notes and images. images holds
+// each group's images keyed by instruction group ID, as returned by
+// ImageService.LoadPatternImages; it may be nil. Images that can't be decoded
+// are noted in place rather than failing the whole document. Stitches are
+// written in terminology terms, which the metadata line names, and groups in
+// the given text style.
+func RenderPatternPDF(pattern *domain.Pattern, images map[int64][]ImageFile, terms domain.Terminology, style TextStyle) ([]byte, error) {
+	pattern = TranslatePattern(pattern, terms)
+	doc := pdf.New()
+
+	doc.SetFont(pdf.HelveticaBold, 20)
+	doc.Text(pattern.Name)
+	doc.SetFont(pdf.Helvetica, 10)
+	doc.Text(pdfMetadata(pattern) + " · " + TerminologyLabel(terms))
+	if pattern.SharedFromName != "" {
+		doc.Text("Shared by " + pattern.SharedFromName)
+	}
+	doc.Space(6)
+
+	if pattern.Description != "" {
+		doc.SetFont(pdf.Helvetica, 11)
+		doc.Text(pattern.Description)
+		doc.Space(6)
+	}
+
+	if len(pattern.Colors) > 0 {
+		pdfHeading(doc, "Colors")
+		doc.SetFont(pdf.Helvetica, 10)
+		for _, c := range pattern.Colors {
+			line := c.Label
+			if c.Name != "" {
+				line += " – " + c.Name
+			}
+			doc.Text(line)
+		}
+		doc.Space(6)
+	}
+
+	if len(pattern.PatternStitches) > 0 {
+		pdfHeading(doc, "Stitch Glossary")
+		doc.SetFont(pdf.Helvetica, 10)
+		for _, ps := range pattern.PatternStitches {
+			line := ps.Abbreviation + " – " + ps.Name
+			if ps.Description != "" {
+				line += ": " + ps.Description
+			}
+			doc.Text(line)
+		}
+		doc.Space(6)
+	}
+
+	pdfHeading(doc, "Instructions")
+	for gi := range pattern.InstructionGroups {
+		g := &pattern.InstructionGroups[gi]
+		if first, _ := pattern.PieceSpan(gi); first == gi {
+			if piece := pattern.PieceAt(gi); piece != nil {
+				doc.Space(4)
+				doc.SetFont(pdf.HelveticaBold, 12)
+				doc.Text(renderPieceHeading(piece))
+				if piece.Notes != "" {
+					doc.SetFont(pdf.Helvetica, 9)
+					doc.Text(piece.Notes)
+				}
+			}
+		}
+
+		if style == TextStyleWritten {
+			doc.SetFont(pdf.Helvetica, 10)
+		} else {
+			doc.SetFont(pdf.Courier, 10)
+		}
+		doc.Text(RenderGroupTextStyle(g, pattern.PatternStitches, style))
+		if g.Notes != "" {
+			doc.SetFont(pdf.Helvetica, 9)
+			doc.TextIndent("Note: "+g.Notes, 12)
+		}
+		for _, img := range images[g.ID] {
+			doc.Space(4)
+			if err := doc.Image(img.Data, pdfImageMaxWidth, pdfImageMaxHeight); err != nil {
+				doc.SetFont(pdf.Helvetica, 9)
+				doc.TextIndent(fmt.Sprintf("[image %s could not be included]", img.Image.Filename), 12)
+			}
+		}
+		doc.Space(4)
+	}
+
+	return doc.Bytes()
+}
+
+// pdfHeading writes a section heading with a rule under it.
+func pdfHeading(doc *pdf.Document, title string) {
+	doc.SetFont(pdf.HelveticaBold, 13)
+	doc.Text(title)
+	doc.Rule()
+}
+
+// pdfMetadata summarizes the pattern's type, difficulty, hook, yarn and sizes
+// on one line.
+func pdfMetadata(pattern *domain.Pattern) string {
+	parts := []string{"Worked in rounds"}
+	if pattern.PatternType == domain.PatternTypeRow {
+		parts[0] = "Worked in rows"
+	}
+	if pattern.Difficulty != "" {
+		parts = append(parts, "Difficulty: "+pattern.Difficulty)
+	}
+	if pattern.HookSize != "" {
+		parts = append(parts, "Hook: "+pattern.HookSize)
+	}
+	if pattern.YarnWeight != "" {
+		parts = append(parts, "Yarn: "+pattern.YarnWeight)
+	}
+	if pattern.IsGraded() {
+		parts = append(parts, "Sizes: "+strings.Join(pattern.Sizes, ", "))
+	}
+	parts = append(parts, fmt.Sprintf("%d stitches worked", StitchCount(pattern)))
+	return strings.Join(parts, " · ")
+}
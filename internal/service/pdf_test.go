@@ -0,0 +1,80 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// pdfContent returns the decompressed content streams of a PDF.
+func pdfContent(t *testing.T, data []byte) string {
+	t.Helper()
+	var sb strings.Builder
+	for _, part := range bytes.Split(data, []byte(">>\nstream\n"))[1:] {
+		end := bytes.Index(part, []byte("\nendstream"))
+		if end == -1 {
+			continue
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(part[:end]))
+		if err != nil {
+			continue // Not deflated, e.g. a JPEG
+		}
+		b, _ := io.ReadAll(zr)
+		sb.Write(b)
+	}
+	return sb.String()
+}
+
+func TestRenderPatternPDF(t *testing.T) {
+	expected := 12
+	pattern := &domain.Pattern{
+		Name:            "Amigurumi Cat",
+		PatternType:     domain.PatternTypeRound,
+		HookSize:        "3.5mm",
+		YarnWeight:      "DK",
+		Difficulty:      "Beginner",
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{ID: 100, Label: "Round 1", RepeatCount: 1, Notes: "Pull the ring tight", StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+			}},
+			{ID: 101, Label: "Round 2", RepeatCount: 1, ExpectedCount: &expected, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 5, Count: 6, RepeatCount: 1},
+			}},
+		},
+	}
+	images := map[int64][]ImageFile{
+		100: {{Image: domain.PatternImage{Filename: "broken.png"}, Data: []byte("not an image")}},
+	}
+
+	data, err := RenderPatternPDF(pattern, images, domain.TerminologyUS, TextStyleNotation)
+	if err != nil {
+		t.Fatalf("RenderPatternPDF: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatal("output is not a PDF")
+	}
+
+	content := pdfContent(t, data)
+	for _, want := range []string{
+		"(Amigurumi Cat)",
+		"Difficulty: Beginner",
+		"Hook: 3.5mm",
+		"Yarn: DK",
+		"(Stitch Glossary)",
+		"sc \x96 Single Crochet",
+		"(Round 1: MR, 6 sc \\(6\\))",
+		"(Round 2: 6 inc \\(12\\))",
+		"(Note: Pull the ring tight)",
+		"[image broken.png could not be included]",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("PDF content missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// maxPatternPieces limits how many pieces a pattern can be split into.
+const maxPatternPieces = 50
+
+// validatePieces checks a pattern's pieces and that every group belongs to
+// one of them, with the groups of each piece kept together in piece order.
+func validatePieces(pattern *domain.Pattern) error {
+	if len(pattern.Pieces) > maxPatternPieces {
+		return fmt.Errorf("%w: a pattern can have at most %d pieces", domain.ErrInvalidInput, maxPatternPieces)
+	}
+	for i, pc := range pattern.Pieces {
+		if pc.Name == "" {
+			return fmt.Errorf("%w: piece %d name is required", domain.ErrInvalidInput, i+1)
+		}
+		if len(pc.Name) > 200 {
+			return fmt.Errorf("%w: piece %d name must be 200 characters or fewer", domain.ErrInvalidInput, i+1)
+		}
+		if len(pc.Notes) > 1000 {
+			return fmt.Errorf("%w: piece %d notes must be 1000 characters or fewer", domain.ErrInvalidInput, i+1)
+		}
+		if pc.MakeCount < 1 {
+			return fmt.Errorf("%w: piece %d make count must be at least 1", domain.ErrInvalidInput, i+1)
+		}
+		if pc.MakeCount > 100 {
+			return fmt.Errorf("%w: piece %d make count must be 100 or fewer", domain.ErrInvalidInput, i+1)
+		}
+	}
+
+	if len(pattern.Pieces) == 0 {
+		return nil
+	}
+	previous := 0
+	for i, g := range pattern.InstructionGroups {
+		if g.PieceIndex < 0 || g.PieceIndex >= len(pattern.Pieces) {
+			return fmt.Errorf("%w: group %d belongs to piece %d, which does not exist", domain.ErrInvalidInput, i+1, g.PieceIndex+1)
+		}
+		if g.PieceIndex < previous {
+			return fmt.Errorf("%w: group %d belongs to piece %d but comes after the groups of piece %d", domain.ErrInvalidInput, i+1, g.PieceIndex+1, previous+1)
+		}
+		previous = g.PieceIndex
+	}
+	return nil
+}
+
+// normalizePieces clears piece references from the groups of a pattern that
+// isn't split into pieces.
+func normalizePieces(pattern *domain.Pattern) {
+	if len(pattern.Pieces) > 0 {
+		return
+	}
+	pattern.Pieces = nil
+	for i := range pattern.InstructionGroups {
+		pattern.InstructionGroups[i].PieceIndex = 0
+	}
+}
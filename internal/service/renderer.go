@@ -2,29 +2,94 @@ package service
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 )
 
+// TextStyle selects how pattern text is written.
+type TextStyle string
+
+const (
+	// TextStyleNotation is standard crochet notation, e.g.
+	// "Rnd 3: *sc, inc* repeat 6 times (18)".
+	TextStyleNotation TextStyle = "notation"
+	// TextStyleWritten spells the notation out for beginners, with full
+	// stitch names and repeats and placements written as sentences.
+	TextStyleWritten TextStyle = "written"
+)
+
+// ParseTextStyle returns the text style named s, defaulting to notation for
+// anything unrecognized.
+func ParseTextStyle(s string) TextStyle {
+	if TextStyle(s) == TextStyleWritten {
+		return TextStyleWritten
+	}
+	return TextStyleNotation
+}
+
 // RenderPatternText renders a pattern as formatted text using standard
 // crochet notation. It uses the pattern's own PatternStitches for abbreviation lookup.
+// Numbers that vary by size in a graded pattern are written as "12 (14, 16)",
+// and stitch counts always come before the stitch: "6 sc, 12 (14, 16) sc".
+// Patterns made of pieces get a heading per piece, e.g. "Arm (make 2)".
+// Entries that change the working yarn color are prefixed "with B:".
+// Consecutive numbered rounds worked the same way are collapsed into a range,
+// e.g. "Rnds 8–15: 36 sc (36)".
 func RenderPatternText(pattern *domain.Pattern) string {
+	return RenderPatternTextStyle(pattern, TextStyleNotation)
+}
+
+// RenderPatternTextStyle renders a pattern as text in the given style.
+func RenderPatternTextStyle(pattern *domain.Pattern, style TextStyle) string {
+	return strings.Join(renderPatternLines(pattern, style, true), "\n")
+}
+
+// renderPatternLines renders a pattern's lines in the given style. With
+// collapse, runs of identical numbered rounds share a line.
+func renderPatternLines(pattern *domain.Pattern, style TextStyle, collapse bool) []string {
 	if pattern == nil || len(pattern.InstructionGroups) == 0 {
-		return ""
+		return nil
 	}
 
 	lookup := buildPatternStitchLookup(pattern.PatternStitches)
+	byID := buildPatternStitchByID(pattern.PatternStitches)
+	colors := &colorTracker{colors: pattern.Colors}
 	var lines []string
+	var run []renderedGroup // Groups since the last piece heading
 
-	for _, g := range pattern.InstructionGroups {
-		line := renderGroup(&g, lookup)
-		if line != "" {
-			lines = append(lines, line)
+	for gi, g := range pattern.InstructionGroups {
+		if first, _ := pattern.PieceSpan(gi); first == gi {
+			if piece := pattern.PieceAt(gi); piece != nil {
+				lines = append(lines, collapseRounds(run)...)
+				run = nil
+				if len(lines) > 0 {
+					lines = append(lines, "")
+				}
+				lines = append(lines, renderPieceHeading(piece))
+			}
 		}
-	}
 
-	return strings.Join(lines, "\n")
+		var line string
+		if style == TextStyleWritten {
+			line = renderWrittenGroup(&g, byID, colors)
+		} else {
+			line = renderGroup(&g, lookup, byID, colors)
+		}
+		if line == "" {
+			continue
+		}
+		// Single numbered rounds are written under their own label, so the
+		// rest of the line can be compared with the rounds around them.
+		rg := renderedGroup{label: line}
+		if r, ok := groupRounds(&g); ok && r.count() == 1 && collapse && strings.HasPrefix(line, g.Label) {
+			rg = renderedGroup{label: g.Label, body: line[len(g.Label):], rounds: r, ok: true}
+		}
+		run = append(run, rg)
+	}
+	return append(lines, collapseRounds(run)...)
 }
 
 // RenderGroupText renders a single instruction group as text.
@@ -33,7 +98,27 @@ func RenderGroupText(g *domain.InstructionGroup, patternStitches []domain.Patter
 		return ""
 	}
 	lookup := buildPatternStitchLookup(patternStitches)
-	return renderGroup(g, lookup)
+	return renderGroup(g, lookup, buildPatternStitchByID(patternStitches), nil)
+}
+
+// RenderGroupTextStyle renders a single instruction group as text in the
+// given style.
+func RenderGroupTextStyle(g *domain.InstructionGroup, patternStitches []domain.PatternStitch, style TextStyle) string {
+	if g == nil {
+		return ""
+	}
+	if style == TextStyleWritten {
+		return renderWrittenGroup(g, buildPatternStitchByID(patternStitches), nil)
+	}
+	return RenderGroupText(g, patternStitches)
+}
+
+// renderPieceHeading renders a piece's name, with its make count if more than one.
+func renderPieceHeading(piece *domain.PatternPiece) string {
+	if piece.MakeCount > 1 {
+		return fmt.Sprintf("%s (make %d)", piece.Name, piece.MakeCount)
+	}
+	return piece.Name
 }
 
 func buildPatternStitchLookup(stitches []domain.PatternStitch) map[int64]string {
@@ -44,39 +129,111 @@ func buildPatternStitchLookup(stitches []domain.PatternStitch) map[int64]string
 	return lookup
 }
 
-func renderGroup(g *domain.InstructionGroup, lookup map[int64]string) string {
+// renderGroup renders a group as "Label: entries (N)", where N is the expected
+// count if set, otherwise the stitches the group produces for the next round.
+// For graded groups N is given for every size. colors may be nil when color
+// changes aren't rendered.
+func renderGroup(g *domain.InstructionGroup, lookup map[int64]string, byID map[int64]domain.PatternStitch, colors *colorTracker) string {
 	if len(g.StitchEntries) == 0 {
 		return g.Label + ":"
 	}
 
 	label := g.Label
-	if g.RepeatCount > 1 {
-		label = fmt.Sprintf("%s (×%d)", g.Label, g.RepeatCount)
+	if g.RepeatCount > 1 || len(g.SizeRepeatCounts) > 0 {
+		label = fmt.Sprintf("%s (×%s)", g.Label, GradedText(g.RepeatCount, g.SizeRepeatCounts))
 	}
 
-	entries := renderEntries(g.StitchEntries, lookup)
-	stitchCount := GroupStitchCount(g)
+	entries := renderNodes(buildEntryTree(g), lookup, colors)
 
 	if g.ExpectedCount != nil {
-		return fmt.Sprintf("%s: %s (%d)", label, entries, *g.ExpectedCount)
+		return fmt.Sprintf("%s: %s (%s)", label, entries, GradedText(*g.ExpectedCount, g.SizeExpectedCounts))
+	}
+	if !groupProducesStitches(g, byID) {
+		return fmt.Sprintf("%s: %s", label, entries)
+	}
+	return fmt.Sprintf("%s: %s (%s)", label, entries, gradedProducedText(g, byID))
+}
+
+// groupProducesStitches reports whether any of a group's stitches leaves a
+// stitch behind. A group made only of stitches like MR has no count
+// worth printing.
+func groupProducesStitches(g *domain.InstructionGroup, byID map[int64]domain.PatternStitch) bool {
+	return slices.ContainsFunc(g.StitchEntries, func(e domain.StitchEntry) bool {
+		_, produces := stitchArithmetic(byID, e.PatternStitchID)
+		return produces > 0
+	})
+}
+
+// gradedProducedText formats the stitches a group produces, per size when
+// any of the group's numbers vary by size.
+func gradedProducedText(g *domain.InstructionGroup, byID map[int64]domain.PatternStitch) string {
+	n := groupSizeCount(g)
+	if n == 0 {
+		return strconv.Itoa(groupProducedCount(g, byID))
+	}
+
+	counts := make([]int, n)
+	for si := range counts {
+		sized := g.ForSize(si)
+		counts[si] = groupProducedCount(&sized, byID)
 	}
-	return fmt.Sprintf("%s: %s (%d)", label, entries, stitchCount)
+	if !slices.ContainsFunc(counts, func(c int) bool { return c != counts[0] }) {
+		return strconv.Itoa(counts[0])
+	}
+	return GradedText(counts[0], counts)
 }
 
-func renderEntries(entries []domain.StitchEntry, lookup map[int64]string) string {
+func renderNodes(nodes []entryNode, lookup map[int64]string, colors *colorTracker) string {
 	var parts []string
 
-	for _, e := range entries {
-		part := renderEntry(&e, lookup)
-		if e.RepeatCount > 1 {
-			part = fmt.Sprintf("*%s, repeat from * %d times", part, e.RepeatCount)
+	for _, n := range nodes {
+		if n.block != nil {
+			parts = append(parts, renderBlock(n, lookup, colors))
+			continue
+		}
+		marker := colors.marker(n.entry)
+		part := renderEntry(n.entry, lookup)
+		if n.entry.RepeatCount > 1 || len(n.entry.SizeRepeatCounts) > 0 {
+			part = fmt.Sprintf("*%s, repeat from * %s times", part, GradedText(n.entry.RepeatCount, n.entry.SizeRepeatCounts))
 		}
-		parts = append(parts, part)
+		parts = append(parts, marker+part)
 	}
 
 	return strings.Join(parts, ", ")
 }
 
+// renderBlock renders a repeat block and its nested contents, e.g.
+// "(sc, inc) x3", "[2 dc, ch 2, 2 dc] in next sp" or "*sc, inc* repeat 6 times".
+func renderBlock(n entryNode, lookup map[int64]string, colors *colorTracker) string {
+	b := n.block
+	inner := renderNodes(n.children, lookup, colors)
+
+	var sb strings.Builder
+
+	switch b.Bracket {
+	case domain.BracketSquare:
+		fmt.Fprintf(&sb, "[%s]", inner)
+	case domain.BracketAsterisk:
+		fmt.Fprintf(&sb, "*%s*", inner)
+	default:
+		fmt.Fprintf(&sb, "(%s)", inner)
+	}
+
+	if b.IntoStitch != "" {
+		fmt.Fprintf(&sb, " %s", b.IntoStitch)
+	}
+
+	if b.RepeatCount > 1 {
+		if b.Bracket == domain.BracketAsterisk {
+			fmt.Fprintf(&sb, " repeat %d times", b.RepeatCount)
+		} else {
+			fmt.Fprintf(&sb, " x%d", b.RepeatCount)
+		}
+	}
+
+	return sb.String()
+}
+
 func renderEntry(e *domain.StitchEntry, lookup map[int64]string) string {
 	abbr := lookup[e.PatternStitchID]
 	if abbr == "" {
@@ -85,7 +242,11 @@ func renderEntry(e *domain.StitchEntry, lookup map[int64]string) string {
 
 	var sb strings.Builder
 
-	if e.Count > 1 {
+	// Counts always come before the stitch, graded ones too, e.g.
+	// "12 (14, 16) sc".
+	if len(e.SizeCounts) > 0 {
+		fmt.Fprintf(&sb, "%s %s", GradedText(e.Count, e.SizeCounts), abbr)
+	} else if e.Count > 1 {
 		fmt.Fprintf(&sb, "%d %s", e.Count, abbr)
 	} else {
 		sb.WriteString(abbr)
@@ -8,12 +8,12 @@ import (
 
 func testPatternStitches() []domain.PatternStitch {
 	return []domain.PatternStitch{
-		{ID: 1, Abbreviation: "sc", Name: "Single Crochet"},
-		{ID: 2, Abbreviation: "dc", Name: "Double Crochet"},
-		{ID: 3, Abbreviation: "ch", Name: "Chain"},
-		{ID: 4, Abbreviation: "MR", Name: "Magic Ring"},
-		{ID: 5, Abbreviation: "inc", Name: "Increase"},
-		{ID: 6, Abbreviation: "sl st", Name: "Slip Stitch"},
+		{ID: 1, Abbreviation: "sc", Name: "Single Crochet", Consumes: 1, Produces: 1},
+		{ID: 2, Abbreviation: "dc", Name: "Double Crochet", Consumes: 1, Produces: 1},
+		{ID: 3, Abbreviation: "ch", Name: "Chain", Consumes: 0, Produces: 1},
+		{ID: 4, Abbreviation: "MR", Name: "Magic Ring", Consumes: 0, Produces: 0},
+		{ID: 5, Abbreviation: "inc", Name: "Increase", Consumes: 1, Produces: 2},
+		{ID: 6, Abbreviation: "sl st", Name: "Slip Stitch", Consumes: 1, Produces: 1},
 	}
 }
 
@@ -49,8 +49,8 @@ func TestRenderPatternText_SimpleGroup(t *testing.T) {
 		},
 	}
 	result := RenderPatternText(pattern)
-	// MR counts as 1 stitch entry (count=1) + 6 sc = 7
-	expected := "Round 1: MR, 6 sc (7)"
+	// MR produces no stitches of its own; the 6 sc worked into it produce 6.
+	expected := "Round 1: MR, 6 sc (6)"
 	if result != expected {
 		t.Fatalf("expected %q, got %q", expected, result)
 	}
@@ -70,8 +70,8 @@ func TestRenderPatternText_GroupWithRepeats(t *testing.T) {
 		},
 	}
 	result := RenderPatternText(pattern)
-	// inc has count=1, repeat=6, so: *inc, repeat from * 6 times (6)
-	expected := "Round 2: *inc, repeat from * 6 times (6)"
+	// inc produces 2 stitches and is repeated 6 times: *inc, repeat from * 6 times (12)
+	expected := "Round 2: *inc, repeat from * 6 times (12)"
 	if result != expected {
 		t.Fatalf("expected %q, got %q", expected, result)
 	}
@@ -127,8 +127,8 @@ func TestRenderPatternText_MixedEntries(t *testing.T) {
 		},
 	}
 	result := RenderPatternText(pattern)
-	// MR counts as 1 stitch entry (count=1) + 6 sc = 7
-	expected := "Round 1: MR, 6 sc (7)\nRound 2: *inc, repeat from * 6 times (6)\nRound 3: *sc, repeat from * 6 times, *inc, repeat from * 6 times (12)"
+	// Counts are the stitches produced: MR makes none, inc makes 2.
+	expected := "Round 1: MR, 6 sc (6)\nRound 2: *inc, repeat from * 6 times (12)\nRound 3: *sc, repeat from * 6 times, *inc, repeat from * 6 times (18)"
 	if result != expected {
 		t.Fatalf("expected:\n%s\ngot:\n%s", expected, result)
 	}
@@ -190,7 +190,7 @@ func TestRenderPatternText_SingleStitch(t *testing.T) {
 		},
 	}
 	result := RenderPatternText(pattern)
-	expected := "Start: MR (1)"
+	expected := "Start: MR"
 	if result != expected {
 		t.Fatalf("expected %q, got %q", expected, result)
 	}
@@ -298,9 +298,173 @@ func TestRenderPatternText_ComplexPattern(t *testing.T) {
 	}
 
 	result := RenderPatternText(pattern)
-	// MR counts as 1 stitch entry (count=1) + 6 sc = 7
-	expected := "Round 1: MR, 6 sc (7)\nRound 2: *inc, repeat from * 6 times (12)\nRounds 3-5 (×3): 12 sc (12)\nFinish: sl st (1)"
+	expected := "Round 1: MR, 6 sc (6)\nRound 2: *inc, repeat from * 6 times (12)\nRounds 3-5 (×3): 12 sc (12)\nFinish: sl st (1)"
 	if result != expected {
 		t.Fatalf("expected:\n%s\ngot:\n%s", expected, result)
 	}
 }
+
+func TestRenderPatternText_NestedRepeatBlocks(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label:       "Round 3",
+				RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 3, Count: 1, RepeatCount: 1}, // ch
+					{PatternStitchID: 1, Count: 1, RepeatCount: 1}, // sc
+					{PatternStitchID: 5, Count: 1, RepeatCount: 1}, // inc
+					{PatternStitchID: 2, Count: 2, RepeatCount: 1}, // 2 dc
+				},
+				RepeatBlocks: []domain.RepeatBlock{
+					{StartEntry: 1, EndEntry: 3, RepeatCount: 4, Bracket: domain.BracketAsterisk},
+					{StartEntry: 1, EndEntry: 2, RepeatCount: 3, Bracket: domain.BracketParen},
+				},
+			},
+		},
+	}
+	result := RenderPatternText(pattern)
+	// Produced: 1 ch + 4 × (3 × (sc + 2 for inc) + 2 dc) = 1 + 4 × 11 = 45
+	expected := "Round 3: ch, *(sc, inc) x3, 2 dc* repeat 4 times (45)"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderPatternText_SquareBracketWithIntoStitch(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label:       "Row 2",
+				RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 2, Count: 2, RepeatCount: 1}, // 2 dc
+					{PatternStitchID: 3, Count: 2, RepeatCount: 1}, // 2 ch
+					{PatternStitchID: 2, Count: 2, RepeatCount: 1}, // 2 dc
+				},
+				RepeatBlocks: []domain.RepeatBlock{
+					{StartEntry: 0, EndEntry: 2, RepeatCount: 1, Bracket: domain.BracketSquare, IntoStitch: "in next sp"},
+				},
+			},
+		},
+	}
+	result := RenderPatternText(pattern)
+	expected := "Row 2: [2 dc, 2 ch, 2 dc] in next sp (6)"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderPatternText_GradedCounts(t *testing.T) {
+	pattern := &domain.Pattern{
+		Sizes:           []string{"S", "M", "L"},
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label:            "Row 1",
+				RepeatCount:      4,
+				SizeRepeatCounts: []int{4, 5, 6},
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 3, Count: 1, RepeatCount: 1},                                 // ch
+					{PatternStitchID: 1, Count: 12, SizeCounts: []int{12, 14, 16}, RepeatCount: 1}, // 12 (14, 16) sc
+					{PatternStitchID: 1, Count: 6, RepeatCount: 1},                                 // 6 sc
+				},
+			},
+		},
+	}
+	result := RenderPatternText(pattern)
+	expected := "Row 1 (×4 (5, 6)): ch, 12 (14, 16) sc, 6 sc (19 (21, 23))"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderPatternText_GradedEntryRepeat(t *testing.T) {
+	pattern := &domain.Pattern{
+		Sizes:           []string{"S", "M"},
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label:       "Round 2",
+				RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 5, Count: 1, RepeatCount: 6, SizeRepeatCounts: []int{6, 8}}, // inc × 6 (8)
+				},
+			},
+		},
+	}
+	result := RenderPatternText(pattern)
+	expected := "Round 2: *inc, repeat from * 6 (8) times (12 (16))"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestGradedText(t *testing.T) {
+	tests := []struct {
+		base  int
+		sizes []int
+		want  string
+	}{
+		{6, nil, "6"},
+		{12, []int{12, 14, 16}, "12 (14, 16)"},
+		{3, []int{3, 4}, "3 (4)"},
+	}
+	for _, tt := range tests {
+		if got := GradedText(tt.base, tt.sizes); got != tt.want {
+			t.Errorf("GradedText(%d, %v) = %q, want %q", tt.base, tt.sizes, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPatternText_Pieces(t *testing.T) {
+	pattern := &domain.Pattern{
+		Pieces: []domain.PatternPiece{
+			{Name: "Head", MakeCount: 1},
+			{Name: "Arm", MakeCount: 2},
+		},
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rnd 1", PieceIndex: 0, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1},
+			}},
+			{Label: "Rnd 1", PieceIndex: 1, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 4, RepeatCount: 1},
+			}},
+		},
+	}
+	result := RenderPatternText(pattern)
+	expected := "Head\nRnd 1: 6 sc (6)\n\nArm (make 2)\nRnd 1: 4 sc (4)"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderPatternText_ColorChanges(t *testing.T) {
+	a, b := 0, 1
+	pattern := &domain.Pattern{
+		Colors: []domain.PatternColor{
+			{Label: "A", Name: "Cream"},
+			{Label: "B", Name: "Navy"},
+		},
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Row 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1, ColorIndex: &a},
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1, ColorIndex: &b},
+			}},
+			// Still working in B, so only the change back to A is marked.
+			{Label: "Row 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1, ColorIndex: &b},
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1, ColorIndex: &a},
+			}},
+		},
+	}
+	result := RenderPatternText(pattern)
+	expected := "Row 1: with A: 2 sc, with B: 2 sc (4)\nRow 2: 2 sc, with A: 2 sc (4)"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// entryNode is a node in the tree formed by a group's stitch entries and
+// the repeat blocks bracketing them. Exactly one of entry or block is set.
+type entryNode struct {
+	entry    *domain.StitchEntry
+	block    *domain.RepeatBlock
+	children []entryNode
+}
+
+// buildEntryTree arranges a group's entries under their repeat blocks.
+func buildEntryTree(g *domain.InstructionGroup) []entryNode {
+	return buildEntryNodes(g, 0, len(g.StitchEntries)-1, g.RepeatBlocks)
+}
+
+// buildEntryNodes builds the nodes for entries from..to (inclusive) using the
+// candidate blocks, all of which must lie within that range.
+func buildEntryNodes(g *domain.InstructionGroup, from, to int, blocks []domain.RepeatBlock) []entryNode {
+	var nodes []entryNode
+	for i := from; i <= to; {
+		b, ok := outermostBlockAt(blocks, i)
+		if !ok {
+			nodes = append(nodes, entryNode{entry: &g.StitchEntries[i]})
+			i++
+			continue
+		}
+
+		var inner []domain.RepeatBlock
+		for _, c := range blocks {
+			if b.Encloses(c) {
+				inner = append(inner, c)
+			}
+		}
+		end := min(b.EndEntry, to)
+		nodes = append(nodes, entryNode{
+			block:    &b,
+			children: buildEntryNodes(g, i, end, inner),
+		})
+		i = end + 1
+	}
+	return nodes
+}
+
+// outermostBlockAt returns the widest block that starts at entry index ei.
+func outermostBlockAt(blocks []domain.RepeatBlock, ei int) (domain.RepeatBlock, bool) {
+	var best domain.RepeatBlock
+	found := false
+	for _, b := range blocks {
+		if b.StartEntry != ei {
+			continue
+		}
+		if !found || b.EndEntry > best.EndEntry {
+			best = b
+			found = true
+		}
+	}
+	return best, found
+}
+
+// enclosingBlocks returns the repeat blocks containing entry index ei,
+// ordered outermost first.
+func enclosingBlocks(g *domain.InstructionGroup, ei int) []domain.RepeatBlock {
+	var blocks []domain.RepeatBlock
+	for _, b := range g.RepeatBlocks {
+		if b.Contains(ei) {
+			blocks = append(blocks, b)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		if blocks[i].StartEntry != blocks[j].StartEntry {
+			return blocks[i].StartEntry < blocks[j].StartEntry
+		}
+		return blocks[i].EndEntry > blocks[j].EndEntry
+	})
+	return blocks
+}
+
+// blockPassStitches computes the stitches worked in a single pass through a
+// repeat block, including the repeats of any blocks nested inside it.
+func blockPassStitches(g *domain.InstructionGroup, b domain.RepeatBlock) int {
+	count := 0
+	for j := b.StartEntry; j <= b.EndEntry && j < len(g.StitchEntries); j++ {
+		e := &g.StitchEntries[j]
+		m := 1
+		for _, inner := range g.RepeatBlocks {
+			if b.Encloses(inner) && inner.Contains(j) {
+				m *= inner.RepeatCount
+			}
+		}
+		count += e.Count * e.RepeatCount * m
+	}
+	return count
+}
+
+// closedBlockMultiplier returns the product of the repeat counts of blocks
+// that contain entry j but not entry ei — the blocks around j that have
+// been fully worked by the time ei is reached.
+func closedBlockMultiplier(g *domain.InstructionGroup, j, ei int) int {
+	m := 1
+	for _, b := range g.RepeatBlocks {
+		if b.Contains(j) && !b.Contains(ei) {
+			m *= b.RepeatCount
+		}
+	}
+	return m
+}
+
+// validateRepeatBlocks checks that a group's blocks cover valid entry ranges
+// and nest cleanly. gi is the 0-based group index used in error messages.
+func validateRepeatBlocks(gi int, g *domain.InstructionGroup) error {
+	for k, b := range g.RepeatBlocks {
+		if b.StartEntry < 0 || b.EndEntry >= len(g.StitchEntries) || b.StartEntry > b.EndEntry {
+			return fmt.Errorf("%w: group %d bracket %d must cover stitches within the group", domain.ErrInvalidInput, gi+1, k+1)
+		}
+		if b.RepeatCount < 1 {
+			return fmt.Errorf("%w: group %d bracket %d repeat count must be at least 1", domain.ErrInvalidInput, gi+1, k+1)
+		}
+		if b.RepeatCount > 1000 {
+			return fmt.Errorf("%w: group %d bracket %d repeat count must be 1000 or fewer", domain.ErrInvalidInput, gi+1, k+1)
+		}
+		switch b.Bracket {
+		case "", domain.BracketParen, domain.BracketSquare, domain.BracketAsterisk:
+		default:
+			return fmt.Errorf("%w: group %d bracket %d has an unknown bracket style", domain.ErrInvalidInput, gi+1, k+1)
+		}
+		if len(b.IntoStitch) > 200 {
+			return fmt.Errorf("%w: group %d bracket %d into-stitch must be 200 characters or fewer", domain.ErrInvalidInput, gi+1, k+1)
+		}
+		for _, other := range g.RepeatBlocks[:k] {
+			if b.StartEntry == other.StartEntry && b.EndEntry == other.EndEntry {
+				return fmt.Errorf("%w: group %d bracket %d duplicates another bracket", domain.ErrInvalidInput, gi+1, k+1)
+			}
+			overlaps := b.StartEntry <= other.EndEntry && other.StartEntry <= b.EndEntry
+			if overlaps && !b.Encloses(other) && !other.Encloses(b) {
+				return fmt.Errorf("%w: group %d bracket %d partially overlaps another bracket", domain.ErrInvalidInput, gi+1, k+1)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// roundLabelPattern matches numbered group labels such as "Rnd 8",
+// "Round 3", "Rows 2-5" or "Rnds 8–15".
+var roundLabelPattern = regexp.MustCompile(`^(?i)(rnds?|rounds?|rows?|r)\.?\s*(\d+)(?:\s*[-–]\s*(\d+))?$`)
+
+// roundRange is a numbered label covering rounds or rows start to end.
+type roundRange struct {
+	word       string // Label word as written, e.g. "Rnd" or "Rows"
+	start, end int
+}
+
+// parseRoundRange parses a numbered round or row label.
+func parseRoundRange(label string) (roundRange, bool) {
+	m := roundLabelPattern.FindStringSubmatch(strings.TrimSpace(label))
+	if m == nil {
+		return roundRange{}, false
+	}
+	r := roundRange{word: m[1]}
+	r.start, _ = strconv.Atoi(m[2])
+	r.end = r.start
+	if m[3] != "" {
+		r.end, _ = strconv.Atoi(m[3])
+	}
+	if r.start < 1 || r.end < r.start {
+		return roundRange{}, false
+	}
+	return r, true
+}
+
+// count returns the number of rounds the range covers.
+func (r roundRange) count() int {
+	return r.end - r.start + 1
+}
+
+// String writes the range as a label, "Rnd 8" or "Rnds 8–15".
+func (r roundRange) String() string {
+	if r.start == r.end {
+		return fmt.Sprintf("%s %d", singularRoundWord(r.word), r.start)
+	}
+	return fmt.Sprintf("%s %d–%d", pluralRoundWord(r.word), r.start, r.end)
+}
+
+func singularRoundWord(word string) string {
+	if len(word) > 1 && (word[len(word)-1] == 's' || word[len(word)-1] == 'S') {
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func pluralRoundWord(word string) string {
+	word = singularRoundWord(word)
+	if len(word) == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// groupRounds returns the rounds a group's label numbers, when the label is a
+// round or row number or range that accounts for each of the group's
+// repeats: "Rnd 8" worked once, or "Rnds 8–15" worked 8 times. Groups whose
+// repeats vary by size don't number their rounds.
+func groupRounds(g *domain.InstructionGroup) (roundRange, bool) {
+	if len(g.SizeRepeatCounts) > 0 {
+		return roundRange{}, false
+	}
+	r, ok := parseRoundRange(g.Label)
+	if !ok || r.count() != g.RepeatCount {
+		return roundRange{}, false
+	}
+	return r, true
+}
+
+// GroupRoundLabel returns the label for one repeat (0-based) of the group at
+// gi. When the group is part of a range of identical rounds, whether a single
+// group labelled "Rnds 8–15" or consecutive groups "Rnd 8" to "Rnd 15" worked
+// the same way, it names the round being worked, e.g. "Rnd 11 (of 8–15)";
+// otherwise it is the group's label.
+func GroupRoundLabel(pattern *domain.Pattern, gi, repeat int) string {
+	g := &pattern.InstructionGroups[gi]
+	r, ok := groupRounds(g)
+	if !ok || repeat < 0 || repeat >= r.count() {
+		return g.Label
+	}
+	run := roundRun(pattern, gi)
+	if run.count() == 1 {
+		return g.Label
+	}
+	return fmt.Sprintf("%s %d (of %d–%d)", singularRoundWord(r.word), r.start+repeat, run.start, run.end)
+}
+
+// roundRun returns the range of rounds covered by the numbered group at gi.
+// A single round is extended over the neighbouring single rounds in its piece
+// that continue its numbering with the same stitches, as collapseRounds
+// would write them.
+func roundRun(pattern *domain.Pattern, gi int) roundRange {
+	groups := pattern.InstructionGroups
+	first, last := 0, len(groups)-1
+	if len(pattern.Pieces) > 0 {
+		first, last = pattern.PieceSpan(gi)
+	}
+
+	run, _ := groupRounds(&groups[gi])
+	if run.count() > 1 {
+		return run
+	}
+
+	body := func(i int) string {
+		return strings.TrimPrefix(RenderGroupText(&groups[i], pattern.PatternStitches), groups[i].Label)
+	}
+	want := body(gi)
+	sameRun := func(i int) (roundRange, bool) {
+		r, ok := groupRounds(&groups[i])
+		if !ok || r.count() > 1 || !strings.EqualFold(singularRoundWord(r.word), singularRoundWord(run.word)) || body(i) != want {
+			return roundRange{}, false
+		}
+		return r, true
+	}
+
+	for i := gi - 1; i >= first; i-- {
+		r, ok := sameRun(i)
+		if !ok || r.end != run.start-1 {
+			break
+		}
+		run.start = r.start
+	}
+	for i := gi + 1; i <= last; i++ {
+		r, ok := sameRun(i)
+		if !ok || r.start != run.end+1 {
+			break
+		}
+		run.end = r.end
+	}
+	return run
+}
+
+// numberGroupLabels gives groups with an empty label a round or row number
+// derived from the pattern type, continuing from the numbered groups before
+// them: "Rnd 8", or "Rnds 8–15" for a group worked 8 times. Numbering starts
+// again at 1 with each piece. Groups whose repeats vary by size are labelled
+// with their first round only.
+func numberGroupLabels(pattern *domain.Pattern) {
+	word := "Rnd"
+	if pattern.PatternType == domain.PatternTypeRow {
+		word = "Row"
+	}
+
+	next := 1
+	for gi := range pattern.InstructionGroups {
+		g := &pattern.InstructionGroups[gi]
+		if first, _ := pattern.PieceSpan(gi); first == gi && pattern.PieceAt(gi) != nil {
+			next = 1
+		}
+
+		if strings.TrimSpace(g.Label) == "" {
+			r := roundRange{word: word, start: next, end: next + max(g.RepeatCount, 1) - 1}
+			if len(g.SizeRepeatCounts) > 0 {
+				r.end = r.start
+			}
+			g.Label = r.String()
+			next += max(g.RepeatCount, 1)
+			continue
+		}
+		if r, ok := parseRoundRange(g.Label); ok {
+			next = r.end + 1
+			if r.count() == 1 && g.RepeatCount > 1 {
+				next = r.start + g.RepeatCount
+			}
+		}
+	}
+}
+
+// renderedGroup is one group's rendered text, split so that runs of
+// identical numbered rounds can be collapsed into a range.
+type renderedGroup struct {
+	label  string
+	body   string // Everything after the label, e.g. ": sc around (36)"
+	rounds roundRange
+	ok     bool // Whether rounds holds the group's round numbers
+}
+
+// collapseRounds merges consecutive single rounds with identical text whose
+// numbers follow on from each other, e.g. "Rnd 8" to "Rnd 15" worked the
+// same way become "Rnds 8–15".
+func collapseRounds(groups []renderedGroup) []string {
+	var lines []string
+	for i := 0; i < len(groups); i++ {
+		g := groups[i]
+		if !g.ok {
+			lines = append(lines, g.label+g.body)
+			continue
+		}
+		r := g.rounds
+		j := i + 1
+		for ; j < len(groups); j++ {
+			next := groups[j]
+			if !next.ok || next.body != g.body || next.rounds.start != r.end+1 ||
+				!strings.EqualFold(singularRoundWord(next.rounds.word), singularRoundWord(r.word)) {
+				break
+			}
+			r.end = next.rounds.end
+		}
+		if j == i+1 {
+			lines = append(lines, g.label+g.body)
+			continue
+		}
+		lines = append(lines, r.String()+g.body)
+		i = j - 1
+	}
+	return lines
+}
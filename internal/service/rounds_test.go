@@ -0,0 +1,195 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func scRound(label string, count int) domain.InstructionGroup {
+	return domain.InstructionGroup{Label: label, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+		{PatternStitchID: 1, Count: count, RepeatCount: 1},
+	}}
+}
+
+func TestParseRoundRange(t *testing.T) {
+	tests := []struct {
+		label      string
+		ok         bool
+		start, end int
+	}{
+		{"Rnd 8", true, 8, 8},
+		{"Rnds 8–15", true, 8, 15},
+		{"Rows 2-5", true, 2, 5},
+		{"round 3", true, 3, 3},
+		{"R12", true, 12, 12},
+		{"Rnds 15-8", false, 0, 0},
+		{"Rnd 0", false, 0, 0},
+		{"Body", false, 0, 0},
+		{"Rnd 3 edging", false, 0, 0},
+	}
+	for _, tt := range tests {
+		r, ok := parseRoundRange(tt.label)
+		if ok != tt.ok || r.start != tt.start || r.end != tt.end {
+			t.Errorf("parseRoundRange(%q) = %d-%d %v, want %d-%d %v", tt.label, r.start, r.end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+
+	if got := (roundRange{word: "Rnd", start: 8, end: 15}).String(); got != "Rnds 8–15" {
+		t.Errorf("range label = %q, want %q", got, "Rnds 8–15")
+	}
+	if got := (roundRange{word: "Rows", start: 4, end: 4}).String(); got != "Row 4" {
+		t.Errorf("single row label = %q, want %q", got, "Row 4")
+	}
+}
+
+func TestRenderPatternText_CollapsesIdenticalRounds(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			scRound("Rnd 7", 30),
+			scRound("Rnd 8", 36),
+			scRound("Rnd 9", 36),
+			scRound("Rnd 10", 36),
+			scRound("Rnd 12", 36), // Not consecutive
+			scRound("Row 13", 36), // Different word
+			scRound("Edging", 36),
+		},
+	}
+
+	got := RenderPatternText(pattern)
+	want := "Rnd 7: 30 sc (30)\n" +
+		"Rnds 8–10: 36 sc (36)\n" +
+		"Rnd 12: 36 sc (36)\n" +
+		"Row 13: 36 sc (36)\n" +
+		"Edging: 36 sc (36)"
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	written := RenderPatternTextStyle(pattern, TextStyleWritten)
+	if want := "Rnds 8–10: Work 36 single crochet, for a total of 36 stitches."; !slices.Contains(strings.Split(written, "\n"), want) {
+		t.Errorf("written style missing %q:\n%s", want, written)
+	}
+}
+
+func TestRenderPatternText_CollapseStopsAtPieces(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		Pieces:          []domain.PatternPiece{{Name: "Head", MakeCount: 1}, {Name: "Body", MakeCount: 1}},
+		InstructionGroups: []domain.InstructionGroup{
+			scRound("Rnd 1", 6),
+			scRound("Rnd 2", 6),
+			scRound("Rnd 3", 6),
+		},
+	}
+	pattern.InstructionGroups[2].PieceIndex = 1
+
+	got := RenderPatternText(pattern)
+	want := "Head\nRnds 1–2: 6 sc (6)\n\nBody\nRnd 3: 6 sc (6)"
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestParsePatternText_RoundRange(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			scRound("Rnd 1", 6),
+			scRound("Rnd 2", 6),
+			scRound("Rnd 3", 6),
+		},
+	}
+	text := RenderPatternText(pattern)
+	if text != "Rnds 1–3: 6 sc (6)" {
+		t.Fatalf("rendered %q", text)
+	}
+
+	parsed, errs := ParsePatternText(text, notationTestLibrary())
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	g := parsed.InstructionGroups[0]
+	if g.Label != "Rnds 1–3" || g.RepeatCount != 3 {
+		t.Errorf("group = %q ×%d, want Rnds 1–3 ×3", g.Label, g.RepeatCount)
+	}
+	if StitchCount(parsed) != StitchCount(pattern) {
+		t.Errorf("stitch count = %d, want %d", StitchCount(parsed), StitchCount(pattern))
+	}
+}
+
+func TestGroupRoundLabel(t *testing.T) {
+	ranged := scRound("Rnds 8–15", 36)
+	ranged.RepeatCount = 8
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			ranged,
+			scRound("Rnd 16", 30),
+			scRound("Rnd 17", 30),
+			scRound("Rnd 18", 24),
+			scRound("Finish", 24),
+		},
+	}
+
+	tests := []struct {
+		gi, repeat int
+		want       string
+	}{
+		{0, 0, "Rnd 8 (of 8–15)"},
+		{0, 3, "Rnd 11 (of 8–15)"},
+		{0, 8, "Rnds 8–15"},
+		{1, 0, "Rnd 16 (of 16–17)"},
+		{2, 0, "Rnd 17 (of 16–17)"},
+		{3, 0, "Rnd 18"},
+		{4, 0, "Finish"},
+	}
+	for _, tt := range tests {
+		if got := GroupRoundLabel(pattern, tt.gi, tt.repeat); got != tt.want {
+			t.Errorf("GroupRoundLabel(%d, %d) = %q, want %q", tt.gi, tt.repeat, got, tt.want)
+		}
+	}
+
+	// A label that doesn't match the group's repeats is left alone.
+	pattern.InstructionGroups[0].RepeatCount = 4
+	if got := GroupRoundLabel(pattern, 0, 1); got != "Rnds 8–15" {
+		t.Errorf("mismatched range = %q, want the plain label", got)
+	}
+}
+
+func TestNumberGroupLabels(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternType: domain.PatternTypeRound,
+		Pieces:      []domain.PatternPiece{{Name: "Head", MakeCount: 1}, {Name: "Arm", MakeCount: 2}},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "", RepeatCount: 1},
+			{Label: "Rnd 2", RepeatCount: 1},
+			{Label: "", RepeatCount: 8},
+			{Label: "Stuff the head", RepeatCount: 1},
+			{Label: "", RepeatCount: 1},
+			{Label: "", RepeatCount: 1, PieceIndex: 1},
+			{Label: "  ", RepeatCount: 3, SizeRepeatCounts: []int{3, 4}, PieceIndex: 1},
+			{Label: "", RepeatCount: 1, PieceIndex: 1},
+		},
+	}
+	numberGroupLabels(pattern)
+
+	want := []string{"Rnd 1", "Rnd 2", "Rnds 3–10", "Stuff the head", "Rnd 11", "Rnd 1", "Rnd 2", "Rnd 5"}
+	for i, g := range pattern.InstructionGroups {
+		if g.Label != want[i] {
+			t.Errorf("group %d label = %q, want %q", i, g.Label, want[i])
+		}
+	}
+
+	rows := &domain.Pattern{
+		PatternType:       domain.PatternTypeRow,
+		InstructionGroups: []domain.InstructionGroup{{Label: "Foundation", RepeatCount: 1}, {RepeatCount: 1}},
+	}
+	numberGroupLabels(rows)
+	if got := rows.InstructionGroups[1].Label; got != "Row 1" {
+		t.Errorf("row label = %q, want Row 1", got)
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// searchUsesPattern matches a stitch filter in a search query: "uses: puff",
+// or `uses:"front post dc"` for a name with spaces.
+var searchUsesPattern = regexp.MustCompile(`(?i)\buses:\s*(?:"([^"]*)"|(\S+))`)
+
+// parseSearchQuery splits a pattern search query into its free text and the
+// stitches named by "uses:" filters, e.g. "popcorn hat uses: puff" searches
+// for "popcorn hat" in patterns that use puff stitches.
+func parseSearchQuery(q string) (string, []string) {
+	var uses []string
+	for _, m := range searchUsesPattern.FindAllStringSubmatch(q, -1) {
+		if stitch := strings.TrimSpace(m[1] + m[2]); stitch != "" {
+			uses = append(uses, stitch)
+		}
+	}
+	text := searchUsesPattern.ReplaceAllString(q, " ")
+	return strings.Join(strings.Fields(text), " "), uses
+}
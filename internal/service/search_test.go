@@ -0,0 +1,27 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		text string
+		uses []string
+	}{
+		{"popcorn hat", "popcorn hat", nil},
+		{"popcorn hat uses: puff", "popcorn hat", []string{"puff"}},
+		{"Uses:puff hat uses: sc", "hat", []string{"puff", "sc"}},
+		{`uses: "front post dc" blanket`, "blanket", []string{"front post dc"}},
+		{"uses:", "uses:", nil},
+		{"houses: 3", "houses: 3", nil},
+	}
+	for _, tt := range tests {
+		text, uses := parseSearchQuery(tt.q)
+		if text != tt.text || !slices.Equal(uses, tt.uses) {
+			t.Errorf("parseSearchQuery(%q) = %q, %q; want %q, %q", tt.q, text, uses, tt.text, tt.uses)
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/mail"
+	"slices"
+	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 )
@@ -22,9 +25,14 @@ func NewShareService(shares domain.PatternShareRepository, patterns domain.Patte
 	return &ShareService{shares: shares, patterns: patterns, users: users}
 }
 
-// CreateGlobalShare creates a global share link for a pattern.
-// If one already exists, returns it (idempotent).
-func (s *ShareService) CreateGlobalShare(ctx context.Context, userID, patternID int64) (*domain.PatternShare, error) {
+// CreateGlobalShare creates a global share link for a pattern with the given
+// permission. If an unexpired one with the same permission already exists,
+// returns it (idempotent).
+func (s *ShareService) CreateGlobalShare(ctx context.Context, userID, patternID int64, permission domain.SharePermission) (*domain.PatternShare, error) {
+	if err := validateSharePermission(permission); err != nil {
+		return nil, err
+	}
+
 	pattern, err := s.patterns.GetByID(ctx, patternID)
 	if err != nil {
 		return nil, err
@@ -42,7 +50,7 @@ func (s *ShareService) CreateGlobalShare(ctx context.Context, userID, patternID
 		return nil, fmt.Errorf("list shares: %w", err)
 	}
 	for _, share := range existing {
-		if share.ShareType == domain.ShareTypeGlobal {
+		if share.ShareType == domain.ShareTypeGlobal && share.Permission == permission && !share.Expired(time.Now()) {
 			return &share, nil
 		}
 	}
@@ -53,9 +61,10 @@ func (s *ShareService) CreateGlobalShare(ctx context.Context, userID, patternID
 	}
 
 	share := &domain.PatternShare{
-		PatternID: patternID,
-		Token:     token,
-		ShareType: domain.ShareTypeGlobal,
+		PatternID:  patternID,
+		Token:      token,
+		ShareType:  domain.ShareTypeGlobal,
+		Permission: permission,
 	}
 	if err := s.shares.Create(ctx, share); err != nil {
 		return nil, fmt.Errorf("create share: %w", err)
@@ -63,12 +72,16 @@ func (s *ShareService) CreateGlobalShare(ctx context.Context, userID, patternID
 	return share, nil
 }
 
-// CreateEmailShare creates an email-bound share link for a pattern.
-// If one already exists for the same email, returns it (idempotent).
-func (s *ShareService) CreateEmailShare(ctx context.Context, userID, patternID int64, recipientEmail string) (*domain.PatternShare, error) {
+// CreateEmailShare creates an email-bound share link for a pattern with the
+// given permission. If an unexpired one with the same permission already
+// exists for the same email, returns it (idempotent).
+func (s *ShareService) CreateEmailShare(ctx context.Context, userID, patternID int64, recipientEmail string, permission domain.SharePermission) (*domain.PatternShare, error) {
 	if _, err := mail.ParseAddress(recipientEmail); err != nil {
 		return nil, fmt.Errorf("%w: invalid email address", domain.ErrInvalidInput)
 	}
+	if err := validateSharePermission(permission); err != nil {
+		return nil, err
+	}
 
 	pattern, err := s.patterns.GetByID(ctx, patternID)
 	if err != nil {
@@ -96,7 +109,7 @@ func (s *ShareService) CreateEmailShare(ctx context.Context, userID, patternID i
 		return nil, fmt.Errorf("list shares: %w", err)
 	}
 	for _, share := range existing {
-		if share.ShareType == domain.ShareTypeEmail && share.RecipientEmail == recipientEmail {
+		if share.ShareType == domain.ShareTypeEmail && share.RecipientEmail == recipientEmail && share.Permission == permission && !share.Expired(time.Now()) {
 			return &share, nil
 		}
 	}
@@ -111,6 +124,7 @@ func (s *ShareService) CreateEmailShare(ctx context.Context, userID, patternID i
 		Token:          token,
 		ShareType:      domain.ShareTypeEmail,
 		RecipientEmail: recipientEmail,
+		Permission:     permission,
 	}
 	if err := s.shares.Create(ctx, share); err != nil {
 		return nil, fmt.Errorf("create email share: %w", err)
@@ -118,6 +132,124 @@ func (s *ShareService) CreateEmailShare(ctx context.Context, userID, patternID i
 	return share, nil
 }
 
+// Publish publishes a pattern to the public gallery, where any signed-in
+// user can find it, view it and save a copy. Publishing a pattern that is
+// already published returns its existing share.
+func (s *ShareService) Publish(ctx context.Context, userID, patternID int64) (*domain.PatternShare, error) {
+	pattern, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+	if pattern.SharedFromUserID != nil {
+		return nil, fmt.Errorf("%w: cannot publish a received pattern", domain.ErrInvalidInput)
+	}
+
+	share, err := s.publicShare(ctx, patternID)
+	if err == nil {
+		return share, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	share = &domain.PatternShare{
+		PatternID:  patternID,
+		Token:      token,
+		ShareType:  domain.ShareTypePublic,
+		Permission: domain.SharePermissionSaveLocked,
+	}
+	if err := s.shares.Create(ctx, share); err != nil {
+		return nil, fmt.Errorf("create public share: %w", err)
+	}
+	return share, nil
+}
+
+// Unpublish removes a pattern from the gallery and invalidates its gallery
+// link. Copies already saved from it are kept.
+func (s *ShareService) Unpublish(ctx context.Context, userID, patternID int64) error {
+	pattern, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return err
+	}
+	if pattern.UserID != userID {
+		return domain.ErrUnauthorized
+	}
+
+	share, err := s.publicShare(ctx, patternID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.shares.Delete(ctx, share.ID)
+}
+
+// PublishedShare returns the share that publishes a pattern to the gallery,
+// or ErrNotFound when the pattern isn't published.
+func (s *ShareService) PublishedShare(ctx context.Context, patternID int64) (*domain.PatternShare, error) {
+	return s.publicShare(ctx, patternID)
+}
+
+// SearchGallery returns a page of the patterns published to the gallery,
+// filtered like a user's own list, with their owners' display names by user
+// ID. "uses:" terms in the query restrict it to patterns using those stitches.
+func (s *ShareService) SearchGallery(ctx context.Context, viewerUserID int64, filter domain.PatternFilter, page domain.PatternPage) (domain.PatternSummaryPage, map[int64]string, error) {
+	var uses []string
+	filter.Query, uses = parseSearchQuery(filter.Query)
+	filter.Uses = append(slices.Clip(filter.Uses), uses...)
+
+	result, err := s.patterns.SearchPublishedSummaries(ctx, viewerUserID, filter, page)
+	if err != nil {
+		return domain.PatternSummaryPage{}, nil, err
+	}
+
+	owners := make(map[int64]string)
+	for _, p := range result.Summaries {
+		if _, ok := owners[p.UserID]; ok {
+			continue
+		}
+		owner, err := s.users.GetByID(ctx, p.UserID)
+		if err != nil {
+			return domain.PatternSummaryPage{}, nil, fmt.Errorf("get owner: %w", err)
+		}
+		owners[p.UserID] = owner.DisplayName
+	}
+	return result, owners, nil
+}
+
+// SaveFromGallery saves a published pattern to the viewer's library, as
+// SaveSharedPattern does for its gallery share.
+func (s *ShareService) SaveFromGallery(ctx context.Context, viewerUserID, patternID int64, withTags bool) (*domain.Pattern, error) {
+	share, err := s.publicShare(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	return s.SaveSharedPattern(ctx, viewerUserID, share.Token, withTags)
+}
+
+// publicShare returns the pattern's gallery share, or ErrNotFound.
+func (s *ShareService) publicShare(ctx context.Context, patternID int64) (*domain.PatternShare, error) {
+	existing, err := s.shares.ListByPattern(ctx, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("list shares: %w", err)
+	}
+	for _, share := range existing {
+		if share.ShareType == domain.ShareTypePublic {
+			return &share, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
 // RevokeShareForPattern revokes a single share after verifying pattern ownership
 // and that the share belongs to the specified pattern.
 func (s *ShareService) RevokeShareForPattern(ctx context.Context, userID, patternID, shareID int64) error {
@@ -140,7 +272,8 @@ func (s *ShareService) RevokeShareForPattern(ctx context.Context, userID, patter
 	return s.shares.Delete(ctx, shareID)
 }
 
-// RevokeAllShares revokes all shares for a pattern.
+// RevokeAllShares revokes all of a pattern's global and email shares. A
+// pattern published to the gallery stays published; use Unpublish for that.
 func (s *ShareService) RevokeAllShares(ctx context.Context, userID, patternID int64) error {
 	pattern, err := s.patterns.GetByID(ctx, patternID)
 	if err != nil {
@@ -150,45 +283,52 @@ func (s *ShareService) RevokeAllShares(ctx context.Context, userID, patternID in
 		return domain.ErrUnauthorized
 	}
 
-	return s.shares.DeleteAllByPattern(ctx, patternID)
+	return s.shares.DeleteNonPublicByPattern(ctx, patternID)
 }
 
 // GetPatternByShareToken loads a pattern by share token, enforcing access rules.
+// A viewerUserID of 0 is a visitor who isn't logged in, who can only open
+// global links; other shares return ErrUnauthorized until they log in.
+// Expired links return ErrShareExpired.
 func (s *ShareService) GetPatternByShareToken(ctx context.Context, viewerUserID int64, token string) (*domain.Pattern, error) {
-	share, err := s.shares.GetByToken(ctx, token)
+	share, err := s.resolveShare(ctx, viewerUserID, token)
 	if err != nil {
 		return nil, err
 	}
+	return s.patterns.GetByID(ctx, share.PatternID)
+}
 
-	if share.ShareType == domain.ShareTypeEmail {
-		viewer, err := s.users.GetByID(ctx, viewerUserID)
-		if err != nil {
-			return nil, fmt.Errorf("get viewer: %w", err)
-		}
-		if viewer.Email != share.RecipientEmail {
-			return nil, domain.ErrUnauthorized
+// ViewSharedPattern loads a pattern by share token as GetPatternByShareToken
+// does, recording the view for the owner unless the viewer is the owner.
+func (s *ShareService) ViewSharedPattern(ctx context.Context, viewerUserID int64, token string) (*domain.Pattern, error) {
+	share, err := s.resolveShare(ctx, viewerUserID, token)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := s.patterns.GetByID(ctx, share.PatternID)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.UserID != viewerUserID {
+		if err := s.recordEvent(ctx, share.ID, domain.ShareEventView, viewerUserID); err != nil {
+			return nil, err
 		}
 	}
-
-	return s.patterns.GetByID(ctx, share.PatternID)
+	return pattern, nil
 }
 
-// SaveSharedPattern saves a shared pattern to the viewer's library as a locked snapshot.
-func (s *ShareService) SaveSharedPattern(ctx context.Context, viewerUserID int64, token string) (*domain.Pattern, error) {
-	share, err := s.shares.GetByToken(ctx, token)
+// SaveSharedPattern saves a shared pattern to the viewer's library as a locked
+// snapshot, or as an editable fork if the share allows it. With withTags, the
+// copy keeps the owner's tags, added to the viewer's own. View-only links
+// return ErrShareViewOnly, and links that have been saved from MaxSaves times
+// return ErrShareLimitReached.
+func (s *ShareService) SaveSharedPattern(ctx context.Context, viewerUserID int64, token string, withTags bool) (*domain.Pattern, error) {
+	share, err := s.resolveShare(ctx, viewerUserID, token)
 	if err != nil {
 		return nil, err
 	}
-
-	// Enforce email-bound access.
-	if share.ShareType == domain.ShareTypeEmail {
-		viewer, err := s.users.GetByID(ctx, viewerUserID)
-		if err != nil {
-			return nil, fmt.Errorf("get viewer: %w", err)
-		}
-		if viewer.Email != share.RecipientEmail {
-			return nil, domain.ErrUnauthorized
-		}
+	if share.Permission == domain.SharePermissionView {
+		return nil, domain.ErrShareViewOnly
 	}
 
 	pattern, err := s.patterns.GetByID(ctx, share.PatternID)
@@ -207,7 +347,7 @@ func (s *ShareService) SaveSharedPattern(ctx context.Context, viewerUserID int64
 		return nil, fmt.Errorf("list shared patterns: %w", err)
 	}
 	for _, sp := range sharedPatterns {
-		if sp.SharedFromUserID != nil && *sp.SharedFromUserID == pattern.UserID && sp.Name == pattern.Name {
+		if sp.SavedFrom(pattern) {
 			return nil, domain.ErrAlreadySaved
 		}
 	}
@@ -218,7 +358,196 @@ func (s *ShareService) SaveSharedPattern(ctx context.Context, viewerUserID int64
 		return nil, fmt.Errorf("get owner: %w", err)
 	}
 
-	return s.patterns.DuplicateAsShared(ctx, pattern.ID, viewerUserID, pattern.UserID, owner.DisplayName)
+	// The copy and its save event are written together, against the limit.
+	return s.patterns.DuplicateAsShared(ctx, share, viewerUserID, pattern.UserID, owner.DisplayName, withTags)
+}
+
+// SourceUpdateAvailable reports whether the pattern a received snapshot was
+// saved from has changed since the snapshot was taken or last updated, while
+// the share it was saved through is still live.
+func (s *ShareService) SourceUpdateAvailable(ctx context.Context, pattern *domain.Pattern) (bool, error) {
+	if !pattern.IsReceivedSnapshot() || pattern.SharedFromPatternID == nil {
+		return false, nil
+	}
+	if _, err := s.sourceShare(ctx, pattern); err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) {
+			return false, nil
+		}
+		return false, err
+	}
+	source, err := s.patterns.GetByID(ctx, *pattern.SharedFromPatternID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("get source pattern: %w", err)
+	}
+	return pattern.SourceUpdated(source.UpdatedAt), nil
+}
+
+// PullSourceUpdate replaces a received snapshot's content and images with the
+// current version of the pattern it was saved from, after verifying
+// ownership. The copy keeps its tags and attribution. Returns ErrInvalidInput for
+// editable forks, whose content is the user's own, and for copies that are
+// already up to date, ErrNotFound once the source has been deleted, and
+// ErrUnauthorized once the share it was saved through is revoked or expired.
+func (s *ShareService) PullSourceUpdate(ctx context.Context, userID, patternID int64) (*domain.Pattern, error) {
+	pattern, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+	if !pattern.IsReceivedSnapshot() {
+		return nil, fmt.Errorf("%w: only saved snapshots can be updated from their source", domain.ErrInvalidInput)
+	}
+	if pattern.SharedFromPatternID == nil {
+		return nil, domain.ErrNotFound
+	}
+	share, err := s.sourceShare(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.patterns.GetByID(ctx, share.PatternID)
+	if err != nil {
+		return nil, err
+	}
+	if !pattern.SourceUpdated(source.UpdatedAt) {
+		return nil, fmt.Errorf("%w: pattern is already up to date", domain.ErrInvalidInput)
+	}
+
+	updated := *pattern
+	updated.Name = source.Name
+	updated.Description = source.Description
+	updated.PatternType = source.PatternType
+	updated.HookSize = source.HookSize
+	updated.YarnWeight = source.YarnWeight
+	updated.Difficulty = source.Difficulty
+	updated.Sizes = source.Sizes
+	updated.Pieces = source.Pieces
+	updated.Colors = source.Colors
+	updated.PatternStitches = source.PatternStitches
+	updated.InstructionGroups = source.InstructionGroups
+	if err := s.patterns.UpdateFromSource(ctx, &updated, source); err != nil {
+		return nil, fmt.Errorf("update pattern from source: %w", err)
+	}
+	return &updated, nil
+}
+
+// SetShareLimits sets when a share link expires and how many times it can be
+// saved from, after verifying pattern ownership. A nil expiresAt and a
+// maxSaves of 0 remove the limits. Gallery listings can't be limited.
+func (s *ShareService) SetShareLimits(ctx context.Context, userID, patternID, shareID int64, expiresAt *time.Time, maxSaves int) error {
+	pattern, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return err
+	}
+	if pattern.UserID != userID {
+		return domain.ErrUnauthorized
+	}
+
+	share, err := s.shares.GetByID(ctx, shareID)
+	if err != nil {
+		return err
+	}
+	if share.PatternID != patternID {
+		return domain.ErrNotFound
+	}
+	if share.ShareType == domain.ShareTypePublic {
+		return fmt.Errorf("%w: gallery listings can't be limited; unpublish the pattern instead", domain.ErrInvalidInput)
+	}
+	if maxSaves < 0 {
+		return fmt.Errorf("%w: maximum saves can't be negative", domain.ErrInvalidInput)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expiry must be in the future", domain.ErrInvalidInput)
+	}
+
+	return s.shares.UpdateLimits(ctx, shareID, expiresAt, maxSaves)
+}
+
+// ListShareEvents returns who opened and saved each share of a pattern,
+// newest first, keyed by share ID (owner only).
+func (s *ShareService) ListShareEvents(ctx context.Context, userID, patternID int64) (map[int64][]domain.ShareEvent, error) {
+	pattern, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	return s.shares.ListEventsByPattern(ctx, patternID)
+}
+
+// resolveShare looks up a share by token and checks that the viewer may use
+// it: it hasn't expired, visitors who aren't logged in only use global links,
+// and email-bound links are used by their recipient.
+func (s *ShareService) resolveShare(ctx context.Context, viewerUserID int64, token string) (*domain.PatternShare, error) {
+	share, err := s.shares.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if share.Expired(time.Now()) {
+		return nil, domain.ErrShareExpired
+	}
+
+	if viewerUserID == 0 && share.ShareType != domain.ShareTypeGlobal {
+		return nil, domain.ErrUnauthorized
+	}
+	if share.ShareType == domain.ShareTypeEmail {
+		viewer, err := s.users.GetByID(ctx, viewerUserID)
+		if err != nil {
+			return nil, fmt.Errorf("get viewer: %w", err)
+		}
+		if viewer.Email != share.RecipientEmail {
+			return nil, domain.ErrUnauthorized
+		}
+	}
+	return share, nil
+}
+
+// sourceShare returns the share a received copy was saved through, checked
+// with resolveShare for the copy's owner. It returns ErrUnauthorized once the
+// share has been revoked or has expired, or for copies saved before shares
+// were tracked.
+func (s *ShareService) sourceShare(ctx context.Context, pattern *domain.Pattern) (*domain.PatternShare, error) {
+	if pattern.SharedFromShareID == nil {
+		return nil, domain.ErrUnauthorized
+	}
+	share, err := s.shares.GetByID(ctx, *pattern.SharedFromShareID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, fmt.Errorf("get source share: %w", err)
+	}
+	share, err = s.resolveShare(ctx, pattern.UserID, share.Token)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrShareExpired) || errors.Is(err, domain.ErrUnauthorized) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if pattern.SharedFromPatternID == nil || share.PatternID != *pattern.SharedFromPatternID {
+		return nil, domain.ErrUnauthorized
+	}
+	return share, nil
+}
+
+// recordEvent logs a view or save of a share; a viewerUserID of 0 is a
+// visitor who isn't logged in.
+func (s *ShareService) recordEvent(ctx context.Context, shareID int64, eventType domain.ShareEventType, viewerUserID int64) error {
+	event := &domain.ShareEvent{ShareID: shareID, Type: eventType}
+	if viewerUserID != 0 {
+		event.UserID = &viewerUserID
+	}
+	if err := s.shares.RecordEvent(ctx, event); err != nil {
+		return fmt.Errorf("record share %s: %w", eventType, err)
+	}
+	return nil
 }
 
 // ListSharesForPattern returns all active shares for a pattern (owner only).
@@ -244,6 +573,14 @@ func (s *ShareService) GetShareByToken(ctx context.Context, token string) (*doma
 	return s.shares.GetByToken(ctx, token)
 }
 
+func validateSharePermission(permission domain.SharePermission) error {
+	switch permission {
+	case domain.SharePermissionView, domain.SharePermissionSaveLocked, domain.SharePermissionSaveEditable:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown share permission %q", domain.ErrInvalidInput, permission)
+}
+
 func generateShareToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
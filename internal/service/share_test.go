@@ -3,7 +3,11 @@ package service_test
 import (
 	"context"
 	"errors"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
 	"github.com/msomdec/stitch-map-2/internal/repository/sqlite"
@@ -18,7 +22,7 @@ func newTestShareService(t *testing.T) (*service.ShareService, *service.PatternS
 	stitchRepo := db.Stitches()
 	userRepo := db.Users()
 	return service.NewShareService(shareRepo, patternRepo, userRepo),
-		service.NewPatternService(patternRepo, stitchRepo),
+		service.NewPatternService(patternRepo, stitchRepo, db.Versions(), db.Tags(), db.Collections(), 50),
 		service.NewStitchService(stitchRepo),
 		db
 }
@@ -49,7 +53,7 @@ func TestShareService_CreateGlobalShare_Success(t *testing.T) {
 	userID := seedUserForTest(t, db, "owner@example.com")
 	p := createTestPattern(t, patternSvc, db, userID)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, userID, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, userID, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
@@ -70,12 +74,12 @@ func TestShareService_CreateGlobalShare_Idempotent(t *testing.T) {
 	userID := seedUserForTest(t, db, "idem@example.com")
 	p := createTestPattern(t, patternSvc, db, userID)
 
-	share1, err := shareSvc.CreateGlobalShare(ctx, userID, p.ID)
+	share1, err := shareSvc.CreateGlobalShare(ctx, userID, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("first CreateGlobalShare: %v", err)
 	}
 
-	share2, err := shareSvc.CreateGlobalShare(ctx, userID, p.ID)
+	share2, err := shareSvc.CreateGlobalShare(ctx, userID, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("second CreateGlobalShare: %v", err)
 	}
@@ -92,7 +96,7 @@ func TestShareService_CreateGlobalShare_NonOwner(t *testing.T) {
 	other := seedUserForTest(t, db, "other2@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	_, err := shareSvc.CreateGlobalShare(ctx, other, p.ID)
+	_, err := shareSvc.CreateGlobalShare(ctx, other, p.ID, domain.SharePermissionSaveLocked)
 	if !errors.Is(err, domain.ErrUnauthorized) {
 		t.Fatalf("expected ErrUnauthorized, got %v", err)
 	}
@@ -106,17 +110,17 @@ func TestShareService_CreateGlobalShare_ReshareReceived(t *testing.T) {
 	p := createTestPattern(t, patternSvc, db, owner)
 
 	// Create a share and save it.
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
-	saved, err := shareSvc.SaveSharedPattern(ctx, recipient, share.Token)
+	saved, err := shareSvc.SaveSharedPattern(ctx, recipient, share.Token, false)
 	if err != nil {
 		t.Fatalf("SaveSharedPattern: %v", err)
 	}
 
 	// Recipient should not be able to reshare.
-	_, err = shareSvc.CreateGlobalShare(ctx, recipient, saved.ID)
+	_, err = shareSvc.CreateGlobalShare(ctx, recipient, saved.ID, domain.SharePermissionSaveLocked)
 	if !errors.Is(err, domain.ErrInvalidInput) {
 		t.Fatalf("expected ErrInvalidInput for reshare, got %v", err)
 	}
@@ -128,7 +132,7 @@ func TestShareService_CreateEmailShare_Success(t *testing.T) {
 	userID := seedUserForTest(t, db, "emailowner@example.com")
 	p := createTestPattern(t, patternSvc, db, userID)
 
-	share, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "friend@example.com")
+	share, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "friend@example.com", domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateEmailShare: %v", err)
 	}
@@ -146,12 +150,12 @@ func TestShareService_CreateEmailShare_Idempotent(t *testing.T) {
 	userID := seedUserForTest(t, db, "emailidem@example.com")
 	p := createTestPattern(t, patternSvc, db, userID)
 
-	share1, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "friend@example.com")
+	share1, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "friend@example.com", domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("first CreateEmailShare: %v", err)
 	}
 
-	share2, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "friend@example.com")
+	share2, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "friend@example.com", domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("second CreateEmailShare: %v", err)
 	}
@@ -167,7 +171,7 @@ func TestShareService_CreateEmailShare_InvalidEmail(t *testing.T) {
 	userID := seedUserForTest(t, db, "invalidemail@example.com")
 	p := createTestPattern(t, patternSvc, db, userID)
 
-	_, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "not-an-email")
+	_, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "not-an-email", domain.SharePermissionSaveLocked)
 	if !errors.Is(err, domain.ErrInvalidInput) {
 		t.Fatalf("expected ErrInvalidInput, got %v", err)
 	}
@@ -179,7 +183,7 @@ func TestShareService_CreateEmailShare_SelfShare(t *testing.T) {
 	userID := seedUserForTest(t, db, "self@example.com")
 	p := createTestPattern(t, patternSvc, db, userID)
 
-	_, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "self@example.com")
+	_, err := shareSvc.CreateEmailShare(ctx, userID, p.ID, "self@example.com", domain.SharePermissionSaveLocked)
 	if !errors.Is(err, domain.ErrInvalidInput) {
 		t.Fatalf("expected ErrInvalidInput for self-share, got %v", err)
 	}
@@ -192,7 +196,7 @@ func TestShareService_GetPatternByShareToken_Global(t *testing.T) {
 	viewer := seedUserForTest(t, db, "viewer@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
@@ -224,7 +228,7 @@ func TestShareService_GetPatternByShareToken_EmailMatch(t *testing.T) {
 	recipient := seedUserForTest(t, db, "match@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateEmailShare(ctx, owner, p.ID, "match@example.com")
+	share, err := shareSvc.CreateEmailShare(ctx, owner, p.ID, "match@example.com", domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateEmailShare: %v", err)
 	}
@@ -246,7 +250,7 @@ func TestShareService_GetPatternByShareToken_EmailMismatch(t *testing.T) {
 	wrong := seedUserForTest(t, db, "wrong@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateEmailShare(ctx, owner, p.ID, "correct@example.com")
+	share, err := shareSvc.CreateEmailShare(ctx, owner, p.ID, "correct@example.com", domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateEmailShare: %v", err)
 	}
@@ -258,6 +262,37 @@ func TestShareService_GetPatternByShareToken_EmailMismatch(t *testing.T) {
 	}
 }
 
+func TestShareService_GetPatternByShareToken_Anonymous(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "anonowner@example.com")
+	p := createTestPattern(t, patternSvc, db, owner)
+
+	global, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	email, err := shareSvc.CreateEmailShare(ctx, owner, p.ID, "someone@example.com", domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateEmailShare: %v", err)
+	}
+
+	// A visitor who isn't logged in can open a global link...
+	pattern, err := shareSvc.GetPatternByShareToken(ctx, 0, global.Token)
+	if err != nil {
+		t.Fatalf("GetPatternByShareToken: %v", err)
+	}
+	if pattern.ID != p.ID {
+		t.Fatalf("expected pattern ID %d, got %d", p.ID, pattern.ID)
+	}
+
+	// ...but not an email-bound one.
+	_, err = shareSvc.GetPatternByShareToken(ctx, 0, email.Token)
+	if !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for an anonymous email share, got %v", err)
+	}
+}
+
 func TestShareService_SaveSharedPattern_Success(t *testing.T) {
 	shareSvc, patternSvc, _, db := newTestShareService(t)
 	ctx := context.Background()
@@ -265,12 +300,12 @@ func TestShareService_SaveSharedPattern_Success(t *testing.T) {
 	viewer := seedUserForTest(t, db, "saveviewer@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
 
-	saved, err := shareSvc.SaveSharedPattern(ctx, viewer, share.Token)
+	saved, err := shareSvc.SaveSharedPattern(ctx, viewer, share.Token, false)
 	if err != nil {
 		t.Fatalf("SaveSharedPattern: %v", err)
 	}
@@ -305,19 +340,19 @@ func TestShareService_SaveSharedPattern_DuplicatePrevention(t *testing.T) {
 	viewer := seedUserForTest(t, db, "dupviewer@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
 
 	// First save should succeed.
-	_, err = shareSvc.SaveSharedPattern(ctx, viewer, share.Token)
+	_, err = shareSvc.SaveSharedPattern(ctx, viewer, share.Token, false)
 	if err != nil {
 		t.Fatalf("first SaveSharedPattern: %v", err)
 	}
 
 	// Second save should fail.
-	_, err = shareSvc.SaveSharedPattern(ctx, viewer, share.Token)
+	_, err = shareSvc.SaveSharedPattern(ctx, viewer, share.Token, false)
 	if !errors.Is(err, domain.ErrAlreadySaved) {
 		t.Fatalf("expected ErrAlreadySaved, got %v", err)
 	}
@@ -329,12 +364,12 @@ func TestShareService_SaveSharedPattern_CannotSaveOwn(t *testing.T) {
 	owner := seedUserForTest(t, db, "ownself@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
 
-	_, err = shareSvc.SaveSharedPattern(ctx, owner, share.Token)
+	_, err = shareSvc.SaveSharedPattern(ctx, owner, share.Token, false)
 	if !errors.Is(err, domain.ErrInvalidInput) {
 		t.Fatalf("expected ErrInvalidInput for saving own pattern, got %v", err)
 	}
@@ -347,7 +382,7 @@ func TestShareService_RevokeShare(t *testing.T) {
 	viewer := seedUserForTest(t, db, "revokeviewer@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
@@ -370,11 +405,11 @@ func TestShareService_RevokeAllShares(t *testing.T) {
 	owner := seedUserForTest(t, db, "revokeallowner@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	_, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	_, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
-	_, err = shareSvc.CreateEmailShare(ctx, owner, p.ID, "a@b.com")
+	_, err = shareSvc.CreateEmailShare(ctx, owner, p.ID, "a@b.com", domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateEmailShare: %v", err)
 	}
@@ -403,6 +438,40 @@ func TestShareService_RevokeAllShares(t *testing.T) {
 	}
 }
 
+func TestShareService_RevokeAllSharesKeepsPublication(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "revokepublished@example.com")
+	viewer := seedUserForTest(t, db, "revokepublishedviewer@example.com")
+	p := createTestPattern(t, patternSvc, db, owner)
+
+	if _, err := shareSvc.Publish(ctx, owner, p.ID); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked); err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+
+	if err := shareSvc.RevokeAllShares(ctx, owner, p.ID); err != nil {
+		t.Fatalf("RevokeAllShares: %v", err)
+	}
+
+	shares, err := shareSvc.ListSharesForPattern(ctx, owner, p.ID)
+	if err != nil {
+		t.Fatalf("ListSharesForPattern: %v", err)
+	}
+	if len(shares) != 1 || shares[0].ShareType != domain.ShareTypePublic {
+		t.Fatalf("expected only the public share to remain, got %+v", shares)
+	}
+	page, _, err := shareSvc.SearchGallery(ctx, viewer, domain.PatternFilter{}, domain.PatternPage{})
+	if err != nil {
+		t.Fatalf("SearchGallery: %v", err)
+	}
+	if len(page.Summaries) != 1 || page.Summaries[0].ID != p.ID {
+		t.Fatalf("expected the pattern to stay in the gallery, got %+v", page.Summaries)
+	}
+}
+
 func TestShareService_ReceivedPatternImmutability(t *testing.T) {
 	shareSvc, patternSvc, _, db := newTestShareService(t)
 	ctx := context.Background()
@@ -410,12 +479,12 @@ func TestShareService_ReceivedPatternImmutability(t *testing.T) {
 	recipient := seedUserForTest(t, db, "immut-recip@example.com")
 	p := createTestPattern(t, patternSvc, db, owner)
 
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
 
-	saved, err := shareSvc.SaveSharedPattern(ctx, recipient, share.Token)
+	saved, err := shareSvc.SaveSharedPattern(ctx, recipient, share.Token, false)
 	if err != nil {
 		t.Fatalf("SaveSharedPattern: %v", err)
 	}
@@ -438,7 +507,7 @@ func TestShareService_ReceivedPatternImmutability(t *testing.T) {
 	}
 
 	// Reshare should fail.
-	_, err = shareSvc.CreateGlobalShare(ctx, recipient, saved.ID)
+	_, err = shareSvc.CreateGlobalShare(ctx, recipient, saved.ID, domain.SharePermissionSaveLocked)
 	if !errors.Is(err, domain.ErrInvalidInput) {
 		t.Fatalf("expected ErrInvalidInput for reshare, got %v", err)
 	}
@@ -452,7 +521,7 @@ func TestShareService_HasSharesByPatternIDs(t *testing.T) {
 	p2 := createTestPattern(t, patternSvc, db, owner)
 
 	// Share only p1.
-	_, err := shareSvc.CreateGlobalShare(ctx, owner, p1.ID)
+	_, err := shareSvc.CreateGlobalShare(ctx, owner, p1.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
@@ -481,11 +550,11 @@ func TestShareService_PatternListSections(t *testing.T) {
 	_ = recipPattern
 
 	// Share owner's pattern and save it as recipient.
-	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID)
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
 	if err != nil {
 		t.Fatalf("CreateGlobalShare: %v", err)
 	}
-	_, err = shareSvc.SaveSharedPattern(ctx, recipient, share.Token)
+	_, err = shareSvc.SaveSharedPattern(ctx, recipient, share.Token, false)
 	if err != nil {
 		t.Fatalf("SaveSharedPattern: %v", err)
 	}
@@ -513,3 +582,444 @@ func TestShareService_PatternListSections(t *testing.T) {
 		t.Fatal("expected SharedFromUserID to be set on shared pattern")
 	}
 }
+
+func TestShareService_PublishAndUnpublish(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "publish-owner@example.com")
+	viewer := seedUserForTest(t, db, "publish-viewer@example.com")
+	p := createTestPattern(t, patternSvc, db, owner)
+
+	if _, err := shareSvc.Publish(ctx, viewer, p.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized publishing another user's pattern, got %v", err)
+	}
+	share, err := shareSvc.Publish(ctx, owner, p.ID)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if share.ShareType != domain.ShareTypePublic {
+		t.Fatalf("expected public share type, got %s", share.ShareType)
+	}
+	again, err := shareSvc.Publish(ctx, owner, p.ID)
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if again.ID != share.ID {
+		t.Fatalf("expected publishing twice to reuse the share, got %d and %d", share.ID, again.ID)
+	}
+
+	page, owners, err := shareSvc.SearchGallery(ctx, viewer, domain.PatternFilter{}, domain.PatternPage{})
+	if err != nil {
+		t.Fatalf("SearchGallery: %v", err)
+	}
+	if len(page.Summaries) != 1 || page.Summaries[0].ID != p.ID {
+		t.Fatalf("expected the published pattern in the gallery, got %+v", page.Summaries)
+	}
+	if owners[owner] == "" {
+		t.Errorf("expected the owner's display name, got %v", owners)
+	}
+
+	saved, err := shareSvc.SaveFromGallery(ctx, viewer, p.ID, false)
+	if err != nil {
+		t.Fatalf("SaveFromGallery: %v", err)
+	}
+	if _, err := shareSvc.SaveFromGallery(ctx, viewer, p.ID, false); !errors.Is(err, domain.ErrAlreadySaved) {
+		t.Fatalf("expected ErrAlreadySaved, got %v", err)
+	}
+	if _, err := shareSvc.Publish(ctx, viewer, saved.ID); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput publishing a received pattern, got %v", err)
+	}
+
+	if err := shareSvc.Unpublish(ctx, owner, p.ID); err != nil {
+		t.Fatalf("Unpublish: %v", err)
+	}
+	if err := shareSvc.Unpublish(ctx, owner, p.ID); err != nil {
+		t.Fatalf("Unpublish of an unpublished pattern: %v", err)
+	}
+	if _, err := shareSvc.GetShareByToken(ctx, share.Token); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected the gallery link to stop working, got %v", err)
+	}
+	page, _, err = shareSvc.SearchGallery(ctx, viewer, domain.PatternFilter{}, domain.PatternPage{})
+	if err != nil {
+		t.Fatalf("SearchGallery: %v", err)
+	}
+	if len(page.Summaries) != 0 {
+		t.Fatalf("expected an empty gallery after unpublishing, got %+v", page.Summaries)
+	}
+	if _, err := shareSvc.SaveFromGallery(ctx, viewer, p.ID, false); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound saving an unpublished pattern, got %v", err)
+	}
+	if _, err := patternSvc.GetByID(ctx, saved.ID); err != nil {
+		t.Fatalf("expected the saved copy to outlive unpublishing: %v", err)
+	}
+}
+
+func TestShareService_ShareExpiry(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "expiry-owner@example.com")
+	viewer := seedUserForTest(t, db, "expiry-viewer@example.com")
+	p := createTestPattern(t, patternSvc, db, owner)
+
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if err := shareSvc.SetShareLimits(ctx, owner, p.ID, share.ID, &past, 0); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an expiry in the past, got %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := shareSvc.SetShareLimits(ctx, viewer, p.ID, share.ID, &future, 0); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for a non-owner, got %v", err)
+	}
+	if err := shareSvc.SetShareLimits(ctx, owner, p.ID, share.ID, &future, 0); err != nil {
+		t.Fatalf("SetShareLimits: %v", err)
+	}
+	if _, err := shareSvc.GetPatternByShareToken(ctx, viewer, share.Token); err != nil {
+		t.Fatalf("expected the link to work before it expires: %v", err)
+	}
+
+	// Let the link lapse.
+	if err := db.Shares().UpdateLimits(ctx, share.ID, &past, 0); err != nil {
+		t.Fatalf("UpdateLimits: %v", err)
+	}
+	if _, err := shareSvc.GetPatternByShareToken(ctx, viewer, share.Token); !errors.Is(err, domain.ErrShareExpired) {
+		t.Fatalf("expected ErrShareExpired viewing, got %v", err)
+	}
+	if _, err := shareSvc.SaveSharedPattern(ctx, viewer, share.Token, false); !errors.Is(err, domain.ErrShareExpired) {
+		t.Fatalf("expected ErrShareExpired saving, got %v", err)
+	}
+
+	// Sharing again makes a fresh link rather than returning the expired one.
+	fresh, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	if fresh.ID == share.ID {
+		t.Fatal("expected a new link in place of the expired one")
+	}
+}
+
+func TestShareService_ShareSaveLimitAndEvents(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "limit-owner@example.com")
+	first := seedUserForTest(t, db, "limit-first@example.com")
+	second := seedUserForTest(t, db, "limit-second@example.com")
+	p := createTestPattern(t, patternSvc, db, owner)
+
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	if err := shareSvc.SetShareLimits(ctx, owner, p.ID, share.ID, nil, 1); err != nil {
+		t.Fatalf("SetShareLimits: %v", err)
+	}
+
+	// Views by the owner aren't recorded; anonymous views are.
+	for _, viewer := range []int64{owner, first, 0} {
+		if _, err := shareSvc.ViewSharedPattern(ctx, viewer, share.Token); err != nil {
+			t.Fatalf("ViewSharedPattern(%d): %v", viewer, err)
+		}
+	}
+	if _, err := shareSvc.SaveSharedPattern(ctx, first, share.Token, false); err != nil {
+		t.Fatalf("SaveSharedPattern: %v", err)
+	}
+	if _, err := shareSvc.SaveSharedPattern(ctx, second, share.Token, false); !errors.Is(err, domain.ErrShareLimitReached) {
+		t.Fatalf("expected ErrShareLimitReached past the save limit, got %v", err)
+	}
+
+	if _, err := shareSvc.ListShareEvents(ctx, first, p.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized listing another user's events, got %v", err)
+	}
+	events, err := shareSvc.ListShareEvents(ctx, owner, p.ID)
+	if err != nil {
+		t.Fatalf("ListShareEvents: %v", err)
+	}
+	got := events[share.ID]
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %+v", got)
+	}
+	// Newest first: the save, then the anonymous view, then the first user's view.
+	if got[0].Type != domain.ShareEventSave || got[0].UserID == nil || *got[0].UserID != first || got[0].UserName == "" {
+		t.Errorf("expected the first user's save, got %+v", got[0])
+	}
+	if got[1].Type != domain.ShareEventView || got[1].UserID != nil {
+		t.Errorf("expected an anonymous view, got %+v", got[1])
+	}
+	if got[2].Type != domain.ShareEventView || got[2].UserID == nil || *got[2].UserID != first {
+		t.Errorf("expected the first user's view, got %+v", got[2])
+	}
+
+	// Lifting the limit lets others save again.
+	if err := shareSvc.SetShareLimits(ctx, owner, p.ID, share.ID, nil, 0); err != nil {
+		t.Fatalf("SetShareLimits: %v", err)
+	}
+	if _, err := shareSvc.SaveSharedPattern(ctx, second, share.Token, false); err != nil {
+		t.Fatalf("SaveSharedPattern after lifting the limit: %v", err)
+	}
+}
+
+func TestShareService_SharePermissions(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "perm-owner@example.com")
+	locked := seedUserForTest(t, db, "perm-locked@example.com")
+	editor := seedUserForTest(t, db, "perm-editor@example.com")
+	p := createTestPattern(t, patternSvc, db, owner)
+
+	if _, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, "print"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an unknown permission, got %v", err)
+	}
+
+	viewOnly, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionView)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	if _, err := shareSvc.ViewSharedPattern(ctx, locked, viewOnly.Token); err != nil {
+		t.Fatalf("ViewSharedPattern: %v", err)
+	}
+	if _, err := shareSvc.SaveSharedPattern(ctx, locked, viewOnly.Token, false); !errors.Is(err, domain.ErrShareViewOnly) {
+		t.Fatalf("expected ErrShareViewOnly saving a view-only link, got %v", err)
+	}
+
+	// Each permission gets its own link.
+	lockedShare, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	editable, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveEditable)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	if lockedShare.Token == viewOnly.Token || editable.Token == lockedShare.Token {
+		t.Fatal("expected a separate link per permission")
+	}
+
+	snapshot, err := shareSvc.SaveSharedPattern(ctx, locked, lockedShare.Token, false)
+	if err != nil {
+		t.Fatalf("SaveSharedPattern: %v", err)
+	}
+	if !snapshot.Locked || snapshot.SharePermission != domain.SharePermissionSaveLocked {
+		t.Errorf("expected a locked snapshot, got locked=%v permission=%q", snapshot.Locked, snapshot.SharePermission)
+	}
+	if err := patternSvc.Update(ctx, locked, snapshot); !errors.Is(err, domain.ErrPatternLocked) {
+		t.Fatalf("expected ErrPatternLocked editing a snapshot, got %v", err)
+	}
+
+	fork, err := shareSvc.SaveSharedPattern(ctx, editor, editable.Token, false)
+	if err != nil {
+		t.Fatalf("SaveSharedPattern: %v", err)
+	}
+	if fork.Locked || fork.SharePermission != domain.SharePermissionSaveEditable || fork.SharedFromName == "" {
+		t.Fatalf("expected an attributed editable fork, got %+v", fork)
+	}
+	updated := versionTestPattern(editor, *fork.PatternStitches[0].LibraryStitchID, "My Variation", 8)
+	updated.ID = fork.ID
+	if err := patternSvc.Update(ctx, editor, updated); err != nil {
+		t.Fatalf("Update fork: %v", err)
+	}
+	got, err := patternSvc.GetByID(ctx, fork.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Name != "My Variation" || got.SharedFromUserID == nil || *got.SharedFromUserID != owner {
+		t.Errorf("expected the renamed fork to keep its attribution, got %+v", got)
+	}
+	if _, err := patternSvc.Duplicate(ctx, editor, fork.ID, editor); err != nil {
+		t.Fatalf("Duplicate fork: %v", err)
+	}
+	// Forks still can't be passed on.
+	if _, err := shareSvc.CreateGlobalShare(ctx, editor, fork.ID, domain.SharePermissionSaveEditable); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput sharing a fork, got %v", err)
+	}
+}
+
+func TestShareService_PullSourceUpdate(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "pull-owner@example.com")
+	recipient := seedUserForTest(t, db, "pull-recipient@example.com")
+	stitchID := seedStitchForTest(t, db)
+	p := versionTestPattern(owner, stitchID, "Coaster", 6)
+	if err := patternSvc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	saved, err := shareSvc.SaveSharedPattern(ctx, recipient, share.Token, false)
+	if err != nil {
+		t.Fatalf("SaveSharedPattern: %v", err)
+	}
+	if _, err := shareSvc.PullSourceUpdate(ctx, recipient, saved.ID); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an up-to-date copy, got %v", err)
+	}
+
+	fixed := versionTestPattern(owner, stitchID, "Coaster (fixed)", 8)
+	fixed.ID = p.ID
+	if err := patternSvc.Update(ctx, owner, fixed); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	copied, err := patternSvc.GetByID(ctx, saved.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if available, err := shareSvc.SourceUpdateAvailable(ctx, copied); err != nil || !available {
+		t.Fatalf("SourceUpdateAvailable = %v, %v; want true", available, err)
+	}
+	if _, err := shareSvc.PullSourceUpdate(ctx, owner, saved.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for another user's copy, got %v", err)
+	}
+
+	if _, err := shareSvc.PullSourceUpdate(ctx, recipient, saved.ID); err != nil {
+		t.Fatalf("PullSourceUpdate: %v", err)
+	}
+	copied, err = patternSvc.GetByID(ctx, saved.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if copied.Name != "Coaster (fixed)" || copied.InstructionGroups[0].StitchEntries[0].Count != 8 {
+		t.Errorf("expected the fixed content, got %q with count %d", copied.Name, copied.InstructionGroups[0].StitchEntries[0].Count)
+	}
+	if !copied.Locked || copied.SharedFromName == "" {
+		t.Errorf("expected the copy to stay a locked, attributed snapshot, got %+v", copied)
+	}
+	if available, err := shareSvc.SourceUpdateAvailable(ctx, copied); err != nil || available {
+		t.Fatalf("SourceUpdateAvailable after pulling = %v, %v; want false", available, err)
+	}
+
+	// Editable forks are the recipient's own and never overwritten.
+	editable, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveEditable)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	other := seedUserForTest(t, db, "pull-fork@example.com")
+	fork, err := shareSvc.SaveSharedPattern(ctx, other, editable.Token, false)
+	if err != nil {
+		t.Fatalf("SaveSharedPattern: %v", err)
+	}
+	if _, err := shareSvc.PullSourceUpdate(ctx, other, fork.ID); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an editable fork, got %v", err)
+	}
+}
+
+func TestShareService_PullSourceUpdate_NeedsLiveShare(t *testing.T) {
+	shareSvc, patternSvc, _, db := newTestShareService(t)
+	ctx := context.Background()
+	owner := seedUserForTest(t, db, "live-owner@example.com")
+	recipient := seedUserForTest(t, db, "live-recipient@example.com")
+	stitchID := seedStitchForTest(t, db)
+	p := versionTestPattern(owner, stitchID, "Coaster", 6)
+	if err := patternSvc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	share, err := shareSvc.CreateGlobalShare(ctx, owner, p.ID, domain.SharePermissionSaveLocked)
+	if err != nil {
+		t.Fatalf("CreateGlobalShare: %v", err)
+	}
+	saved, err := shareSvc.SaveSharedPattern(ctx, recipient, share.Token, false)
+	if err != nil {
+		t.Fatalf("SaveSharedPattern: %v", err)
+	}
+	if _, err := shareSvc.Publish(ctx, owner, p.ID); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	fromGallery, err := shareSvc.SaveFromGallery(ctx, seedUserForTest(t, db, "live-gallery@example.com"), p.ID, false)
+	if err != nil {
+		t.Fatalf("SaveFromGallery: %v", err)
+	}
+
+	fixed := versionTestPattern(owner, stitchID, "Coaster (fixed)", 8)
+	fixed.ID = p.ID
+	if err := patternSvc.Update(ctx, owner, fixed); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// An expired share stops offering updates.
+	past := time.Now().Add(-time.Hour)
+	if err := db.Shares().UpdateLimits(ctx, share.ID, &past, 0); err != nil {
+		t.Fatalf("UpdateLimits: %v", err)
+	}
+	copied, err := patternSvc.GetByID(ctx, saved.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if available, err := shareSvc.SourceUpdateAvailable(ctx, copied); err != nil || available {
+		t.Fatalf("SourceUpdateAvailable with an expired share = %v, %v; want false", available, err)
+	}
+	if _, err := shareSvc.PullSourceUpdate(ctx, recipient, saved.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized pulling through an expired share, got %v", err)
+	}
+
+	// So does a revoked one.
+	if err := shareSvc.RevokeShareForPattern(ctx, owner, p.ID, share.ID); err != nil {
+		t.Fatalf("RevokeShareForPattern: %v", err)
+	}
+	if _, err := shareSvc.PullSourceUpdate(ctx, recipient, saved.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized pulling through a revoked share, got %v", err)
+	}
+
+	// And unpublishing ends updates for copies saved from the gallery.
+	if err := shareSvc.Unpublish(ctx, owner, p.ID); err != nil {
+		t.Fatalf("Unpublish: %v", err)
+	}
+	if _, err := shareSvc.PullSourceUpdate(ctx, fromGallery.UserID, fromGallery.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized pulling after unpublishing, got %v", err)
+	}
+}
+
+func TestImageService_SharedImageURL(t *testing.T) {
+	images := service.NewImageService(nil, nil, nil, []byte("test-signing-key"))
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	raw := images.SharedImageURL("tok", 42, now)
+	if !strings.HasPrefix(raw, "/shared-images/42?") {
+		t.Fatalf("SharedImageURL = %q, want a /shared-images/42 URL", raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse URL: %v", err)
+	}
+	q := u.Query()
+	expires, _ := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if want := time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC).Unix(); expires != want {
+		t.Errorf("expires = %d, want %d", expires, want)
+	}
+	// URLs made within the same hour match, so browsers can cache the image.
+	if again := images.SharedImageURL("tok", 42, now.Add(20*time.Minute)); again != raw {
+		t.Errorf("expected the same URL within the hour, got %q and %q", raw, again)
+	}
+
+	sig := q.Get("sig")
+	if err := images.VerifySharedImageURL("tok", 42, expires, sig, now); err != nil {
+		t.Fatalf("VerifySharedImageURL: %v", err)
+	}
+	if err := images.VerifySharedImageURL("other", 42, expires, sig, now); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for another token, got %v", err)
+	}
+	if err := images.VerifySharedImageURL("tok", 43, expires, sig, now); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for another image, got %v", err)
+	}
+	if err := images.VerifySharedImageURL("tok", 42, expires+3600, sig, now); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for an extended expiry, got %v", err)
+	}
+	if err := images.VerifySharedImageURL("tok", 42, expires, sig, time.Unix(expires, 0)); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized once expired, got %v", err)
+	}
+	other := service.NewImageService(nil, nil, nil, []byte("another-key"))
+	if err := other.VerifySharedImageURL("tok", 42, expires, sig, now); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized under another key, got %v", err)
+	}
+	// The signing key is derived from the JWT secret rather than being it.
+	derived := service.NewImageService(nil, nil, nil, service.SharedImageSigningKey("test-signing-key"))
+	if err := derived.VerifySharedImageURL("tok", 42, expires, sig, now); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for a URL signed with the raw secret, got %v", err)
+	}
+}
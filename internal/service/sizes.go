@@ -0,0 +1,158 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// maxPatternSizes limits how many sizes a graded pattern can be written for.
+const maxPatternSizes = 12
+
+// GradedText formats a graded number in the conventional "12 (14, 16)" form,
+// with the first size outside the parentheses. Numbers that aren't graded are
+// formatted plainly.
+func GradedText(base int, sizes []int) string {
+	if len(sizes) < 2 {
+		return strconv.Itoa(base)
+	}
+	rest := make([]string, len(sizes)-1)
+	for i, n := range sizes[1:] {
+		rest[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%d (%s)", sizes[0], strings.Join(rest, ", "))
+}
+
+// validateSizes checks a pattern's size names and that every graded number
+// has exactly one value per size, each within the same limits as the
+// ungraded number.
+func validateSizes(pattern *domain.Pattern) error {
+	if len(pattern.Sizes) > maxPatternSizes {
+		return fmt.Errorf("%w: a pattern can have at most %d sizes", domain.ErrInvalidInput, maxPatternSizes)
+	}
+	seen := make(map[string]bool, len(pattern.Sizes))
+	for i, name := range pattern.Sizes {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("%w: size %d name is required", domain.ErrInvalidInput, i+1)
+		}
+		if len(name) > 30 {
+			return fmt.Errorf("%w: size %d name must be 30 characters or fewer", domain.ErrInvalidInput, i+1)
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			return fmt.Errorf("%w: size %q is listed more than once", domain.ErrInvalidInput, name)
+		}
+		seen[key] = true
+	}
+
+	for i, g := range pattern.InstructionGroups {
+		what := fmt.Sprintf("group %d repeat count", i+1)
+		if err := validateGraded(pattern.Sizes, g.SizeRepeatCounts, what, 1, 1000); err != nil {
+			return err
+		}
+		if g.ExpectedCount == nil && len(g.SizeExpectedCounts) > 0 {
+			return fmt.Errorf("%w: group %d has per-size expected counts but no expected count", domain.ErrInvalidInput, i+1)
+		}
+		what = fmt.Sprintf("group %d expected count", i+1)
+		if err := validateGraded(pattern.Sizes, g.SizeExpectedCounts, what, 0, 1000000); err != nil {
+			return err
+		}
+		for j, e := range g.StitchEntries {
+			what = fmt.Sprintf("group %d entry %d count", i+1, j+1)
+			if err := validateGraded(pattern.Sizes, e.SizeCounts, what, 1, 10000); err != nil {
+				return err
+			}
+			what = fmt.Sprintf("group %d entry %d repeat count", i+1, j+1)
+			if err := validateGraded(pattern.Sizes, e.SizeRepeatCounts, what, 1, 1000); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateGraded checks one graded number. A nil list means the number is
+// the same for every size and is checked elsewhere.
+func validateGraded(sizes []string, values []int, what string, lo, hi int) error {
+	if values == nil {
+		return nil
+	}
+	if len(sizes) == 0 {
+		return fmt.Errorf("%w: %s varies by size but the pattern has no sizes", domain.ErrInvalidInput, what)
+	}
+	if len(values) != len(sizes) {
+		return fmt.Errorf("%w: %s has %d values but the pattern has %d sizes", domain.ErrInvalidInput, what, len(values), len(sizes))
+	}
+	for i, v := range values {
+		if v < lo {
+			return fmt.Errorf("%w: %s for size %s must be at least %d", domain.ErrInvalidInput, what, strings.TrimSpace(sizes[i]), lo)
+		}
+		if v > hi {
+			return fmt.Errorf("%w: %s for size %s must be %d or fewer", domain.ErrInvalidInput, what, strings.TrimSpace(sizes[i]), hi)
+		}
+	}
+	return nil
+}
+
+// normalizeSizes puts a validated graded pattern into canonical form: size
+// names are trimmed, every graded number's base value is its first size, and
+// numbers that are the same for every size are stored ungraded.
+func normalizeSizes(pattern *domain.Pattern) {
+	if len(pattern.Sizes) == 0 {
+		pattern.Sizes = nil
+		return
+	}
+	for i := range pattern.Sizes {
+		pattern.Sizes[i] = strings.TrimSpace(pattern.Sizes[i])
+	}
+
+	for i := range pattern.InstructionGroups {
+		g := &pattern.InstructionGroups[i]
+		g.SizeRepeatCounts = normalizeGraded(&g.RepeatCount, g.SizeRepeatCounts)
+		if g.ExpectedCount != nil {
+			g.SizeExpectedCounts = normalizeGraded(g.ExpectedCount, g.SizeExpectedCounts)
+		}
+		for j := range g.StitchEntries {
+			e := &g.StitchEntries[j]
+			e.SizeCounts = normalizeGraded(&e.Count, e.SizeCounts)
+			e.SizeRepeatCounts = normalizeGraded(&e.RepeatCount, e.SizeRepeatCounts)
+		}
+	}
+}
+
+// normalizeGraded sets *base to the first size's value and returns the
+// per-size values, or nil if they are all the same.
+func normalizeGraded(base *int, values []int) []int {
+	if len(values) == 0 {
+		return nil
+	}
+	*base = values[0]
+	if !slices.ContainsFunc(values, func(v int) bool { return v != values[0] }) {
+		return nil
+	}
+	return values
+}
+
+// groupSizeCount returns how many sizes a group's graded numbers cover, or 0
+// if nothing in the group varies by size.
+func groupSizeCount(g *domain.InstructionGroup) int {
+	n := max(len(g.SizeRepeatCounts), len(g.SizeExpectedCounts))
+	for _, e := range g.StitchEntries {
+		n = max(n, len(e.SizeCounts), len(e.SizeRepeatCounts))
+	}
+	return n
+}
+
+// sessionPattern resolves a pattern to the size a work session follows.
+// Out-of-range sizes fall back to the first size.
+func sessionPattern(pattern *domain.Pattern, session *domain.WorkSession) *domain.Pattern {
+	si := session.SizeIndex
+	if si < 0 || si >= len(pattern.Sizes) {
+		si = 0
+	}
+	return pattern.ForSize(si)
+}
@@ -50,7 +50,9 @@ func (s *StitchService) GetByID(ctx context.Context, id int64) (*domain.Stitch,
 
 // CreateCustom creates a new custom stitch for a user.
 // It rejects abbreviations that conflict with predefined stitches.
-func (s *StitchService) CreateCustom(ctx context.Context, userID int64, abbreviation, name, description, category string) (*domain.Stitch, error) {
+// consumes and produces describe the stitch's effect on the stitch count.
+// ukAbbreviation is how the stitch is written in UK terms, if differently.
+func (s *StitchService) CreateCustom(ctx context.Context, userID int64, abbreviation, name, description, category, ukAbbreviation string, consumes, produces int) (*domain.Stitch, error) {
 	if abbreviation == "" || name == "" {
 		return nil, fmt.Errorf("%w: abbreviation and name are required", domain.ErrInvalidInput)
 	}
@@ -66,6 +68,12 @@ func (s *StitchService) CreateCustom(ctx context.Context, userID int64, abbrevia
 	if len(category) > 50 {
 		return nil, fmt.Errorf("%w: category must be 50 characters or fewer", domain.ErrInvalidInput)
 	}
+	if len(ukAbbreviation) > 20 {
+		return nil, fmt.Errorf("%w: UK abbreviation must be 20 characters or fewer", domain.ErrInvalidInput)
+	}
+	if err := validateStitchArithmetic(consumes, produces); err != nil {
+		return nil, err
+	}
 
 	// Check if abbreviation conflicts with a predefined stitch.
 	_, err := s.stitches.GetByAbbreviation(ctx, abbreviation, nil)
@@ -81,12 +89,15 @@ func (s *StitchService) CreateCustom(ctx context.Context, userID int64, abbrevia
 	}
 
 	stitch := &domain.Stitch{
-		Abbreviation: abbreviation,
-		Name:         name,
-		Description:  description,
-		Category:     category,
-		IsCustom:     true,
-		UserID:       &userID,
+		Abbreviation:   abbreviation,
+		Name:           name,
+		Description:    description,
+		Category:       category,
+		Consumes:       consumes,
+		Produces:       produces,
+		IsCustom:       true,
+		UKAbbreviation: ukAbbreviation,
+		UserID:         &userID,
 	}
 
 	if err := s.stitches.Create(ctx, stitch); err != nil {
@@ -97,7 +108,7 @@ func (s *StitchService) CreateCustom(ctx context.Context, userID int64, abbrevia
 }
 
 // UpdateCustom updates an existing custom stitch. Only the owner can update it.
-func (s *StitchService) UpdateCustom(ctx context.Context, userID int64, id int64, abbreviation, name, description, category string) (*domain.Stitch, error) {
+func (s *StitchService) UpdateCustom(ctx context.Context, userID int64, id int64, abbreviation, name, description, category, ukAbbreviation string, consumes, produces int) (*domain.Stitch, error) {
 	stitch, err := s.stitches.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -122,6 +133,12 @@ func (s *StitchService) UpdateCustom(ctx context.Context, userID int64, id int64
 	if len(category) > 50 {
 		return nil, fmt.Errorf("%w: category must be 50 characters or fewer", domain.ErrInvalidInput)
 	}
+	if len(ukAbbreviation) > 20 {
+		return nil, fmt.Errorf("%w: UK abbreviation must be 20 characters or fewer", domain.ErrInvalidInput)
+	}
+	if err := validateStitchArithmetic(consumes, produces); err != nil {
+		return nil, err
+	}
 
 	// If abbreviation changed, check it doesn't conflict with predefined.
 	if abbreviation != stitch.Abbreviation {
@@ -137,6 +154,9 @@ func (s *StitchService) UpdateCustom(ctx context.Context, userID int64, id int64
 	stitch.Abbreviation = abbreviation
 	stitch.Name = name
 	stitch.Description = description
+	stitch.Consumes = consumes
+	stitch.Produces = produces
+	stitch.UKAbbreviation = ukAbbreviation
 	if category != "" {
 		stitch.Category = category
 	}
@@ -162,6 +182,44 @@ func (s *StitchService) DeleteCustom(ctx context.Context, userID int64, id int64
 	return s.stitches.Delete(ctx, id)
 }
 
+// KnownIDs returns the set of stitch IDs a user has marked as known.
+func (s *StitchService) KnownIDs(ctx context.Context, userID int64) (map[int64]bool, error) {
+	ids, err := s.stitches.ListKnownIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	return known, nil
+}
+
+// SetKnown marks a predefined stitch, or one of the user's custom stitches,
+// as known or not known by the user. Other users' custom stitches are not
+// found.
+func (s *StitchService) SetKnown(ctx context.Context, userID, id int64, known bool) error {
+	stitch, err := s.stitches.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stitch.IsCustom && (stitch.UserID == nil || *stitch.UserID != userID) {
+		return domain.ErrNotFound
+	}
+	return s.stitches.SetKnown(ctx, userID, id, known)
+}
+
+// validateStitchArithmetic checks a stitch's consumed and produced counts.
+func validateStitchArithmetic(consumes, produces int) error {
+	if consumes < 0 || produces < 0 {
+		return fmt.Errorf("%w: stitches used and made cannot be negative", domain.ErrInvalidInput)
+	}
+	if consumes > 100 || produces > 100 {
+		return fmt.Errorf("%w: stitches used and made must be 100 or fewer", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
 // SeedPredefined inserts all predefined stitches. It is idempotent — existing
 // stitches are skipped based on abbreviation with NULL user_id.
 func (s *StitchService) SeedPredefined(ctx context.Context) error {
@@ -180,45 +238,56 @@ func (s *StitchService) SeedPredefined(ctx context.Context) error {
 	return nil
 }
 
+// isPredefinedStitch reports whether a stitch with this abbreviation and
+// category is one of the predefined stitches.
+func isPredefinedStitch(abbreviation, category string) bool {
+	for _, st := range predefinedStitches {
+		if st.Abbreviation == abbreviation {
+			return st.Category == category
+		}
+	}
+	return false
+}
+
 var predefinedStitches = []domain.Stitch{
 	// Basic Stitches
-	{Abbreviation: "ch", Name: "Chain", Category: "basic"},
-	{Abbreviation: "sl st", Name: "Slip Stitch", Category: "basic"},
-	{Abbreviation: "sc", Name: "Single Crochet", Category: "basic"},
-	{Abbreviation: "hdc", Name: "Half Double Crochet", Category: "basic"},
-	{Abbreviation: "dc", Name: "Double Crochet", Category: "basic"},
-	{Abbreviation: "tr", Name: "Treble Crochet", Category: "basic"},
-	{Abbreviation: "dtr", Name: "Double Treble Crochet", Category: "basic"},
+	{Abbreviation: "ch", Name: "Chain", Category: "basic", Consumes: 0, Produces: 1},
+	{Abbreviation: "sl st", Name: "Slip Stitch", Category: "basic", Consumes: 1, Produces: 1},
+	{Abbreviation: "sc", Name: "Single Crochet", Category: "basic", Consumes: 1, Produces: 1},
+	{Abbreviation: "hdc", Name: "Half Double Crochet", Category: "basic", Consumes: 1, Produces: 1},
+	{Abbreviation: "dc", Name: "Double Crochet", Category: "basic", Consumes: 1, Produces: 1},
+	{Abbreviation: "tr", Name: "Treble Crochet", Category: "basic", Consumes: 1, Produces: 1},
+	{Abbreviation: "dtr", Name: "Double Treble Crochet", Category: "basic", Consumes: 1, Produces: 1},
 	// Increase / Decrease
-	{Abbreviation: "inc", Name: "Increase (2 stitches in one)", Category: "increase"},
-	{Abbreviation: "dec", Name: "Decrease (2 stitches together)", Category: "decrease"},
-	{Abbreviation: "sc2tog", Name: "Single Crochet 2 Together", Category: "decrease"},
-	{Abbreviation: "hdc2tog", Name: "Half Double Crochet 2 Together", Category: "decrease"},
-	{Abbreviation: "dc2tog", Name: "Double Crochet 2 Together", Category: "decrease"},
-	{Abbreviation: "dc3tog", Name: "Double Crochet 3 Together", Category: "decrease"},
-	{Abbreviation: "tr2tog", Name: "Treble Crochet 2 Together", Category: "decrease"},
+	{Abbreviation: "inc", Name: "Increase (2 stitches in one)", Category: "increase", Consumes: 1, Produces: 2},
+	{Abbreviation: "dec", Name: "Decrease (2 stitches together)", Category: "decrease", Consumes: 2, Produces: 1},
+	{Abbreviation: "sc2tog", Name: "Single Crochet 2 Together", Category: "decrease", Consumes: 2, Produces: 1},
+	{Abbreviation: "hdc2tog", Name: "Half Double Crochet 2 Together", Category: "decrease", Consumes: 2, Produces: 1},
+	{Abbreviation: "dc2tog", Name: "Double Crochet 2 Together", Category: "decrease", Consumes: 2, Produces: 1},
+	{Abbreviation: "dc3tog", Name: "Double Crochet 3 Together", Category: "decrease", Consumes: 3, Produces: 1},
+	{Abbreviation: "tr2tog", Name: "Treble Crochet 2 Together", Category: "decrease", Consumes: 2, Produces: 1},
 	// Post Stitches
-	{Abbreviation: "FPsc", Name: "Front Post Single Crochet", Category: "post"},
-	{Abbreviation: "BPsc", Name: "Back Post Single Crochet", Category: "post"},
-	{Abbreviation: "FPdc", Name: "Front Post Double Crochet", Category: "post"},
-	{Abbreviation: "BPdc", Name: "Back Post Double Crochet", Category: "post"},
-	{Abbreviation: "FPtr", Name: "Front Post Treble Crochet", Category: "post"},
-	{Abbreviation: "BPtr", Name: "Back Post Treble Crochet", Category: "post"},
+	{Abbreviation: "FPsc", Name: "Front Post Single Crochet", Category: "post", Consumes: 1, Produces: 1},
+	{Abbreviation: "BPsc", Name: "Back Post Single Crochet", Category: "post", Consumes: 1, Produces: 1},
+	{Abbreviation: "FPdc", Name: "Front Post Double Crochet", Category: "post", Consumes: 1, Produces: 1},
+	{Abbreviation: "BPdc", Name: "Back Post Double Crochet", Category: "post", Consumes: 1, Produces: 1},
+	{Abbreviation: "FPtr", Name: "Front Post Treble Crochet", Category: "post", Consumes: 1, Produces: 1},
+	{Abbreviation: "BPtr", Name: "Back Post Treble Crochet", Category: "post", Consumes: 1, Produces: 1},
 	// Loop Variations
-	{Abbreviation: "BLO", Name: "Back Loop Only", Category: "advanced"},
-	{Abbreviation: "FLO", Name: "Front Loop Only", Category: "advanced"},
+	{Abbreviation: "BLO", Name: "Back Loop Only", Category: "advanced", Consumes: 0, Produces: 0},
+	{Abbreviation: "FLO", Name: "Front Loop Only", Category: "advanced", Consumes: 0, Produces: 0},
 	// Specialty Stitches
-	{Abbreviation: "pc", Name: "Popcorn Stitch", Category: "specialty"},
-	{Abbreviation: "puff", Name: "Puff Stitch", Category: "specialty"},
-	{Abbreviation: "cl", Name: "Cluster", Category: "specialty"},
-	{Abbreviation: "sh", Name: "Shell", Category: "specialty"},
-	{Abbreviation: "bob", Name: "Bobble", Category: "specialty"},
-	{Abbreviation: "crab st", Name: "Crab Stitch (Reverse SC)", Category: "specialty"},
-	{Abbreviation: "lp st", Name: "Loop Stitch", Category: "specialty"},
-	{Abbreviation: "v-st", Name: "V-Stitch", Category: "specialty"},
+	{Abbreviation: "pc", Name: "Popcorn Stitch", Category: "specialty", Consumes: 1, Produces: 1},
+	{Abbreviation: "puff", Name: "Puff Stitch", Category: "specialty", Consumes: 1, Produces: 1},
+	{Abbreviation: "cl", Name: "Cluster", Category: "specialty", Consumes: 1, Produces: 1},
+	{Abbreviation: "sh", Name: "Shell", Category: "specialty", Consumes: 1, Produces: 5},
+	{Abbreviation: "bob", Name: "Bobble", Category: "specialty", Consumes: 1, Produces: 1},
+	{Abbreviation: "crab st", Name: "Crab Stitch (Reverse SC)", Category: "specialty", Consumes: 1, Produces: 1},
+	{Abbreviation: "lp st", Name: "Loop Stitch", Category: "specialty", Consumes: 1, Produces: 1},
+	{Abbreviation: "v-st", Name: "V-Stitch", Category: "specialty", Consumes: 1, Produces: 2},
 	// Action
-	{Abbreviation: "sk", Name: "Skip", Category: "action"},
-	{Abbreviation: "yo", Name: "Yarn Over", Category: "action"},
-	{Abbreviation: "tch", Name: "Turning Chain", Category: "action"},
-	{Abbreviation: "MR", Name: "Magic Ring", Category: "action"},
+	{Abbreviation: "sk", Name: "Skip", Category: "action", Consumes: 1, Produces: 0},
+	{Abbreviation: "yo", Name: "Yarn Over", Category: "action", Consumes: 0, Produces: 0},
+	{Abbreviation: "tch", Name: "Turning Chain", Category: "action", Consumes: 0, Produces: 0},
+	{Abbreviation: "MR", Name: "Magic Ring", Category: "action", Consumes: 0, Produces: 0},
 }
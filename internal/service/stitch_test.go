@@ -3,6 +3,7 @@ package service_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
@@ -36,6 +37,37 @@ func TestStitchService_SeedPredefined(t *testing.T) {
 	}
 }
 
+func TestStitchService_SeedPredefined_Arithmetic(t *testing.T) {
+	svc, _ := newTestStitchService(t)
+	ctx := context.Background()
+
+	if err := svc.SeedPredefined(ctx); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+
+	predefined, err := svc.ListPredefined(ctx)
+	if err != nil {
+		t.Fatalf("ListPredefined: %v", err)
+	}
+
+	want := map[string][2]int{
+		"sc":     {1, 1},
+		"inc":    {1, 2},
+		"sc2tog": {2, 1},
+		"ch":     {0, 1},
+		"sk":     {1, 0},
+	}
+	for _, s := range predefined {
+		w, ok := want[s.Abbreviation]
+		if !ok {
+			continue
+		}
+		if s.Consumes != w[0] || s.Produces != w[1] {
+			t.Fatalf("%s: expected %d → %d, got %d → %d", s.Abbreviation, w[0], w[1], s.Consumes, s.Produces)
+		}
+	}
+}
+
 func TestStitchService_SeedPredefined_Idempotent(t *testing.T) {
 	svc, _ := newTestStitchService(t)
 	ctx := context.Background()
@@ -69,7 +101,7 @@ func TestStitchService_CreateCustom_Success(t *testing.T) {
 		t.Fatalf("Create user: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, user.ID, "msc", "My Special Crochet", "A custom stitch", "custom")
+	stitch, err := svc.CreateCustom(ctx, user.ID, "msc", "My Special Crochet", "A custom stitch", "custom", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
@@ -88,6 +120,67 @@ func TestStitchService_CreateCustom_Success(t *testing.T) {
 	}
 }
 
+func TestStitchService_CreateCustom_UKAbbreviation(t *testing.T) {
+	svc, db := newTestStitchService(t)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "uk@example.com", DisplayName: "UK", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, user); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+
+	stitch, err := svc.CreateCustom(ctx, user.ID, "esc", "Extended Single Crochet", "", "", "edc", 1, 1)
+	if err != nil {
+		t.Fatalf("CreateCustom: %v", err)
+	}
+	got, err := svc.GetByID(ctx, stitch.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.UKAbbreviation != "edc" {
+		t.Errorf("UKAbbreviation = %q, want edc", got.UKAbbreviation)
+	}
+
+	if _, err := svc.UpdateCustom(ctx, user.ID, stitch.ID, "esc", "Extended Single Crochet", "", "", "", 1, 1); err != nil {
+		t.Fatalf("UpdateCustom: %v", err)
+	}
+	got, _ = svc.GetByID(ctx, stitch.ID)
+	if got.UKAbbreviation != "" {
+		t.Errorf("UKAbbreviation after clearing = %q, want empty", got.UKAbbreviation)
+	}
+
+	if _, err := svc.CreateCustom(ctx, user.ID, "long", "Long", "", "", strings.Repeat("x", 21), 1, 1); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("long UK abbreviation: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestStitchService_CreateCustom_Arithmetic(t *testing.T) {
+	svc, db := newTestStitchService(t)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "arith@example.com", DisplayName: "Arith", PasswordHash: "hash"}
+	if err := db.Users().Create(ctx, user); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+
+	stitch, err := svc.CreateCustom(ctx, user.ID, "sc3tog", "Single Crochet 3 Together", "", "", "", 3, 1)
+	if err != nil {
+		t.Fatalf("CreateCustom: %v", err)
+	}
+	found, err := svc.GetByID(ctx, stitch.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Consumes != 3 || found.Produces != 1 {
+		t.Fatalf("expected 3 → 1, got %d → %d", found.Consumes, found.Produces)
+	}
+
+	_, err = svc.CreateCustom(ctx, user.ID, "neg", "Negative", "", "", "", -1, 1)
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for negative consumes, got %v", err)
+	}
+}
+
 func TestStitchService_CreateCustom_DefaultCategory(t *testing.T) {
 	svc, db := newTestStitchService(t)
 	ctx := context.Background()
@@ -98,7 +191,7 @@ func TestStitchService_CreateCustom_DefaultCategory(t *testing.T) {
 		t.Fatalf("Create user: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, user.ID, "dfc", "Default Category", "", "")
+	stitch, err := svc.CreateCustom(ctx, user.ID, "dfc", "Default Category", "", "", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
@@ -123,7 +216,7 @@ func TestStitchService_CreateCustom_RejectReservedAbbreviation(t *testing.T) {
 	}
 
 	// Try to create a custom stitch with a predefined abbreviation "sc".
-	_, err := svc.CreateCustom(ctx, user.ID, "sc", "My SC", "", "")
+	_, err := svc.CreateCustom(ctx, user.ID, "sc", "My SC", "", "", "", 1, 1)
 	if !errors.Is(err, domain.ErrReservedAbbreviation) {
 		t.Fatalf("expected ErrReservedAbbreviation, got %v", err)
 	}
@@ -151,7 +244,7 @@ func TestStitchService_CreateCustom_InvalidInput(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := svc.CreateCustom(ctx, user.ID, tc.abbreviation, tc.stitchName, "", "")
+			_, err := svc.CreateCustom(ctx, user.ID, tc.abbreviation, tc.stitchName, "", "", "", 1, 1)
 			if !errors.Is(err, domain.ErrInvalidInput) {
 				t.Fatalf("expected ErrInvalidInput, got %v", err)
 			}
@@ -174,7 +267,7 @@ func TestStitchService_ListAll(t *testing.T) {
 	}
 
 	// Create a custom stitch.
-	_, err := svc.CreateCustom(ctx, user.ID, "xyz", "XYZ Stitch", "", "")
+	_, err := svc.CreateCustom(ctx, user.ID, "xyz", "XYZ Stitch", "", "", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
@@ -200,12 +293,12 @@ func TestStitchService_UpdateCustom_Success(t *testing.T) {
 		t.Fatalf("Create user: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, user.ID, "upd", "Update Me", "", "custom")
+	stitch, err := svc.CreateCustom(ctx, user.ID, "upd", "Update Me", "", "custom", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
 
-	updated, err := svc.UpdateCustom(ctx, user.ID, stitch.ID, "upd2", "Updated Name", "New desc", "specialty")
+	updated, err := svc.UpdateCustom(ctx, user.ID, stitch.ID, "upd2", "Updated Name", "New desc", "specialty", "", 1, 1)
 	if err != nil {
 		t.Fatalf("UpdateCustom: %v", err)
 	}
@@ -234,13 +327,13 @@ func TestStitchService_UpdateCustom_WrongUser(t *testing.T) {
 		t.Fatalf("Create u2: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, u1.ID, "own", "Owner Stitch", "", "")
+	stitch, err := svc.CreateCustom(ctx, u1.ID, "own", "Owner Stitch", "", "", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
 
 	// Other user tries to update.
-	_, err = svc.UpdateCustom(ctx, u2.ID, stitch.ID, "own", "Hacked", "", "")
+	_, err = svc.UpdateCustom(ctx, u2.ID, stitch.ID, "own", "Hacked", "", "", "", 1, 1)
 	if !errors.Is(err, domain.ErrUnauthorized) {
 		t.Fatalf("expected ErrUnauthorized, got %v", err)
 	}
@@ -260,13 +353,13 @@ func TestStitchService_UpdateCustom_RejectReservedAbbreviation(t *testing.T) {
 		t.Fatalf("Create user: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, user.ID, "orig", "Original", "", "")
+	stitch, err := svc.CreateCustom(ctx, user.ID, "orig", "Original", "", "", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
 
 	// Try to change abbreviation to a predefined one.
-	_, err = svc.UpdateCustom(ctx, user.ID, stitch.ID, "sc", "Renamed", "", "")
+	_, err = svc.UpdateCustom(ctx, user.ID, stitch.ID, "sc", "Renamed", "", "", "", 1, 1)
 	if !errors.Is(err, domain.ErrReservedAbbreviation) {
 		t.Fatalf("expected ErrReservedAbbreviation, got %v", err)
 	}
@@ -282,7 +375,7 @@ func TestStitchService_DeleteCustom_Success(t *testing.T) {
 		t.Fatalf("Create user: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, user.ID, "del", "Delete Me", "", "")
+	stitch, err := svc.CreateCustom(ctx, user.ID, "del", "Delete Me", "", "", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
@@ -312,7 +405,7 @@ func TestStitchService_DeleteCustom_WrongUser(t *testing.T) {
 		t.Fatalf("Create u2: %v", err)
 	}
 
-	stitch, err := svc.CreateCustom(ctx, u1.ID, "own2", "Owner Stitch", "", "")
+	stitch, err := svc.CreateCustom(ctx, u1.ID, "own2", "Owner Stitch", "", "", "", 1, 1)
 	if err != nil {
 		t.Fatalf("CreateCustom: %v", err)
 	}
@@ -322,3 +415,62 @@ func TestStitchService_DeleteCustom_WrongUser(t *testing.T) {
 		t.Fatalf("expected ErrUnauthorized, got %v", err)
 	}
 }
+
+func TestStitchService_SetKnown(t *testing.T) {
+	svc, db := newTestStitchService(t)
+	ctx := context.Background()
+
+	if err := svc.SeedPredefined(ctx); err != nil {
+		t.Fatalf("SeedPredefined: %v", err)
+	}
+	userRepo := db.Users()
+	u1 := &domain.User{Email: "knows@example.com", DisplayName: "Knows", PasswordHash: "hash"}
+	u2 := &domain.User{Email: "other-knows@example.com", DisplayName: "Other", PasswordHash: "hash"}
+	if err := userRepo.Create(ctx, u1); err != nil {
+		t.Fatalf("Create u1: %v", err)
+	}
+	if err := userRepo.Create(ctx, u2); err != nil {
+		t.Fatalf("Create u2: %v", err)
+	}
+
+	sc, err := db.Stitches().GetByAbbreviation(ctx, "sc", nil)
+	if err != nil {
+		t.Fatalf("GetByAbbreviation: %v", err)
+	}
+	custom, err := svc.CreateCustom(ctx, u1.ID, "msc", "Modified Single Crochet", "", "", "", 1, 1)
+	if err != nil {
+		t.Fatalf("CreateCustom: %v", err)
+	}
+
+	if err := svc.SetKnown(ctx, u1.ID, sc.ID, true); err != nil {
+		t.Fatalf("SetKnown sc: %v", err)
+	}
+	if err := svc.SetKnown(ctx, u1.ID, sc.ID, true); err != nil {
+		t.Fatalf("SetKnown sc again: %v", err)
+	}
+	if err := svc.SetKnown(ctx, u1.ID, custom.ID, true); err != nil {
+		t.Fatalf("SetKnown custom: %v", err)
+	}
+	known, err := svc.KnownIDs(ctx, u1.ID)
+	if err != nil {
+		t.Fatalf("KnownIDs: %v", err)
+	}
+	if len(known) != 2 || !known[sc.ID] || !known[custom.ID] {
+		t.Fatalf("known = %v, want sc and msc", known)
+	}
+
+	if err := svc.SetKnown(ctx, u1.ID, sc.ID, false); err != nil {
+		t.Fatalf("SetKnown false: %v", err)
+	}
+	if known, _ := svc.KnownIDs(ctx, u1.ID); known[sc.ID] {
+		t.Error("sc should no longer be known")
+	}
+
+	// Other users' custom stitches can't be marked.
+	if err := svc.SetKnown(ctx, u2.ID, custom.ID, true); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another user's stitch, got %v", err)
+	}
+	if err := svc.SetKnown(ctx, u1.ID, 99999, true); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing stitch, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// ListTags returns the user's tags by name, with how many patterns have each.
+func (s *PatternService) ListTags(ctx context.Context, userID int64) ([]domain.Tag, error) {
+	return s.tags.ListByUser(ctx, userID)
+}
+
+// PatternTags returns a pattern's tags, sorted by name.
+func (s *PatternService) PatternTags(ctx context.Context, patternID int64) ([]domain.Tag, error) {
+	tags, err := s.tags.ListByPatternIDs(ctx, []int64{patternID})
+	if err != nil {
+		return nil, err
+	}
+	return tags[patternID], nil
+}
+
+// CreateTag creates a tag for the user. Tag names are unique per user,
+// ignoring case.
+func (s *PatternService) CreateTag(ctx context.Context, userID int64, name string) (*domain.Tag, error) {
+	name, err := validateTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	tag := &domain.Tag{UserID: userID, Name: name}
+	if err := s.tags.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// RenameTag renames one of the user's tags.
+func (s *PatternService) RenameTag(ctx context.Context, userID, tagID int64, name string) error {
+	if _, err := s.ownedTag(ctx, userID, tagID); err != nil {
+		return err
+	}
+	name, err := validateTagName(name)
+	if err != nil {
+		return err
+	}
+	return s.tags.Rename(ctx, tagID, name)
+}
+
+// DeleteTag deletes one of the user's tags, removing it from its patterns.
+func (s *PatternService) DeleteTag(ctx context.Context, userID, tagID int64) error {
+	if _, err := s.ownedTag(ctx, userID, tagID); err != nil {
+		return err
+	}
+	return s.tags.Delete(ctx, tagID)
+}
+
+// TagPatterns adds one of the user's tags to each of the user's patterns.
+// Patterns that already have it are left as they are.
+func (s *PatternService) TagPatterns(ctx context.Context, userID, tagID int64, patternIDs []int64) error {
+	if _, err := s.ownedTag(ctx, userID, tagID); err != nil {
+		return err
+	}
+	if err := s.checkPatternsOwned(ctx, userID, patternIDs); err != nil {
+		return err
+	}
+	return s.tags.AddToPatterns(ctx, tagID, patternIDs)
+}
+
+// TagPatternsByName adds the user's tag with the given name to each of the
+// user's patterns, creating the tag if the user doesn't have it yet.
+func (s *PatternService) TagPatternsByName(ctx context.Context, userID int64, name string, patternIDs []int64) (*domain.Tag, error) {
+	name, err := validateTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPatternsOwned(ctx, userID, patternIDs); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tags.GetByName(ctx, userID, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		tag = &domain.Tag{UserID: userID, Name: name}
+		err = s.tags.Create(ctx, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tags.AddToPatterns(ctx, tag.ID, patternIDs); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// UntagPatterns removes one of the user's tags from each of the user's
+// patterns.
+func (s *PatternService) UntagPatterns(ctx context.Context, userID, tagID int64, patternIDs []int64) error {
+	if _, err := s.ownedTag(ctx, userID, tagID); err != nil {
+		return err
+	}
+	if err := s.checkPatternsOwned(ctx, userID, patternIDs); err != nil {
+		return err
+	}
+	return s.tags.RemoveFromPatterns(ctx, tagID, patternIDs)
+}
+
+// ownedTag returns one of the user's tags, or ErrUnauthorized when the tag
+// belongs to someone else.
+func (s *PatternService) ownedTag(ctx context.Context, userID, tagID int64) (*domain.Tag, error) {
+	tag, err := s.tags.GetByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+	return tag, nil
+}
+
+// checkPatternsOwned returns ErrUnauthorized unless every pattern is the
+// user's, and ErrInvalidInput when there are none.
+func (s *PatternService) checkPatternsOwned(ctx context.Context, userID int64, patternIDs []int64) error {
+	if len(patternIDs) == 0 {
+		return fmt.Errorf("%w: select at least one pattern", domain.ErrInvalidInput)
+	}
+	for _, id := range patternIDs {
+		p, err := s.patterns.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if p.UserID != userID {
+			return domain.ErrUnauthorized
+		}
+	}
+	return nil
+}
+
+func validateTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", fmt.Errorf("%w: tag name is required", domain.ErrInvalidInput)
+	}
+	if len(name) > 50 {
+		return "", fmt.Errorf("%w: tag name must be 50 characters or fewer", domain.ErrInvalidInput)
+	}
+	return name, nil
+}
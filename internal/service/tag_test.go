@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/service"
+)
+
+func createPatternForTest(t *testing.T, svc *service.PatternService, userID, stitchID int64, name string) *domain.Pattern {
+	t.Helper()
+	p := &domain.Pattern{
+		UserID:      userID,
+		Name:        name,
+		PatternType: domain.PatternTypeRound,
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Round 1", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{SortOrder: 0, PatternStitchID: stitchID, Count: 6, RepeatCount: 1},
+				}},
+		},
+	}
+	if err := svc.Create(context.Background(), p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return p
+}
+
+func TestPatternService_Tags(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "tags@example.com")
+	otherID := seedUserForTest(t, db, "other-tags@example.com")
+	stitchID := seedStitchForTest(t, db)
+	hat := createPatternForTest(t, svc, userID, stitchID, "Hat")
+	scarf := createPatternForTest(t, svc, userID, stitchID, "Scarf")
+	theirs := createPatternForTest(t, svc, otherID, stitchID, "Their Hat")
+
+	if _, err := svc.CreateTag(ctx, userID, "   "); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a blank name, got %v", err)
+	}
+
+	tag, err := svc.TagPatternsByName(ctx, userID, "  winter   wear ", []int64{hat.ID})
+	if err != nil {
+		t.Fatalf("TagPatternsByName: %v", err)
+	}
+	if tag.Name != "winter wear" {
+		t.Errorf("tag name = %q, want spaces collapsed", tag.Name)
+	}
+	// The same name, in any case, reuses the tag.
+	again, err := svc.TagPatternsByName(ctx, userID, "Winter Wear", []int64{scarf.ID})
+	if err != nil {
+		t.Fatalf("TagPatternsByName: %v", err)
+	}
+	if again.ID != tag.ID {
+		t.Fatalf("expected the existing tag to be reused, got %+v", again)
+	}
+
+	if err := svc.TagPatterns(ctx, userID, tag.ID, []int64{theirs.ID}); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized tagging another user's pattern, got %v", err)
+	}
+	if err := svc.TagPatterns(ctx, otherID, tag.ID, []int64{theirs.ID}); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized using another user's tag, got %v", err)
+	}
+	if err := svc.TagPatterns(ctx, userID, tag.ID, nil); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput with no patterns, got %v", err)
+	}
+
+	dup, err := svc.Duplicate(ctx, userID, hat.ID, userID)
+	if err != nil {
+		t.Fatalf("Duplicate: %v", err)
+	}
+	tags, err := svc.PatternTags(ctx, dup.ID)
+	if err != nil {
+		t.Fatalf("PatternTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].ID != tag.ID {
+		t.Fatalf("expected the duplicate to keep its tag, got %+v", tags)
+	}
+
+	if err := svc.UntagPatterns(ctx, userID, tag.ID, []int64{hat.ID, scarf.ID}); err != nil {
+		t.Fatalf("UntagPatterns: %v", err)
+	}
+	list, err := svc.ListTags(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(list) != 1 || list[0].PatternCount != 1 {
+		t.Fatalf("expected the tag on only the duplicate, got %+v", list)
+	}
+
+	if err := svc.DeleteTag(ctx, otherID, tag.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized deleting another user's tag, got %v", err)
+	}
+	if err := svc.DeleteTag(ctx, userID, tag.ID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+}
+
+func TestPatternService_Collections(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "collections@example.com")
+	otherID := seedUserForTest(t, db, "other-collections@example.com")
+	stitchID := seedStitchForTest(t, db)
+	hat := createPatternForTest(t, svc, userID, stitchID, "Hat")
+	scarf := createPatternForTest(t, svc, userID, stitchID, "Scarf")
+
+	if err := svc.CreateCollection(ctx, &domain.Collection{UserID: userID, Name: " "}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a blank name, got %v", err)
+	}
+	c := &domain.Collection{UserID: userID, Name: "Christmas 2026 gifts"}
+	if err := svc.CreateCollection(ctx, c); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+
+	if err := svc.AddToCollection(ctx, userID, c.ID, []int64{hat.ID, scarf.ID}); err != nil {
+		t.Fatalf("AddToCollection: %v", err)
+	}
+	if _, err := svc.GetCollection(ctx, otherID, c.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for another user, got %v", err)
+	}
+
+	if err := svc.ReorderCollection(ctx, userID, c.ID, []int64{scarf.ID}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an order missing a pattern, got %v", err)
+	}
+	if err := svc.ReorderCollection(ctx, userID, c.ID, []int64{scarf.ID, hat.ID}); err != nil {
+		t.Fatalf("ReorderCollection: %v", err)
+	}
+	got, err := svc.GetCollection(ctx, userID, c.ID)
+	if err != nil {
+		t.Fatalf("GetCollection: %v", err)
+	}
+	if len(got.PatternIDs) != 2 || got.PatternIDs[0] != scarf.ID || got.PatternIDs[1] != hat.ID {
+		t.Fatalf("PatternIDs = %v, want scarf then hat", got.PatternIDs)
+	}
+
+	if err := svc.DeleteCollection(ctx, userID, c.ID); err != nil {
+		t.Fatalf("DeleteCollection: %v", err)
+	}
+	if _, err := svc.GetByID(ctx, hat.ID); err != nil {
+		t.Fatalf("expected patterns to outlive their collection: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// Terminologies lists the supported terminologies in the order they're
+// offered to users.
+var Terminologies = []domain.Terminology{domain.TerminologyUS, domain.TerminologyUK}
+
+// IsTerminology reports whether t is a supported terminology.
+func IsTerminology(t domain.Terminology) bool {
+	for _, known := range Terminologies {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// TerminologyLabel returns the label shown beside text written in t, e.g.
+// "UK terms".
+func TerminologyLabel(t domain.Terminology) string {
+	switch t {
+	case domain.TerminologyUK:
+		return "UK terms"
+	default:
+		return "US terms"
+	}
+}
+
+// stitchTerm is a predefined stitch as written in another terminology.
+type stitchTerm struct {
+	Abbreviation string
+	Name         string
+}
+
+// stitchTerms maps each predefined stitch's US abbreviation to how it is
+// written in the other terminologies. Every predefined stitch has an entry,
+// even where the terms agree, so a missing translation shows up in tests.
+var stitchTerms = map[domain.Terminology]map[string]stitchTerm{
+	domain.TerminologyUK: {
+		"ch":    {"ch", "Chain"},
+		"sl st": {"ss", "Slip Stitch"},
+		"sc":    {"dc", "Double Crochet"},
+		"hdc":   {"htr", "Half Treble Crochet"},
+		"dc":    {"tr", "Treble Crochet"},
+		"tr":    {"dtr", "Double Treble Crochet"},
+		"dtr":   {"trtr", "Triple Treble Crochet"},
+
+		"inc":     {"inc", "Increase (2 stitches in one)"},
+		"dec":     {"dec", "Decrease (2 stitches together)"},
+		"sc2tog":  {"dc2tog", "Double Crochet 2 Together"},
+		"hdc2tog": {"htr2tog", "Half Treble Crochet 2 Together"},
+		"dc2tog":  {"tr2tog", "Treble Crochet 2 Together"},
+		"dc3tog":  {"tr3tog", "Treble Crochet 3 Together"},
+		"tr2tog":  {"dtr2tog", "Double Treble Crochet 2 Together"},
+
+		"FPsc": {"FPdc", "Front Post Double Crochet"},
+		"BPsc": {"BPdc", "Back Post Double Crochet"},
+		"FPdc": {"FPtr", "Front Post Treble Crochet"},
+		"BPdc": {"BPtr", "Back Post Treble Crochet"},
+		"FPtr": {"FPdtr", "Front Post Double Treble Crochet"},
+		"BPtr": {"BPdtr", "Back Post Double Treble Crochet"},
+
+		"BLO": {"BLO", "Back Loop Only"},
+		"FLO": {"FLO", "Front Loop Only"},
+
+		"pc":      {"pc", "Popcorn Stitch"},
+		"puff":    {"puff", "Puff Stitch"},
+		"cl":      {"cl", "Cluster"},
+		"sh":      {"sh", "Shell"},
+		"bob":     {"bob", "Bobble"},
+		"crab st": {"crab st", "Crab Stitch (Reverse DC)"},
+		"lp st":   {"lp st", "Loop Stitch"},
+		"v-st":    {"v-st", "V-Stitch"},
+
+		"sk":  {"miss", "Miss"},
+		"yo":  {"yrh", "Yarn Round Hook"},
+		"tch": {"tch", "Turning Chain"},
+		"MR":  {"MR", "Magic Ring"},
+	},
+}
+
+// TranslateStitch returns a pattern stitch with its abbreviation and name in
+// terminology t. Predefined stitches use the mapping table; custom stitches
+// use the UK abbreviation they declare, if any, and keep their name. Stitches
+// written in US terms, or with no translation, are returned unchanged.
+func TranslateStitch(ps domain.PatternStitch, t domain.Terminology) domain.PatternStitch {
+	if t == domain.TerminologyUS || t == "" {
+		return ps
+	}
+	if isPredefinedStitch(ps.Abbreviation, ps.Category) {
+		if term, ok := stitchTerms[t][ps.Abbreviation]; ok {
+			ps.Abbreviation = term.Abbreviation
+			ps.Name = term.Name
+		}
+		return ps
+	}
+	if t == domain.TerminologyUK && ps.UKAbbreviation != "" {
+		ps.Abbreviation = ps.UKAbbreviation
+	}
+	return ps
+}
+
+// TranslatePattern returns a copy of the pattern with its stitches written
+// in terminology t, ready to render or track. The pattern's structure is
+// shared with the original, which is returned as is for US terms.
+func TranslatePattern(pattern *domain.Pattern, t domain.Terminology) *domain.Pattern {
+	if pattern == nil || t == domain.TerminologyUS || t == "" {
+		return pattern
+	}
+	translated := *pattern
+	translated.PatternStitches = make([]domain.PatternStitch, len(pattern.PatternStitches))
+	for i, ps := range pattern.PatternStitches {
+		translated.PatternStitches[i] = TranslateStitch(ps, t)
+	}
+	return &translated
+}
+
+// StitchAbbreviationIn returns a library stitch's abbreviation in
+// terminology t.
+func StitchAbbreviationIn(st domain.Stitch, t domain.Terminology) string {
+	return TranslateStitch(domain.PatternStitch{
+		Abbreviation:   st.Abbreviation,
+		Category:       st.Category,
+		UKAbbreviation: st.UKAbbreviation,
+	}, t).Abbreviation
+}
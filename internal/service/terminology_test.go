@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func TestStitchTerms_CoverPredefined(t *testing.T) {
+	for term, table := range stitchTerms {
+		for _, st := range predefinedStitches {
+			if _, ok := table[st.Abbreviation]; !ok {
+				t.Errorf("%s terms have no entry for %q", term, st.Abbreviation)
+			}
+		}
+		if len(table) != len(predefinedStitches) {
+			t.Errorf("%s terms have %d entries, want %d", term, len(table), len(predefinedStitches))
+		}
+	}
+}
+
+func TestTranslateStitch(t *testing.T) {
+	tests := []struct {
+		name string
+		ps   domain.PatternStitch
+		want string
+	}{
+		{"predefined", domain.PatternStitch{Abbreviation: "sc", Name: "Single Crochet", Category: "basic"}, "dc"},
+		{"predefined same in both", domain.PatternStitch{Abbreviation: "MR", Name: "Magic Ring", Category: "action"}, "MR"},
+		{"custom with translation", domain.PatternStitch{Abbreviation: "esc", Name: "Extended SC", Category: "custom", UKAbbreviation: "edc"}, "edc"},
+		{"custom without translation", domain.PatternStitch{Abbreviation: "msc", Name: "My Stitch", Category: "custom"}, "msc"},
+		{"custom reusing a US abbreviation", domain.PatternStitch{Abbreviation: "dc", Name: "Mine", Category: "custom"}, "dc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TranslateStitch(tt.ps, domain.TerminologyUK).Abbreviation; got != tt.want {
+				t.Errorf("UK abbreviation = %q, want %q", got, tt.want)
+			}
+			if got := TranslateStitch(tt.ps, domain.TerminologyUS); got != tt.ps {
+				t.Errorf("US translation changed the stitch: %+v", got)
+			}
+		})
+	}
+
+	uk := TranslateStitch(domain.PatternStitch{Abbreviation: "hdc", Name: "Half Double Crochet", Category: "basic"}, domain.TerminologyUK)
+	if uk.Name != "Half Treble Crochet" {
+		t.Errorf("UK name = %q, want Half Treble Crochet", uk.Name)
+	}
+}
+
+func TestTranslatePattern_RenderText(t *testing.T) {
+	stitches := []domain.PatternStitch{
+		{ID: 1, Abbreviation: "sc", Name: "Single Crochet", Category: "basic", Consumes: 1, Produces: 1},
+		{ID: 2, Abbreviation: "dc", Name: "Double Crochet", Category: "basic", Consumes: 1, Produces: 1},
+		{ID: 3, Abbreviation: "sl st", Name: "Slip Stitch", Category: "basic", Consumes: 1, Produces: 1},
+	}
+	pattern := &domain.Pattern{
+		Name:            "Swatch",
+		PatternType:     domain.PatternTypeRow,
+		PatternStitches: stitches,
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Row 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1},
+				{PatternStitchID: 2, Count: 2, RepeatCount: 1},
+				{PatternStitchID: 3, Count: 1, RepeatCount: 1},
+			}},
+		},
+	}
+
+	if got, want := RenderPatternText(TranslatePattern(pattern, domain.TerminologyUK)), "Row 1: 2 dc, 2 tr, ss (5)"; got != want {
+		t.Errorf("UK text = %q, want %q", got, want)
+	}
+	if got, want := RenderPatternText(TranslatePattern(pattern, domain.TerminologyUS)), "Row 1: 2 sc, 2 dc, sl st (5)"; got != want {
+		t.Errorf("US text = %q, want %q", got, want)
+	}
+	if pattern.PatternStitches[0].Abbreviation != "sc" {
+		t.Error("TranslatePattern modified the original pattern")
+	}
+}
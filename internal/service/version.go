@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// ListVersions returns the saved versions of a user's pattern, newest first.
+func (s *PatternService) ListVersions(ctx context.Context, userID, patternID int64) ([]domain.PatternVersion, error) {
+	existing, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+	return s.versions.ListByPattern(ctx, patternID)
+}
+
+// GetVersion returns a saved version of a user's pattern, with its snapshot.
+func (s *PatternService) GetVersion(ctx context.Context, userID, patternID, versionID int64) (*domain.PatternVersion, error) {
+	existing, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.UserID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	version, err := s.versions.GetByID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if version.PatternID != patternID {
+		return nil, domain.ErrNotFound
+	}
+	return version, nil
+}
+
+// RestoreVersion makes a saved version the pattern's current content. The
+// content it replaces is kept as a new version, so a restore can be undone.
+func (s *PatternService) RestoreVersion(ctx context.Context, userID, patternID, versionID int64) error {
+	version, err := s.GetVersion(ctx, userID, patternID, versionID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.patterns.GetByID(ctx, patternID)
+	if err != nil {
+		return err
+	}
+	if existing.IsReceivedSnapshot() || existing.Locked {
+		return domain.ErrPatternLocked
+	}
+
+	// The snapshot's pattern stitches keep their old IDs, which the
+	// repository remaps as it re-inserts them.
+	restored := *version.Snapshot
+	restored.ID = existing.ID
+	restored.UserID = existing.UserID
+
+	if err := s.patterns.UpdateWithVersion(ctx, &restored, versionOf(existing), s.keepVersions); err != nil {
+		return fmt.Errorf("restore version: %w", err)
+	}
+	return nil
+}
+
+// versionOf returns a snapshot of a pattern's current content, to be saved as
+// its next version.
+func versionOf(pattern *domain.Pattern) *domain.PatternVersion {
+	return &domain.PatternVersion{
+		PatternID: pattern.ID,
+		Name:      pattern.Name,
+		Snapshot:  pattern,
+	}
+}
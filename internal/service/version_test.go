@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+	"github.com/msomdec/stitch-map-2/internal/service"
+)
+
+func versionTestPattern(userID, stitchID int64, name string, count int) *domain.Pattern {
+	return &domain.Pattern{
+		UserID:      userID,
+		Name:        name,
+		PatternType: domain.PatternTypeRound,
+		InstructionGroups: []domain.InstructionGroup{
+			{SortOrder: 0, Label: "Round 1", RepeatCount: 1,
+				StitchEntries: []domain.StitchEntry{
+					{SortOrder: 0, PatternStitchID: stitchID, Count: count, RepeatCount: 1},
+				}},
+		},
+	}
+}
+
+func TestPatternService_Update_SavesVersion(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "versions@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := versionTestPattern(userID, stitchID, "Coaster", 6)
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	updated := versionTestPattern(userID, stitchID, "Coaster v2", 8)
+	updated.ID = p.ID
+	if err := svc.Update(ctx, userID, updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	versions, err := svc.ListVersions(ctx, userID, p.ID)
+	if err != nil {
+		t.Fatalf("ListVersions: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Version != 1 || versions[0].Name != "Coaster" {
+		t.Fatalf("expected version 1 of Coaster, got %+v", versions)
+	}
+
+	v, err := svc.GetVersion(ctx, userID, p.ID, versions[0].ID)
+	if err != nil {
+		t.Fatalf("GetVersion: %v", err)
+	}
+	if n := service.StitchCount(v.Snapshot); n != 6 {
+		t.Fatalf("expected the snapshot to have the original 6 stitches, got %d", n)
+	}
+}
+
+func TestPatternService_RestoreVersion(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "restore@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := versionTestPattern(userID, stitchID, "Coaster", 6)
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	updated := versionTestPattern(userID, stitchID, "Coaster v2", 8)
+	updated.ID = p.ID
+	if err := svc.Update(ctx, userID, updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	versions, _ := svc.ListVersions(ctx, userID, p.ID)
+	if err := svc.RestoreVersion(ctx, userID, p.ID, versions[0].ID); err != nil {
+		t.Fatalf("RestoreVersion: %v", err)
+	}
+
+	got, err := svc.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Name != "Coaster" || service.StitchCount(got) != 6 {
+		t.Fatalf("expected the original Coaster with 6 stitches, got %q with %d", got.Name, service.StitchCount(got))
+	}
+	if got.PatternStitches[0].Abbreviation != "sc" {
+		t.Fatalf("expected the restored pattern stitch sc, got %+v", got.PatternStitches)
+	}
+
+	// The replaced content is kept, so the restore can be undone.
+	versions, _ = svc.ListVersions(ctx, userID, p.ID)
+	if len(versions) != 2 || versions[0].Name != "Coaster v2" {
+		t.Fatalf("expected Coaster v2 saved as version 2, got %+v", versions)
+	}
+}
+
+func TestPatternService_Versions_OtherUser(t *testing.T) {
+	svc, _, db := newTestPatternService(t)
+	ctx := context.Background()
+
+	ownerID := seedUserForTest(t, db, "owner@example.com")
+	otherID := seedUserForTest(t, db, "other@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := versionTestPattern(ownerID, stitchID, "Coaster", 6)
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	updated := versionTestPattern(ownerID, stitchID, "Coaster v2", 8)
+	updated.ID = p.ID
+	if err := svc.Update(ctx, ownerID, updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	versions, _ := svc.ListVersions(ctx, ownerID, p.ID)
+
+	if _, err := svc.ListVersions(ctx, otherID, p.ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized listing, got %v", err)
+	}
+	if err := svc.RestoreVersion(ctx, otherID, p.ID, versions[0].ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized restoring, got %v", err)
+	}
+}
+
+func TestPatternService_Versions_Retention(t *testing.T) {
+	_, db := newTestAuthService(t)
+	svc := service.NewPatternService(db.Patterns(), db.Stitches(), db.Versions(), db.Tags(), db.Collections(), 2)
+	ctx := context.Background()
+
+	userID := seedUserForTest(t, db, "retention@example.com")
+	stitchID := seedStitchForTest(t, db)
+
+	p := versionTestPattern(userID, stitchID, "Coaster", 1)
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for count := 2; count <= 5; count++ {
+		updated := versionTestPattern(userID, stitchID, "Coaster", count)
+		updated.ID = p.ID
+		if err := svc.Update(ctx, userID, updated); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	versions, err := svc.ListVersions(ctx, userID, p.ID)
+	if err != nil {
+		t.Fatalf("ListVersions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 4 || versions[1].Version != 3 {
+		t.Fatalf("expected only versions 4 and 3 to be kept, got %+v", versions)
+	}
+}
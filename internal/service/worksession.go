@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/msomdec/stitch-map-2/internal/domain"
@@ -19,8 +20,10 @@ func NewWorkSessionService(sessions domain.WorkSessionRepository, patterns domai
 	return &WorkSessionService{sessions: sessions, patterns: patterns}
 }
 
-// Start creates a new active work session for the given pattern.
-func (s *WorkSessionService) Start(ctx context.Context, userID, patternID int64) (*domain.WorkSession, error) {
+// Start creates a new active work session for the given pattern. For graded
+// patterns, sizeIndex selects which of the pattern's sizes to follow; it must
+// be 0 for patterns that aren't graded.
+func (s *WorkSessionService) Start(ctx context.Context, userID, patternID int64, sizeIndex int) (*domain.WorkSession, error) {
 	// Verify the pattern exists and belongs to the user.
 	pattern, err := s.patterns.GetByID(ctx, patternID)
 	if err != nil {
@@ -32,10 +35,14 @@ func (s *WorkSessionService) Start(ctx context.Context, userID, patternID int64)
 	if len(pattern.InstructionGroups) == 0 {
 		return nil, fmt.Errorf("%w: pattern has no instruction groups", domain.ErrInvalidInput)
 	}
+	if sizeIndex < 0 || sizeIndex >= max(len(pattern.Sizes), 1) {
+		return nil, fmt.Errorf("%w: size must be one of the pattern's sizes", domain.ErrInvalidInput)
+	}
 
 	session := &domain.WorkSession{
 		PatternID: patternID,
 		UserID:    userID,
+		SizeIndex: sizeIndex,
 		Status:    domain.SessionStatusActive,
 	}
 
@@ -89,9 +96,10 @@ func (s *WorkSessionService) Abandon(ctx context.Context, id int64) error {
 	return s.sessions.Delete(ctx, id)
 }
 
-// NavigateForward advances the session position by one stitch.
-// Returns true if the pattern is now completed.
+// NavigateForward advances the session position by one stitch, following the
+// session's size of a graded pattern. Returns true if the pattern is now completed.
 func NavigateForward(session *domain.WorkSession, pattern *domain.Pattern) bool {
+	pattern = sessionPattern(pattern, session)
 	groups := pattern.InstructionGroups
 	if len(groups) == 0 {
 		return true
@@ -131,8 +139,7 @@ func NavigateForward(session *domain.WorkSession, pattern *domain.Pattern) bool
 
 	// Repeats exhausted — advance to next stitch entry.
 	session.CurrentStitchRepeat = 0
-	session.CurrentStitchIndex++
-	if session.CurrentStitchIndex < len(entries) {
+	if advanceEntry(session, group) {
 		return false
 	}
 
@@ -145,6 +152,7 @@ func advanceGroupRepeat(session *domain.WorkSession, pattern *domain.Pattern) bo
 	group := &pattern.InstructionGroups[session.CurrentGroupIndex]
 
 	session.CurrentStitchIndex = 0
+	session.BlockRepeats = enterBlocks(nil, enclosingBlocks(group, 0), false)
 	session.CurrentGroupRepeat++
 	if session.CurrentGroupRepeat < group.RepeatCount {
 		return false
@@ -154,13 +162,26 @@ func advanceGroupRepeat(session *domain.WorkSession, pattern *domain.Pattern) bo
 	return advanceGroup(session, pattern)
 }
 
-// advanceGroup moves to the next instruction group.
+// advanceGroup moves to the next instruction group. After the last group of
+// a piece with copies left to make, it starts the piece's next copy instead.
 func advanceGroup(session *domain.WorkSession, pattern *domain.Pattern) bool {
-	session.CurrentGroupIndex++
+	gi := session.CurrentGroupIndex
+	first, last := pattern.PieceSpan(gi)
+	switch {
+	case gi == last && session.CurrentPieceCopy+1 < pattern.MakeCount(gi):
+		session.CurrentPieceCopy++
+		session.CurrentGroupIndex = first
+	case gi == last:
+		session.CurrentPieceCopy = 0
+		session.CurrentGroupIndex++
+	default:
+		session.CurrentGroupIndex++
+	}
 	session.CurrentGroupRepeat = 0
 	session.CurrentStitchIndex = 0
 	session.CurrentStitchRepeat = 0
 	session.CurrentStitchCount = 0
+	session.BlockRepeats = nil
 
 	if session.CurrentGroupIndex >= len(pattern.InstructionGroups) {
 		return true // Pattern completed.
@@ -172,17 +193,101 @@ func advanceGroup(session *domain.WorkSession, pattern *domain.Pattern) bool {
 		return advanceGroup(session, pattern)
 	}
 
+	session.BlockRepeats = enterBlocks(nil, enclosingBlocks(group, 0), false)
 	return false
 }
 
-// NavigateBackward retreats the session position by one stitch.
-// Returns false if already at the beginning (no-op).
+// advanceEntry moves to the next stitch entry in the current group repeat,
+// looping back to the start of any repeat block that closes at the current
+// entry and still has repeats left. Returns false once the last entry of the
+// group repeat has been worked.
+func advanceEntry(session *domain.WorkSession, group *domain.InstructionGroup) bool {
+	ei := session.CurrentStitchIndex
+	enclosing := enclosingBlocks(group, ei)
+	repeats := syncBlockRepeats(session.BlockRepeats, len(enclosing))
+
+	// Close the blocks ending at this entry, innermost first.
+	for k := len(enclosing) - 1; k >= 0 && enclosing[k].EndEntry == ei; k-- {
+		repeats[k]++
+		if repeats[k] < enclosing[k].RepeatCount {
+			start := enclosing[k].StartEntry
+			session.CurrentStitchIndex = start
+			session.BlockRepeats = enterBlocks(repeats[:k+1], enclosingBlocks(group, start), false)
+			return true
+		}
+		repeats = repeats[:k]
+	}
+
+	if ei+1 >= len(group.StitchEntries) {
+		return false
+	}
+	session.CurrentStitchIndex = ei + 1
+	session.BlockRepeats = enterBlocks(repeats, enclosingBlocks(group, ei+1), false)
+	return true
+}
+
+// retreatEntry moves to the previous stitch entry in the current group
+// repeat, stepping back into the previous pass of any repeat block that opens
+// at the current entry. Returns false if already at the first stitch of the
+// group repeat.
+func retreatEntry(session *domain.WorkSession, group *domain.InstructionGroup) bool {
+	ei := session.CurrentStitchIndex
+	enclosing := enclosingBlocks(group, ei)
+	repeats := syncBlockRepeats(session.BlockRepeats, len(enclosing))
+
+	// Reopen the blocks starting at this entry, innermost first.
+	for k := len(enclosing) - 1; k >= 0 && enclosing[k].StartEntry == ei; k-- {
+		if repeats[k] > 0 {
+			repeats[k]--
+			end := enclosing[k].EndEntry
+			session.CurrentStitchIndex = end
+			session.BlockRepeats = enterBlocks(repeats[:k+1], enclosingBlocks(group, end), true)
+			return true
+		}
+		repeats = repeats[:k]
+	}
+
+	if ei == 0 {
+		return false
+	}
+	session.CurrentStitchIndex = ei - 1
+	session.BlockRepeats = enterBlocks(repeats, enclosingBlocks(group, ei-1), true)
+	return true
+}
+
+// syncBlockRepeats returns a copy of repeats sized to n counters, padding
+// with zeros. Sessions saved before repeat blocks existed have no counters.
+func syncBlockRepeats(repeats []int, n int) []int {
+	synced := make([]int, n, n+1)
+	copy(synced, repeats)
+	return synced
+}
+
+// enterBlocks appends counters for the enclosing blocks not yet covered by
+// repeats. New counters start at the first pass, or the last pass when
+// entering from the end (navigating backward).
+func enterBlocks(repeats []int, enclosing []domain.RepeatBlock, last bool) []int {
+	for k := len(repeats); k < len(enclosing); k++ {
+		if last {
+			repeats = append(repeats, enclosing[k].RepeatCount-1)
+		} else {
+			repeats = append(repeats, 0)
+		}
+	}
+	return repeats
+}
+
+// NavigateBackward retreats the session position by one stitch, following the
+// session's size of a graded pattern. Returns false if already at the beginning (no-op).
 func NavigateBackward(session *domain.WorkSession, pattern *domain.Pattern) bool {
+	pattern = sessionPattern(pattern, session)
 	if session.CurrentGroupIndex == 0 &&
+		session.CurrentPieceCopy == 0 &&
 		session.CurrentGroupRepeat == 0 &&
 		session.CurrentStitchIndex == 0 &&
 		session.CurrentStitchRepeat == 0 &&
-		session.CurrentStitchCount == 0 {
+		session.CurrentStitchCount == 0 &&
+		!slices.ContainsFunc(session.BlockRepeats, func(r int) bool { return r > 0 }) {
 		return false // Already at the beginning.
 	}
 
@@ -201,8 +306,8 @@ func NavigateBackward(session *domain.WorkSession, pattern *domain.Pattern) bool
 	}
 
 	// Try to retreat to the previous stitch entry.
-	if session.CurrentStitchIndex > 0 {
-		session.CurrentStitchIndex--
+	if session.CurrentGroupIndex < len(pattern.InstructionGroups) &&
+		retreatEntry(session, &pattern.InstructionGroups[session.CurrentGroupIndex]) {
 		entry := &pattern.InstructionGroups[session.CurrentGroupIndex].StitchEntries[session.CurrentStitchIndex]
 		session.CurrentStitchRepeat = entry.RepeatCount - 1
 		session.CurrentStitchCount = entry.Count - 1
@@ -213,10 +318,7 @@ func NavigateBackward(session *domain.WorkSession, pattern *domain.Pattern) bool
 	if session.CurrentGroupRepeat > 0 {
 		session.CurrentGroupRepeat--
 		group := &pattern.InstructionGroups[session.CurrentGroupIndex]
-		lastEntry := &group.StitchEntries[len(group.StitchEntries)-1]
-		session.CurrentStitchIndex = len(group.StitchEntries) - 1
-		session.CurrentStitchRepeat = lastEntry.RepeatCount - 1
-		session.CurrentStitchCount = lastEntry.Count - 1
+		retreatToLastStitch(session, group)
 		return true
 	}
 
@@ -224,22 +326,45 @@ func NavigateBackward(session *domain.WorkSession, pattern *domain.Pattern) bool
 	return retreatToPreviousGroup(session, pattern)
 }
 
-// retreatToPreviousGroup moves to the last stitch of the previous non-empty group.
+// retreatToPreviousGroup moves to the last stitch of the previous non-empty
+// group. From the first group of a later copy of a piece, that is the last
+// group of the copy before it.
 func retreatToPreviousGroup(session *domain.WorkSession, pattern *domain.Pattern) bool {
-	for session.CurrentGroupIndex > 0 {
-		session.CurrentGroupIndex--
+	for {
+		gi := session.CurrentGroupIndex
+		first, last := pattern.PieceSpan(gi)
+		switch {
+		case gi == first && session.CurrentPieceCopy > 0:
+			session.CurrentPieceCopy--
+			session.CurrentGroupIndex = last
+		case gi == 0:
+			return false
+		case gi == first:
+			session.CurrentGroupIndex--
+			session.CurrentPieceCopy = pattern.MakeCount(gi-1) - 1
+		default:
+			session.CurrentGroupIndex--
+		}
+
 		group := &pattern.InstructionGroups[session.CurrentGroupIndex]
 		if len(group.StitchEntries) == 0 {
 			continue // Skip empty groups.
 		}
 		session.CurrentGroupRepeat = group.RepeatCount - 1
-		lastEntry := &group.StitchEntries[len(group.StitchEntries)-1]
-		session.CurrentStitchIndex = len(group.StitchEntries) - 1
-		session.CurrentStitchRepeat = lastEntry.RepeatCount - 1
-		session.CurrentStitchCount = lastEntry.Count - 1
+		retreatToLastStitch(session, group)
 		return true
 	}
-	return false
+}
+
+// retreatToLastStitch positions the session on the final stitch of a group
+// repeat, inside the last pass of every repeat block around it.
+func retreatToLastStitch(session *domain.WorkSession, group *domain.InstructionGroup) {
+	last := len(group.StitchEntries) - 1
+	lastEntry := &group.StitchEntries[last]
+	session.CurrentStitchIndex = last
+	session.CurrentStitchRepeat = lastEntry.RepeatCount - 1
+	session.CurrentStitchCount = lastEntry.Count - 1
+	session.BlockRepeats = enterBlocks(nil, enclosingBlocks(group, last), true)
 }
 
 // AdvanceSession handles a forward navigation request, updating the session state.
@@ -262,24 +387,125 @@ func (s *WorkSessionService) RetreatSession(ctx context.Context, session *domain
 	return s.sessions.Update(ctx, session)
 }
 
+// RemapSessions fits the user's unfinished sessions on a pattern to its new
+// content, after it changed from before to after. Each session moves to the
+// group with the same label and keeps its place within it where that place
+// still exists; otherwise it restarts the group. Returns the number of
+// sessions that had to restart.
+func (s *WorkSessionService) RemapSessions(ctx context.Context, userID int64, before, after *domain.Pattern) (int, error) {
+	sessions, err := s.sessions.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("get active sessions: %w", err)
+	}
+
+	restarted := 0
+	for i := range sessions {
+		session := &sessions[i]
+		if session.PatternID != after.ID {
+			continue
+		}
+		if remapSession(session, before, after) {
+			restarted++
+		}
+		if err := s.sessions.Update(ctx, session); err != nil {
+			return restarted, fmt.Errorf("update session: %w", err)
+		}
+	}
+	return restarted, nil
+}
+
+// remapSession moves a session from its position in before to the same place
+// in after, which has at least one group. Returns true if the session had to
+// restart its group.
+func remapSession(session *domain.WorkSession, before, after *domain.Pattern) bool {
+	restart := false
+	if session.SizeIndex >= max(len(after.Sizes), 1) {
+		session.SizeIndex = 0
+		restart = true
+	}
+
+	gi := session.CurrentGroupIndex
+	groups := after.InstructionGroups
+	if gi >= len(before.InstructionGroups) || gi >= len(groups) || groups[gi].Label != before.InstructionGroups[gi].Label {
+		ni := -1
+		if gi < len(before.InstructionGroups) {
+			label := before.InstructionGroups[gi].Label
+			ni = slices.IndexFunc(groups, func(g domain.InstructionGroup) bool { return g.Label == label })
+		}
+		if ni < 0 {
+			ni = min(gi, len(groups)-1)
+			restart = true
+		}
+		session.CurrentGroupIndex = ni
+	}
+
+	pattern := sessionPattern(after, session)
+	group := &pattern.InstructionGroups[session.CurrentGroupIndex]
+	if !restart && sessionPositionExists(session, pattern, group) {
+		return false
+	}
+
+	session.CurrentPieceCopy = min(session.CurrentPieceCopy, pattern.MakeCount(session.CurrentGroupIndex)-1)
+	session.CurrentGroupRepeat = 0
+	session.CurrentStitchIndex = 0
+	session.CurrentStitchRepeat = 0
+	session.CurrentStitchCount = 0
+	session.BlockRepeats = enterBlocks(nil, enclosingBlocks(group, 0), false)
+	return true
+}
+
+// sessionPositionExists reports whether a session's place within its current
+// group is one the group has.
+func sessionPositionExists(session *domain.WorkSession, pattern *domain.Pattern, group *domain.InstructionGroup) bool {
+	if session.CurrentPieceCopy >= pattern.MakeCount(session.CurrentGroupIndex) || session.CurrentGroupRepeat >= group.RepeatCount {
+		return false
+	}
+	if len(group.StitchEntries) == 0 {
+		return session.CurrentStitchIndex == 0
+	}
+	if session.CurrentStitchIndex >= len(group.StitchEntries) {
+		return false
+	}
+	entry := group.StitchEntries[session.CurrentStitchIndex]
+	if session.CurrentStitchRepeat >= entry.RepeatCount || session.CurrentStitchCount >= entry.Count {
+		return false
+	}
+	enclosing := enclosingBlocks(group, session.CurrentStitchIndex)
+	if len(session.BlockRepeats) > len(enclosing) {
+		return false
+	}
+	for k, r := range session.BlockRepeats {
+		if r >= enclosing[k].RepeatCount {
+			return false
+		}
+	}
+	return true
+}
+
 // SessionProgress computes the overall progress of a session through a pattern.
 type SessionProgress struct {
 	CompletedStitches int
 	TotalStitches     int
 	Percentage        float64
-	GroupLabel        string
-	GroupRepeatInfo   string // e.g., "Repeat 2 of 4"
-	CurrentAbbr       string // Current stitch abbreviation
-	CurrentName       string // Current stitch name
-	PrevAbbr          string // Previous stitch abbreviation (empty if at start)
-	NextAbbr          string // Next stitch abbreviation (empty if at end)
-	CurrentGroupID    int64  // Database ID of the current instruction group
+	SizeName          string               // Size being followed, for graded patterns
+	PieceInfo         string               // e.g., "Arm 2 of 2" for patterns made of pieces
+	GroupLabel        string               // e.g., "Rnd 11 (of 8–15)" within a range of identical rounds
+	GroupRepeatInfo   string               // e.g., "Repeat 2 of 4"
+	BlockRepeatInfo   string               // e.g., "Bracket repeat 3 of 6" for the innermost repeating block
+	CurrentAbbr       string               // Current stitch abbreviation
+	CurrentName       string               // Current stitch name
+	PrevAbbr          string               // Previous stitch abbreviation (empty if at start)
+	NextAbbr          string               // Next stitch abbreviation (empty if at end)
+	CurrentColor      *domain.PatternColor // Color the current stitch is worked in, for patterns with a palette
+	NextColor         *domain.PatternColor // Set when the next stitch changes to a different color
+	CurrentGroupID    int64                // Database ID of the current instruction group
 	Groups            []GroupProgress
 }
 
 // GroupProgress tracks progress for an individual instruction group.
 type GroupProgress struct {
 	Label            string
+	Piece            string // Name of the piece the group belongs to, if any
 	RepeatCount      int
 	CurrentRepeat    int    // 1-based, only meaningful when Status == "current"
 	Status           string // "completed", "current", "upcoming"
@@ -287,8 +513,15 @@ type GroupProgress struct {
 	TotalInGroup     int
 }
 
-// ComputeProgress calculates the current progress through a pattern.
+// ComputeProgress calculates the current progress through a pattern, using the
+// numbers of the session's size for graded patterns.
 func ComputeProgress(session *domain.WorkSession, pattern *domain.Pattern) SessionProgress {
+	var sizeName string
+	if session.SizeIndex >= 0 && session.SizeIndex < len(pattern.Sizes) {
+		sizeName = pattern.Sizes[session.SizeIndex]
+	}
+	pattern = sessionPattern(pattern, session)
+
 	lookup := buildPatternStitchLookup(pattern.PatternStitches)
 	nameLookup := buildPatternStitchNameLookup(pattern.PatternStitches)
 	total := StitchCount(pattern)
@@ -300,39 +533,17 @@ func ComputeProgress(session *domain.WorkSession, pattern *domain.Pattern) Sessi
 	for gi := range groups {
 		group := &groups[gi]
 		groupTotal := GroupStitchCount(group)
+		completed += groupTotal * group.RepeatCount * workedCopies(session, pattern, gi)
 
-		if gi < session.CurrentGroupIndex {
-			completed += groupTotal * group.RepeatCount
-			continue
-		}
-		if gi > session.CurrentGroupIndex {
-			break
-		}
-
-		// Current group: count completed repeats.
-		completed += groupTotal * session.CurrentGroupRepeat
-
-		// Count completed entries in the current repeat.
-		for ei := 0; ei < len(group.StitchEntries); ei++ {
-			entry := &group.StitchEntries[ei]
-			entryTotal := entry.Count * entry.RepeatCount
-
-			if ei < session.CurrentStitchIndex {
-				completed += entryTotal
-				continue
-			}
-			if ei > session.CurrentStitchIndex {
-				break
-			}
-
-			// Current entry: count completed repeats.
-			completed += entry.Count * session.CurrentStitchRepeat
-			// Count completed stitches in the current repeat.
-			completed += session.CurrentStitchCount
+		if gi == session.CurrentGroupIndex {
+			// Current group: count completed repeats, then the current repeat.
+			completed += groupTotal * session.CurrentGroupRepeat
+			completed += completedInGroupRepeat(session, group)
 		}
 	}
 
 	progress := SessionProgress{
+		SizeName:          sizeName,
 		CompletedStitches: completed,
 		TotalStitches:     total,
 	}
@@ -345,7 +556,13 @@ func ComputeProgress(session *domain.WorkSession, pattern *domain.Pattern) Sessi
 	if session.CurrentGroupIndex < len(groups) {
 		group := &groups[session.CurrentGroupIndex]
 		progress.CurrentGroupID = group.ID
-		progress.GroupLabel = group.Label
+		progress.GroupLabel = GroupRoundLabel(pattern, session.CurrentGroupIndex, session.CurrentGroupRepeat)
+		if piece := pattern.PieceAt(session.CurrentGroupIndex); piece != nil {
+			progress.PieceInfo = piece.Name
+			if piece.MakeCount > 1 {
+				progress.PieceInfo = fmt.Sprintf("%s %d of %d", piece.Name, session.CurrentPieceCopy+1, piece.MakeCount)
+			}
+		}
 		if group.RepeatCount > 1 {
 			progress.GroupRepeatInfo = fmt.Sprintf("Repeat %d of %d", session.CurrentGroupRepeat+1, group.RepeatCount)
 		}
@@ -355,12 +572,32 @@ func ComputeProgress(session *domain.WorkSession, pattern *domain.Pattern) Sessi
 			entry := &group.StitchEntries[session.CurrentStitchIndex]
 			progress.CurrentAbbr = lookup[entry.PatternStitchID]
 			progress.CurrentName = nameLookup[entry.PatternStitchID]
+
+			enclosing := enclosingBlocks(group, session.CurrentStitchIndex)
+			repeats := syncBlockRepeats(session.BlockRepeats, len(enclosing))
+			for k := len(enclosing) - 1; k >= 0; k-- {
+				if enclosing[k].RepeatCount > 1 {
+					progress.BlockRepeatInfo = fmt.Sprintf("Bracket repeat %d of %d", repeats[k]+1, enclosing[k].RepeatCount)
+					break
+				}
+			}
 		}
 
 		// Previous stitch info.
 		progress.PrevAbbr = getPrevStitchAbbr(session, pattern, lookup)
 		// Next stitch info.
 		progress.NextAbbr = getNextStitchAbbr(session, pattern, lookup)
+
+		// Working color, and an upcoming change of color.
+		current := workingColor(session, pattern)
+		progress.CurrentColor = ColorAt(pattern.Colors, current)
+		if progress.CurrentColor != nil && progress.NextAbbr != "" {
+			next := copySessionPosition(session)
+			NavigateForward(&next, pattern)
+			if ci := workingColor(&next, pattern); ci != nil && *ci != *current {
+				progress.NextColor = ColorAt(pattern.Colors, ci)
+			}
+		}
 	}
 
 	// Build per-group progress.
@@ -368,36 +605,31 @@ func ComputeProgress(session *domain.WorkSession, pattern *domain.Pattern) Sessi
 	for gi := range groups {
 		group := &groups[gi]
 		singleRepeatCount := GroupStitchCount(group)
-		totalInGroup := singleRepeatCount * group.RepeatCount
+		singleCopyCount := singleRepeatCount * group.RepeatCount
+		totalInGroup := singleCopyCount * pattern.MakeCount(gi)
 
 		gp := GroupProgress{
 			Label:        group.Label,
 			RepeatCount:  group.RepeatCount,
 			TotalInGroup: totalInGroup,
 		}
+		if piece := pattern.PieceAt(gi); piece != nil {
+			gp.Piece = piece.Name
+		}
+
+		// Copies of earlier pieces, and earlier copies of this piece.
+		gp.CompletedInGroup = singleCopyCount * workedCopies(session, pattern, gi)
 
 		if gi < session.CurrentGroupIndex {
 			gp.Status = "completed"
-			gp.CompletedInGroup = totalInGroup
 		} else if gi == session.CurrentGroupIndex {
 			gp.Status = "current"
 			gp.CurrentRepeat = session.CurrentGroupRepeat + 1 // 1-based
-			// Completed stitches within this group: full repeats + partial current repeat.
-			completedInGroup := singleRepeatCount * session.CurrentGroupRepeat
-			for ei := 0; ei < len(group.StitchEntries); ei++ {
-				entry := &group.StitchEntries[ei]
-				entryTotal := entry.Count * entry.RepeatCount
-				if ei < session.CurrentStitchIndex {
-					completedInGroup += entryTotal
-				} else if ei == session.CurrentStitchIndex {
-					completedInGroup += entry.Count * session.CurrentStitchRepeat
-					completedInGroup += session.CurrentStitchCount
-				}
-			}
-			gp.CompletedInGroup = completedInGroup
+			// Completed stitches within this copy: full repeats + partial current repeat.
+			gp.CompletedInGroup += singleRepeatCount * session.CurrentGroupRepeat
+			gp.CompletedInGroup += completedInGroupRepeat(session, group)
 		} else {
 			gp.Status = "upcoming"
-			gp.CompletedInGroup = 0
 		}
 
 		progress.Groups[gi] = gp
@@ -414,85 +646,99 @@ func buildPatternStitchNameLookup(stitches []domain.PatternStitch) map[int64]str
 	return lookup
 }
 
-// getPrevStitchAbbr returns the abbreviation of the stitch that would come before
-// the current position, or empty if at the start.
-func getPrevStitchAbbr(session *domain.WorkSession, pattern *domain.Pattern, lookup map[int64]string) string {
-	// If we can retreat within the same entry, it's the same stitch.
-	if session.CurrentStitchCount > 0 || session.CurrentStitchRepeat > 0 {
-		entry := &pattern.InstructionGroups[session.CurrentGroupIndex].StitchEntries[session.CurrentStitchIndex]
-		return lookup[entry.PatternStitchID]
+// completedInGroupRepeat counts the stitches already worked in the current
+// repeat of a group, including earlier passes through open repeat blocks.
+func completedInGroupRepeat(session *domain.WorkSession, group *domain.InstructionGroup) int {
+	ei := session.CurrentStitchIndex
+	if ei >= len(group.StitchEntries) {
+		return GroupStitchCount(group)
 	}
 
-	// Check previous entry in the same group repeat.
-	if session.CurrentStitchIndex > 0 {
-		entry := &pattern.InstructionGroups[session.CurrentGroupIndex].StitchEntries[session.CurrentStitchIndex-1]
-		return lookup[entry.PatternStitchID]
-	}
+	completed := 0
 
-	// Check last entry of the previous group repeat.
-	if session.CurrentGroupRepeat > 0 {
-		group := &pattern.InstructionGroups[session.CurrentGroupIndex]
-		if len(group.StitchEntries) > 0 {
-			entry := &group.StitchEntries[len(group.StitchEntries)-1]
-			return lookup[entry.PatternStitchID]
-		}
+	// Entries before the current one, multiplied by the blocks around them
+	// that have already been worked in full.
+	for j := 0; j < ei; j++ {
+		e := &group.StitchEntries[j]
+		completed += e.Count * e.RepeatCount * closedBlockMultiplier(group, j, ei)
 	}
 
-	// Check previous group.
-	for gi := session.CurrentGroupIndex - 1; gi >= 0; gi-- {
-		group := &pattern.InstructionGroups[gi]
-		if len(group.StitchEntries) > 0 {
-			entry := &group.StitchEntries[len(group.StitchEntries)-1]
-			return lookup[entry.PatternStitchID]
-		}
+	// Finished passes through the blocks still open at the current entry.
+	enclosing := enclosingBlocks(group, ei)
+	repeats := syncBlockRepeats(session.BlockRepeats, len(enclosing))
+	for k, b := range enclosing {
+		completed += repeats[k] * blockPassStitches(group, b)
 	}
 
-	return ""
+	// Current entry: completed repeats plus stitches in the current repeat.
+	entry := &group.StitchEntries[ei]
+	completed += entry.Count*session.CurrentStitchRepeat + session.CurrentStitchCount
+	return completed
+}
+
+// getPrevStitchAbbr returns the abbreviation of the stitch that would come before
+// the current position, or empty if at the start.
+func getPrevStitchAbbr(session *domain.WorkSession, pattern *domain.Pattern, lookup map[int64]string) string {
+	prev := copySessionPosition(session)
+	if !NavigateBackward(&prev, pattern) {
+		return ""
+	}
+	return currentStitchAbbr(&prev, pattern, lookup)
 }
 
 // getNextStitchAbbr returns the abbreviation of the stitch that would come after
 // the current position, or empty if at the end.
 func getNextStitchAbbr(session *domain.WorkSession, pattern *domain.Pattern, lookup map[int64]string) string {
 	gi := session.CurrentGroupIndex
-	if gi >= len(pattern.InstructionGroups) {
+	if gi >= len(pattern.InstructionGroups) ||
+		session.CurrentStitchIndex >= len(pattern.InstructionGroups[gi].StitchEntries) {
 		return ""
 	}
 
-	group := &pattern.InstructionGroups[gi]
-	ei := session.CurrentStitchIndex
-	if ei >= len(group.StitchEntries) {
+	next := copySessionPosition(session)
+	if NavigateForward(&next, pattern) {
 		return ""
 	}
+	return currentStitchAbbr(&next, pattern, lookup)
+}
 
-	entry := &group.StitchEntries[ei]
-
-	// Check if there are more stitches in the current count.
-	if session.CurrentStitchCount+1 < entry.Count {
-		return lookup[entry.PatternStitchID]
-	}
-
-	// Check if there are more repeats of the current entry.
-	if session.CurrentStitchRepeat+1 < entry.RepeatCount {
-		return lookup[entry.PatternStitchID]
+// workedCopies returns how many copies of the group at index gi have been
+// fully worked before the session's current position. Groups of the current
+// piece have been worked once per finished copy, plus once more if they come
+// before the current group.
+func workedCopies(session *domain.WorkSession, pattern *domain.Pattern, gi int) int {
+	cur := session.CurrentGroupIndex
+	first, last := pattern.PieceSpan(cur)
+	inCurrentPiece := first <= gi && gi <= last
+
+	switch {
+	case inCurrentPiece && gi < cur:
+		return session.CurrentPieceCopy + 1
+	case inCurrentPiece:
+		return session.CurrentPieceCopy
+	case gi < cur:
+		return pattern.MakeCount(gi)
+	default:
+		return 0
 	}
+}
 
-	// Check next entry in the group.
-	if ei+1 < len(group.StitchEntries) {
-		return lookup[group.StitchEntries[ei+1].PatternStitchID]
-	}
+// copySessionPosition returns a copy of the session that can be navigated
+// without affecting the original.
+func copySessionPosition(session *domain.WorkSession) domain.WorkSession {
+	c := *session
+	c.BlockRepeats = slices.Clone(session.BlockRepeats)
+	return c
+}
 
-	// Check next group repeat.
-	if session.CurrentGroupRepeat+1 < group.RepeatCount && len(group.StitchEntries) > 0 {
-		return lookup[group.StitchEntries[0].PatternStitchID]
+// currentStitchAbbr returns the abbreviation of the stitch at the session's position.
+func currentStitchAbbr(session *domain.WorkSession, pattern *domain.Pattern, lookup map[int64]string) string {
+	if session.CurrentGroupIndex >= len(pattern.InstructionGroups) {
+		return ""
 	}
-
-	// Check next group.
-	for ngi := gi + 1; ngi < len(pattern.InstructionGroups); ngi++ {
-		nextGroup := &pattern.InstructionGroups[ngi]
-		if len(nextGroup.StitchEntries) > 0 {
-			return lookup[nextGroup.StitchEntries[0].PatternStitchID]
-		}
+	group := &pattern.InstructionGroups[session.CurrentGroupIndex]
+	if session.CurrentStitchIndex >= len(group.StitchEntries) {
+		return ""
 	}
-
-	return ""
+	return lookup[group.StitchEntries[session.CurrentStitchIndex].PatternStitchID]
 }
@@ -543,8 +543,8 @@ func TestComputeProgress_GroupLabel(t *testing.T) {
 
 	progress := ComputeProgress(session, pattern)
 
-	if progress.GroupLabel != "Rounds 3-5" {
-		t.Fatalf("expected group label 'Rounds 3-5', got %q", progress.GroupLabel)
+	if progress.GroupLabel != "Round 3 (of 3–5)" {
+		t.Fatalf("expected group label 'Round 3 (of 3–5)', got %q", progress.GroupLabel)
 	}
 	if progress.GroupRepeatInfo != "Repeat 1 of 3" {
 		t.Fatalf("expected 'Repeat 1 of 3', got %q", progress.GroupRepeatInfo)
@@ -685,3 +685,392 @@ func TestComputeProgress_GroupsWithRepeats(t *testing.T) {
 		t.Fatalf("expected 2 completed in group, got %d", g.CompletedInGroup)
 	}
 }
+
+// nestedBlockPattern creates a pattern with nested repeat blocks.
+// "Round 1 (×2): ch, *(sc, inc) x3, 2 dc* repeat 2 times"
+func nestedBlockPattern() *domain.Pattern {
+	return &domain.Pattern{
+		PatternStitches: []domain.PatternStitch{
+			{ID: 1, Abbreviation: "sc", Name: "Single Crochet"},
+			{ID: 2, Abbreviation: "dc", Name: "Double Crochet"},
+			{ID: 3, Abbreviation: "ch", Name: "Chain"},
+			{ID: 5, Abbreviation: "inc", Name: "Increase"},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label:       "Round 1",
+				RepeatCount: 2,
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 3, Count: 1, RepeatCount: 1}, // ch
+					{PatternStitchID: 1, Count: 1, RepeatCount: 1}, // sc
+					{PatternStitchID: 5, Count: 1, RepeatCount: 1}, // inc
+					{PatternStitchID: 2, Count: 2, RepeatCount: 1}, // 2 dc
+				},
+				RepeatBlocks: []domain.RepeatBlock{
+					{StartEntry: 1, EndEntry: 3, RepeatCount: 2, Bracket: domain.BracketAsterisk},
+					{StartEntry: 1, EndEntry: 2, RepeatCount: 3, Bracket: domain.BracketParen},
+				},
+			},
+		},
+	}
+}
+
+func TestNavigateForward_NestedRepeatBlocks(t *testing.T) {
+	pattern := nestedBlockPattern()
+	session := newSession()
+	lookup := buildPatternStitchLookup(pattern.PatternStitches)
+
+	oneRound := []string{
+		"ch",
+		"sc", "inc", "sc", "inc", "sc", "inc", "dc", "dc",
+		"sc", "inc", "sc", "inc", "sc", "inc", "dc", "dc",
+	}
+	expected := append(append([]string{}, oneRound...), oneRound...)
+
+	total := StitchCount(pattern)
+	if total != len(expected) {
+		t.Fatalf("expected %d total stitches, got %d", len(expected), total)
+	}
+
+	for i, want := range expected {
+		if got := currentStitchAbbr(session, pattern, lookup); got != want {
+			t.Fatalf("stitch %d: expected %q, got %q", i+1, want, got)
+		}
+		if got := ComputeProgress(session, pattern).CompletedStitches; got != i {
+			t.Fatalf("stitch %d: expected %d completed, got %d", i+1, i, got)
+		}
+		completed := NavigateForward(session, pattern)
+		if completed != (i == len(expected)-1) {
+			t.Fatalf("stitch %d: unexpected completed=%v", i+1, completed)
+		}
+	}
+}
+
+func TestNavigateBackward_NestedRepeatBlocks(t *testing.T) {
+	pattern := nestedBlockPattern()
+	session := newSession()
+	total := StitchCount(pattern)
+
+	for range total - 1 {
+		NavigateForward(session, pattern)
+	}
+
+	// Walk back to the start, checking progress decreases one stitch at a time.
+	for i := total - 1; i > 0; i-- {
+		if got := ComputeProgress(session, pattern).CompletedStitches; got != i {
+			t.Fatalf("expected %d completed, got %d", i, got)
+		}
+		if !NavigateBackward(session, pattern) {
+			t.Fatalf("unable to move backward with %d completed", i)
+		}
+	}
+
+	if session.CurrentGroupRepeat != 0 || session.CurrentStitchIndex != 0 ||
+		session.CurrentStitchRepeat != 0 || session.CurrentStitchCount != 0 {
+		t.Fatalf("expected back at start, got gr=%d si=%d sr=%d sc=%d",
+			session.CurrentGroupRepeat, session.CurrentStitchIndex,
+			session.CurrentStitchRepeat, session.CurrentStitchCount)
+	}
+	if NavigateBackward(session, pattern) {
+		t.Fatal("should not be able to move backward at start")
+	}
+}
+
+func TestComputeProgress_BlockRepeatInfo(t *testing.T) {
+	pattern := nestedBlockPattern()
+	session := newSession()
+
+	// ch, sc, inc, sc → now on the second inc of the inner block.
+	for range 4 {
+		NavigateForward(session, pattern)
+	}
+
+	progress := ComputeProgress(session, pattern)
+	if progress.BlockRepeatInfo != "Bracket repeat 2 of 3" {
+		t.Fatalf("expected 'Bracket repeat 2 of 3', got %q", progress.BlockRepeatInfo)
+	}
+	if progress.PrevAbbr != "sc" || progress.NextAbbr != "sc" {
+		t.Fatalf("expected prev/next sc/sc, got %q/%q", progress.PrevAbbr, progress.NextAbbr)
+	}
+}
+
+// gradedPattern creates a two-size pattern: "Row 1: 3 (5) sc" worked 1 (2) times.
+func gradedPattern() *domain.Pattern {
+	return &domain.Pattern{
+		Sizes: []string{"S", "M"},
+		PatternStitches: []domain.PatternStitch{
+			{ID: 1, Abbreviation: "sc", Name: "Single Crochet"},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{
+				Label:            "Row 1",
+				RepeatCount:      1,
+				SizeRepeatCounts: []int{1, 2},
+				StitchEntries: []domain.StitchEntry{
+					{PatternStitchID: 1, Count: 3, SizeCounts: []int{3, 5}, RepeatCount: 1},
+				},
+			},
+		},
+	}
+}
+
+func TestNavigateForward_GradedPatternFollowsSessionSize(t *testing.T) {
+	tests := []struct {
+		sizeIndex int
+		stitches  int
+	}{
+		{0, 3},  // S: 3 sc once
+		{1, 10}, // M: 5 sc twice
+	}
+	for _, tt := range tests {
+		pattern := gradedPattern()
+		session := newSession()
+		session.SizeIndex = tt.sizeIndex
+
+		for i := range tt.stitches - 1 {
+			if NavigateForward(session, pattern) {
+				t.Fatalf("size %d: completed too early at stitch %d", tt.sizeIndex, i+1)
+			}
+		}
+		if !NavigateForward(session, pattern) {
+			t.Fatalf("size %d: expected completion after %d stitches", tt.sizeIndex, tt.stitches)
+		}
+	}
+}
+
+func TestNavigateBackward_GradedPattern(t *testing.T) {
+	pattern := gradedPattern()
+	session := newSession()
+	session.SizeIndex = 1
+
+	// Finish the first repeat of 5 sc, then step back into it.
+	for range 5 {
+		NavigateForward(session, pattern)
+	}
+	if session.CurrentGroupRepeat != 1 {
+		t.Fatalf("expected second group repeat, got %d", session.CurrentGroupRepeat)
+	}
+	NavigateBackward(session, pattern)
+	if session.CurrentGroupRepeat != 0 || session.CurrentStitchCount != 4 {
+		t.Fatalf("expected repeat 0 stitch 4, got repeat %d stitch %d", session.CurrentGroupRepeat, session.CurrentStitchCount)
+	}
+}
+
+func TestComputeProgress_GradedPattern(t *testing.T) {
+	pattern := gradedPattern()
+	session := newSession()
+	session.SizeIndex = 1
+
+	progress := ComputeProgress(session, pattern)
+	if progress.TotalStitches != 10 {
+		t.Fatalf("expected 10 total stitches for size M, got %d", progress.TotalStitches)
+	}
+	if progress.SizeName != "M" {
+		t.Fatalf("expected size name M, got %q", progress.SizeName)
+	}
+	if progress.GroupRepeatInfo != "Repeat 1 of 2" {
+		t.Fatalf("expected 'Repeat 1 of 2', got %q", progress.GroupRepeatInfo)
+	}
+}
+
+// piecedPattern creates a pattern of two pieces: "Body: 2 sc" made once, then
+// "Arm (make 2): Rnd 1: 1 sc, Rnd 2: 2 sc".
+func piecedPattern() *domain.Pattern {
+	return &domain.Pattern{
+		Pieces: []domain.PatternPiece{
+			{Name: "Body", MakeCount: 1},
+			{Name: "Arm", MakeCount: 2},
+		},
+		PatternStitches: []domain.PatternStitch{
+			{ID: 1, Abbreviation: "sc", Name: "Single Crochet"},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Body", PieceIndex: 0, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1},
+			}},
+			{Label: "Rnd 1", PieceIndex: 1, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 1, RepeatCount: 1},
+			}},
+			{Label: "Rnd 2", PieceIndex: 1, RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1},
+			}},
+		},
+	}
+}
+
+func TestNavigateForward_PieceMakeCount(t *testing.T) {
+	pattern := piecedPattern()
+	session := newSession()
+
+	// Body (2) + Arm (3) twice = 8 stitches.
+	for i := range 7 {
+		if NavigateForward(session, pattern) {
+			t.Fatalf("completed too early at stitch %d", i+1)
+		}
+		if i == 4 {
+			// 2 body + 3 of the first arm: now on Rnd 1 of the second arm.
+			if session.CurrentGroupIndex != 1 || session.CurrentPieceCopy != 1 {
+				t.Fatalf("expected group 1 copy 1, got group %d copy %d", session.CurrentGroupIndex, session.CurrentPieceCopy)
+			}
+		}
+	}
+	if !NavigateForward(session, pattern) {
+		t.Fatal("expected completion after both arms")
+	}
+}
+
+func TestNavigateBackward_AcrossPieceCopies(t *testing.T) {
+	pattern := piecedPattern()
+	session := newSession()
+
+	for range 5 {
+		NavigateForward(session, pattern)
+	}
+	// Back from the start of the second arm to the last stitch of the first.
+	NavigateBackward(session, pattern)
+	if session.CurrentGroupIndex != 2 || session.CurrentPieceCopy != 0 || session.CurrentStitchCount != 1 {
+		t.Fatalf("expected group 2 copy 0 stitch 1, got group %d copy %d stitch %d",
+			session.CurrentGroupIndex, session.CurrentPieceCopy, session.CurrentStitchCount)
+	}
+
+	// Back to the start of the pattern.
+	for range 4 {
+		NavigateBackward(session, pattern)
+	}
+	if NavigateBackward(session, pattern) {
+		t.Fatal("expected to be at the beginning")
+	}
+}
+
+func TestComputeProgress_PieceCopies(t *testing.T) {
+	pattern := piecedPattern()
+	session := newSession()
+
+	// 2 body + 3 first arm + 1 of the second arm's Rnd 2.
+	for range 6 {
+		NavigateForward(session, pattern)
+	}
+
+	progress := ComputeProgress(session, pattern)
+	if progress.TotalStitches != 8 {
+		t.Fatalf("expected 8 total stitches, got %d", progress.TotalStitches)
+	}
+	if progress.CompletedStitches != 6 {
+		t.Fatalf("expected 6 completed stitches, got %d", progress.CompletedStitches)
+	}
+	if progress.PieceInfo != "Arm 2 of 2" {
+		t.Fatalf("expected 'Arm 2 of 2', got %q", progress.PieceInfo)
+	}
+	rnd1 := progress.Groups[1]
+	if rnd1.CompletedInGroup != 2 || rnd1.TotalInGroup != 2 || rnd1.Piece != "Arm" {
+		t.Fatalf("expected Rnd 1 of Arm 2/2, got %+v", rnd1)
+	}
+	rnd2 := progress.Groups[2]
+	if rnd2.CompletedInGroup != 2 || rnd2.TotalInGroup != 4 {
+		t.Fatalf("expected Rnd 2 2/4, got %d/%d", rnd2.CompletedInGroup, rnd2.TotalInGroup)
+	}
+}
+
+func colorworkPattern() *domain.Pattern {
+	b, c := 1, 2
+	return &domain.Pattern{
+		Colors: []domain.PatternColor{
+			{Label: "A", Name: "Cream"},
+			{Label: "B", Name: "Navy"},
+			{Label: "C", Name: "Rust"},
+		},
+		PatternStitches: []domain.PatternStitch{
+			{ID: 1, Abbreviation: "sc", Name: "Single Crochet"},
+		},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Row 1", RepeatCount: 2, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 2, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 1, RepeatCount: 1, ColorIndex: &b},
+				{PatternStitchID: 1, Count: 1, RepeatCount: 1, ColorIndex: &c},
+			}},
+		},
+	}
+}
+
+func TestComputeProgress_WorkingColor(t *testing.T) {
+	pattern := colorworkPattern()
+	session := newSession()
+
+	// The pattern starts in the first color, with no change coming yet.
+	progress := ComputeProgress(session, pattern)
+	if progress.CurrentColor == nil || progress.CurrentColor.Label != "A" {
+		t.Fatalf("expected to start in A, got %+v", progress.CurrentColor)
+	}
+	if progress.NextColor != nil {
+		t.Fatalf("expected no color change yet, got %+v", progress.NextColor)
+	}
+
+	// Last stitch before B: warn about the change.
+	NavigateForward(session, pattern)
+	progress = ComputeProgress(session, pattern)
+	if progress.NextColor == nil || progress.NextColor.Label != "B" {
+		t.Fatalf("expected a change to B next, got %+v", progress.NextColor)
+	}
+
+	NavigateForward(session, pattern)
+	progress = ComputeProgress(session, pattern)
+	if progress.CurrentColor.Label != "B" {
+		t.Fatalf("expected to be working in B, got %q", progress.CurrentColor.Label)
+	}
+
+	// The second repeat starts in C, carried over from the end of the first.
+	NavigateForward(session, pattern)
+	NavigateForward(session, pattern)
+	progress = ComputeProgress(session, pattern)
+	if session.CurrentGroupRepeat != 1 || progress.CurrentColor.Label != "C" {
+		t.Fatalf("expected repeat 1 in C, got repeat %d in %q", session.CurrentGroupRepeat, progress.CurrentColor.Label)
+	}
+}
+
+func TestComputeProgress_NoPalette(t *testing.T) {
+	progress := ComputeProgress(newSession(), piecedPattern())
+	if progress.CurrentColor != nil || progress.NextColor != nil {
+		t.Fatalf("expected no colors without a palette, got %+v, %+v", progress.CurrentColor, progress.NextColor)
+	}
+}
+
+func TestRemapSession(t *testing.T) {
+	before := multiGroupPattern()
+
+	// A round inserted before the session's keeps it on the same round.
+	after := multiGroupPattern()
+	after.InstructionGroups = append([]domain.InstructionGroup{{
+		Label:         "Round 0",
+		RepeatCount:   1,
+		StitchEntries: []domain.StitchEntry{{PatternStitchID: 1, Count: 1, RepeatCount: 1}},
+	}}, after.InstructionGroups...)
+	session := &domain.WorkSession{CurrentGroupIndex: 1, CurrentStitchRepeat: 2}
+	if remapSession(session, before, after) {
+		t.Fatal("expected the session to keep its place")
+	}
+	if session.CurrentGroupIndex != 2 || session.CurrentStitchRepeat != 2 {
+		t.Errorf("position = group %d repeat %d, want group 2 repeat 2", session.CurrentGroupIndex, session.CurrentStitchRepeat)
+	}
+
+	// A place the changed round no longer has restarts it.
+	after = multiGroupPattern()
+	after.InstructionGroups[1].StitchEntries[0].RepeatCount = 2
+	session = &domain.WorkSession{CurrentGroupIndex: 1, CurrentStitchRepeat: 2}
+	if !remapSession(session, before, after) {
+		t.Fatal("expected the session to restart its round")
+	}
+	if session.CurrentGroupIndex != 1 || session.CurrentStitchRepeat != 0 {
+		t.Errorf("position = group %d repeat %d, want the start of group 1", session.CurrentGroupIndex, session.CurrentStitchRepeat)
+	}
+
+	// A removed round moves the session to the nearest one left.
+	after = multiGroupPattern()
+	after.InstructionGroups = after.InstructionGroups[:1]
+	session = &domain.WorkSession{CurrentGroupIndex: 1, CurrentStitchRepeat: 1}
+	if !remapSession(session, before, after) {
+		t.Fatal("expected the session to restart")
+	}
+	if session.CurrentGroupIndex != 0 || session.CurrentStitchRepeat != 0 {
+		t.Errorf("position = group %d repeat %d, want the start of group 0", session.CurrentGroupIndex, session.CurrentStitchRepeat)
+	}
+}
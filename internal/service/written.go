@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+// placementWords expands the abbreviations commonly used in stitch
+// placements, e.g. "in next sp" or "in 2nd ch from hook".
+var placementWords = map[string]string{
+	"st":     "stitch",
+	"sts":    "stitches",
+	"sp":     "space",
+	"sps":    "spaces",
+	"ch":     "chain",
+	"ch-sp":  "chain space",
+	"ch-sps": "chain spaces",
+	"lp":     "loop",
+	"lps":    "loops",
+	"rnd":    "round",
+	"beg":    "beginning",
+	"prev":   "previous",
+	"rem":    "remaining",
+	"tog":    "together",
+	"MR":     "magic ring",
+}
+
+// placementPrepositions are followed by "the" in a written placement unless
+// a determiner or a number already follows them.
+var placementPrepositions = map[string]bool{
+	"in": true, "into": true, "around": true, "through": true, "under": true, "from": true,
+}
+
+var placementDeterminers = map[string]bool{
+	"the": true, "a": true, "an": true, "each": true, "every": true, "all": true, "both": true,
+	"this": true, "that": true, "these": true, "those": true, "it": true, "them": true, "any": true,
+}
+
+// writtenRenderer writes a group's stitches out in full for beginners.
+type writtenRenderer struct {
+	byID   map[int64]domain.PatternStitch
+	colors *colorTracker
+}
+
+// renderWrittenGroup renders a group as sentences, e.g.
+// "Rnd 3: Work 1 single crochet, then 1 increase. Repeat the sequence 6
+// times, for a total of 18 stitches." colors may be nil when color changes
+// aren't rendered.
+func renderWrittenGroup(g *domain.InstructionGroup, byID map[int64]domain.PatternStitch, colors *colorTracker) string {
+	if len(g.StitchEntries) == 0 {
+		return g.Label + ":"
+	}
+
+	label := g.Label
+	if g.RepeatCount > 1 || len(g.SizeRepeatCounts) > 0 {
+		label = fmt.Sprintf("%s (work this %s times)", g.Label, GradedText(g.RepeatCount, g.SizeRepeatCounts))
+	}
+
+	total := gradedProducedText(g, byID)
+	if g.ExpectedCount != nil {
+		total = GradedText(*g.ExpectedCount, g.SizeExpectedCounts)
+	}
+	unit := "stitches"
+	if total == "1" {
+		unit = "stitch"
+	}
+
+	w := &writtenRenderer{byID: byID, colors: colors}
+	sentences := w.sentences(buildEntryTree(g))
+	if g.ExpectedCount != nil || groupProducesStitches(g, byID) {
+		sentences[len(sentences)-1] += fmt.Sprintf(", for a total of %s %s", total, unit)
+	}
+	return fmt.Sprintf("%s: %s.", label, strings.Join(sentences, ". "))
+}
+
+// sentences writes a group's top-level nodes. Runs of plain entries share a
+// sentence; each repeat block gets its own, followed by how often to repeat
+// it, so it's always clear where the repeated sequence starts.
+func (w *writtenRenderer) sentences(nodes []entryNode) []string {
+	var sentences, run []string
+	flush := func() {
+		if len(run) > 0 {
+			sentences = append(sentences, "Work "+strings.Join(run, ", then "))
+			run = nil
+		}
+	}
+
+	for _, n := range nodes {
+		if n.block == nil {
+			run = append(run, w.entry(n.entry))
+			continue
+		}
+		flush()
+		s := "Work " + strings.Join(w.clauses(n.children), ", then ")
+		if n.block.IntoStitch != "" {
+			s += ", all " + w.placement(n.block.IntoStitch)
+		}
+		sentences = append(sentences, s)
+		if n.block.RepeatCount > 1 {
+			sentences = append(sentences, fmt.Sprintf("Repeat the sequence %d times", n.block.RepeatCount))
+		}
+	}
+	flush()
+	return sentences
+}
+
+// clauses writes the nodes inside a repeat block. Nested blocks are
+// bracketed, e.g. "(1 single crochet, then 1 increase) 3 times".
+func (w *writtenRenderer) clauses(nodes []entryNode) []string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if n.block == nil {
+			parts = append(parts, w.entry(n.entry))
+			continue
+		}
+		inner := strings.Join(w.clauses(n.children), ", then ")
+		if n.block.IntoStitch != "" {
+			inner += ", all " + w.placement(n.block.IntoStitch)
+		}
+		part := "(" + inner + ")"
+		if n.block.RepeatCount > 1 {
+			part += fmt.Sprintf(" %d times", n.block.RepeatCount)
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// entry writes one stitch entry, e.g. "6 single crochet in the magic ring".
+// Actions that make no stitches, like a magic ring, aren't counted.
+func (w *writtenRenderer) entry(e *domain.StitchEntry) string {
+	var sb strings.Builder
+	if c := w.colors.change(e); c != nil {
+		fmt.Fprintf(&sb, "in color %s", c.Label)
+		if c.Name != "" {
+			fmt.Fprintf(&sb, " (%s)", c.Name)
+		}
+		sb.WriteString(", ")
+	}
+
+	ps, ok := w.byID[e.PatternStitchID]
+	name := "?"
+	if ok {
+		name = writtenStitchName(ps)
+	}
+	if e.Count == 1 && len(e.SizeCounts) == 0 && ok && ps.Consumes == 0 && ps.Produces == 0 {
+		sb.WriteString(name)
+	} else {
+		fmt.Fprintf(&sb, "%s %s", GradedText(e.Count, e.SizeCounts), name)
+	}
+
+	if e.IntoStitch != "" {
+		sb.WriteString(" " + w.placement(e.IntoStitch))
+	}
+	if e.RepeatCount > 1 || len(e.SizeRepeatCounts) > 0 {
+		fmt.Fprintf(&sb, ", %s times", GradedText(e.RepeatCount, e.SizeRepeatCounts))
+	}
+	return sb.String()
+}
+
+// placement writes a stitch placement such as "in next sp" out in full:
+// "in the next space". Abbreviations of the pattern's own stitches are
+// replaced with their names.
+func (w *writtenRenderer) placement(into string) string {
+	names := make(map[string]string, len(w.byID))
+	for _, ps := range w.byID {
+		if ps.Name != "" {
+			names[ps.Abbreviation] = writtenStitchName(ps)
+		}
+	}
+
+	words := strings.Fields(into)
+	var out []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if i+1 < len(words) {
+			if name, ok := names[word+" "+words[i+1]]; ok {
+				word = name
+				i++
+			}
+		}
+		if name, ok := names[word]; ok {
+			word = name
+		} else if expanded, ok := placementWords[word]; ok {
+			word = expanded
+		}
+		out = append(out, word)
+
+		if placementPrepositions[strings.ToLower(words[i])] && i+1 < len(words) {
+			next := strings.ToLower(words[i+1])
+			if _, err := strconv.Atoi(next); err != nil && !placementDeterminers[next] {
+				out = append(out, "the")
+			}
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// writtenStitchName returns a stitch's name for use mid-sentence, with
+// capitalized words lowercased but acronyms kept: "Crab Stitch (Reverse DC)"
+// becomes "crab stitch (reverse DC)". Stitches without a name fall back to
+// their abbreviation.
+func writtenStitchName(ps domain.PatternStitch) string {
+	if ps.Name == "" {
+		return ps.Abbreviation
+	}
+	words := strings.Split(ps.Name, " ")
+	for i, word := range words {
+		if isCapitalized(word) {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isCapitalized reports whether a word's only capital letter is its first
+// letter, ignoring leading punctuation like "(".
+func isCapitalized(word string) bool {
+	seenLetter := false
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if seenLetter && unicode.IsUpper(r) {
+			return false
+		}
+		if !seenLetter && !unicode.IsUpper(r) {
+			return false
+		}
+		seenLetter = true
+	}
+	return seenLetter
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/msomdec/stitch-map-2/internal/domain"
+)
+
+func TestParseTextStyle(t *testing.T) {
+	tests := map[string]TextStyle{
+		"written":  TextStyleWritten,
+		"notation": TextStyleNotation,
+		"":         TextStyleNotation,
+		"fancy":    TextStyleNotation,
+	}
+	for in, want := range tests {
+		if got := ParseTextStyle(in); got != want {
+			t.Errorf("ParseTextStyle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderPatternTextStyle_Written(t *testing.T) {
+	colorB := 1
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		Colors:          []domain.PatternColor{{Label: "A"}, {Label: "B", Name: "Cream"}},
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Rnd 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 4, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 6, RepeatCount: 1, IntoStitch: "in MR"},
+			}},
+			{Label: "Rnd 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 1, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 5, Count: 1, RepeatCount: 1},
+			}, RepeatBlocks: []domain.RepeatBlock{
+				{StartEntry: 0, EndEntry: 1, RepeatCount: 6, Bracket: domain.BracketAsterisk},
+			}},
+			{Label: "Rnd 3", RepeatCount: 2, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 3, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 2, Count: 2, RepeatCount: 1, ColorIndex: &colorB},
+				{PatternStitchID: 6, Count: 1, RepeatCount: 1, IntoStitch: "in first st"},
+			}},
+		},
+	}
+
+	got := RenderPatternTextStyle(pattern, TextStyleWritten)
+	want := "Rnd 1: Work magic ring, then 6 single crochet in the magic ring, for a total of 6 stitches.\n" +
+		"Rnd 2: Work 1 single crochet, then 1 increase. Repeat the sequence 6 times, for a total of 18 stitches.\n" +
+		"Rnd 3 (work this 2 times): Work 1 chain, then in color B (Cream), 2 double crochet, then 1 slip stitch in the first stitch, for a total of 4 stitches."
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	if got := RenderPatternTextStyle(pattern, TextStyleNotation); got != RenderPatternText(pattern) {
+		t.Errorf("notation style = %q, want RenderPatternText output", got)
+	}
+}
+
+func TestRenderPatternTextStyle_WrittenNestedBlocks(t *testing.T) {
+	pattern := &domain.Pattern{
+		PatternStitches: testPatternStitches(),
+		InstructionGroups: []domain.InstructionGroup{
+			{Label: "Row 2", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+				{PatternStitchID: 3, Count: 1, RepeatCount: 1},
+				{PatternStitchID: 2, Count: 2, RepeatCount: 1},
+				{PatternStitchID: 3, Count: 2, RepeatCount: 1},
+				{PatternStitchID: 2, Count: 2, RepeatCount: 1},
+				{PatternStitchID: 1, Count: 1, RepeatCount: 3, SizeRepeatCounts: []int{3, 4}},
+			}, RepeatBlocks: []domain.RepeatBlock{
+				{StartEntry: 1, EndEntry: 4, RepeatCount: 2, Bracket: domain.BracketAsterisk},
+				{StartEntry: 1, EndEntry: 3, RepeatCount: 1, Bracket: domain.BracketSquare, IntoStitch: "in next ch-sp"},
+			}},
+		},
+	}
+
+	got := RenderPatternTextStyle(pattern, TextStyleWritten)
+	want := "Row 2: Work 1 chain. " +
+		"Work (2 double crochet, then 2 chain, then 2 double crochet, all in the next chain space), then 1 single crochet, 3 (4) times. " +
+		"Repeat the sequence 2 times, for a total of 19 (21) stitches."
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRenderGroupTextStyle_Written(t *testing.T) {
+	g := &domain.InstructionGroup{Label: "Row 1", RepeatCount: 1, StitchEntries: []domain.StitchEntry{
+		{PatternStitchID: 6, Count: 1, RepeatCount: 1, IntoStitch: "in 2nd ch from hook"},
+	}}
+	got := RenderGroupTextStyle(g, testPatternStitches(), TextStyleWritten)
+	want := "Row 1: Work 1 slip stitch in the 2nd chain from the hook, for a total of 1 stitch."
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	g.StitchEntries[0].PatternStitchID = 99
+	if got, want := RenderGroupTextStyle(g, testPatternStitches(), TextStyleWritten), "Row 1: Work 1 ? in the 2nd chain from the hook, for a total of 1 stitch."; got != want {
+		t.Errorf("unknown stitch: expected %q, got %q", want, got)
+	}
+	if got := RenderGroupTextStyle(nil, testPatternStitches(), TextStyleWritten); got != "" {
+		t.Errorf("nil group = %q, want empty", got)
+	}
+}
+
+func TestWrittenStitchName(t *testing.T) {
+	tests := map[string]string{
+		"Single Crochet":               "single crochet",
+		"Crab Stitch (Reverse DC)":     "crab stitch (reverse DC)",
+		"Increase (2 stitches in one)": "increase (2 stitches in one)",
+		"V-Stitch":                     "V-Stitch",
+	}
+	for name, want := range tests {
+		if got := writtenStitchName(domain.PatternStitch{Name: name}); got != want {
+			t.Errorf("writtenStitchName(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := writtenStitchName(domain.PatternStitch{Abbreviation: "xst"}); got != "xst" {
+		t.Errorf("unnamed stitch = %q, want its abbreviation", got)
+	}
+}
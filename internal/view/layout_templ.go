@@ -42,12 +42,12 @@ func Layout(title string, displayName string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, " - StitchMap</title><link rel=\"stylesheet\" href=\"https://cdn.jsdelivr.net/npm/bulma@1.0.2/css/bulma.min.css\"><script type=\"module\" src=\"https://cdn.jsdelivr.net/gh/starfederation/datastar@1.0.0-RC.7/bundles/datastar.js\"></script><style>\n\t\t\t\tinput[type=number]::-webkit-inner-spin-button,\n\t\t\t\tinput[type=number]::-webkit-outer-spin-button {\n\t\t\t\t\t-webkit-appearance: none;\n\t\t\t\t\tmargin: 0;\n\t\t\t\t}\n\t\t\t\tinput[type=number] {\n\t\t\t\t\t-moz-appearance: textfield;\n\t\t\t\t}\n\t\t\t\t/* Hide remove-part button when there's only one part */\n\t\t\t\t#pattern-parts > .box:only-child .remove-part-btn {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t}\n\t\t\t\t/* Hide remove-entry button when there's only one entry */\n\t\t\t\t[id^=\"entries-\"] > [id^=\"entry-\"]:only-child .remove-entry-btn {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t}\n\t\t\t\t/* Form inside flex containers — makes form invisible to layout */\n\t\t\t\t.form-contents { display: contents; }\n\t\t\t\t/* Pattern text preview */\n\t\t\t\t.pattern-text {\n\t\t\t\t\twhite-space: pre-wrap;\n\t\t\t\t\tbackground: #f5f5f5;\n\t\t\t\t\tpadding: 1rem;\n\t\t\t\t\tborder-radius: 4px;\n\t\t\t\t\tfont-family: monospace;\n\t\t\t\t\tfont-size: 0.85rem;\n\t\t\t\t}\n\t\t\t\t/* Card footer button — removes default button chrome */\n\t\t\t\t.card-footer-button {\n\t\t\t\t\tborder: none;\n\t\t\t\t\tbackground: none;\n\t\t\t\t\tcursor: pointer;\n\t\t\t\t}\n\t\t\t\t/* Absolutely-positioned close/remove button inside a .box */\n\t\t\t\t.box-close-btn {\n\t\t\t\t\tposition: absolute;\n\t\t\t\t\ttop: 0.75rem;\n\t\t\t\t\tright: 0.75rem;\n\t\t\t\t}\n\t\t\t\t/* Work session current-part tag highlight */\n\t\t\t\t.tag-current {\n\t\t\t\t\tborder: 2px solid hsl(171, 100%, 41%);\n\t\t\t\t\tfont-weight: bold;\n\t\t\t\t}\n\t\t\t\t/* Work session stitch display layout */\n\t\t\t\t.stitch-display-row { gap: 2rem; }\n\t\t\t\t.stitch-context { min-width: 80px; }\n\t\t\t</style></head><body><nav class=\"navbar is-primary\" role=\"navigation\" aria-label=\"main navigation\" data-signals=\"{navOpen: false}\"><div class=\"navbar-brand\"><a class=\"navbar-item has-text-weight-bold\" href=\"/\" aria-label=\"StitchMap home\">StitchMap</a> <a role=\"button\" class=\"navbar-burger\" aria-label=\"menu\" aria-expanded=\"false\" data-target=\"navbarMenu\" data-on:click=\"$navOpen = !$navOpen\" data-class:is-active=\"$navOpen\"><span aria-hidden=\"true\"></span> <span aria-hidden=\"true\"></span> <span aria-hidden=\"true\"></span></a></div><div id=\"navbarMenu\" class=\"navbar-menu\" data-class:is-active=\"$navOpen\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, " - StitchMap</title><link rel=\"stylesheet\" href=\"https://cdn.jsdelivr.net/npm/bulma@1.0.2/css/bulma.min.css\"><script type=\"module\" src=\"https://cdn.jsdelivr.net/gh/starfederation/datastar@1.0.0-RC.7/bundles/datastar.js\"></script><style>\n\t\t\t\tinput[type=number]::-webkit-inner-spin-button,\n\t\t\t\tinput[type=number]::-webkit-outer-spin-button {\n\t\t\t\t\t-webkit-appearance: none;\n\t\t\t\t\tmargin: 0;\n\t\t\t\t}\n\t\t\t\tinput[type=number] {\n\t\t\t\t\t-moz-appearance: textfield;\n\t\t\t\t}\n\t\t\t\t/* Hide remove-part button when there's only one part */\n\t\t\t\t#pattern-parts > .box:only-child .remove-part-btn {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t}\n\t\t\t\t/* Hide remove-entry button when there's only one entry */\n\t\t\t\t[id^=\"entries-\"] > [id^=\"entry-\"]:only-child .remove-entry-btn {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t}\n\t\t\t\t/* Number editor piece rows so parts can refer to them by piece # */\n\t\t\t\t#pattern-pieces { counter-reset: piece; }\n\t\t\t\t#pattern-pieces .piece-number::before {\n\t\t\t\t\tcounter-increment: piece;\n\t\t\t\t\tcontent: counter(piece);\n\t\t\t\t}\n\t\t\t\t/* Yarn color swatch shown next to a palette label */\n\t\t\t\t.color-swatch {\n\t\t\t\t\tdisplay: inline-block;\n\t\t\t\t\twidth: 0.9em;\n\t\t\t\t\theight: 0.9em;\n\t\t\t\t\tborder: 1px solid #dbdbdb;\n\t\t\t\t\tborder-radius: 2px;\n\t\t\t\t\tvertical-align: middle;\n\t\t\t\t}\n\t\t\t\t/* Form inside flex containers — makes form invisible to layout */\n\t\t\t\t.form-contents { display: contents; }\n\t\t\t\t/* Pattern text preview */\n\t\t\t\t.pattern-text {\n\t\t\t\t\twhite-space: pre-wrap;\n\t\t\t\t\tbackground: #f5f5f5;\n\t\t\t\t\tpadding: 1rem;\n\t\t\t\t\tborder-radius: 4px;\n\t\t\t\t\tfont-family: monospace;\n\t\t\t\t\tfont-size: 0.85rem;\n\t\t\t\t}\n\t\t\t\t/* Symbol chart — scales down to the box, never up */\n\t\t\t\t.symbol-chart img {\n\t\t\t\t\twidth: auto;\n\t\t\t\t\tmax-width: 100%;\n\t\t\t\t\tmax-height: 40rem;\n\t\t\t\t\tmargin: 0 auto;\n\t\t\t\t}\n\t\t\t\t/* Card footer button — removes default button chrome */\n\t\t\t\t.card-footer-button {\n\t\t\t\t\tborder: none;\n\t\t\t\t\tbackground: none;\n\t\t\t\t\tcursor: pointer;\n\t\t\t\t}\n\t\t\t\t/* Absolutely-positioned close/remove button inside a .box */\n\t\t\t\t.box-close-btn {\n\t\t\t\t\tposition: absolute;\n\t\t\t\t\ttop: 0.75rem;\n\t\t\t\t\tright: 0.75rem;\n\t\t\t\t}\n\t\t\t\t/* Work session current-part tag highlight */\n\t\t\t\t.tag-current {\n\t\t\t\t\tborder: 2px solid hsl(171, 100%, 41%);\n\t\t\t\t\tfont-weight: bold;\n\t\t\t\t}\n\t\t\t\t/* Work session stitch display layout */\n\t\t\t\t.stitch-display-row { gap: 2rem; }\n\t\t\t\t.stitch-context { min-width: 80px; }\n\t\t\t</style></head><body><nav class=\"navbar is-primary\" role=\"navigation\" aria-label=\"main navigation\" data-signals=\"{navOpen: false}\"><div class=\"navbar-brand\"><a class=\"navbar-item has-text-weight-bold\" href=\"/\" aria-label=\"StitchMap home\">StitchMap</a> <a role=\"button\" class=\"navbar-burger\" aria-label=\"menu\" aria-expanded=\"false\" data-target=\"navbarMenu\" data-on:click=\"$navOpen = !$navOpen\" data-class:is-active=\"$navOpen\"><span aria-hidden=\"true\"></span> <span aria-hidden=\"true\"></span> <span aria-hidden=\"true\"></span></a></div><div id=\"navbarMenu\" class=\"navbar-menu\" data-class:is-active=\"$navOpen\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if displayName != "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div class=\"navbar-start\" role=\"menubar\"><a class=\"navbar-item\" href=\"/dashboard\" role=\"menuitem\">Dashboard</a> <a class=\"navbar-item\" href=\"/patterns\" role=\"menuitem\">Patterns</a> <a class=\"navbar-item\" href=\"/stitches\" role=\"menuitem\">Stitch Library</a></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div class=\"navbar-start\" role=\"menubar\"><a class=\"navbar-item\" href=\"/dashboard\" role=\"menuitem\">Dashboard</a> <a class=\"navbar-item\" href=\"/patterns\" role=\"menuitem\">Patterns</a> <a class=\"navbar-item\" href=\"/collections\" role=\"menuitem\">Collections</a> <a class=\"navbar-item\" href=\"/gallery\" role=\"menuitem\">Gallery</a> <a class=\"navbar-item\" href=\"/stitches\" role=\"menuitem\">Stitch Library</a></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -64,13 +64,13 @@ func Layout(title string, displayName string) templ.Component {
 			var templ_7745c5c3_Var3 string
 			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(displayName)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/view/layout.templ`, Line: 94, Col: 64}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/view/layout.templ`, Line: 118, Col: 64}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</a><div class=\"navbar-dropdown is-right\" role=\"menu\"><form method=\"POST\" action=\"/logout\"><button class=\"navbar-item button is-ghost\" type=\"submit\" role=\"menuitem\">Logout</button></form></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</a><div class=\"navbar-dropdown is-right\" role=\"menu\"><a class=\"navbar-item\" href=\"/settings\" role=\"menuitem\">Settings</a><form method=\"POST\" action=\"/logout\"><button class=\"navbar-item button is-ghost\" type=\"submit\" role=\"menuitem\">Logout</button></form></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
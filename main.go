@@ -52,6 +52,21 @@ func main() {
 		bcryptCost = parsed
 	}
 
+	// Earlier versions kept per pattern for history and restore; 0 keeps all.
+	keepVersions := 50
+	if v := os.Getenv("PATTERN_VERSION_LIMIT"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			slog.Error("invalid PATTERN_VERSION_LIMIT", "error", err)
+			os.Exit(1)
+		}
+		if parsed < 0 {
+			slog.Error("PATTERN_VERSION_LIMIT must not be negative", "value", parsed)
+			os.Exit(1)
+		}
+		keepVersions = parsed
+	}
+
 	db, err := sqlite.New(dbPath)
 	if err != nil {
 		slog.Error("failed to open database", "error", err)
@@ -67,9 +82,9 @@ func main() {
 
 	authService := service.NewAuthService(db.Users(), jwtSecret, bcryptCost)
 	stitchService := service.NewStitchService(db.Stitches())
-	patternService := service.NewPatternService(db.Patterns(), db.Stitches())
+	patternService := service.NewPatternService(db.Patterns(), db.Stitches(), db.Versions(), db.Tags(), db.Collections(), keepVersions)
 	sessionService := service.NewWorkSessionService(db.Sessions(), db.Patterns())
-	imageService := service.NewImageService(db.PatternImages(), db.FileStore(), db.Patterns())
+	imageService := service.NewImageService(db.PatternImages(), db.FileStore(), db.Patterns(), service.SharedImageSigningKey(jwtSecret))
 	shareService := service.NewShareService(db.Shares(), db.Patterns(), db.Users())
 
 	// Seed predefined stitches (idempotent).